@@ -73,6 +73,7 @@ type RunConfig struct {
 	SecurityGroupIds                  []string                   `mapstructure:"security_group_ids"`
 	SourceAmi                         string                     `mapstructure:"source_ami"`
 	SourceAmiFilter                   AmiFilterOptions           `mapstructure:"source_ami_filter"`
+	SpotFallbackAttempts              int                        `mapstructure:"spot_fallback_attempts"`
 	SpotInstanceTypes                 []string                   `mapstructure:"spot_instance_types"`
 	SpotPrice                         string                     `mapstructure:"spot_price"`
 	SpotPriceAutoProduct              string                     `mapstructure:"spot_price_auto_product"`
@@ -161,6 +162,14 @@ func (c *RunConfig) Prepare(ctx *interpolate.Context) []error {
 		}
 	}
 
+	if c.SpotFallbackAttempts < 0 {
+		errs = append(errs, fmt.Errorf(
+			"spot_fallback_attempts must not be negative"))
+	} else if c.SpotFallbackAttempts > 0 && !c.IsSpotInstance() {
+		errs = append(errs, fmt.Errorf(
+			"spot_fallback_attempts should not be set when not requesting a spot instance"))
+	}
+
 	if c.UserData != "" && c.UserDataFile != "" {
 		errs = append(errs, fmt.Errorf("Only one of user_data or user_data_file can be specified."))
 	} else if c.UserDataFile != "" {
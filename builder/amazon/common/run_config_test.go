@@ -137,6 +137,26 @@ func TestRunConfigPrepare_SpotAuto(t *testing.T) {
 	}
 }
 
+func TestRunConfigPrepare_SpotFallbackAttempts(t *testing.T) {
+	c := testConfig()
+	c.SpotFallbackAttempts = 3
+	if err := c.Prepare(nil); len(err) != 1 {
+		t.Fatalf("Should error if spot_fallback_attempts is set without spot_price")
+	}
+
+	c = testConfig()
+	c.SpotPrice = "auto"
+	c.SpotFallbackAttempts = -1
+	if err := c.Prepare(nil); len(err) != 1 {
+		t.Fatalf("Should error if spot_fallback_attempts is negative")
+	}
+
+	c.SpotFallbackAttempts = 3
+	if err := c.Prepare(nil); len(err) != 0 {
+		t.Fatalf("err: %s", err)
+	}
+}
+
 func TestRunConfigPrepare_SSHPort(t *testing.T) {
 	c := testConfig()
 	c.Comm.SSHPort = 0
@@ -19,6 +19,9 @@ import (
 	"github.com/hashicorp/packer/template/interpolate"
 )
 
+// spotRetryDelay is the time to wait between failed spot requests.
+const spotRetryDelay = 30 * time.Second
+
 type StepRunSpotInstance struct {
 	AssociatePublicIpAddress          bool
 	BlockDevices                      BlockDevices
@@ -31,6 +34,7 @@ type StepRunSpotInstance struct {
 	InstanceInitiatedShutdownBehavior string
 	InstanceType                      string
 	SourceAMI                         string
+	SpotFallbackAttempts              int
 	SpotPrice                         string
 	SpotTags                          TagMap
 	SpotInstanceTypes                 []string
@@ -237,76 +241,58 @@ func (s *StepRunSpotInstance) Run(ctx context.Context, state multistep.StateBag)
 		overrides = append(overrides, &override)
 	}
 
-	createFleetInput := &ec2.CreateFleetInput{
-		LaunchTemplateConfigs: []*ec2.FleetLaunchTemplateConfigRequest{
-			{
-				LaunchTemplateSpecification: &ec2.FleetLaunchTemplateSpecificationRequest{
-					LaunchTemplateName: aws.String(launchTemplateName),
-					Version:            aws.String("1"),
-				},
-				Overrides: overrides,
-			},
-		},
-		ReplaceUnhealthyInstances: aws.Bool(false),
-		TargetCapacitySpecification: &ec2.TargetCapacitySpecificationRequest{
-			TotalTargetCapacity:       aws.Int64(1),
-			DefaultTargetCapacityType: aws.String("spot"),
-		},
-		Type: aws.String("instant"),
+	// Send the spot request, up to spot_fallback_attempts times when the
+	// request can't be fulfilled
+	attempts := s.SpotFallbackAttempts
+	if attempts < 1 {
+		attempts = 1
 	}
+	for i := 1; ; i++ {
+		instanceId, err = s.sendFleetRequest(ctx, ec2conn, ui, state,
+			fleetInput(launchTemplateName, "1", ec2.DefaultTargetCapacityTypeSpot, overrides))
+		if _, ok := err.(*fleetUnfulfilledError); !ok || i == attempts {
+			break
+		}
 
-	// Create the request for the spot instance.
-	req, createOutput := ec2conn.CreateFleetRequest(createFleetInput)
-	ui.Message(fmt.Sprintf("Sending spot request (%s)...", req.RequestID))
-
-	// Tag the spot instance request (not the eventual spot instance)
-	spotTags, err := s.SpotTags.EC2Tags(s.Ctx, *ec2conn.Config.Region, state)
-	if err != nil {
-		err := fmt.Errorf("Error generating tags for spot request: %s", err)
-		state.Put("error", err)
-		ui.Error(err.Error())
-		return multistep.ActionHalt
+		ui.Error(fmt.Sprintf("Spot request %d of %d failed: %s", i, attempts, err))
+		select {
+		case <-ctx.Done():
+			state.Put("error", ctx.Err())
+			return multistep.ActionHalt
+		case <-time.After(spotRetryDelay):
+		}
 	}
-	spotTags.Report(ui)
-
-	if len(spotTags) > 0 && s.SpotTags.IsSet() {
-		err = retry.Config{
-			Tries:       11,
-			ShouldRetry: func(error) bool { return false },
-			RetryDelay:  (&retry.Backoff{InitialBackoff: 200 * time.Millisecond, MaxBackoff: 30 * time.Second, Multiplier: 2}).Linear,
-		}.Run(ctx, func(ctx context.Context) error {
-			_, err := ec2conn.CreateTags(&ec2.CreateTagsInput{
-				Tags:      spotTags,
-				Resources: []*string{aws.String(req.RequestID)},
-			})
-			return err
+
+	// Launch an on-demand instance when none of the spot requests could be
+	// fulfilled
+	if _, ok := err.(*fleetUnfulfilledError); ok && s.SpotFallbackAttempts > 0 {
+		ui.Error(fmt.Sprintf("Spot request %d of %d failed: %s", attempts, attempts, err))
+		ui.Say("Falling back to an on-demand instance...")
+
+		// The launch template requests spot instances, so add a version
+		// of it without the market options
+		templateData.InstanceMarketOptions = nil
+		_, err = ec2conn.CreateLaunchTemplateVersion(&ec2.CreateLaunchTemplateVersionInput{
+			LaunchTemplateData: templateData,
+			LaunchTemplateName: aws.String(launchTemplateName),
+			VersionDescription: aws.String("template generated by packer for launching on-demand instances"),
 		})
 		if err != nil {
-			err := fmt.Errorf("Error tagging spot request: %s", err)
+			err := fmt.Errorf("Error creating launch template for on-demand instance: %s", err)
 			state.Put("error", err)
 			ui.Error(err.Error())
 			return multistep.ActionHalt
 		}
-	}
 
-	// Actually send the spot connection request.
-	err = req.Send()
-	if err != nil {
-		err := fmt.Errorf("Error waiting for spot request (%s) to become ready: %s", req.RequestID, err)
-		state.Put("error", err)
-		ui.Error(err.Error())
-		return multistep.ActionHalt
+		instanceId, err = s.sendFleetRequest(ctx, ec2conn, ui, state,
+			fleetInput(launchTemplateName, "2", ec2.DefaultTargetCapacityTypeOnDemand, overrides))
 	}
-
-	if len(createOutput.Errors) > 0 {
-		err := fmt.Errorf("error sending spot request: %s", *createOutput.Errors[0])
+	if err != nil {
 		state.Put("error", err)
 		ui.Error(err.Error())
 		return multistep.ActionHalt
 	}
 
-	instanceId = *createOutput.Instances[0].InstanceIds[0]
-
 	// Set the instance ID so that the cleanup works properly
 	s.instanceId = instanceId
 
@@ -448,3 +434,88 @@ func (s *StepRunSpotInstance) Cleanup(state multistep.StateBag) {
 		ui.Error(err.Error())
 	}
 }
+
+// fleetUnfulfilledError is returned by sendFleetRequest when EC2 couldn't
+// launch an instance, such as when there is no spot capacity at the price.
+type fleetUnfulfilledError struct {
+	Err *ec2.CreateFleetError
+}
+
+func (e *fleetUnfulfilledError) Error() string {
+	return fmt.Sprintf("%s: %s",
+		aws.StringValue(e.Err.ErrorCode), aws.StringValue(e.Err.ErrorMessage))
+}
+
+// fleetInput returns the request for an instant fleet of a single instance
+// of the given capacity type, "spot" or "on-demand", launched from the
+// version of the launch template.
+func fleetInput(launchTemplateName, version, capacityType string,
+	overrides []*ec2.FleetLaunchTemplateOverridesRequest) *ec2.CreateFleetInput {
+	return &ec2.CreateFleetInput{
+		LaunchTemplateConfigs: []*ec2.FleetLaunchTemplateConfigRequest{
+			{
+				LaunchTemplateSpecification: &ec2.FleetLaunchTemplateSpecificationRequest{
+					LaunchTemplateName: aws.String(launchTemplateName),
+					Version:            aws.String(version),
+				},
+				Overrides: overrides,
+			},
+		},
+		ReplaceUnhealthyInstances: aws.Bool(false),
+		TargetCapacitySpecification: &ec2.TargetCapacitySpecificationRequest{
+			TotalTargetCapacity:       aws.Int64(1),
+			DefaultTargetCapacityType: aws.String(capacityType),
+		},
+		Type: aws.String("instant"),
+	}
+}
+
+// sendFleetRequest creates the fleet and returns the ID of its instance.
+func (s *StepRunSpotInstance) sendFleetRequest(ctx context.Context, ec2conn *ec2.EC2,
+	ui packer.Ui, state multistep.StateBag, input *ec2.CreateFleetInput) (string, error) {
+	capacityType := *input.TargetCapacitySpecification.DefaultTargetCapacityType
+
+	req, createOutput := ec2conn.CreateFleetRequest(input)
+	ui.Message(fmt.Sprintf("Sending %s request (%s)...", capacityType, req.RequestID))
+
+	// Tag the spot instance request (not the eventual spot instance)
+	if capacityType == ec2.DefaultTargetCapacityTypeSpot {
+		spotTags, err := s.SpotTags.EC2Tags(s.Ctx, *ec2conn.Config.Region, state)
+		if err != nil {
+			return "", fmt.Errorf("Error generating tags for spot request: %s", err)
+		}
+		spotTags.Report(ui)
+
+		if len(spotTags) > 0 && s.SpotTags.IsSet() {
+			err = retry.Config{
+				Tries:       11,
+				ShouldRetry: func(error) bool { return false },
+				RetryDelay:  (&retry.Backoff{InitialBackoff: 200 * time.Millisecond, MaxBackoff: 30 * time.Second, Multiplier: 2}).Linear,
+			}.Run(ctx, func(ctx context.Context) error {
+				_, err := ec2conn.CreateTags(&ec2.CreateTagsInput{
+					Tags:      spotTags,
+					Resources: []*string{aws.String(req.RequestID)},
+				})
+				return err
+			})
+			if err != nil {
+				return "", fmt.Errorf("Error tagging spot request: %s", err)
+			}
+		}
+	}
+
+	// Actually send the request.
+	if err := req.Send(); err != nil {
+		return "", fmt.Errorf("Error waiting for %s request (%s) to become ready: %s",
+			capacityType, req.RequestID, err)
+	}
+
+	if len(createOutput.Instances) == 0 || len(createOutput.Instances[0].InstanceIds) == 0 {
+		if len(createOutput.Errors) > 0 {
+			return "", &fleetUnfulfilledError{createOutput.Errors[0]}
+		}
+		return "", fmt.Errorf("error sending %s request: no instance was launched", capacityType)
+	}
+
+	return *createOutput.Instances[0].InstanceIds[0], nil
+}
@@ -138,3 +138,26 @@ func TestCreateTemplateData(t *testing.T) {
 		t.Fatalf("Template shouldn't contain network interfaces object if subnet_id is unset.")
 	}
 }
+
+func TestFleetInput(t *testing.T) {
+	overrides := []*ec2.FleetLaunchTemplateOverridesRequest{
+		{InstanceType: aws.String("t2.micro")},
+	}
+	input := fleetInput("packer-fleet-launch-template", "2", ec2.DefaultTargetCapacityTypeOnDemand, overrides)
+
+	spec := input.LaunchTemplateConfigs[0].LaunchTemplateSpecification
+	if *spec.LaunchTemplateName != "packer-fleet-launch-template" || *spec.Version != "2" {
+		t.Fatalf("bad launch template: %#v", spec)
+	}
+	if len(input.LaunchTemplateConfigs[0].Overrides) != 1 {
+		t.Fatalf("bad overrides: %#v", input.LaunchTemplateConfigs[0].Overrides)
+	}
+
+	capacity := input.TargetCapacitySpecification
+	if *capacity.TotalTargetCapacity != 1 || *capacity.DefaultTargetCapacityType != "on-demand" {
+		t.Fatalf("bad target capacity: %#v", capacity)
+	}
+	if *input.Type != "instant" {
+		t.Fatalf("bad type: %s", *input.Type)
+	}
+}
@@ -210,6 +210,7 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			IamInstanceProfile:       b.config.IamInstanceProfile,
 			InstanceType:             b.config.InstanceType,
 			SourceAMI:                b.config.SourceAmi,
+			SpotFallbackAttempts:     b.config.SpotFallbackAttempts,
 			SpotPrice:                b.config.SpotPrice,
 			SpotInstanceTypes:        b.config.SpotInstanceTypes,
 			Tags:                     b.config.RunTags,
@@ -0,0 +1,37 @@
+package armimage
+
+import (
+	"fmt"
+	"log"
+	"os"
+)
+
+// Artifact is the image that the builder built.
+type Artifact struct {
+	image string
+}
+
+func (*Artifact) BuilderId() string {
+	return BuilderId
+}
+
+func (a *Artifact) Files() []string {
+	return []string{a.image}
+}
+
+func (a *Artifact) Id() string {
+	return "Image"
+}
+
+func (a *Artifact) String() string {
+	return fmt.Sprintf("Image: %s", a.image)
+}
+
+func (a *Artifact) State(name string) interface{} {
+	return nil
+}
+
+func (a *Artifact) Destroy() error {
+	log.Printf("Deleting %s", a.image)
+	return os.Remove(a.image)
+}
@@ -0,0 +1,118 @@
+package armimage
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+func testConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"iso_url":                 "https://downloads.raspberrypi.org/raspbian_lite/images/raspbian_lite-2019-09-30/2019-09-26-raspbian-buster-lite.zip",
+		"iso_checksum":            "a50237c2f718bd8d806b96df5b9d2174ce8b789eda1f03434ed2213bbca6c6ff",
+		"iso_checksum_type":       "sha256",
+		packer.BuildNameConfigKey: "foo",
+	}
+}
+
+func TestBuilder_ImplementsBuilder(t *testing.T) {
+	var raw interface{}
+	raw = &Builder{}
+	if _, ok := raw.(packer.Builder); !ok {
+		t.Fatalf("Builder should be a builder")
+	}
+}
+
+func TestBuilderPrepare_Defaults(t *testing.T) {
+	var b Builder
+	warns, err := b.Prepare(testConfig())
+	if len(warns) > 0 {
+		t.Fatalf("bad: %#v", warns)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	if !reflect.DeepEqual(b.config.ImageMounts, []string{"/boot", "/"}) {
+		t.Errorf("bad image mounts: %#v", b.config.ImageMounts)
+	}
+
+	if len(b.config.ChrootMounts) != 4 {
+		t.Errorf("bad chroot mounts: %#v", b.config.ChrootMounts)
+	}
+
+	if b.config.QemuBinary != "qemu-arm-static" {
+		t.Errorf("bad qemu binary: %s", b.config.QemuBinary)
+	}
+
+	if b.config.TargetExtension != "zip" {
+		t.Errorf("bad target extension: %s", b.config.TargetExtension)
+	}
+
+	if b.config.OutputDir != "output-foo" {
+		t.Errorf("bad output dir: %s", b.config.OutputDir)
+	}
+
+	if b.config.OutputFilename != "image.img" {
+		t.Errorf("bad output filename: %s", b.config.OutputFilename)
+	}
+}
+
+func TestBuilderPrepare_InvalidKey(t *testing.T) {
+	var b Builder
+	config := testConfig()
+
+	// Add a random key
+	config["i_should_not_be_valid"] = true
+	warns, err := b.Prepare(config)
+	if len(warns) > 0 {
+		t.Fatalf("bad: %#v", warns)
+	}
+	if err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestBuilderPrepare_ImageMounts(t *testing.T) {
+	cases := []struct {
+		mounts []string
+		valid  bool
+	}{
+		{[]string{"/"}, true},
+		{[]string{"/boot/firmware", "/"}, true},
+		{[]string{"", "/"}, true},
+		{[]string{"/boot"}, false},
+		{[]string{"boot", "/"}, false},
+	}
+
+	for _, tc := range cases {
+		var b Builder
+		config := testConfig()
+		config["image_mounts"] = tc.mounts
+
+		_, err := b.Prepare(config)
+		if tc.valid && err != nil {
+			t.Errorf("%#v: should not have error: %s", tc.mounts, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("%#v: should have error", tc.mounts)
+		}
+	}
+}
+
+func TestBuilderPrepare_ChrootMounts(t *testing.T) {
+	var b Builder
+	config := testConfig()
+
+	config["chroot_mounts"] = [][]string{
+		{"bind", "/dev"},
+	}
+	warns, err := b.Prepare(config)
+	if len(warns) > 0 {
+		t.Fatalf("bad: %#v", warns)
+	}
+	if err == nil {
+		t.Fatal("should have error")
+	}
+}
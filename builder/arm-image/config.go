@@ -0,0 +1,124 @@
+package armimage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/helper/config"
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/template/interpolate"
+)
+
+type Config struct {
+	common.PackerConfig `mapstructure:",squash"`
+	common.ISOConfig    `mapstructure:",squash"`
+
+	ImageMounts    []string   `mapstructure:"image_mounts"`
+	ImageSize      uint       `mapstructure:"image_size"`
+	ChrootMounts   [][]string `mapstructure:"chroot_mounts"`
+	CommandWrapper string     `mapstructure:"command_wrapper"`
+	QemuBinary     string     `mapstructure:"qemu_binary"`
+	OutputDir      string     `mapstructure:"output_directory"`
+	OutputFilename string     `mapstructure:"output_filename"`
+
+	ctx interpolate.Context
+}
+
+func NewConfig(raws ...interface{}) (*Config, []string, error) {
+	c := new(Config)
+
+	err := config.Decode(c, &config.DecodeOpts{
+		Interpolate:        true,
+		InterpolateContext: &c.ctx,
+		InterpolateFilter: &interpolate.RenderFilter{
+			Exclude: []string{
+				"command_wrapper",
+			},
+		},
+	}, raws...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var errs *packer.MultiError
+	var warnings []string
+
+	// Defaults
+	if len(c.ImageMounts) == 0 {
+		// The layout of Raspberry Pi images
+		c.ImageMounts = []string{"/boot", "/"}
+	}
+
+	if len(c.ChrootMounts) == 0 {
+		c.ChrootMounts = [][]string{
+			{"proc", "proc", "/proc"},
+			{"sysfs", "sysfs", "/sys"},
+			{"bind", "/dev", "/dev"},
+			{"devpts", "devpts", "/dev/pts"},
+		}
+	}
+
+	if c.CommandWrapper == "" {
+		c.CommandWrapper = "{{.Command}}"
+	}
+
+	if c.QemuBinary == "" {
+		c.QemuBinary = "qemu-arm-static"
+	}
+
+	if c.OutputDir == "" {
+		c.OutputDir = fmt.Sprintf("output-%s", c.PackerBuildName)
+	}
+
+	if c.OutputFilename == "" {
+		c.OutputFilename = "image.img"
+	}
+
+	if c.TargetExtension == "" {
+		c.TargetExtension = imageExtension(c.ISOUrls, c.RawSingleISOUrl)
+	}
+
+	isoWarnings, isoErrs := c.ISOConfig.Prepare(&c.ctx)
+	warnings = append(warnings, isoWarnings...)
+	errs = packer.MultiErrorAppend(errs, isoErrs...)
+
+	hasRoot := false
+	for _, mount := range c.ImageMounts {
+		if mount == "/" {
+			hasRoot = true
+		}
+		if mount != "" && !path.IsAbs(mount) {
+			errs = packer.MultiErrorAppend(
+				errs, fmt.Errorf("image_mounts entry %q must be an absolute path", mount))
+		}
+	}
+	if !hasRoot {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("image_mounts must mount a partition at /"))
+	}
+
+	for _, mounts := range c.ChrootMounts {
+		if len(mounts) != 3 {
+			errs = packer.MultiErrorAppend(
+				errs, errors.New("Each chroot_mounts entry should be three elements."))
+			break
+		}
+	}
+
+	if !c.PackerForce {
+		if _, err := os.Stat(c.OutputDir); err == nil {
+			errs = packer.MultiErrorAppend(
+				errs,
+				fmt.Errorf("Output directory '%s' already exists. It must not exist.", c.OutputDir))
+		}
+	}
+
+	if errs != nil && len(errs.Errors) > 0 {
+		return nil, warnings, errs
+	}
+
+	return c, warnings, nil
+}
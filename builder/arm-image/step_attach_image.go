@@ -0,0 +1,120 @@
+package armimage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/packer/builder/amazon/chroot"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// This step attaches the image to a loop device, with a device for each
+// of its partitions, and grows the root partition to the end of the image
+// if the image was grown.
+//
+// Uses:
+//   config *Config
+//   image_grown bool
+//   image_path string
+//   ui     packer.Ui
+//   wrappedCommand chroot.CommandWrapper
+//
+// Produces:
+//   device string - The loop device of the image, such as /dev/loop0
+type stepAttachImage struct {
+	device string
+}
+
+func (s *stepAttachImage) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	config := state.Get("config").(*Config)
+	imagePath := state.Get("image_path").(string)
+	ui := state.Get("ui").(packer.Ui)
+	wrappedCommand := state.Get("wrappedCommand").(chroot.CommandWrapper)
+
+	ui.Say("Attaching the image to a loop device...")
+	device, err := run(wrappedCommand, fmt.Sprintf("losetup --find --show --partscan %s", imagePath))
+	if err != nil {
+		err := fmt.Errorf("Error attaching the image: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	log.Printf("Loop device: %s", device)
+	s.device = device
+	state.Put("device", device)
+
+	if state.Get("image_grown").(bool) {
+		partition := rootPartition(config.ImageMounts)
+		ui.Say(fmt.Sprintf("Growing partition %d to the end of the image...", partition))
+		commands := []string{
+			fmt.Sprintf("parted -s %s resizepart %d 100%%", device, partition),
+			fmt.Sprintf("partx -u %s", device),
+			fmt.Sprintf("e2fsck -f -p %s", partitionDevice(device, partition)),
+			fmt.Sprintf("resize2fs %s", partitionDevice(device, partition)),
+		}
+		for _, command := range commands {
+			if _, err := run(wrappedCommand, command); err != nil {
+				err := fmt.Errorf("Error growing the root partition: %s", err)
+				state.Put("error", err)
+				ui.Error(err.Error())
+				return multistep.ActionHalt
+			}
+		}
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *stepAttachImage) Cleanup(state multistep.StateBag) {
+	if s.device == "" {
+		return
+	}
+
+	ui := state.Get("ui").(packer.Ui)
+	wrappedCommand := state.Get("wrappedCommand").(chroot.CommandWrapper)
+
+	ui.Say("Detaching the image from the loop device...")
+	if _, err := run(wrappedCommand, fmt.Sprintf("losetup -d %s", s.device)); err != nil {
+		ui.Error(fmt.Sprintf("Error detaching the image: %s", err))
+	}
+}
+
+// rootPartition returns the number of the partition that image_mounts
+// mounts at /.
+func rootPartition(mounts []string) int {
+	for i, mount := range mounts {
+		if mount == "/" {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// partitionDevice returns the device of a partition of a loop device, such
+// as /dev/loop0p2.
+func partitionDevice(device string, partition int) string {
+	return fmt.Sprintf("%sp%d", device, partition)
+}
+
+// run runs a command through the command wrapper, and returns its output.
+func run(wrappedCommand chroot.CommandWrapper, command string) (string, error) {
+	command, err := wrappedCommand(command)
+	if err != nil {
+		return "", fmt.Errorf("Error creating command: %s", err)
+	}
+
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	cmd := chroot.ShellCommand(command)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	log.Printf("Executing: %s", command)
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s\nStderr: %s", err, stderr.String())
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,194 @@
+package armimage
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+	"github.com/ulikunitz/xz"
+)
+
+// compressions are the extensions of the compressed base images that the
+// builder unpacks.
+var compressions = map[string]bool{
+	".gz":  true,
+	".xz":  true,
+	".zip": true,
+}
+
+// imageExtension returns the extension to cache the base image with, which
+// keeps the extension of its compression, such as img.xz.
+func imageExtension(urls []string, single string) string {
+	raw := single
+	if raw == "" && len(urls) > 0 {
+		raw = urls[0]
+	}
+	if u, err := url.Parse(raw); err == nil {
+		raw = u.Path
+	}
+
+	ext := path.Ext(raw)
+	if ext == "" {
+		return "img"
+	}
+	if compressions[ext] && path.Ext(strings.TrimSuffix(raw, ext)) == ".img" {
+		return "img" + ext
+	}
+	return ext[1:]
+}
+
+// This step copies the base image into the output directory, unpacking
+// it if it's compressed, and grows it to image_size.
+//
+// Uses:
+//   config *Config
+//   iso_path string
+//   ui     packer.Ui
+//
+// Produces:
+//   image_path string - The path of the image to build
+//   image_grown bool - Whether the image was grown
+type stepCopyImage struct{}
+
+func (s *stepCopyImage) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	config := state.Get("config").(*Config)
+	src := state.Get("iso_path").(string)
+	ui := state.Get("ui").(packer.Ui)
+
+	dst := filepath.Join(config.OutputDir, config.OutputFilename)
+	ui.Say(fmt.Sprintf("Copying the base image to %s...", dst))
+	if err := copyImage(src, dst); err != nil {
+		err := fmt.Errorf("Error copying the base image: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	state.Put("image_path", dst)
+
+	grown := false
+	if config.ImageSize > 0 {
+		fi, err := os.Stat(dst)
+		if err != nil {
+			err := fmt.Errorf("Error reading the image: %s", err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+
+		size := int64(config.ImageSize) * 1024 * 1024
+		if size > fi.Size() {
+			ui.Say(fmt.Sprintf("Growing the image to %d MB...", config.ImageSize))
+			if err := os.Truncate(dst, size); err != nil {
+				err := fmt.Errorf("Error growing the image: %s", err)
+				state.Put("error", err)
+				ui.Error(err.Error())
+				return multistep.ActionHalt
+			}
+			grown = true
+		} else {
+			ui.Message(fmt.Sprintf("The image is already larger than %d MB", config.ImageSize))
+		}
+	}
+	state.Put("image_grown", grown)
+
+	return multistep.ActionContinue
+}
+
+func (s *stepCopyImage) Cleanup(state multistep.StateBag) {}
+
+// copyImage copies the image at src to dst, unpacking it according to the
+// extension of src.
+func copyImage(src, dst string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader
+	switch filepath.Ext(src) {
+	case ".gz":
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	case ".xz":
+		r, err = xz.NewReader(f)
+		if err != nil {
+			return err
+		}
+	case ".zip":
+		rc, err := unzipImage(src)
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		r = rc
+	default:
+		r = f
+	}
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// unzipImage opens the image in the zip archive at src, which must be its
+// only .img file.
+func unzipImage(src string) (io.ReadCloser, error) {
+	zr, err := zip.OpenReader(src)
+	if err != nil {
+		return nil, err
+	}
+
+	var image *zip.File
+	for _, f := range zr.File {
+		if filepath.Ext(f.Name) != ".img" {
+			continue
+		}
+		if image != nil {
+			zr.Close()
+			return nil, fmt.Errorf("the archive has more than one image: %s and %s", image.Name, f.Name)
+		}
+		image = f
+	}
+	if image == nil {
+		zr.Close()
+		return nil, fmt.Errorf("the archive has no .img file")
+	}
+
+	rc, err := image.Open()
+	if err != nil {
+		zr.Close()
+		return nil, err
+	}
+	return &zipImage{rc, zr}, nil
+}
+
+// zipImage closes the archive along with the image in it.
+type zipImage struct {
+	io.ReadCloser
+	zr *zip.ReadCloser
+}
+
+func (z *zipImage) Close() error {
+	z.ReadCloser.Close()
+	return z.zr.Close()
+}
@@ -0,0 +1,107 @@
+package armimage
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestImageExtension(t *testing.T) {
+	cases := map[string]string{
+		"https://example.com/raspbian-buster-lite.zip":               "zip",
+		"https://example.com/ubuntu-18.04.3-arm64+raspi3.img.xz":     "img.xz",
+		"https://example.com/archlinux.img.gz?mirror=1":              "img.gz",
+		"file:///var/images/2019-09-26-raspbian-buster-lite.img":     "img",
+		"https://example.com/images/latest":                          "img",
+		"https://example.com/ubuntu-18.04.3-preinstalled-server.tar": "tar",
+	}
+
+	for url, expected := range cases {
+		if actual := imageExtension(nil, url); actual != expected {
+			t.Errorf("%s: expected %q, got %q", url, expected, actual)
+		}
+	}
+
+	if actual := imageExtension([]string{"https://example.com/a.img.xz"}, ""); actual != "img.xz" {
+		t.Errorf("bad extension of iso_urls: %s", actual)
+	}
+}
+
+func TestCopyImage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	contents := "image contents"
+
+	plain := filepath.Join(dir, "plain.img")
+	if err := ioutil.WriteFile(plain, []byte(contents), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	gz := filepath.Join(dir, "image.img.gz")
+	f, err := os.Create(gz)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	gzw := gzip.NewWriter(f)
+	gzw.Write([]byte(contents))
+	gzw.Close()
+	f.Close()
+
+	zipped := filepath.Join(dir, "image.zip")
+	f, err = os.Create(zipped)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	zw := zip.NewWriter(f)
+	readme, _ := zw.Create("README")
+	readme.Write([]byte("not the image"))
+	image, _ := zw.Create("raspbian.img")
+	image.Write([]byte(contents))
+	zw.Close()
+	f.Close()
+
+	for _, src := range []string{plain, gz, zipped} {
+		dst := filepath.Join(dir, "output.img")
+		if err := copyImage(src, dst); err != nil {
+			t.Fatalf("%s: err: %s", src, err)
+		}
+
+		actual, err := ioutil.ReadFile(dst)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if string(actual) != contents {
+			t.Errorf("%s: bad contents: %q", src, actual)
+		}
+	}
+}
+
+func TestCopyImage_ZipWithoutImage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	zipped := filepath.Join(dir, "image.zip")
+	f, err := os.Create(zipped)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	zw := zip.NewWriter(f)
+	readme, _ := zw.Create("README")
+	readme.Write([]byte("not the image"))
+	zw.Close()
+	f.Close()
+
+	if err := copyImage(zipped, filepath.Join(dir, "output.img")); err == nil {
+		t.Fatal("should have error")
+	}
+}
@@ -0,0 +1,73 @@
+package armimage
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/packer/builder/amazon/chroot"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// This step mounts the chroot_mounts, such as /proc, within the chroot.
+//
+// Uses:
+//   config *Config
+//   mount_path string
+//   ui     packer.Ui
+//   wrappedCommand chroot.CommandWrapper
+//
+// Produces:
+//   <nothing>
+type stepMountExtra struct {
+	mounts []string
+}
+
+func (s *stepMountExtra) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	config := state.Get("config").(*Config)
+	mountPath := state.Get("mount_path").(string)
+	ui := state.Get("ui").(packer.Ui)
+	wrappedCommand := state.Get("wrappedCommand").(chroot.CommandWrapper)
+
+	ui.Say("Mounting additional paths within the chroot...")
+	for _, mountInfo := range config.ChrootMounts {
+		innerPath := mountPath + mountInfo[2]
+
+		if err := os.MkdirAll(innerPath, 0755); err != nil {
+			err := fmt.Errorf("Error creating mount directory: %s", err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+
+		flags := "-t " + mountInfo[0]
+		if mountInfo[0] == "bind" {
+			flags = "--bind"
+		}
+
+		ui.Message(fmt.Sprintf("Mounting: %s", mountInfo[2]))
+		command := fmt.Sprintf("mount %s %s %s", flags, mountInfo[1], innerPath)
+		if _, err := run(wrappedCommand, command); err != nil {
+			err := fmt.Errorf("Error mounting: %s", err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+		s.mounts = append(s.mounts, innerPath)
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *stepMountExtra) Cleanup(state multistep.StateBag) {
+	ui := state.Get("ui").(packer.Ui)
+	wrappedCommand := state.Get("wrappedCommand").(chroot.CommandWrapper)
+
+	for i := len(s.mounts) - 1; i >= 0; i-- {
+		if _, err := run(wrappedCommand, fmt.Sprintf("umount %s", s.mounts[i])); err != nil {
+			ui.Error(fmt.Sprintf("Error unmounting %s: %s", s.mounts[i], err))
+			return
+		}
+	}
+}
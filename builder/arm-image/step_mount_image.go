@@ -0,0 +1,113 @@
+package armimage
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/hashicorp/packer/builder/amazon/chroot"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// This step mounts the partitions of the image where image_mounts says,
+// under a temporary directory.
+//
+// Uses:
+//   config *Config
+//   device string
+//   ui     packer.Ui
+//   wrappedCommand chroot.CommandWrapper
+//
+// Produces:
+//   mount_path string - The directory the root partition is mounted on
+type stepMountImage struct {
+	mountPath string
+	mounts    []string
+}
+
+func (s *stepMountImage) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	config := state.Get("config").(*Config)
+	device := state.Get("device").(string)
+	ui := state.Get("ui").(packer.Ui)
+	wrappedCommand := state.Get("wrappedCommand").(chroot.CommandWrapper)
+
+	mountPath, err := ioutil.TempDir("", "packer-arm-image")
+	if err != nil {
+		err := fmt.Errorf("Error creating mount directory: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	s.mountPath = mountPath
+
+	ui.Say("Mounting the partitions of the image...")
+	for _, m := range partitionMounts(config.ImageMounts) {
+		innerPath := filepath.Join(mountPath, m.path)
+		if err := os.MkdirAll(innerPath, 0755); err != nil {
+			err := fmt.Errorf("Error creating mount directory: %s", err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+
+		ui.Message(fmt.Sprintf("Mounting partition %d on %s", m.partition, m.path))
+		command := fmt.Sprintf("mount %s %s", partitionDevice(device, m.partition), innerPath)
+		if _, err := run(wrappedCommand, command); err != nil {
+			err := fmt.Errorf("Error mounting partition %d: %s", m.partition, err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+		s.mounts = append(s.mounts, innerPath)
+	}
+
+	state.Put("mount_path", mountPath)
+	return multistep.ActionContinue
+}
+
+func (s *stepMountImage) Cleanup(state multistep.StateBag) {
+	if s.mountPath == "" {
+		return
+	}
+
+	ui := state.Get("ui").(packer.Ui)
+	wrappedCommand := state.Get("wrappedCommand").(chroot.CommandWrapper)
+
+	ui.Say("Unmounting the partitions of the image...")
+	for i := len(s.mounts) - 1; i >= 0; i-- {
+		if _, err := run(wrappedCommand, fmt.Sprintf("umount %s", s.mounts[i])); err != nil {
+			ui.Error(fmt.Sprintf("Error unmounting %s: %s", s.mounts[i], err))
+			return
+		}
+	}
+
+	if err := os.Remove(s.mountPath); err != nil {
+		ui.Error(fmt.Sprintf("Error removing mount directory: %s", err))
+	}
+}
+
+type partitionMount struct {
+	partition int
+	path      string
+}
+
+// partitionMounts returns the partitions to mount, in the order to mount
+// them: parents before the directories in them.
+func partitionMounts(imageMounts []string) []partitionMount {
+	var mounts []partitionMount
+	for i, path := range imageMounts {
+		if path == "" {
+			continue
+		}
+		mounts = append(mounts, partitionMount{i + 1, path})
+	}
+
+	sort.SliceStable(mounts, func(i, j int) bool {
+		return len(mounts[i].path) < len(mounts[j].path)
+	})
+	return mounts
+}
@@ -0,0 +1,25 @@
+package armimage
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPartitionMounts(t *testing.T) {
+	mounts := partitionMounts([]string{"/boot", "", "/", "/boot/firmware"})
+	expected := []partitionMount{
+		{3, "/"},
+		{1, "/boot"},
+		{4, "/boot/firmware"},
+	}
+
+	if !reflect.DeepEqual(mounts, expected) {
+		t.Fatalf("bad: %#v", mounts)
+	}
+}
+
+func TestRootPartition(t *testing.T) {
+	if p := rootPartition([]string{"/boot", "/"}); p != 2 {
+		t.Fatalf("bad: %d", p)
+	}
+}
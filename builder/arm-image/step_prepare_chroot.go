@@ -0,0 +1,101 @@
+package armimage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/hashicorp/packer/builder/amazon/chroot"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// This step copies the qemu user emulator into the chroot, so that the
+// ARM binaries of the image run on the host through binfmt_misc, and the
+// resolv.conf of the host, so that provisioners can reach the network.
+// Both are removed, and the resolv.conf of the image restored, once the
+// provisioners have run.
+//
+// Uses:
+//   config *Config
+//   mount_path string
+//   ui     packer.Ui
+//   wrappedCommand chroot.CommandWrapper
+//
+// Produces:
+//   <nothing>
+type stepPrepareChroot struct {
+	qemuPath   string
+	resolvConf string
+	backup     bool
+}
+
+func (s *stepPrepareChroot) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	config := state.Get("config").(*Config)
+	mountPath := state.Get("mount_path").(string)
+	ui := state.Get("ui").(packer.Ui)
+	wrappedCommand := state.Get("wrappedCommand").(chroot.CommandWrapper)
+
+	qemuBinary, err := exec.LookPath(config.QemuBinary)
+	if err != nil {
+		err := fmt.Errorf("Error finding %s, is qemu-user-static installed? %s", config.QemuBinary, err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	ui.Say(fmt.Sprintf("Copying %s into the chroot...", qemuBinary))
+	qemuPath := filepath.Join(mountPath, "usr", "bin", filepath.Base(qemuBinary))
+	if _, err := run(wrappedCommand, fmt.Sprintf("cp %s %s", qemuBinary, qemuPath)); err != nil {
+		err := fmt.Errorf("Error copying qemu: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	s.qemuPath = qemuPath
+
+	resolvConf := filepath.Join(mountPath, "etc", "resolv.conf")
+	if _, err := os.Lstat(resolvConf); err == nil {
+		if _, err := run(wrappedCommand, fmt.Sprintf("mv %s %s.packer-bak", resolvConf, resolvConf)); err != nil {
+			err := fmt.Errorf("Error moving resolv.conf aside: %s", err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+		s.backup = true
+	}
+	s.resolvConf = resolvConf
+	if _, err := run(wrappedCommand, fmt.Sprintf("cp /etc/resolv.conf %s", resolvConf)); err != nil {
+		err := fmt.Errorf("Error copying resolv.conf: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *stepPrepareChroot) Cleanup(state multistep.StateBag) {
+	ui := state.Get("ui").(packer.Ui)
+	wrappedCommand := state.Get("wrappedCommand").(chroot.CommandWrapper)
+
+	if s.resolvConf != "" {
+		if _, err := run(wrappedCommand, fmt.Sprintf("rm -f %s", s.resolvConf)); err != nil {
+			ui.Error(fmt.Sprintf("Error removing resolv.conf: %s", err))
+		}
+		if s.backup {
+			command := fmt.Sprintf("mv %s.packer-bak %s", s.resolvConf, s.resolvConf)
+			if _, err := run(wrappedCommand, command); err != nil {
+				ui.Error(fmt.Sprintf("Error restoring resolv.conf: %s", err))
+			}
+		}
+	}
+
+	if s.qemuPath != "" {
+		if _, err := run(wrappedCommand, fmt.Sprintf("rm -f %s", s.qemuPath)); err != nil {
+			ui.Error(fmt.Sprintf("Error removing qemu: %s", err))
+		}
+	}
+}
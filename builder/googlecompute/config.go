@@ -52,6 +52,7 @@ type Config struct {
 	OmitExternalIP               bool                           `mapstructure:"omit_external_ip"`
 	OnHostMaintenance            string                         `mapstructure:"on_host_maintenance"`
 	Preemptible                  bool                           `mapstructure:"preemptible"`
+	PreemptibleFallbackAttempts  int                            `mapstructure:"preemptible_fallback_attempts"`
 	RawStateTimeout              string                         `mapstructure:"state_timeout"`
 	Region                       string                         `mapstructure:"region"`
 	Scopes                       []string                       `mapstructure:"scopes"`
@@ -115,6 +116,14 @@ func NewConfig(raws ...interface{}) (*Config, []string, error) {
 		errs = packer.MultiErrorAppend(errs,
 			errors.New("on_host_maintenance must be TERMINATE when using preemptible instances."))
 	}
+	if c.PreemptibleFallbackAttempts < 0 {
+		errs = packer.MultiErrorAppend(errs,
+			errors.New("preemptible_fallback_attempts must not be negative."))
+	} else if c.PreemptibleFallbackAttempts > 0 && !c.Preemptible {
+		errs = packer.MultiErrorAppend(errs,
+			errors.New("preemptible_fallback_attempts requires preemptible to be true."))
+	}
+
 	// Setting OnHostMaintenance Correct Defaults
 	//   "MIGRATE" : Possible and default if Preemptible is false
 	//   "TERMINATE": Required if Preemptible is true
@@ -136,6 +136,11 @@ func TestConfigPrepare(t *testing.T) {
 			"SO VERY BAD",
 			true,
 		},
+		{
+			"preemptible_fallback_attempts",
+			3,
+			true,
+		},
 		{
 			"image_family",
 			nil,
@@ -294,6 +299,49 @@ func TestConfigPrepareAccelerator(t *testing.T) {
 	}
 }
 
+func TestConfigPreparePreemptibleFallback(t *testing.T) {
+	cases := []struct {
+		Keys   []string
+		Values []interface{}
+		Err    bool
+	}{
+		{
+			[]string{"preemptible", "preemptible_fallback_attempts"},
+			[]interface{}{true, 3},
+			false,
+		},
+		{
+			[]string{"preemptible", "preemptible_fallback_attempts"},
+			[]interface{}{true, -1},
+			true,
+		},
+		{
+			[]string{"preemptible", "preemptible_fallback_attempts"},
+			[]interface{}{false, 3},
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		raw, tempfile := testConfig(t)
+		defer os.Remove(tempfile)
+
+		errStr := ""
+		for k := range tc.Keys {
+			errStr += fmt.Sprintf("%s:%v, ", tc.Keys[k], tc.Values[k])
+			raw[tc.Keys[k]] = tc.Values[k]
+		}
+
+		_, warns, errs := NewConfig(raw)
+
+		if tc.Err {
+			testConfigErr(t, warns, errs, strings.TrimRight(errStr, ", "))
+		} else {
+			testConfigOk(t, warns, errs)
+		}
+	}
+}
+
 func TestConfigPrepareServiceAccount(t *testing.T) {
 	cases := []struct {
 		Keys   []string
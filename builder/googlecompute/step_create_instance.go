@@ -5,12 +5,29 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/packer/helper/multistep"
 	"github.com/hashicorp/packer/packer"
 )
 
+// preemptibleRetryDelay is the time to wait between failed attempts to
+// create a preemptible instance.
+var preemptibleRetryDelay = 30 * time.Second
+
+var errCreateInstanceTimeout = errors.New("time out while waiting for instance to create")
+
+// isCapacityError returns whether err is GCE failing to create the instance
+// because the zone lacks the resources for it, which for a preemptible
+// instance may be only temporary. The operation errors only keep their
+// messages, so both the code and the message are checked.
+func isCapacityError(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "ZONE_RESOURCE_POOL_EXHAUSTED") ||
+		strings.Contains(msg, "does not have enough resources available")
+}
+
 // StepCreateInstance represents a Packer build step that creates GCE instances.
 type StepCreateInstance struct {
 	Debug bool
@@ -90,6 +107,23 @@ func getImage(c *Config, d Driver) (*Image, error) {
 	}
 }
 
+// createInstance creates the instance and waits for the creation to
+// complete.
+func createInstance(c *Config, d Driver, ui packer.Ui, instanceConfig *InstanceConfig) error {
+	errCh, err := d.RunInstance(instanceConfig)
+	if err != nil {
+		return err
+	}
+
+	ui.Message("Waiting for creation operation to complete...")
+	select {
+	case err = <-errCh:
+	case <-time.After(c.stateTimeout):
+		err = errCreateInstanceTimeout
+	}
+	return err
+}
+
 // Run executes the Packer build step that creates a GCE instance.
 func (s *StepCreateInstance) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
 	c := state.Get("config").(*Config)
@@ -114,7 +148,6 @@ func (s *StepCreateInstance) Run(ctx context.Context, state multistep.StateBag)
 	ui.Say("Creating instance...")
 	name := c.InstanceName
 
-	var errCh <-chan error
 	var metadata map[string]string
 	metadata, errs := c.createInstanceMetadata(sourceImage, string(c.Comm.SSHPublicKey))
 	if errs != nil {
@@ -123,7 +156,7 @@ func (s *StepCreateInstance) Run(ctx context.Context, state multistep.StateBag)
 		return multistep.ActionHalt
 	}
 
-	errCh, err = d.RunInstance(&InstanceConfig{
+	instanceConfig := &InstanceConfig{
 		AcceleratorType:              c.AcceleratorType,
 		AcceleratorCount:             c.AcceleratorCount,
 		Address:                      c.Address,
@@ -148,17 +181,38 @@ func (s *StepCreateInstance) Run(ctx context.Context, state multistep.StateBag)
 		Subnetwork:                   c.Subnetwork,
 		Tags:                         c.Tags,
 		Zone:                         c.Zone,
-	})
+	}
 
-	if err == nil {
-		ui.Message("Waiting for creation operation to complete...")
+	// Create the instance, up to preemptible_fallback_attempts times when
+	// the zone has no capacity for a preemptible instance
+	attempts := c.PreemptibleFallbackAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	for i := 1; ; i++ {
+		err = createInstance(c, d, ui, instanceConfig)
+		if err == nil || !isCapacityError(err) || i == attempts {
+			break
+		}
+
+		ui.Error(fmt.Sprintf("Creating preemptible instance %d of %d failed: %s", i, attempts, err))
 		select {
-		case err = <-errCh:
-		case <-time.After(c.stateTimeout):
-			err = errors.New("time out while waiting for instance to create")
+		case <-ctx.Done():
+			state.Put("error", ctx.Err())
+			return multistep.ActionHalt
+		case <-time.After(preemptibleRetryDelay):
 		}
 	}
 
+	// Create a standard instance when none of the preemptible instances
+	// could be created
+	if err != nil && isCapacityError(err) && c.PreemptibleFallbackAttempts > 0 {
+		ui.Error(fmt.Sprintf("Creating preemptible instance %d of %d failed: %s", attempts, attempts, err))
+		ui.Say("Falling back to a standard instance...")
+		instanceConfig.Preemptible = false
+		err = createInstance(c, d, ui, instanceConfig)
+	}
+
 	if err != nil {
 		err := fmt.Errorf("Error creating instance: %s", err)
 		state.Put("error", err)
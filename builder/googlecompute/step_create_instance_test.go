@@ -248,6 +248,75 @@ func TestStepCreateInstance_errorTimeout(t *testing.T) {
 	assert.False(t, ok, "State should not have an instance name.")
 }
 
+func TestStepCreateInstance_preemptibleFallback(t *testing.T) {
+	state := testState(t)
+	step := new(StepCreateInstance)
+	defer step.Cleanup(state)
+
+	defer func(delay time.Duration) { preemptibleRetryDelay = delay }(preemptibleRetryDelay)
+	preemptibleRetryDelay = time.Millisecond
+
+	state.Put("ssh_public_key", "key")
+
+	c := state.Get("config").(*Config)
+	c.Preemptible = true
+	c.PreemptibleFallbackAttempts = 2
+
+	d := state.Get("driver").(*DriverMock)
+	d.RunInstanceErr = errors.New("The zone 'projects/test-project/zones/us-central1-a' does not have enough resources available to fulfill the request.")
+	d.GetImageResult = StubImage("test-image", "test-project", []string{}, 100)
+
+	// run the step
+	assert.Equal(t, step.Run(context.Background(), state), multistep.ActionHalt, "Step should have failed and halted.")
+
+	// Verify that the last attempt was a standard instance
+	assert.False(t, d.RunInstanceConfig.Preemptible, "Last instance should not be preemptible.")
+	_, ok := state.GetOk("error")
+	assert.True(t, ok, "State should have an error.")
+	_, ok = state.GetOk("instance_name")
+	assert.False(t, ok, "State should not have an instance name.")
+}
+
+func TestStepCreateInstance_preemptibleOtherError(t *testing.T) {
+	state := testState(t)
+	step := new(StepCreateInstance)
+	defer step.Cleanup(state)
+
+	defer func(delay time.Duration) { preemptibleRetryDelay = delay }(preemptibleRetryDelay)
+	preemptibleRetryDelay = time.Millisecond
+
+	state.Put("ssh_public_key", "key")
+
+	c := state.Get("config").(*Config)
+	c.Preemptible = true
+	c.PreemptibleFallbackAttempts = 2
+
+	d := state.Get("driver").(*DriverMock)
+	d.RunInstanceErr = errors.New("Invalid value for field 'resource.machineType'")
+	d.GetImageResult = StubImage("test-image", "test-project", []string{}, 100)
+
+	// run the step
+	assert.Equal(t, step.Run(context.Background(), state), multistep.ActionHalt, "Step should have failed and halted.")
+
+	// Verify that the error didn't fall back to a standard instance
+	assert.True(t, d.RunInstanceConfig.Preemptible, "Instance should still be preemptible.")
+	_, ok := state.GetOk("error")
+	assert.True(t, ok, "State should have an error.")
+}
+
+func TestIsCapacityError(t *testing.T) {
+	cases := map[string]bool{
+		"ZONE_RESOURCE_POOL_EXHAUSTED":                                                 true,
+		"Error 503: ZONE_RESOURCE_POOL_EXHAUSTED_WITH_DETAILS":                         true,
+		"The zone 'us-central1-a' does not have enough resources available to fulfill": true,
+		"Invalid value for field 'resource.machineType'":                               false,
+		"time out while waiting for instance to create":                                false,
+	}
+	for msg, expected := range cases {
+		assert.Equal(t, expected, isCapacityError(errors.New(msg)), msg)
+	}
+}
+
 func TestStepCreateInstance_noServiceAccount(t *testing.T) {
 	state := testState(t)
 	step := new(StepCreateInstance)
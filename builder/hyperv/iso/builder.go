@@ -388,11 +388,13 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			ResultKey:    "iso_path",
 			Url:          b.config.ISOUrls,
 			Extension:    b.config.TargetExtension,
+			Headers:      b.config.ISOHTTPHeaders,
 			TargetPath:   b.config.TargetPath,
 		},
 		&common.StepCreateFloppy{
 			Files:       b.config.FloppyConfig.FloppyFiles,
 			Directories: b.config.FloppyConfig.FloppyDirectories,
+			Content:     b.config.FloppyConfig.FloppyContent,
 		},
 		&common.StepHTTPServer{
 			HTTPDir:     b.config.HTTPDir,
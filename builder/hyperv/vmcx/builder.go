@@ -423,6 +423,7 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 				ResultKey:    "iso_path",
 				Url:          b.config.ISOUrls,
 				Extension:    b.config.TargetExtension,
+				Headers:      b.config.ISOHTTPHeaders,
 				TargetPath:   b.config.TargetPath,
 			},
 		)
@@ -432,6 +433,7 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 		&common.StepCreateFloppy{
 			Files:       b.config.FloppyFiles,
 			Directories: b.config.FloppyConfig.FloppyDirectories,
+			Content:     b.config.FloppyConfig.FloppyContent,
 		},
 		&common.StepHTTPServer{
 			HTTPDir:     b.config.HTTPDir,
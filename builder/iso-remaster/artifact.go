@@ -0,0 +1,37 @@
+package isoremaster
+
+import (
+	"fmt"
+	"log"
+	"os"
+)
+
+// Artifact is the ISO that the builder built.
+type Artifact struct {
+	iso string
+}
+
+func (*Artifact) BuilderId() string {
+	return BuilderId
+}
+
+func (a *Artifact) Files() []string {
+	return []string{a.iso}
+}
+
+func (a *Artifact) Id() string {
+	return "ISO"
+}
+
+func (a *Artifact) String() string {
+	return fmt.Sprintf("ISO: %s", a.iso)
+}
+
+func (a *Artifact) State(name string) interface{} {
+	return nil
+}
+
+func (a *Artifact) Destroy() error {
+	log.Printf("Deleting %s", a.iso)
+	return os.Remove(a.iso)
+}
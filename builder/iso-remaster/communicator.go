@@ -0,0 +1,149 @@
+package isoremaster
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+// Communicator is a communicator that works on the extracted tree of an
+// ISO: the paths it uploads to and downloads from are relative to the
+// root of the tree, and it runs commands on the local machine, in the root
+// of the tree.
+type Communicator struct {
+	Root string
+}
+
+func (c *Communicator) Start(ctx context.Context, cmd *packer.RemoteCmd) error {
+	localCmd := exec.Command("/bin/sh", "-c", cmd.Command)
+	localCmd.Dir = c.Root
+	localCmd.Env = append(os.Environ(), "PACKER_ISO_ROOT="+c.Root)
+	localCmd.Stdin = cmd.Stdin
+	localCmd.Stdout = cmd.Stdout
+	localCmd.Stderr = cmd.Stderr
+	log.Printf("Executing in %s: %s", c.Root, cmd.Command)
+	if err := localCmd.Start(); err != nil {
+		return err
+	}
+
+	go func() {
+		exitStatus := 0
+		if err := localCmd.Wait(); err != nil {
+			if exitErr, ok := err.(*exec.ExitError); ok {
+				exitStatus = 1
+
+				// There is no process-independent way to get the REAL
+				// exit status so we just try to go deeper.
+				if status, ok := exitErr.Sys().(syscall.WaitStatus); ok {
+					exitStatus = status.ExitStatus()
+				}
+			}
+		}
+
+		log.Printf(
+			"Execution exited with '%d': '%s'",
+			exitStatus, cmd.Command)
+		cmd.SetExited(exitStatus)
+	}()
+
+	return nil
+}
+
+func (c *Communicator) Upload(dst string, r io.Reader, fi *os.FileInfo) error {
+	dst = c.path(dst)
+	log.Printf("Uploading to ISO tree: %s", dst)
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	mode := os.FileMode(0644)
+	if fi != nil {
+		mode = (*fi).Mode().Perm()
+	}
+
+	f, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (c *Communicator) UploadDir(dst string, src string, exclude []string) error {
+	// Like cp -R, a src with a trailing "/" copies the contents of src,
+	// and a src without copies src itself.
+	dst = c.path(dst)
+	if !strings.HasSuffix(src, "/") {
+		dst = filepath.Join(dst, filepath.Base(src))
+	}
+	log.Printf("Uploading directory '%s' to '%s'", src, dst)
+
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		for _, pattern := range exclude {
+			matched, _ := filepath.Match(pattern, rel)
+			if !matched {
+				matched, _ = filepath.Match(pattern, info.Name())
+			}
+			if matched {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+		}
+
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return c.Upload(strings.TrimPrefix(target, c.Root), f, &info)
+	})
+}
+
+func (c *Communicator) Download(src string, w io.Writer) error {
+	src = c.path(src)
+	log.Printf("Downloading from ISO tree: %s", src)
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(w, f)
+	return err
+}
+
+func (c *Communicator) DownloadDir(src string, dst string, exclude []string) error {
+	return fmt.Errorf("DownloadDir is not implemented for iso-remaster")
+}
+
+// path returns the local path of a path in the tree. Paths that would
+// escape the tree, such as ../etc, stay inside it.
+func (c *Communicator) path(p string) string {
+	return filepath.Join(c.Root, filepath.Clean("/"+p))
+}
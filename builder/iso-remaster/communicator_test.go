@@ -0,0 +1,117 @@
+package isoremaster
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+func TestCommunicator_ImplementsCommunicator(t *testing.T) {
+	var raw interface{}
+	raw = &Communicator{}
+	if _, ok := raw.(packer.Communicator); !ok {
+		t.Fatalf("Communicator should be a communicator")
+	}
+}
+
+func TestCommunicator_Upload(t *testing.T) {
+	root, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(root)
+
+	c := &Communicator{Root: root}
+	for _, dst := range []string{"/isolinux/ks.cfg", "../../ks2.cfg"} {
+		if err := c.Upload(dst, strings.NewReader("text"), nil); err != nil {
+			t.Fatalf("%s: err: %s", dst, err)
+		}
+	}
+
+	for _, path := range []string{"isolinux/ks.cfg", "ks2.cfg"} {
+		contents, err := ioutil.ReadFile(filepath.Join(root, path))
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if string(contents) != "text" {
+			t.Fatalf("bad contents of %s: %s", path, contents)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := c.Download("/isolinux/ks.cfg", &buf); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if buf.String() != "text" {
+		t.Fatalf("bad download: %s", buf.String())
+	}
+}
+
+func TestCommunicator_UploadDir(t *testing.T) {
+	root, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(root)
+
+	src, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(src)
+
+	if err := os.MkdirAll(filepath.Join(src, "Packages"), 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	for _, path := range []string{"Packages/foo.rpm", "Packages/foo.rpm.orig"} {
+		if err := ioutil.WriteFile(filepath.Join(src, path), []byte("rpm"), 0644); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+
+	c := &Communicator{Root: root}
+	if err := c.UploadDir("/", src+"/", []string{"*.orig"}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(root, "Packages", "foo.rpm")); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "Packages", "foo.rpm.orig")); err == nil {
+		t.Fatal("excluded file should not be uploaded")
+	}
+}
+
+func TestCommunicator_Start(t *testing.T) {
+	root, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(root)
+
+	var stdout bytes.Buffer
+	c := &Communicator{Root: root}
+	cmd := &packer.RemoteCmd{
+		Command: "touch ks.cfg && echo $PACKER_ISO_ROOT",
+		Stdout:  &stdout,
+	}
+	if err := c.Start(context.Background(), cmd); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if status := cmd.Wait(); status != 0 {
+		t.Fatalf("bad exit status: %d", status)
+	}
+
+	if strings.TrimSpace(stdout.String()) != root {
+		t.Fatalf("bad output: %s", stdout.String())
+	}
+	if _, err := os.Stat(filepath.Join(root, "ks.cfg")); err != nil {
+		t.Fatalf("the command should run in the root of the tree: %s", err)
+	}
+}
@@ -0,0 +1,75 @@
+package isoremaster
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/helper/config"
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/template/interpolate"
+)
+
+type Config struct {
+	common.PackerConfig `mapstructure:",squash"`
+	common.ISOConfig    `mapstructure:",squash"`
+
+	XorrisoPath    string `mapstructure:"xorriso_path"`
+	VolumeID       string `mapstructure:"volume_id"`
+	OutputDir      string `mapstructure:"output_directory"`
+	OutputFilename string `mapstructure:"output_filename"`
+
+	ctx interpolate.Context
+}
+
+func NewConfig(raws ...interface{}) (*Config, []string, error) {
+	c := new(Config)
+
+	err := config.Decode(c, &config.DecodeOpts{
+		Interpolate:        true,
+		InterpolateContext: &c.ctx,
+	}, raws...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var errs *packer.MultiError
+	var warnings []string
+
+	// Defaults
+	if c.XorrisoPath == "" {
+		c.XorrisoPath = "xorriso"
+	}
+
+	if c.OutputDir == "" {
+		c.OutputDir = fmt.Sprintf("output-%s", c.PackerBuildName)
+	}
+
+	if c.OutputFilename == "" {
+		c.OutputFilename = fmt.Sprintf("packer-%s.iso", c.PackerBuildName)
+	}
+
+	isoWarnings, isoErrs := c.ISOConfig.Prepare(&c.ctx)
+	warnings = append(warnings, isoWarnings...)
+	errs = packer.MultiErrorAppend(errs, isoErrs...)
+
+	// ISO 9660 volume IDs are at most 32 characters
+	if len(c.VolumeID) > 32 {
+		errs = packer.MultiErrorAppend(
+			errs, fmt.Errorf("volume_id must be at most 32 characters"))
+	}
+
+	if !c.PackerForce {
+		if _, err := os.Stat(c.OutputDir); err == nil {
+			errs = packer.MultiErrorAppend(
+				errs,
+				fmt.Errorf("Output directory '%s' already exists. It must not exist.", c.OutputDir))
+		}
+	}
+
+	if errs != nil && len(errs.Errors) > 0 {
+		return nil, warnings, errs
+	}
+
+	return c, warnings, nil
+}
@@ -0,0 +1,92 @@
+package isoremaster
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// This step builds the ISO from the source ISO and the provisioned tree.
+// xorriso replays the boot setup of the source ISO, such as its El Torito
+// boot images, EFI partition and isohybrid MBR, so that the ISO boots the
+// same way from a CD or a USB stick.
+//
+// Uses:
+//   config *Config
+//   iso_files []string
+//   iso_path string
+//   iso_root string
+//   ui     packer.Ui
+//
+// Produces:
+//   output_path string - The path of the built ISO
+type stepBuildISO struct{}
+
+func (s *stepBuildISO) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	config := state.Get("config").(*Config)
+	files := state.Get("iso_files").([]string)
+	isoPath := state.Get("iso_path").(string)
+	root := state.Get("iso_root").(string)
+	ui := state.Get("ui").(packer.Ui)
+
+	output := filepath.Join(config.OutputDir, config.OutputFilename)
+	ui.Say(fmt.Sprintf("Building ISO %s...", output))
+
+	args, err := buildArgs(config, isoPath, root, output, files)
+	if err != nil {
+		err := fmt.Errorf("Error comparing the ISO tree to the source ISO: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	if err := xorriso(config.XorrisoPath, args...); err != nil {
+		err := fmt.Errorf("Error building the ISO: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	state.Put("output_path", output)
+	return multistep.ActionContinue
+}
+
+func (s *stepBuildISO) Cleanup(state multistep.StateBag) {}
+
+// buildArgs returns the arguments of xorriso to build the ISO at output. It
+// maps the tree over the tree of the source ISO, and removes the files of
+// the source ISO that the provisioners removed from the tree.
+func buildArgs(config *Config, isoPath, root, output string, files []string) ([]string, error) {
+	args := []string{
+		"-indev", isoPath,
+		"-outdev", output,
+		"-boot_image", "any", "replay",
+	}
+	if config.VolumeID != "" {
+		args = append(args, "-volid", config.VolumeID)
+	}
+
+	// The files are in the order filepath.Walk found them, so a removed
+	// directory comes before its files, which -rm_r removes with it
+	removed := ""
+	for _, file := range files {
+		if removed != "" && strings.HasPrefix(file, removed+"/") {
+			continue
+		}
+
+		_, err := os.Lstat(filepath.Join(root, filepath.FromSlash(file)))
+		if os.IsNotExist(err) {
+			args = append(args, "-rm_r", file, "--")
+			removed = file
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	return append(args, "-map", root, "/"), nil
+}
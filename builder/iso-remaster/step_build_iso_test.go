@@ -0,0 +1,52 @@
+package isoremaster
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBuildArgs(t *testing.T) {
+	root, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(root)
+
+	if err := os.MkdirAll(filepath.Join(root, "isolinux"), 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(root, "isolinux", "isolinux.cfg"), nil, 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	files := []string{
+		"/EFI",
+		"/EFI/BOOT",
+		"/EFI/BOOT/grub.cfg",
+		"/TRANS.TBL",
+		"/isolinux",
+		"/isolinux/isolinux.cfg",
+	}
+	config := &Config{VolumeID: "CentOS 7 x86_64"}
+
+	args, err := buildArgs(config, "source.iso", root, "output.iso", files)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := []string{
+		"-indev", "source.iso",
+		"-outdev", "output.iso",
+		"-boot_image", "any", "replay",
+		"-volid", "CentOS 7 x86_64",
+		"-rm_r", "/EFI", "--",
+		"-rm_r", "/TRANS.TBL", "--",
+		"-map", root, "/",
+	}
+	if !reflect.DeepEqual(args, expected) {
+		t.Fatalf("bad: %#v", args)
+	}
+}
@@ -0,0 +1,94 @@
+package isoremaster
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/packer/tmp"
+)
+
+// This step extracts the tree of the source ISO into a temporary
+// directory, and makes it writable for the provisioners.
+//
+// Uses:
+//   config *Config
+//   iso_path string
+//   ui     packer.Ui
+//
+// Produces:
+//   iso_files []string - The paths of the files of the source ISO
+//   iso_root string - The directory of the extracted tree
+type stepExtractISO struct {
+	root string
+}
+
+func (s *stepExtractISO) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	config := state.Get("config").(*Config)
+	isoPath := state.Get("iso_path").(string)
+	ui := state.Get("ui").(packer.Ui)
+
+	root, err := tmp.Dir("packer-iso-remaster")
+	if err != nil {
+		err := fmt.Errorf("Error creating directory to extract the ISO into: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	s.root = root
+
+	ui.Say("Extracting the ISO...")
+	err = xorriso(config.XorrisoPath,
+		"-osirrox", "on",
+		"-indev", isoPath,
+		"-extract", "/", root)
+	if err != nil {
+		err := fmt.Errorf("Error extracting the ISO: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	// The files of an ISO are read-only
+	var files []string
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != root {
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			files = append(files, "/"+filepath.ToSlash(rel))
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return nil
+		}
+		return os.Chmod(path, info.Mode().Perm()|0200)
+	})
+	if err != nil {
+		err := fmt.Errorf("Error making the ISO tree writable: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	state.Put("iso_files", files)
+	state.Put("iso_root", root)
+	return multistep.ActionContinue
+}
+
+func (s *stepExtractISO) Cleanup(state multistep.StateBag) {
+	if s.root == "" {
+		return
+	}
+
+	ui := state.Get("ui").(packer.Ui)
+	if err := os.RemoveAll(s.root); err != nil {
+		ui.Error(fmt.Sprintf("Error removing the ISO tree: %s", err))
+	}
+}
@@ -0,0 +1,41 @@
+package isoremaster
+
+import (
+	"context"
+	"log"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// This step runs the provisioners against the extracted tree of the ISO.
+//
+// Uses:
+//   hook   packer.Hook
+//   iso_root string
+//   ui     packer.Ui
+//
+// Produces:
+//   <nothing>
+type stepProvision struct{}
+
+func (s *stepProvision) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	hook := state.Get("hook").(packer.Hook)
+	root := state.Get("iso_root").(string)
+	ui := state.Get("ui").(packer.Ui)
+
+	comm := &Communicator{
+		Root: root,
+	}
+
+	// Provision
+	log.Println("Running the provision hook")
+	if err := hook.Run(ctx, packer.HookProvision, ui, comm, nil); err != nil {
+		state.Put("error", err)
+		return multistep.ActionHalt
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *stepProvision) Cleanup(state multistep.StateBag) {}
@@ -0,0 +1,28 @@
+package isoremaster
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+)
+
+// xorriso runs xorriso with the arguments, and returns its error output
+// in the error when it fails.
+func xorriso(path string, args ...string) error {
+	var stderr bytes.Buffer
+
+	log.Printf("Executing xorriso: %#v", args)
+	cmd := exec.Command(path, args...)
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+
+	stderrString := strings.TrimSpace(stderr.String())
+	log.Printf("stderr: %s", stderrString)
+
+	if _, ok := err.(*exec.ExitError); ok {
+		err = fmt.Errorf("xorriso error: %s", stderrString)
+	}
+	return err
+}
@@ -0,0 +1,40 @@
+package kubevirt
+
+import (
+	"fmt"
+)
+
+// Artifact is the containerDisk image that the builder pushed.
+type Artifact struct {
+	// The name of the image, with its registry and tag
+	image string
+
+	// The ID of the image
+	imageID string
+}
+
+func (*Artifact) BuilderId() string {
+	return BuilderId
+}
+
+func (*Artifact) Files() []string {
+	return nil
+}
+
+func (a *Artifact) Id() string {
+	return a.image
+}
+
+func (a *Artifact) String() string {
+	return fmt.Sprintf("A containerDisk image was pushed: %s (ID: %s)", a.image, a.imageID)
+}
+
+func (a *Artifact) State(name string) interface{} {
+	return nil
+}
+
+// Destroy doesn't delete the image, since registries don't offer a common
+// way to.
+func (a *Artifact) Destroy() error {
+	return nil
+}
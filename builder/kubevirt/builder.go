@@ -0,0 +1,108 @@
+// The kubevirt package contains a packer.Builder implementation that builds
+// containerDisk images for KubeVirt, by provisioning a VM on a Kubernetes
+// cluster.
+package kubevirt
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/packer/builder/docker"
+	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/helper/communicator"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// The unique id for the builder
+const BuilderId = "packer.kubevirt"
+
+type Builder struct {
+	config Config
+	runner multistep.Runner
+}
+
+func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
+	c, warnings, errs := NewConfig(raws...)
+	if errs != nil {
+		return warnings, errs
+	}
+	b.config = *c
+
+	return nil, nil
+}
+
+func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (packer.Artifact, error) {
+	driver := &KubectlDriver{
+		KubectlPath: b.config.KubectlPath,
+		Kubeconfig:  b.config.Kubeconfig,
+		Context:     b.config.KubeContext,
+		Namespace:   b.config.Namespace,
+	}
+	if err := driver.Verify(); err != nil {
+		return nil, err
+	}
+
+	dockerDriver := &docker.DockerDriver{Ctx: &b.config.ctx, Ui: ui}
+	if err := dockerDriver.Verify(); err != nil {
+		return nil, err
+	}
+
+	// Set up the state
+	state := new(multistep.BasicStateBag)
+	state.Put("config", &b.config)
+	state.Put("docker_driver", dockerDriver)
+	state.Put("driver", driver)
+	state.Put("hook", hook)
+	state.Put("ui", ui)
+
+	// Build the steps
+	steps := []multistep.Step{
+		&stepCreateSSHKey{
+			Debug:        b.config.PackerDebug,
+			DebugKeyPath: fmt.Sprintf("kubevirt_%s.pem", b.config.PackerBuildName),
+		},
+		&stepCreateVM{},
+		&stepCreateService{},
+		&communicator.StepConnect{
+			Config:    &b.config.Comm,
+			Host:      commHost,
+			SSHConfig: b.config.Comm.SSHConfigFunc(),
+			SSHPort:   commPort,
+		},
+		&common.StepProvision{},
+		&common.StepCleanupTempKeys{
+			Comm: &b.config.Comm,
+		},
+		&stepStopVM{},
+		&stepExportDisk{},
+	}
+
+	// Run the steps
+	b.runner = common.NewRunner(steps, b.config.PackerConfig, ui)
+	b.runner.Run(ctx, state)
+
+	// If there was an error, return that
+	if rawErr, ok := state.GetOk("error"); ok {
+		return nil, rawErr.(error)
+	}
+
+	if _, ok := state.GetOk("image_id"); !ok {
+		return nil, nil
+	}
+
+	artifact := &Artifact{
+		image:   b.config.Image,
+		imageID: state.Get("image_id").(string),
+	}
+
+	return artifact, nil
+}
+
+func commHost(state multistep.StateBag) (string, error) {
+	return state.Get("ssh_host").(string), nil
+}
+
+func commPort(state multistep.StateBag) (int, error) {
+	return state.Get("ssh_port").(int), nil
+}
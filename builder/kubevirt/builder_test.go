@@ -0,0 +1,157 @@
+package kubevirt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+func testConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"source_image": "kubevirt/fedora-cloud-container-disk-demo",
+		"image":        "registry.example.com/vms/fedora:latest",
+		"ssh_username": "fedora",
+	}
+}
+
+func TestBuilder_ImplementsBuilder(t *testing.T) {
+	var raw interface{}
+	raw = &Builder{}
+	if _, ok := raw.(packer.Builder); !ok {
+		t.Fatalf("Builder should be a builder")
+	}
+}
+
+func TestBuilderPrepare_Defaults(t *testing.T) {
+	var b Builder
+	warnings, err := b.Prepare(testConfig())
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	if b.config.Namespace != "default" {
+		t.Errorf("bad namespace: %s", b.config.Namespace)
+	}
+
+	if b.config.DiskSize != "10Gi" {
+		t.Errorf("bad disk size: %s", b.config.DiskSize)
+	}
+
+	if !reName.MatchString(b.config.VMName) {
+		t.Errorf("bad vm name: %s", b.config.VMName)
+	}
+
+	if b.config.ServiceType != "LoadBalancer" {
+		t.Errorf("bad service type: %s", b.config.ServiceType)
+	}
+
+	if b.config.VMTimeout != 30*time.Minute {
+		t.Errorf("bad vm timeout: %s", b.config.VMTimeout)
+	}
+}
+
+func TestBuilderPrepare_InvalidKey(t *testing.T) {
+	var b Builder
+	config := testConfig()
+
+	// Add a random key
+	config["i_should_not_be_valid"] = true
+	warnings, err := b.Prepare(config)
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestBuilderPrepare_Source(t *testing.T) {
+	var b Builder
+	config := testConfig()
+
+	delete(config, "source_image")
+	if _, err := b.Prepare(config); err == nil {
+		t.Fatal("should have error")
+	}
+
+	config["source_pvc"] = "images/fedora"
+	b = Builder{}
+	if _, err := b.Prepare(config); err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+	if namespace, name := b.config.sourcePVC(); namespace != "images" || name != "fedora" {
+		t.Errorf("bad source pvc: %s/%s", namespace, name)
+	}
+
+	config["source_image"] = "kubevirt/fedora-cloud-container-disk-demo"
+	b = Builder{}
+	if _, err := b.Prepare(config); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestBuilderPrepare_VMName(t *testing.T) {
+	cases := map[string]bool{
+		"packer-fedora":  true,
+		"Packer-Fedora":  false,
+		"packer_fedora":  false,
+		"-packer":        false,
+		"packer-fedora-": false,
+	}
+
+	for name, valid := range cases {
+		var b Builder
+		config := testConfig()
+		config["vm_name"] = name
+		_, err := b.Prepare(config)
+		if valid && err != nil {
+			t.Errorf("%s: should not have error: %s", name, err)
+		}
+		if !valid && err == nil {
+			t.Errorf("%s: should have error", name)
+		}
+	}
+}
+
+func TestBuilderPrepare_ServiceType(t *testing.T) {
+	var b Builder
+	config := testConfig()
+
+	config["service_type"] = "NodePort"
+	if _, err := b.Prepare(config); err == nil {
+		t.Fatal("should have error")
+	}
+
+	config["node_address"] = "10.0.0.10"
+	b = Builder{}
+	if _, err := b.Prepare(config); err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	config["service_type"] = "ClusterIP"
+	b = Builder{}
+	if _, err := b.Prepare(config); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestBuilderPrepare_UserData(t *testing.T) {
+	var b Builder
+	config := testConfig()
+
+	// Nothing would authorize the temporary key
+	config["user_data"] = "#cloud-config"
+	if _, err := b.Prepare(config); err == nil {
+		t.Fatal("should have error")
+	}
+
+	config["ssh_password"] = "fedora"
+	b = Builder{}
+	if _, err := b.Prepare(config); err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+}
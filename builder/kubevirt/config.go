@@ -0,0 +1,197 @@
+package kubevirt
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/uuid"
+	"github.com/hashicorp/packer/helper/communicator"
+	"github.com/hashicorp/packer/helper/config"
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/template/interpolate"
+)
+
+// reName matches the names of Kubernetes objects, short enough to be
+// suffixed with the names of the objects that the builder creates for the
+// VM.
+var reName = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]{0,54}[a-z0-9])?$`)
+
+type Config struct {
+	common.PackerConfig `mapstructure:",squash"`
+	Comm                communicator.Config `mapstructure:",squash"`
+
+	KubectlPath string `mapstructure:"kubectl_path"`
+	Kubeconfig  string `mapstructure:"kubeconfig"`
+	KubeContext string `mapstructure:"kube_context"`
+	Namespace   string `mapstructure:"namespace"`
+
+	SourceImage  string `mapstructure:"source_image"`
+	SourcePVC    string `mapstructure:"source_pvc"`
+	DiskSize     string `mapstructure:"disk_size"`
+	StorageClass string `mapstructure:"storage_class"`
+
+	VMName       string `mapstructure:"vm_name"`
+	CpuCount     int    `mapstructure:"cpus"`
+	MemorySize   int    `mapstructure:"memory"`
+	UserData     string `mapstructure:"user_data"`
+	UserDataFile string `mapstructure:"user_data_file"`
+
+	ServiceType string `mapstructure:"service_type"`
+	NodeAddress string `mapstructure:"node_address"`
+
+	Image         string `mapstructure:"image"`
+	ExporterImage string `mapstructure:"exporter_image"`
+	LoginServer   string `mapstructure:"login_server"`
+	LoginUsername string `mapstructure:"login_username"`
+	LoginPassword string `mapstructure:"login_password"`
+
+	VMTimeout       time.Duration `mapstructure:"vm_timeout"`
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+
+	ctx interpolate.Context
+}
+
+func NewConfig(raws ...interface{}) (*Config, []string, error) {
+	c := new(Config)
+
+	err := config.Decode(c, &config.DecodeOpts{
+		Interpolate:        true,
+		InterpolateContext: &c.ctx,
+	}, raws...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Defaults
+	if c.KubectlPath == "" {
+		c.KubectlPath = "kubectl"
+	}
+
+	if c.Namespace == "" {
+		c.Namespace = "default"
+	}
+
+	if c.DiskSize == "" {
+		c.DiskSize = "10Gi"
+	}
+
+	if c.VMName == "" {
+		// Default to packer-[time-ordered-uuid]
+		c.VMName = fmt.Sprintf("packer-%s", uuid.TimeOrderedUUID())
+	}
+
+	if c.CpuCount < 1 {
+		c.CpuCount = 1
+	}
+
+	if c.MemorySize < 1 {
+		c.MemorySize = 2048
+	}
+
+	if c.ServiceType == "" {
+		c.ServiceType = "LoadBalancer"
+	}
+
+	if c.ExporterImage == "" {
+		c.ExporterImage = "busybox"
+	}
+
+	if c.VMTimeout == 0 {
+		// Importing the source disk may take long
+		c.VMTimeout = 30 * time.Minute
+	}
+
+	if c.ShutdownTimeout == 0 {
+		c.ShutdownTimeout = 5 * time.Minute
+	}
+
+	var errs *packer.MultiError
+	if es := c.Comm.Prepare(&c.ctx); len(es) > 0 {
+		errs = packer.MultiErrorAppend(errs, es...)
+	}
+
+	if c.Comm.Type != "ssh" {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("the kubevirt builder only supports the ssh communicator"))
+	}
+
+	if c.SourceImage == "" && c.SourcePVC == "" {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("one of source_image or source_pvc is required"))
+	} else if c.SourceImage != "" && c.SourcePVC != "" {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("only one of source_image or source_pvc can be specified"))
+	}
+
+	if !reName.MatchString(c.VMName) {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("vm_name must be at most 56 lowercase letters, digits or dashes"))
+	}
+
+	switch c.ServiceType {
+	case "LoadBalancer":
+	case "NodePort":
+		if c.NodeAddress == "" {
+			errs = packer.MultiErrorAppend(
+				errs, errors.New("node_address must be specified with a NodePort service"))
+		}
+	default:
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("service_type must be one of 'LoadBalancer' or 'NodePort'"))
+	}
+
+	if c.UserData != "" && c.UserDataFile != "" {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("only one of user_data or user_data_file can be specified"))
+	} else if c.UserDataFile != "" {
+		contents, err := ioutil.ReadFile(c.UserDataFile)
+		if err != nil {
+			errs = packer.MultiErrorAppend(
+				errs, fmt.Errorf("user_data_file not found: %s", c.UserDataFile))
+		}
+		c.UserData = string(contents)
+	}
+
+	// The temporary key is only authorized by the default user data
+	if c.UserData != "" && !c.hasSSHCredentials() {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("ssh_password or ssh_private_key_file must be specified with user_data"))
+	}
+
+	if c.Image == "" {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("image must be specified"))
+	}
+
+	if c.LoginUsername != "" && c.LoginPassword == "" {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("login_password must be specified with login_username"))
+	}
+
+	if errs != nil && len(errs.Errors) > 0 {
+		return nil, nil, errs
+	}
+
+	packer.LogSecretFilter.Set(c.LoginPassword)
+	return c, nil, nil
+}
+
+// hasSSHCredentials returns whether the user gave the credentials to log
+// into the VM, rather than the builder creating a temporary key.
+func (c *Config) hasSSHCredentials() bool {
+	return c.Comm.SSHPassword != "" || c.Comm.SSHPrivateKeyFile != "" || c.Comm.SSHAgentAuth
+}
+
+// sourcePVC returns the namespace and the name of source_pvc, which may be
+// in another namespace, such as images/centos-7.
+func (c *Config) sourcePVC() (string, string) {
+	if i := strings.Index(c.SourcePVC, "/"); i != -1 {
+		return c.SourcePVC[:i], c.SourcePVC[i+1:]
+	}
+	return c.Namespace, c.SourcePVC
+}
@@ -0,0 +1,120 @@
+package kubevirt
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os/exec"
+	"strings"
+)
+
+// A driver is able to talk to a Kubernetes cluster with KubeVirt and
+// perform certain operations with it.
+type Driver interface {
+	// Apply creates or updates the objects of a manifest.
+	Apply(manifest []byte) error
+
+	// Delete deletes an object, such as vm/packer, and waits until it's
+	// gone. Objects that don't exist are ignored.
+	Delete(object string) error
+
+	// Patch merges the patch into an object.
+	Patch(object, patch string) error
+
+	// Get returns the value of a JSONPath expression of an object, or "" if
+	// the object doesn't exist.
+	Get(object, jsonPath string) (string, error)
+
+	// Exec runs a command in a pod, and copies its output to dst.
+	Exec(pod string, dst io.Writer, command ...string) error
+
+	// Verify checks to make sure that this driver should function
+	// properly. If there is any indication the driver can't function,
+	// this will return an error.
+	Verify() error
+}
+
+// KubectlDriver is a Driver that runs kubectl against the cluster of a
+// kubeconfig.
+type KubectlDriver struct {
+	KubectlPath string
+	Kubeconfig  string
+	Context     string
+	Namespace   string
+}
+
+func (d *KubectlDriver) Apply(manifest []byte) error {
+	log.Printf("Applying manifest:\n%s", manifest)
+	_, err := d.kubectl(bytes.NewReader(manifest), nil, "apply", "-f", "-")
+	return err
+}
+
+func (d *KubectlDriver) Delete(object string) error {
+	_, err := d.kubectl(nil, nil, "delete", object, "--ignore-not-found")
+	return err
+}
+
+func (d *KubectlDriver) Patch(object, patch string) error {
+	_, err := d.kubectl(nil, nil, "patch", object, "--type", "merge", "-p", patch)
+	return err
+}
+
+func (d *KubectlDriver) Get(object, jsonPath string) (string, error) {
+	return d.kubectl(nil, nil, "get", object, "--ignore-not-found",
+		"-o", fmt.Sprintf("jsonpath=%s", jsonPath))
+}
+
+func (d *KubectlDriver) Exec(pod string, dst io.Writer, command ...string) error {
+	args := append([]string{"exec", pod, "--"}, command...)
+	_, err := d.kubectl(nil, dst, args...)
+	return err
+}
+
+func (d *KubectlDriver) Verify() error {
+	if _, err := exec.LookPath(d.KubectlPath); err != nil {
+		return fmt.Errorf("kubectl not found: %s", err)
+	}
+	_, err := d.kubectl(nil, nil, "get", "crd", "virtualmachines.kubevirt.io")
+	return err
+}
+
+// kubectl runs kubectl with stdin, and returns its output, unless it is
+// copied to stdout.
+func (d *KubectlDriver) kubectl(stdin io.Reader, stdout io.Writer, args ...string) (string, error) {
+	var out, stderr bytes.Buffer
+	if stdout == nil {
+		stdout = &out
+	}
+
+	args = append(d.globalArgs(), args...)
+	log.Printf("Executing kubectl: %#v", args)
+	cmd := exec.Command(d.KubectlPath, args...)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+
+	stdoutString := strings.TrimSpace(out.String())
+	stderrString := strings.TrimSpace(stderr.String())
+
+	if _, ok := err.(*exec.ExitError); ok {
+		err = fmt.Errorf("kubectl error: %s", stderrString)
+	}
+
+	log.Printf("stdout: %s", stdoutString)
+	log.Printf("stderr: %s", stderrString)
+
+	return stdoutString, err
+}
+
+func (d *KubectlDriver) globalArgs() []string {
+	var args []string
+	if d.Kubeconfig != "" {
+		args = append(args, "--kubeconfig", d.Kubeconfig)
+	}
+	if d.Context != "" {
+		args = append(args, "--context", d.Context)
+	}
+	return append(args, "--namespace", d.Namespace)
+}
@@ -0,0 +1,31 @@
+package kubevirt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestKubectlDriver_Impl(t *testing.T) {
+	var _ Driver = new(KubectlDriver)
+}
+
+func TestKubectlDriver_globalArgs(t *testing.T) {
+	d := &KubectlDriver{Namespace: "default"}
+	if args := d.globalArgs(); !reflect.DeepEqual(args, []string{"--namespace", "default"}) {
+		t.Errorf("bad args: %#v", args)
+	}
+
+	d = &KubectlDriver{
+		Kubeconfig: "/home/packer/.kube/config",
+		Context:    "lab",
+		Namespace:  "images",
+	}
+	expected := []string{
+		"--kubeconfig", "/home/packer/.kube/config",
+		"--context", "lab",
+		"--namespace", "images",
+	}
+	if args := d.globalArgs(); !reflect.DeepEqual(args, expected) {
+		t.Errorf("bad args: %#v", args)
+	}
+}
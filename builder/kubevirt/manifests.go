@@ -0,0 +1,170 @@
+package kubevirt
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// buildLabel labels the VM, so that the service selects the pod that it
+// runs in.
+const buildLabel = "packer.io/build"
+
+// object is a Kubernetes object, which kubectl takes as JSON as well as
+// YAML.
+type object map[string]interface{}
+
+// diskName returns the name of the data volume of the VM, which holds its
+// disk.
+func diskName(c *Config) string {
+	return c.VMName + "-disk"
+}
+
+// vmManifest returns the manifest of the VM. Its disk is a data volume,
+// imported by CDI from the source image or cloned from the source PVC, so
+// that the changes of the provisioners outlive the VM. The data volume
+// belongs to the VM, and is deleted with it.
+func vmManifest(c *Config, userData string) ([]byte, error) {
+	source := object{
+		"registry": object{"url": "docker://" + c.SourceImage},
+	}
+	if c.SourcePVC != "" {
+		namespace, name := c.sourcePVC()
+		source = object{
+			"pvc": object{"namespace": namespace, "name": name},
+		}
+	}
+
+	pvc := object{
+		"accessModes": []string{"ReadWriteOnce"},
+		"resources": object{
+			"requests": object{"storage": c.DiskSize},
+		},
+	}
+	if c.StorageClass != "" {
+		pvc["storageClassName"] = c.StorageClass
+	}
+
+	labels := object{buildLabel: c.VMName}
+
+	vm := object{
+		"apiVersion": "kubevirt.io/v1",
+		"kind":       "VirtualMachine",
+		"metadata": object{
+			"name":   c.VMName,
+			"labels": labels,
+		},
+		"spec": object{
+			"runStrategy": "Always",
+			"dataVolumeTemplates": []object{{
+				"apiVersion": "cdi.kubevirt.io/v1beta1",
+				"kind":       "DataVolume",
+				"metadata":   object{"name": diskName(c)},
+				"spec": object{
+					"source": source,
+					"pvc":    pvc,
+				},
+			}},
+			"template": object{
+				"metadata": object{"labels": labels},
+				"spec": object{
+					// Stopping the VM shuts its guest down through ACPI
+					// for up to this long
+					"terminationGracePeriodSeconds": int(c.ShutdownTimeout.Seconds()),
+					"domain": object{
+						"cpu": object{"cores": c.CpuCount},
+						"resources": object{
+							"requests": object{"memory": fmt.Sprintf("%dMi", c.MemorySize)},
+						},
+						"devices": object{
+							"disks": []object{
+								{"name": "rootdisk", "disk": object{"bus": "virtio"}},
+								{"name": "cloudinit", "disk": object{"bus": "virtio"}},
+							},
+						},
+					},
+					"volumes": []object{
+						{"name": "rootdisk", "dataVolume": object{"name": diskName(c)}},
+						{"name": "cloudinit", "cloudInitNoCloud": object{"userData": userData}},
+					},
+				},
+			},
+		},
+	}
+
+	return json.MarshalIndent(vm, "", "  ")
+}
+
+// defaultUserData returns the cloud-init user data that authorizes the
+// temporary key for the SSH user.
+func defaultUserData(c *Config, publicKey string) string {
+	return "#cloud-config\n" +
+		"users:\n" +
+		"  - name: " + strconv.Quote(c.Comm.SSHUsername) + "\n" +
+		"    sudo: ALL=(ALL) NOPASSWD:ALL\n" +
+		"    ssh_authorized_keys:\n" +
+		"      - " + strconv.Quote(publicKey) + "\n"
+}
+
+// serviceName returns the name of the service to connect to the VM with.
+func serviceName(c *Config) string {
+	return c.VMName + "-ssh"
+}
+
+// serviceManifest returns the manifest of the service that exposes the SSH
+// port of the VM.
+func serviceManifest(c *Config) ([]byte, error) {
+	service := object{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"metadata":   object{"name": serviceName(c)},
+		"spec": object{
+			"type":     c.ServiceType,
+			"selector": object{buildLabel: c.VMName},
+			"ports": []object{{
+				"name":       "ssh",
+				"protocol":   "TCP",
+				"port":       c.Comm.SSHPort,
+				"targetPort": c.Comm.SSHPort,
+			}},
+		},
+	}
+
+	return json.MarshalIndent(service, "", "  ")
+}
+
+// exporterName returns the name of the pod that exports the disk.
+func exporterName(c *Config) string {
+	return c.VMName + "-export"
+}
+
+// exporterManifest returns the manifest of a pod that mounts the data
+// volume of the stopped VM at /disk, where CDI keeps the disk in disk.img,
+// so that it can be read with kubectl exec.
+func exporterManifest(c *Config) ([]byte, error) {
+	pod := object{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata":   object{"name": exporterName(c)},
+		"spec": object{
+			"restartPolicy": "Never",
+			"containers": []object{{
+				"name":    "export",
+				"image":   c.ExporterImage,
+				"command": []string{"sleep", "86400"},
+				"volumeMounts": []object{
+					{"name": "disk", "mountPath": "/disk", "readOnly": true},
+				},
+			}},
+			"volumes": []object{{
+				"name": "disk",
+				"persistentVolumeClaim": object{
+					"claimName": diskName(c),
+					"readOnly":  true,
+				},
+			}},
+		},
+	}
+
+	return json.MarshalIndent(pod, "", "  ")
+}
@@ -0,0 +1,140 @@
+package kubevirt
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/packer/helper/communicator"
+)
+
+func testManifestConfig() *Config {
+	return &Config{
+		Comm: communicator.Config{
+			SSHUsername: "fedora",
+			SSHPort:     22,
+		},
+		Namespace:       "default",
+		SourceImage:     "kubevirt/fedora-cloud-container-disk-demo",
+		DiskSize:        "10Gi",
+		VMName:          "packer-fedora",
+		CpuCount:        2,
+		MemorySize:      2048,
+		ServiceType:     "LoadBalancer",
+		ExporterImage:   "busybox",
+		ShutdownTimeout: 5 * time.Minute,
+	}
+}
+
+// get returns the value at the path of keys and indexes of a decoded
+// manifest.
+func get(v interface{}, path ...interface{}) interface{} {
+	for _, p := range path {
+		switch p := p.(type) {
+		case string:
+			v = v.(map[string]interface{})[p]
+		case int:
+			v = v.([]interface{})[p]
+		}
+	}
+	return v
+}
+
+func decode(t *testing.T, manifest []byte, err error) map[string]interface{} {
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	var v map[string]interface{}
+	if err := json.Unmarshal(manifest, &v); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	return v
+}
+
+func TestVMManifest(t *testing.T) {
+	c := testManifestConfig()
+	manifest, err := vmManifest(c, "#cloud-config")
+	vm := decode(t, manifest, err)
+
+	if version := get(vm, "apiVersion"); version != "kubevirt.io/v1" {
+		t.Errorf("bad apiVersion: %v", version)
+	}
+	if strategy := get(vm, "spec", "runStrategy"); strategy != "Always" {
+		t.Errorf("bad runStrategy: %v", strategy)
+	}
+	if running := get(vm, "spec", "running"); running != nil {
+		t.Errorf("running should not be set: %v", running)
+	}
+	if kind := get(vm, "spec", "dataVolumeTemplates", 0, "kind"); kind != "DataVolume" {
+		t.Errorf("bad data volume kind: %v", kind)
+	}
+	if version := get(vm, "spec", "dataVolumeTemplates", 0, "apiVersion"); version != "cdi.kubevirt.io/v1beta1" {
+		t.Errorf("bad data volume apiVersion: %v", version)
+	}
+	if source := get(vm, "spec", "dataVolumeTemplates", 0, "spec", "source", "registry", "url"); source != "docker://kubevirt/fedora-cloud-container-disk-demo" {
+		t.Errorf("bad source: %v", source)
+	}
+	if labels := get(vm, "spec", "template", "metadata", "labels", buildLabel); labels != "packer-fedora" {
+		t.Errorf("bad labels: %v", labels)
+	}
+	if memory := get(vm, "spec", "template", "spec", "domain", "resources", "requests", "memory"); memory != "2048Mi" {
+		t.Errorf("bad memory: %v", memory)
+	}
+	if grace := get(vm, "spec", "template", "spec", "terminationGracePeriodSeconds"); grace != 300.0 {
+		t.Errorf("bad grace period: %v", grace)
+	}
+	if volume := get(vm, "spec", "template", "spec", "volumes", 0, "dataVolume", "name"); volume != "packer-fedora-disk" {
+		t.Errorf("bad volume: %v", volume)
+	}
+
+	c.SourceImage = ""
+	c.SourcePVC = "images/fedora"
+	c.StorageClass = "fast"
+	manifest, err = vmManifest(c, "#cloud-config")
+	vm = decode(t, manifest, err)
+
+	if namespace := get(vm, "spec", "dataVolumeTemplates", 0, "spec", "source", "pvc", "namespace"); namespace != "images" {
+		t.Errorf("bad source namespace: %v", namespace)
+	}
+	if class := get(vm, "spec", "dataVolumeTemplates", 0, "spec", "pvc", "storageClassName"); class != "fast" {
+		t.Errorf("bad storage class: %v", class)
+	}
+}
+
+func TestDefaultUserData(t *testing.T) {
+	userData := defaultUserData(testManifestConfig(), "ssh-rsa AAAA")
+	if !strings.HasPrefix(userData, "#cloud-config\n") {
+		t.Errorf("bad user data: %s", userData)
+	}
+	if !strings.Contains(userData, `- name: "fedora"`) || !strings.Contains(userData, `- "ssh-rsa AAAA"`) {
+		t.Errorf("bad user data: %s", userData)
+	}
+}
+
+func TestServiceManifest(t *testing.T) {
+	manifest, err := serviceManifest(testManifestConfig())
+	service := decode(t, manifest, err)
+
+	if name := get(service, "metadata", "name"); name != "packer-fedora-ssh" {
+		t.Errorf("bad name: %v", name)
+	}
+	if selector := get(service, "spec", "selector", buildLabel); selector != "packer-fedora" {
+		t.Errorf("bad selector: %v", selector)
+	}
+	if port := get(service, "spec", "ports", 0, "targetPort"); port != 22.0 {
+		t.Errorf("bad port: %v", port)
+	}
+}
+
+func TestExporterManifest(t *testing.T) {
+	manifest, err := exporterManifest(testManifestConfig())
+	pod := decode(t, manifest, err)
+
+	if claim := get(pod, "spec", "volumes", 0, "persistentVolumeClaim", "claimName"); claim != "packer-fedora-disk" {
+		t.Errorf("bad claim: %v", claim)
+	}
+	if path := get(pod, "spec", "containers", 0, "volumeMounts", 0, "mountPath"); path != "/disk" {
+		t.Errorf("bad mount path: %v", path)
+	}
+}
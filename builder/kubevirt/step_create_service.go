@@ -0,0 +1,99 @@
+package kubevirt
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// stepCreateService exposes the SSH port of the VM with a service.
+//
+// Uses:
+//   config *Config
+//   driver Driver
+//   ui     packer.Ui
+//
+// Produces:
+//   ssh_host string - The address to connect to
+//   ssh_port int    - The port to connect to
+type stepCreateService struct {
+	serviceName string
+}
+
+func (s *stepCreateService) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	c := state.Get("config").(*Config)
+	driver := state.Get("driver").(Driver)
+	ui := state.Get("ui").(packer.Ui)
+
+	manifest, err := serviceManifest(c)
+	if err == nil {
+		ui.Say(fmt.Sprintf("Creating %s service...", c.ServiceType))
+		err = driver.Apply(manifest)
+	}
+	if err != nil {
+		err := fmt.Errorf("Error creating service: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	// We use this in cleanup
+	s.serviceName = serviceName(c)
+
+	host, port, err := serviceAddress(ctx, c, driver)
+	if err != nil {
+		err := fmt.Errorf("Error waiting for the address of the service: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	ui.Message(fmt.Sprintf("SSH address: %s:%d", host, port))
+
+	state.Put("ssh_host", host)
+	state.Put("ssh_port", port)
+	return multistep.ActionContinue
+}
+
+func (s *stepCreateService) Cleanup(state multistep.StateBag) {
+	if s.serviceName == "" {
+		return
+	}
+
+	driver := state.Get("driver").(Driver)
+	ui := state.Get("ui").(packer.Ui)
+
+	ui.Say("Deleting service...")
+	if err := driver.Delete("service/" + s.serviceName); err != nil {
+		ui.Error(fmt.Sprintf(
+			"Error deleting service. Please delete it manually: %s", err))
+	}
+}
+
+// serviceAddress returns the address of the service: the ingress of a load
+// balancer, or the node port on node_address.
+func serviceAddress(ctx context.Context, c *Config, driver Driver) (string, int, error) {
+	object := "service/" + serviceName(c)
+	notEmpty := func(value string) bool { return value != "" }
+
+	if c.ServiceType == "NodePort" {
+		port, err := waitFor(ctx, driver, object, "{.spec.ports[0].nodePort}", c.VMTimeout, notEmpty)
+		if err != nil {
+			return "", 0, err
+		}
+		nodePort, err := strconv.Atoi(port)
+		if err != nil {
+			return "", 0, fmt.Errorf("bad node port %q", port)
+		}
+		return c.NodeAddress, nodePort, nil
+	}
+
+	ingress := "{.status.loadBalancer.ingress[0].ip}{.status.loadBalancer.ingress[0].hostname}"
+	host, err := waitFor(ctx, driver, object, ingress, c.VMTimeout, notEmpty)
+	if err != nil {
+		return "", 0, err
+	}
+	return host, c.Comm.SSHPort, nil
+}
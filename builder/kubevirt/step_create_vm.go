@@ -0,0 +1,76 @@
+package kubevirt
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// stepCreateVM creates the VM and waits until it runs, which includes the
+// import of its disk.
+//
+// Uses:
+//   config *Config
+//   driver Driver
+//   ssh_public_key string
+//   ui     packer.Ui
+type stepCreateVM struct {
+	vmName string
+}
+
+func (s *stepCreateVM) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	c := state.Get("config").(*Config)
+	driver := state.Get("driver").(Driver)
+	publicKey := state.Get("ssh_public_key").(string)
+	ui := state.Get("ui").(packer.Ui)
+
+	userData := c.UserData
+	if userData == "" {
+		userData = defaultUserData(c, publicKey)
+	}
+
+	manifest, err := vmManifest(c, userData)
+	if err == nil {
+		ui.Say(fmt.Sprintf("Creating VM %s...", c.VMName))
+		err = driver.Apply(manifest)
+	}
+	if err != nil {
+		err := fmt.Errorf("Error creating VM: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	// We use this in cleanup
+	s.vmName = c.VMName
+
+	ui.Say("Waiting for the VM to run...")
+	_, err = waitFor(ctx, driver, "vmi/"+c.VMName, "{.status.phase}", c.VMTimeout,
+		func(phase string) bool { return phase == "Running" })
+	if err != nil {
+		err := fmt.Errorf("Error waiting for the VM to run: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *stepCreateVM) Cleanup(state multistep.StateBag) {
+	if s.vmName == "" {
+		return
+	}
+
+	driver := state.Get("driver").(Driver)
+	ui := state.Get("ui").(packer.Ui)
+
+	// The data volume belongs to the VM, and is deleted with it
+	ui.Say("Deleting VM...")
+	if err := driver.Delete("vm/" + s.vmName); err != nil {
+		ui.Error(fmt.Sprintf(
+			"Error deleting VM. Please delete it manually: %s", err))
+	}
+}
@@ -0,0 +1,129 @@
+package kubevirt
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/packer/builder/docker"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/packer/tmp"
+)
+
+// stepExportDisk exports the disk of the stopped VM as a containerDisk
+// image, which holds it in /disk, and pushes the image to its registry.
+//
+// Uses:
+//   config *Config
+//   docker_driver docker.Driver
+//   driver Driver
+//   ui     packer.Ui
+//
+// Produces:
+//   image_id string - The ID of the pushed image
+type stepExportDisk struct {
+	podName string
+	tarball string
+	imageID string
+}
+
+func (s *stepExportDisk) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	c := state.Get("config").(*Config)
+	dockerDriver := state.Get("docker_driver").(docker.Driver)
+	driver := state.Get("driver").(Driver)
+	ui := state.Get("ui").(packer.Ui)
+
+	halt := func(err error) multistep.StepAction {
+		err = fmt.Errorf("Error exporting disk: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	manifest, err := exporterManifest(c)
+	if err == nil {
+		ui.Say("Creating pod to export the disk...")
+		err = driver.Apply(manifest)
+	}
+	if err != nil {
+		return halt(err)
+	}
+
+	// We use this in cleanup
+	s.podName = exporterName(c)
+
+	_, err = waitFor(ctx, driver, "pod/"+s.podName, "{.status.phase}", c.VMTimeout,
+		func(phase string) bool { return phase == "Running" })
+	if err != nil {
+		return halt(err)
+	}
+
+	f, err := tmp.File("packer-kubevirt-disk")
+	if err != nil {
+		return halt(err)
+	}
+	s.tarball = f.Name()
+
+	// The tarball holds disk/disk.img, which is where KubeVirt looks for
+	// the disk in a containerDisk
+	ui.Say("Downloading the disk...")
+	err = driver.Exec(s.podName, f, "tar", "-c", "-C", "/", "disk/disk.img")
+	f.Close()
+	if err != nil {
+		return halt(err)
+	}
+
+	ui.Say(fmt.Sprintf("Importing the disk as %s...", c.Image))
+	imageID, err := dockerDriver.Import(s.tarball, nil, c.Image)
+	if err != nil {
+		return halt(err)
+	}
+	s.imageID = imageID
+
+	if c.LoginUsername != "" {
+		ui.Message("Logging in...")
+		if err := dockerDriver.Login(c.LoginServer, c.LoginUsername, c.LoginPassword); err != nil {
+			return halt(fmt.Errorf("Error logging in: %s", err))
+		}
+		defer func() {
+			ui.Message("Logging out...")
+			if err := dockerDriver.Logout(c.LoginServer); err != nil {
+				ui.Error(fmt.Sprintf("Error logging out: %s", err))
+			}
+		}()
+	}
+
+	ui.Say(fmt.Sprintf("Pushing %s...", c.Image))
+	if err := dockerDriver.Push(c.Image); err != nil {
+		return halt(err)
+	}
+
+	state.Put("image_id", imageID)
+	return multistep.ActionContinue
+}
+
+func (s *stepExportDisk) Cleanup(state multistep.StateBag) {
+	driver := state.Get("driver").(Driver)
+	ui := state.Get("ui").(packer.Ui)
+
+	// The local image and tarball are only needed until the image is pushed
+	if s.imageID != "" {
+		dockerDriver := state.Get("docker_driver").(docker.Driver)
+		if err := dockerDriver.DeleteImage(s.imageID); err != nil {
+			ui.Error(fmt.Sprintf("Error deleting the local image: %s", err))
+		}
+	}
+
+	if s.tarball != "" {
+		os.Remove(s.tarball)
+	}
+
+	if s.podName != "" {
+		ui.Say("Deleting the export pod...")
+		if err := driver.Delete("pod/" + s.podName); err != nil {
+			ui.Error(fmt.Sprintf(
+				"Error deleting the export pod. Please delete it manually: %s", err))
+		}
+	}
+}
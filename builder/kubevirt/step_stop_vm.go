@@ -0,0 +1,44 @@
+package kubevirt
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// stepStopVM stops the VM so that its disk is consistent when it is
+// exported. KubeVirt shuts the guest down through ACPI, and kills it once
+// shutdown_timeout has passed.
+type stepStopVM struct{}
+
+func (s *stepStopVM) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	c := state.Get("config").(*Config)
+	driver := state.Get("driver").(Driver)
+	ui := state.Get("ui").(packer.Ui)
+
+	ui.Say("Stopping VM...")
+	if err := driver.Patch("vm/"+c.VMName, `{"spec":{"runStrategy":"Halted"}}`); err != nil {
+		err := fmt.Errorf("Error stopping VM: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	// The instance of the VM is deleted once it has stopped
+	gone := func(name string) bool { return name == "" }
+	timeout := c.ShutdownTimeout + c.VMTimeout
+	if _, err := waitFor(ctx, driver, "vmi/"+c.VMName, "{.metadata.name}", timeout, gone); err != nil {
+		err := fmt.Errorf("Error waiting for the VM to stop: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *stepStopVM) Cleanup(state multistep.StateBag) {
+	// no cleanup
+}
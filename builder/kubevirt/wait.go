@@ -0,0 +1,37 @@
+package kubevirt
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/packer/common/retry"
+)
+
+// pollInterval is the time between two checks of the state of an object.
+var pollInterval = 5 * time.Second
+
+// waitFor waits until the JSONPath expression of the object has a value
+// that done returns true for, and returns the value.
+func waitFor(ctx context.Context, driver Driver, object, jsonPath string, timeout time.Duration,
+	done func(string) bool) (string, error) {
+	var value string
+	err := retry.Config{
+		StartTimeout: timeout,
+		RetryDelay:   func() time.Duration { return pollInterval },
+	}.Run(ctx, func(ctx context.Context) error {
+		var err error
+		value, err = driver.Get(object, jsonPath)
+		if err != nil {
+			return err
+		}
+		if !done(value) {
+			return fmt.Errorf("%s of %s is %q", jsonPath, object, value)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("timeout while waiting for %s: %s", object, err)
+	}
+	return value, nil
+}
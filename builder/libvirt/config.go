@@ -0,0 +1,212 @@
+package libvirt
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/bootcommand"
+	"github.com/hashicorp/packer/helper/communicator"
+	"github.com/hashicorp/packer/helper/config"
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/template/interpolate"
+)
+
+var diskBuses = map[string]bool{
+	"ide":    true,
+	"sata":   true,
+	"scsi":   true,
+	"virtio": true,
+}
+
+var ipAddressSources = map[string]bool{
+	"agent": true,
+	"arp":   true,
+	"lease": true,
+}
+
+type Config struct {
+	common.PackerConfig   `mapstructure:",squash"`
+	common.HTTPConfig     `mapstructure:",squash"`
+	common.ISOConfig      `mapstructure:",squash"`
+	bootcommand.VNCConfig `mapstructure:",squash"`
+	Comm                  communicator.Config `mapstructure:",squash"`
+
+	LibvirtURI      string        `mapstructure:"libvirt_uri"`
+	VirshPath       string        `mapstructure:"virsh_path"`
+	VMName          string        `mapstructure:"vm_name"`
+	DomainType      string        `mapstructure:"domain_type"`
+	MachineType     string        `mapstructure:"machine_type"`
+	CpuCount        int           `mapstructure:"cpus"`
+	MemorySize      int           `mapstructure:"memory"`
+	StoragePool     string        `mapstructure:"storage_pool"`
+	DiskSize        uint          `mapstructure:"disk_size"`
+	DiskBus         string        `mapstructure:"disk_bus"`
+	Format          string        `mapstructure:"format"`
+	Network         string        `mapstructure:"network"`
+	NetworkModel    string        `mapstructure:"network_model"`
+	IPAddressSource string        `mapstructure:"ip_address_source"`
+	VNCBindAddress  string        `mapstructure:"vnc_bind_address"`
+	KeepVolume      bool          `mapstructure:"keep_volume"`
+	OutputDir       string        `mapstructure:"output_directory"`
+	ShutdownCommand string        `mapstructure:"shutdown_command"`
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+
+	ctx interpolate.Context
+}
+
+func NewConfig(raws ...interface{}) (*Config, []string, error) {
+	c := new(Config)
+
+	err := config.Decode(c, &config.DecodeOpts{
+		Interpolate:        true,
+		InterpolateContext: &c.ctx,
+		InterpolateFilter: &interpolate.RenderFilter{
+			Exclude: []string{
+				"boot_command",
+			},
+		},
+	}, raws...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var errs *packer.MultiError
+	var warnings []string
+
+	// Defaults
+	if c.LibvirtURI == "" {
+		c.LibvirtURI = "qemu:///system"
+	}
+
+	if c.VirshPath == "" {
+		c.VirshPath = "virsh"
+	}
+
+	if c.VMName == "" {
+		c.VMName = fmt.Sprintf("packer-%s", c.PackerBuildName)
+	}
+
+	if c.DomainType == "" {
+		c.DomainType = "kvm"
+	}
+
+	if c.CpuCount < 1 {
+		c.CpuCount = 1
+	}
+
+	if c.MemorySize < 1 {
+		c.MemorySize = 1024
+	}
+
+	if c.StoragePool == "" {
+		c.StoragePool = "default"
+	}
+
+	if c.DiskSize == 0 {
+		c.DiskSize = 40960
+	}
+
+	if c.DiskBus == "" {
+		c.DiskBus = "virtio"
+	}
+
+	if c.Format == "" {
+		c.Format = "qcow2"
+	}
+
+	if c.Network == "" {
+		c.Network = "default"
+	}
+
+	if c.NetworkModel == "" {
+		c.NetworkModel = "virtio"
+	}
+
+	if c.IPAddressSource == "" {
+		c.IPAddressSource = "lease"
+	}
+
+	if c.VNCBindAddress == "" {
+		c.VNCBindAddress = "127.0.0.1"
+
+		// The display of a domain on a remote host is only reachable
+		// when it listens on the addresses of that host
+		if c.remoteHost() != "" {
+			c.VNCBindAddress = "0.0.0.0"
+		}
+	}
+
+	if c.OutputDir == "" {
+		c.OutputDir = fmt.Sprintf("output-%s", c.PackerBuildName)
+	}
+
+	if c.ShutdownTimeout == 0 {
+		c.ShutdownTimeout = 5 * time.Minute
+	}
+
+	isoWarnings, isoErrs := c.ISOConfig.Prepare(&c.ctx)
+	warnings = append(warnings, isoWarnings...)
+	errs = packer.MultiErrorAppend(errs, isoErrs...)
+
+	errs = packer.MultiErrorAppend(errs, c.HTTPConfig.Prepare(&c.ctx)...)
+	errs = packer.MultiErrorAppend(errs, c.VNCConfig.Prepare(&c.ctx)...)
+	if es := c.Comm.Prepare(&c.ctx); len(es) > 0 {
+		errs = packer.MultiErrorAppend(errs, es...)
+	}
+
+	if _, err := url.Parse(c.LibvirtURI); err != nil {
+		errs = packer.MultiErrorAppend(
+			errs, fmt.Errorf("invalid libvirt_uri: %s", err))
+	}
+
+	if ip := net.ParseIP(c.VNCBindAddress); ip != nil && ip.IsLoopback() &&
+		c.remoteHost() != "" && !c.VNCConfig.DisableVNC {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("vnc_bind_address can't be a loopback address with a remote libvirt_uri, "+
+				"the boot command couldn't reach the display"))
+	}
+
+	if !(c.Format == "qcow2" || c.Format == "raw") {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("invalid format, only 'qcow2' or 'raw' are allowed"))
+	}
+
+	if !diskBuses[c.DiskBus] {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("unrecognized disk_bus type"))
+	}
+
+	if !ipAddressSources[c.IPAddressSource] {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("ip_address_source must be one of 'lease', 'agent' or 'arp'"))
+	}
+
+	if !c.PackerForce {
+		if _, err := os.Stat(c.OutputDir); err == nil {
+			errs = packer.MultiErrorAppend(
+				errs,
+				fmt.Errorf("Output directory '%s' already exists. It must not exist.", c.OutputDir))
+		}
+	}
+
+	if errs != nil && len(errs.Errors) > 0 {
+		return nil, warnings, errs
+	}
+
+	return c, warnings, nil
+}
+
+// remoteHost returns the host of a remote libvirt URI, such as
+// qemu+ssh://root@host/system, or "" for a local URI.
+func (c *Config) remoteHost() string {
+	u, err := url.Parse(c.LibvirtURI)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
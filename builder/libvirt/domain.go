@@ -0,0 +1,131 @@
+package libvirt
+
+import (
+	"bytes"
+	"encoding/xml"
+	"text/template"
+)
+
+// The domain boots from its disk, so that it boots from the CD-ROM until an
+// operating system is installed and from the disk once the installer
+// reboots.
+const domainTemplate = `<domain type='{{.DomainType | xml}}'>
+  <name>{{.Name | xml}}</name>
+  <memory unit='MiB'>{{.Memory}}</memory>
+  <vcpu>{{.CPUs}}</vcpu>
+  <os>
+    <type{{if .MachineType}} machine='{{.MachineType | xml}}'{{end}}>hvm</type>
+    <boot dev='hd'/>
+    <boot dev='cdrom'/>
+  </os>
+  <features>
+    <acpi/>
+    <apic/>
+  </features>
+  <on_poweroff>destroy</on_poweroff>
+  <on_reboot>restart</on_reboot>
+  <on_crash>destroy</on_crash>
+  <devices>
+    <disk type='volume' device='disk'>
+      <driver name='qemu' type='{{.DiskFormat | xml}}'/>
+      <source pool='{{.Pool | xml}}' volume='{{.DiskVolume | xml}}'/>
+      <target dev='{{.DiskTarget}}' bus='{{.DiskBus | xml}}'/>
+    </disk>
+    <disk type='volume' device='cdrom'>
+      <driver name='qemu' type='raw'/>
+      <source pool='{{.Pool | xml}}' volume='{{.ISOVolume | xml}}'/>
+      <target dev='{{.CDROMTarget}}' bus='{{.CDROMBus}}'/>
+      <readonly/>
+    </disk>
+    <interface type='network'>
+      <source network='{{.Network | xml}}'/>
+      <model type='{{.NetworkModel | xml}}'/>
+    </interface>
+    <graphics type='vnc' autoport='yes' listen='{{.VNCBindAddress | xml}}'/>
+    <serial type='pty'/>
+    <console type='pty'/>
+  </devices>
+</domain>
+`
+
+type domainTemplateData struct {
+	DomainType     string
+	Name           string
+	Memory         int
+	CPUs           int
+	MachineType    string
+	Pool           string
+	DiskVolume     string
+	DiskFormat     string
+	DiskBus        string
+	DiskTarget     string
+	ISOVolume      string
+	CDROMBus       string
+	CDROMTarget    string
+	Network        string
+	NetworkModel   string
+	VNCBindAddress string
+}
+
+// busPrefixes are the prefixes of the target device names of each bus.
+var busPrefixes = map[string]string{
+	"ide":    "hd",
+	"sata":   "sd",
+	"scsi":   "sd",
+	"virtio": "vd",
+}
+
+// domainXML returns the XML description of the domain to install from the
+// ISO volume onto the disk volume.
+func domainXML(c *Config, diskVolume, isoVolume string) (string, error) {
+	data := domainTemplateData{
+		DomainType:     c.DomainType,
+		Name:           c.VMName,
+		Memory:         c.MemorySize,
+		CPUs:           c.CpuCount,
+		MachineType:    c.MachineType,
+		Pool:           c.StoragePool,
+		DiskVolume:     diskVolume,
+		DiskFormat:     c.Format,
+		DiskBus:        c.DiskBus,
+		DiskTarget:     busPrefixes[c.DiskBus] + "a",
+		ISOVolume:      isoVolume,
+		CDROMBus:       "sata",
+		CDROMTarget:    "sda",
+		Network:        c.Network,
+		NetworkModel:   c.NetworkModel,
+		VNCBindAddress: c.VNCBindAddress,
+	}
+
+	// Keep the CD-ROM on the bus of the disk when it's IDE, for machines
+	// without a SATA controller
+	if c.DiskBus == "ide" {
+		data.CDROMBus = "ide"
+	}
+	if prefix := busPrefixes[data.CDROMBus]; prefix == busPrefixes[c.DiskBus] {
+		data.CDROMTarget = prefix + "b"
+	} else {
+		data.CDROMTarget = prefix + "a"
+	}
+
+	t, err := template.New("domain").Funcs(template.FuncMap{
+		"xml": xmlEscape,
+	}).Parse(domainTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func xmlEscape(s string) (string, error) {
+	var buf bytes.Buffer
+	if err := xml.EscapeText(&buf, []byte(s)); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
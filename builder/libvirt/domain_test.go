@@ -0,0 +1,113 @@
+package libvirt
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func testDomainConfig() *Config {
+	return &Config{
+		VMName:         "packer-foo",
+		DomainType:     "kvm",
+		CpuCount:       2,
+		MemorySize:     2048,
+		StoragePool:    "default",
+		DiskBus:        "virtio",
+		Format:         "qcow2",
+		Network:        "default",
+		NetworkModel:   "virtio",
+		VNCBindAddress: "127.0.0.1",
+	}
+}
+
+func TestDomainXML(t *testing.T) {
+	desc, err := domainXML(testDomainConfig(), "packer-foo.qcow2", "packer-foo.iso")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	var v interface{}
+	if err := xml.Unmarshal([]byte(desc), &v); err != nil {
+		t.Fatalf("invalid XML: %s\n%s", err, desc)
+	}
+
+	for _, s := range []string{
+		"<name>packer-foo</name>",
+		"<memory unit='MiB'>2048</memory>",
+		"<vcpu>2</vcpu>",
+		"<source pool='default' volume='packer-foo.qcow2'/>",
+		"<target dev='vda' bus='virtio'/>",
+		"<source pool='default' volume='packer-foo.iso'/>",
+		"<target dev='sda' bus='sata'/>",
+		"<graphics type='vnc' autoport='yes' listen='127.0.0.1'/>",
+	} {
+		if !strings.Contains(desc, s) {
+			t.Errorf("expected %q in:\n%s", s, desc)
+		}
+	}
+
+	if strings.Contains(desc, "machine=") {
+		t.Errorf("unexpected machine type in:\n%s", desc)
+	}
+}
+
+func TestDomainXML_IDE(t *testing.T) {
+	c := testDomainConfig()
+	c.DiskBus = "ide"
+	c.MachineType = "pc"
+
+	desc, err := domainXML(c, "packer-foo.qcow2", "packer-foo.iso")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	for _, s := range []string{
+		"<type machine='pc'>hvm</type>",
+		"<target dev='hda' bus='ide'/>",
+		"<target dev='hdb' bus='ide'/>",
+	} {
+		if !strings.Contains(desc, s) {
+			t.Errorf("expected %q in:\n%s", s, desc)
+		}
+	}
+}
+
+func TestDomainXML_SATA(t *testing.T) {
+	c := testDomainConfig()
+	c.DiskBus = "sata"
+
+	desc, err := domainXML(c, "packer-foo.qcow2", "packer-foo.iso")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	for _, s := range []string{
+		"<target dev='sda' bus='sata'/>",
+		"<target dev='sdb' bus='sata'/>",
+	} {
+		if !strings.Contains(desc, s) {
+			t.Errorf("expected %q in:\n%s", s, desc)
+		}
+	}
+}
+
+func TestDomainXML_Escape(t *testing.T) {
+	c := testDomainConfig()
+	c.VMName = "foo<'bar'>"
+
+	desc, err := domainXML(c, "disk", "iso")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	var domain struct {
+		Name string `xml:"name"`
+	}
+	if err := xml.Unmarshal([]byte(desc), &domain); err != nil {
+		t.Fatalf("invalid XML: %s\n%s", err, desc)
+	}
+	if domain.Name != c.VMName {
+		t.Fatalf("bad name: %s", domain.Name)
+	}
+}
@@ -0,0 +1,240 @@
+package libvirt
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/packer/packer/tmp"
+)
+
+// A driver is able to talk to libvirtd and perform certain operations with
+// it.
+type Driver interface {
+	// DefineDomain defines a persistent domain from its XML description.
+	DefineDomain(ctx context.Context, desc string) error
+
+	// StartDomain boots a defined domain.
+	StartDomain(ctx context.Context, name string) error
+
+	// ShutdownDomain asks the guest of the domain to shut down.
+	ShutdownDomain(ctx context.Context, name string) error
+
+	// DestroyDomain stops a running domain, forcefully.
+	DestroyDomain(ctx context.Context, name string) error
+
+	// UndefineDomain removes a stopped domain.
+	UndefineDomain(ctx context.Context, name string) error
+
+	// DomainState returns the state of the domain, such as "running" or
+	// "shut off".
+	DomainState(ctx context.Context, name string) (string, error)
+
+	// DomainIP returns the first IPv4 address of the domain, read from the
+	// given source: "lease", "agent" or "arp".
+	DomainIP(ctx context.Context, name string, source string) (string, error)
+
+	// VNCPort returns the port of the VNC display of the running domain.
+	VNCPort(ctx context.Context, name string) (int, error)
+
+	// CreateVolume creates a volume of the given size in bytes in the
+	// storage pool.
+	CreateVolume(ctx context.Context, pool, name string, size uint64, format string) error
+
+	// UploadVolume copies the contents of a local file to the volume.
+	UploadVolume(ctx context.Context, pool, name, src string) error
+
+	// DownloadVolume copies the contents of the volume to a local file.
+	DownloadVolume(ctx context.Context, pool, name, dst string) error
+
+	// DeleteVolume removes the volume from the storage pool.
+	DeleteVolume(ctx context.Context, pool, name string) error
+
+	// NetworkAddress returns the IPv4 address of the host on a network
+	// managed by libvirt.
+	NetworkAddress(ctx context.Context, network string) (string, error)
+
+	// Verify checks to make sure that this driver should function
+	// properly. If there is any indication the driver can't function,
+	// this will return an error.
+	Verify() error
+}
+
+// VirshDriver is a Driver that runs virsh against the libvirtd at URI,
+// which may be remote, such as qemu+ssh://root@host/system.
+type VirshDriver struct {
+	VirshPath string
+	URI       string
+}
+
+func (d *VirshDriver) DefineDomain(ctx context.Context, desc string) error {
+	f, err := tmp.File("packer-libvirt-domain")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.WriteString(desc)
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Defining domain:\n%s", desc)
+	_, err = d.virsh(ctx, "define", f.Name())
+	return err
+}
+
+func (d *VirshDriver) StartDomain(ctx context.Context, name string) error {
+	_, err := d.virsh(ctx, "start", name)
+	return err
+}
+
+func (d *VirshDriver) ShutdownDomain(ctx context.Context, name string) error {
+	_, err := d.virsh(ctx, "shutdown", name)
+	return err
+}
+
+func (d *VirshDriver) DestroyDomain(ctx context.Context, name string) error {
+	_, err := d.virsh(ctx, "destroy", name)
+	return err
+}
+
+func (d *VirshDriver) UndefineDomain(ctx context.Context, name string) error {
+	_, err := d.virsh(ctx, "undefine", name)
+	return err
+}
+
+func (d *VirshDriver) DomainState(ctx context.Context, name string) (string, error) {
+	return d.virsh(ctx, "domstate", name)
+}
+
+func (d *VirshDriver) DomainIP(ctx context.Context, name string, source string) (string, error) {
+	stdout, err := d.virsh(ctx, "domifaddr", name, "--source", source)
+	if err != nil {
+		return "", err
+	}
+	return parseDomIfAddr(stdout)
+}
+
+func (d *VirshDriver) VNCPort(ctx context.Context, name string) (int, error) {
+	stdout, err := d.virsh(ctx, "vncdisplay", name)
+	if err != nil {
+		return 0, err
+	}
+	return parseVNCDisplay(stdout)
+}
+
+func (d *VirshDriver) CreateVolume(ctx context.Context, pool, name string, size uint64, format string) error {
+	_, err := d.virsh(ctx, "vol-create-as", pool, name, strconv.FormatUint(size, 10), "--format", format)
+	return err
+}
+
+func (d *VirshDriver) UploadVolume(ctx context.Context, pool, name, src string) error {
+	_, err := d.virsh(ctx, "vol-upload", "--pool", pool, name, src)
+	return err
+}
+
+func (d *VirshDriver) DownloadVolume(ctx context.Context, pool, name, dst string) error {
+	_, err := d.virsh(ctx, "vol-download", "--pool", pool, name, dst)
+	return err
+}
+
+func (d *VirshDriver) DeleteVolume(ctx context.Context, pool, name string) error {
+	_, err := d.virsh(ctx, "vol-delete", "--pool", pool, name)
+	return err
+}
+
+func (d *VirshDriver) NetworkAddress(ctx context.Context, network string) (string, error) {
+	stdout, err := d.virsh(ctx, "net-dumpxml", network)
+	if err != nil {
+		return "", err
+	}
+	return parseNetworkAddress(stdout)
+}
+
+func (d *VirshDriver) Verify() error {
+	if _, err := exec.LookPath(d.VirshPath); err != nil {
+		return fmt.Errorf("virsh not found: %s", err)
+	}
+	_, err := d.virsh(context.Background(), "version")
+	return err
+}
+
+func (d *VirshDriver) virsh(ctx context.Context, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	args = append([]string{"--connect", d.URI, "--quiet"}, args...)
+	log.Printf("Executing virsh: %#v", args)
+	cmd := exec.CommandContext(ctx, d.VirshPath, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+
+	stdoutString := strings.TrimSpace(stdout.String())
+	stderrString := strings.TrimSpace(stderr.String())
+
+	if _, ok := err.(*exec.ExitError); ok {
+		err = fmt.Errorf("virsh error: %s", stderrString)
+	}
+
+	log.Printf("stdout: %s", stdoutString)
+	log.Printf("stderr: %s", stderrString)
+
+	return stdoutString, err
+}
+
+// parseDomIfAddr returns the first IPv4 address of the output of virsh
+// domifaddr, which lists the addresses in CIDR notation:
+//
+//   vnet0      52:54:00:ef:3a:2a    ipv4         192.168.122.146/24
+func parseDomIfAddr(stdout string) (string, error) {
+	for _, line := range strings.Split(stdout, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || fields[len(fields)-2] != "ipv4" {
+			continue
+		}
+		return strings.Split(fields[len(fields)-1], "/")[0], nil
+	}
+	return "", fmt.Errorf("no IPv4 address found")
+}
+
+// parseVNCDisplay returns the port of the output of virsh vncdisplay, which
+// is the listen address and the display number, such as 127.0.0.1:0.
+func parseVNCDisplay(stdout string) (int, error) {
+	i := strings.LastIndex(stdout, ":")
+	if i == -1 {
+		return 0, fmt.Errorf("can't parse VNC display %q", stdout)
+	}
+	display, err := strconv.Atoi(stdout[i+1:])
+	if err != nil {
+		return 0, fmt.Errorf("can't parse VNC display %q: %s", stdout, err)
+	}
+	return 5900 + display, nil
+}
+
+// parseNetworkAddress returns the address of the first ip element of the
+// XML description of a network.
+func parseNetworkAddress(xmlDesc string) (string, error) {
+	var network struct {
+		IPs []struct {
+			Address string `xml:"address,attr"`
+			Family  string `xml:"family,attr"`
+		} `xml:"ip"`
+	}
+	if err := xml.Unmarshal([]byte(xmlDesc), &network); err != nil {
+		return "", err
+	}
+	for _, ip := range network.IPs {
+		if ip.Family == "" || ip.Family == "ipv4" {
+			return ip.Address, nil
+		}
+	}
+	return "", fmt.Errorf("the network has no IPv4 address")
+}
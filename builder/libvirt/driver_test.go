@@ -0,0 +1,72 @@
+package libvirt
+
+import "testing"
+
+func TestVirshDriver_impl(t *testing.T) {
+	var _ Driver = new(VirshDriver)
+}
+
+func TestParseDomIfAddr(t *testing.T) {
+	stdout := `vnet0      52:54:00:ef:3a:2a    ipv6         fe80::5054:ff:feef:3a2a/64
+vnet0      52:54:00:ef:3a:2a    ipv4         192.168.122.146/24`
+
+	ip, err := parseDomIfAddr(stdout)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if ip != "192.168.122.146" {
+		t.Fatalf("bad: %s", ip)
+	}
+
+	if _, err := parseDomIfAddr(""); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestParseVNCDisplay(t *testing.T) {
+	cases := map[string]int{
+		"127.0.0.1:0": 5900,
+		":3":          5903,
+		"[::1]:1":     5901,
+	}
+
+	for stdout, expected := range cases {
+		port, err := parseVNCDisplay(stdout)
+		if err != nil {
+			t.Fatalf("%s: err: %s", stdout, err)
+		}
+		if port != expected {
+			t.Errorf("%s: expected %d, got %d", stdout, expected, port)
+		}
+	}
+
+	if _, err := parseVNCDisplay("none"); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestParseNetworkAddress(t *testing.T) {
+	desc := `<network>
+  <name>default</name>
+  <forward mode='nat'/>
+  <bridge name='virbr0' stp='on' delay='0'/>
+  <ip family='ipv6' address='fd00::1' prefix='64'/>
+  <ip address='192.168.122.1' netmask='255.255.255.0'>
+    <dhcp>
+      <range start='192.168.122.2' end='192.168.122.254'/>
+    </dhcp>
+  </ip>
+</network>`
+
+	ip, err := parseNetworkAddress(desc)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if ip != "192.168.122.1" {
+		t.Fatalf("bad: %s", ip)
+	}
+
+	if _, err := parseNetworkAddress("<network/>"); err == nil {
+		t.Fatal("should have error")
+	}
+}
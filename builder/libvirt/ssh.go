@@ -0,0 +1,21 @@
+package libvirt
+
+import (
+	"context"
+	"log"
+
+	"github.com/hashicorp/packer/helper/multistep"
+)
+
+func commHost(host string) func(multistep.StateBag) (string, error) {
+	return func(state multistep.StateBag) (string, error) {
+		if host != "" {
+			log.Printf("Using ssh_host value: %s", host)
+			return host, nil
+		}
+
+		config := state.Get("config").(*Config)
+		driver := state.Get("driver").(Driver)
+		return driver.DomainIP(context.TODO(), config.VMName, config.IPAddressSource)
+	}
+}
@@ -0,0 +1,91 @@
+package libvirt
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// This step defines the domain and boots it from the ISO.
+//
+// Uses:
+//   config *Config
+//   disk_volume string
+//   driver Driver
+//   iso_volume string
+//   ui     packer.Ui
+//
+// Produces:
+//   vnc_port int - The port of the VNC display of the domain
+type stepCreateDomain struct {
+	name string
+}
+
+func (s *stepCreateDomain) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	config := state.Get("config").(*Config)
+	diskVolume := state.Get("disk_volume").(string)
+	driver := state.Get("driver").(Driver)
+	isoVolume := state.Get("iso_volume").(string)
+	ui := state.Get("ui").(packer.Ui)
+
+	desc, err := domainXML(config, diskVolume, isoVolume)
+	if err != nil {
+		err := fmt.Errorf("Error creating domain description: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	ui.Say(fmt.Sprintf("Defining domain %s...", config.VMName))
+	if err := driver.DefineDomain(ctx, desc); err != nil {
+		err := fmt.Errorf("Error defining domain: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	s.name = config.VMName
+
+	ui.Say("Starting domain, booting from CD-ROM...")
+	if err := driver.StartDomain(ctx, config.VMName); err != nil {
+		err := fmt.Errorf("Error starting domain: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	vncPort, err := driver.VNCPort(ctx, config.VMName)
+	if err != nil {
+		err := fmt.Errorf("Error reading the VNC display of the domain: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	log.Printf("VNC port: %d", vncPort)
+	state.Put("vnc_port", vncPort)
+
+	return multistep.ActionContinue
+}
+
+func (s *stepCreateDomain) Cleanup(state multistep.StateBag) {
+	if s.name == "" {
+		return
+	}
+
+	driver := state.Get("driver").(Driver)
+	ui := state.Get("ui").(packer.Ui)
+
+	if domState, err := driver.DomainState(context.TODO(), s.name); err == nil && domState != "shut off" {
+		ui.Say("Stopping domain...")
+		if err := driver.DestroyDomain(context.TODO(), s.name); err != nil {
+			ui.Error(fmt.Sprintf("Error stopping domain: %s", err))
+		}
+	}
+
+	ui.Say("Undefining domain...")
+	if err := driver.UndefineDomain(context.TODO(), s.name); err != nil {
+		ui.Error(fmt.Sprintf("Error undefining domain: %s", err))
+	}
+}
@@ -0,0 +1,66 @@
+package libvirt
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// This step creates the volume of the storage pool that the operating
+// system is installed on. It is deleted at the end of the build, unless
+// keep_volume is set and the build succeeded.
+//
+// Uses:
+//   config *Config
+//   driver Driver
+//   ui     packer.Ui
+//
+// Produces:
+//   disk_volume string - The name of the volume of the disk
+type stepCreateVolume struct {
+	volume string
+}
+
+func (s *stepCreateVolume) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	config := state.Get("config").(*Config)
+	driver := state.Get("driver").(Driver)
+	ui := state.Get("ui").(packer.Ui)
+
+	name := fmt.Sprintf("%s.%s", config.VMName, config.Format)
+	ui.Say(fmt.Sprintf("Creating volume %s in pool %s...", name, config.StoragePool))
+	size := uint64(config.DiskSize) * 1024 * 1024
+	if err := driver.CreateVolume(ctx, config.StoragePool, name, size, config.Format); err != nil {
+		err := fmt.Errorf("Error creating volume: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	s.volume = name
+
+	state.Put("disk_volume", name)
+	return multistep.ActionContinue
+}
+
+func (s *stepCreateVolume) Cleanup(state multistep.StateBag) {
+	if s.volume == "" {
+		return
+	}
+
+	config := state.Get("config").(*Config)
+	driver := state.Get("driver").(Driver)
+	ui := state.Get("ui").(packer.Ui)
+
+	_, cancelled := state.GetOk(multistep.StateCancelled)
+	_, halted := state.GetOk(multistep.StateHalted)
+	if config.KeepVolume && !cancelled && !halted {
+		ui.Say(fmt.Sprintf("Keeping volume %s in pool %s", s.volume, config.StoragePool))
+		return
+	}
+
+	ui.Say("Deleting volume...")
+	if err := driver.DeleteVolume(context.TODO(), config.StoragePool, s.volume); err != nil {
+		ui.Error(fmt.Sprintf("Error deleting volume: %s", err))
+	}
+}
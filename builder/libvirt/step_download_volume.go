@@ -0,0 +1,42 @@
+package libvirt
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// This step copies the volume of the disk into the output directory.
+//
+// Uses:
+//   config *Config
+//   disk_volume string
+//   driver Driver
+//   ui     packer.Ui
+//
+// Produces:
+//   <nothing>
+type stepDownloadVolume struct{}
+
+func (s *stepDownloadVolume) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	config := state.Get("config").(*Config)
+	diskVolume := state.Get("disk_volume").(string)
+	driver := state.Get("driver").(Driver)
+	ui := state.Get("ui").(packer.Ui)
+
+	path := filepath.Join(config.OutputDir, diskVolume)
+	ui.Say(fmt.Sprintf("Downloading volume to %s...", path))
+	if err := driver.DownloadVolume(ctx, config.StoragePool, diskVolume, path); err != nil {
+		err := fmt.Errorf("Error downloading volume: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *stepDownloadVolume) Cleanup(state multistep.StateBag) {}
@@ -0,0 +1,93 @@
+package libvirt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// This step shuts down the domain. It runs the shutdown command if there
+// is one, or otherwise asks the guest to shut down through libvirt, then
+// waits for the domain to stop.
+//
+// Uses:
+//   communicator packer.Communicator
+//   config *Config
+//   driver Driver
+//   ui     packer.Ui
+//
+// Produces:
+//   <nothing>
+type stepShutdown struct{}
+
+func (s *stepShutdown) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	config := state.Get("config").(*Config)
+	driver := state.Get("driver").(Driver)
+	ui := state.Get("ui").(packer.Ui)
+
+	if state.Get("communicator") == nil {
+		ui.Say("Waiting for shutdown...")
+	} else if config.ShutdownCommand != "" {
+		comm := state.Get("communicator").(packer.Communicator)
+		ui.Say("Gracefully halting virtual machine...")
+		log.Printf("Executing shutdown command: %s", config.ShutdownCommand)
+		cmd := &packer.RemoteCmd{Command: config.ShutdownCommand}
+		if err := cmd.RunWithUi(ctx, comm, ui); err != nil {
+			err := fmt.Errorf("Failed to send shutdown command: %s", err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+	} else {
+		ui.Say("Halting the virtual machine...")
+		if err := driver.ShutdownDomain(ctx, config.VMName); err != nil {
+			err := fmt.Errorf("Error stopping VM: %s", err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+	}
+
+	log.Printf("Waiting max %s for shutdown to complete", config.ShutdownTimeout)
+	if err := waitForShutdown(ctx, driver, config.VMName, config.ShutdownTimeout); err != nil {
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	log.Println("VM shut down.")
+	return multistep.ActionContinue
+}
+
+func (s *stepShutdown) Cleanup(state multistep.StateBag) {}
+
+// pollInterval is how often the state of the domain is checked.
+var pollInterval = 2 * time.Second
+
+func waitForShutdown(ctx context.Context, driver Driver, name string, timeout time.Duration) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		domState, err := driver.DomainState(ctx, name)
+		if err != nil {
+			return fmt.Errorf("Error reading the state of the domain: %s", err)
+		}
+		if domState == "shut off" {
+			return nil
+		}
+
+		select {
+		case <-time.After(pollInterval):
+		case <-timer.C:
+			return errors.New("Timeout while waiting for machine to shut down.")
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
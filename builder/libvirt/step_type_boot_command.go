@@ -0,0 +1,162 @@
+package libvirt
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/bootcommand"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/template/interpolate"
+	"github.com/mitchellh/go-vnc"
+)
+
+type bootCommandTemplateData struct {
+	HTTPIP   string
+	HTTPPort int
+	Name     string
+}
+
+// This step "types" the boot command into the domain over VNC.
+//
+// Uses:
+//   config *Config
+//   driver Driver
+//   http_port int
+//   ui     packer.Ui
+//   vnc_port int
+//
+// Produces:
+//   <nothing>
+type stepTypeBootCommand struct{}
+
+func (s *stepTypeBootCommand) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	config := state.Get("config").(*Config)
+	debug := state.Get("debug").(bool)
+	driver := state.Get("driver").(Driver)
+	httpPort := state.Get("http_port").(int)
+	ui := state.Get("ui").(packer.Ui)
+	vncPort := state.Get("vnc_port").(int)
+
+	if config.VNCConfig.DisableVNC {
+		log.Println("Skipping boot command step...")
+		return multistep.ActionContinue
+	}
+
+	// Wait the for the vm to boot.
+	if int64(config.BootWait) > 0 {
+		ui.Say(fmt.Sprintf("Waiting %s for boot...", config.BootWait.String()))
+		select {
+		case <-time.After(config.BootWait):
+			break
+		case <-ctx.Done():
+			return multistep.ActionHalt
+		}
+	}
+
+	var pauseFn multistep.DebugPauseFn
+	if debug {
+		pauseFn = state.Get("pauseFn").(multistep.DebugPauseFn)
+	}
+
+	// The display of a domain on a remote host is only reachable when it
+	// listens on all of the addresses of that host
+	vncIP := config.VNCBindAddress
+	if host := config.remoteHost(); host != "" && vncIP == "0.0.0.0" {
+		vncIP = host
+	}
+
+	// Connect to VNC
+	ui.Say(fmt.Sprintf("Connecting to VM via VNC (%s:%d)", vncIP, vncPort))
+
+	nc, err := net.Dial("tcp", net.JoinHostPort(vncIP, strconv.Itoa(vncPort)))
+	if err != nil {
+		err := fmt.Errorf("Error connecting to VNC: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	defer nc.Close()
+
+	c, err := vnc.Client(nc, &vnc.ClientConfig{Exclusive: false})
+	if err != nil {
+		err := fmt.Errorf("Error handshaking with VNC: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	defer c.Close()
+
+	log.Printf("Connected to VNC desktop: %s", c.DesktopName)
+
+	hostIP, err := httpIP(ctx, config, driver)
+	if err != nil {
+		err := fmt.Errorf("Error finding the address of the HTTP server: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	common.SetHTTPIP(hostIP)
+	configCtx := config.ctx
+	configCtx.Data = &bootCommandTemplateData{
+		hostIP,
+		httpPort,
+		config.VMName,
+	}
+
+	d := bootcommand.NewVNCDriver(c, config.VNCConfig.BootKeyInterval)
+
+	ui.Say("Typing the boot command over VNC...")
+	command, err := interpolate.Render(config.VNCConfig.FlatBootCommand(), &configCtx)
+	if err != nil {
+		err := fmt.Errorf("Error preparing boot command: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	seq, err := bootcommand.GenerateExpressionSequence(command)
+	if err != nil {
+		err := fmt.Errorf("Error generating boot command: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	if err := seq.Do(ctx, d); err != nil {
+		err := fmt.Errorf("Error running boot command: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	if pauseFn != nil {
+		pauseFn(multistep.DebugLocationAfterRun, fmt.Sprintf("boot_command: %s", command), state)
+	}
+
+	return multistep.ActionContinue
+}
+
+func (*stepTypeBootCommand) Cleanup(multistep.StateBag) {}
+
+// httpIP returns the address that the domain reaches the HTTP server on.
+// That's the address of the host on the network of the domain when
+// libvirtd is local, or the address packer reaches the remote host from.
+func httpIP(ctx context.Context, config *Config, driver Driver) (string, error) {
+	host := config.remoteHost()
+	if host == "" {
+		return driver.NetworkAddress(ctx, config.Network)
+	}
+
+	conn, err := net.Dial("udp", net.JoinHostPort(host, "22"))
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	return conn.LocalAddr().(*net.UDPAddr).IP.String(), nil
+}
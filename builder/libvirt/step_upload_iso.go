@@ -0,0 +1,75 @@
+package libvirt
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// This step uploads the ISO into a volume of the storage pool, so that
+// remote hosts can attach it to the domain.
+//
+// Uses:
+//   config *Config
+//   driver Driver
+//   iso_path string
+//   ui     packer.Ui
+//
+// Produces:
+//   iso_volume string - The name of the volume of the ISO
+type stepUploadISO struct {
+	volume string
+}
+
+func (s *stepUploadISO) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	config := state.Get("config").(*Config)
+	driver := state.Get("driver").(Driver)
+	isoPath := state.Get("iso_path").(string)
+	ui := state.Get("ui").(packer.Ui)
+
+	fi, err := os.Stat(isoPath)
+	if err != nil {
+		err := fmt.Errorf("Error reading ISO: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	name := config.VMName + ".iso"
+	ui.Say(fmt.Sprintf("Uploading ISO to volume %s in pool %s...", name, config.StoragePool))
+	if err := driver.CreateVolume(ctx, config.StoragePool, name, uint64(fi.Size()), "raw"); err != nil {
+		err := fmt.Errorf("Error creating ISO volume: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	s.volume = name
+
+	if err := driver.UploadVolume(ctx, config.StoragePool, name, isoPath); err != nil {
+		err := fmt.Errorf("Error uploading ISO: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	state.Put("iso_volume", name)
+	return multistep.ActionContinue
+}
+
+func (s *stepUploadISO) Cleanup(state multistep.StateBag) {
+	if s.volume == "" {
+		return
+	}
+
+	config := state.Get("config").(*Config)
+	driver := state.Get("driver").(Driver)
+	ui := state.Get("ui").(packer.Ui)
+
+	ui.Say("Deleting ISO volume...")
+	if err := driver.DeleteVolume(context.TODO(), config.StoragePool, s.volume); err != nil {
+		ui.Error(fmt.Sprintf("Error deleting ISO volume: %s", err))
+	}
+}
@@ -64,6 +64,7 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (ret
 			Comm: &b.config.Comm,
 		},
 		&stepShutdownLinode{client},
+		&stepResizeDisk{client},
 		&stepCreateImage{client},
 	}
 
@@ -188,6 +188,50 @@ func TestBuilderPrepare_StateTimeout(t *testing.T) {
 
 }
 
+func TestBuilderPrepare_ImageMaxSize(t *testing.T) {
+	var b Builder
+	config := testConfig()
+
+	// Test default
+	warnings, err := b.Prepare(config)
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	if b.config.ImageMaxSize != 6144 {
+		t.Errorf("invalid: %d", b.config.ImageMaxSize)
+	}
+
+	// Test set
+	config["image_max_size"] = 10240
+	b = Builder{}
+	warnings, err = b.Prepare(config)
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	if b.config.ImageMaxSize != 10240 {
+		t.Errorf("invalid: %d", b.config.ImageMaxSize)
+	}
+
+	// Test bad
+	config["image_max_size"] = -1
+	b = Builder{}
+	warnings, err = b.Prepare(config)
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err == nil {
+		t.Fatal("should have error")
+	}
+}
+
 func TestBuilderPrepare_ImageLabel(t *testing.T) {
 	var b Builder
 	config := testConfig()
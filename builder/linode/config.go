@@ -33,6 +33,7 @@ type Config struct {
 	RootSSHKey   string   `mapstructure:"root_ssh_key"`
 	ImageLabel   string   `mapstructure:"image_label"`
 	Description  string   `mapstructure:"image_description"`
+	ImageMaxSize int      `mapstructure:"image_max_size"`
 
 	RawStateTimeout string `mapstructure:"state_timeout"`
 
@@ -40,6 +41,10 @@ type Config struct {
 	interCtx     interpolate.Context
 }
 
+// defaultImageMaxSize is the size in MB of the largest disk Linode
+// Images accept by default.
+const defaultImageMaxSize = 6144
+
 func createRandomRootPassword() (string, error) {
 	rawRootPass := make([]byte, 50)
 	_, err := rand.Read(rawRootPass)
@@ -99,6 +104,10 @@ func NewConfig(raws ...interface{}) (*Config, []string, error) {
 		}
 	}
 
+	if c.ImageMaxSize == 0 {
+		c.ImageMaxSize = defaultImageMaxSize
+	}
+
 	if c.RawStateTimeout == "" {
 		c.stateTimeout = 5 * time.Minute
 	} else {
@@ -136,6 +145,11 @@ func NewConfig(raws ...interface{}) (*Config, []string, error) {
 			errs, errors.New("image is required"))
 	}
 
+	if c.ImageMaxSize < 0 {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("image_max_size must be positive"))
+	}
+
 	if c.Tags == nil {
 		c.Tags = make([]string, 0)
 	}
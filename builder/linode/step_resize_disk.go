@@ -0,0 +1,47 @@
+package linode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+	"github.com/linode/linodego"
+)
+
+// stepResizeDisk shrinks the disk of the Linode to the largest size an
+// image can be created from. Linodes are created with a disk using all of
+// the storage of their type, which is often larger than that.
+type stepResizeDisk struct {
+	client linodego.Client
+}
+
+func (s *stepResizeDisk) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	c := state.Get("config").(*Config)
+	ui := state.Get("ui").(packer.Ui)
+	disk := state.Get("disk").(*linodego.InstanceDisk)
+	instance := state.Get("instance").(*linodego.Instance)
+
+	if disk.Size <= c.ImageMaxSize {
+		return multistep.ActionContinue
+	}
+
+	ui.Say(fmt.Sprintf("Resizing disk from %d MB to %d MB...", disk.Size, c.ImageMaxSize))
+	err := s.client.ResizeInstanceDisk(ctx, instance.ID, disk.ID, c.ImageMaxSize)
+	if err == nil {
+		disk, err = s.client.WaitForInstanceDiskStatus(ctx, instance.ID, disk.ID, linodego.DiskReady,
+			int(c.stateTimeout.Seconds()))
+	}
+	if err != nil {
+		err = errors.New("Error resizing disk: " + err.Error())
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	state.Put("disk", disk)
+	return multistep.ActionContinue
+}
+
+func (s *stepResizeDisk) Cleanup(state multistep.StateBag) {}
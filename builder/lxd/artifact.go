@@ -6,6 +6,10 @@ import (
 
 type Artifact struct {
 	id string
+
+	// remote is the image server the image was published to, if not the
+	// local image store.
+	remote string
 }
 
 func (*Artifact) BuilderId() string {
@@ -21,6 +25,9 @@ func (a *Artifact) Id() string {
 }
 
 func (a *Artifact) String() string {
+	if a.remote != "" {
+		return fmt.Sprintf("image: %s:%s", a.remote, a.id)
+	}
 	return fmt.Sprintf("image: %s", a.id)
 }
 
@@ -29,6 +36,10 @@ func (a *Artifact) State(name string) interface{} {
 }
 
 func (a *Artifact) Destroy() error {
-	_, err := LXDCommand("image", "delete", a.id)
+	image := a.id
+	if a.remote != "" {
+		image = fmt.Sprintf("%s:%s", a.remote, a.id)
+	}
+	_, err := LXDCommand("image", "delete", image)
 	return err
 }
@@ -60,7 +60,8 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 	}
 
 	artifact := &Artifact{
-		id: state.Get("imageFingerprint").(string),
+		id:     state.Get("imageFingerprint").(string),
+		remote: b.config.PublishRemoteName,
 	}
 
 	return artifact, nil
@@ -54,6 +54,25 @@ func TestBuilderPrepare_ConfigFile(t *testing.T) {
 		t.Fatalf("should not have error: %s", err)
 	}
 
+	// Good, publish to a remote image server
+	config = testConfig()
+	config["publish_remote_name"] = "remote"
+	config["virtual_machine"] = true
+	b = Builder{}
+	warnings, err = b.Prepare(config)
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+	if b.config.PublishRemoteName != "remote" {
+		t.Fatalf("bad: %s", b.config.PublishRemoteName)
+	}
+	if !b.config.VirtualMachine {
+		t.Fatal("virtual_machine should be set")
+	}
+
 	// Bad, missing image name
 	config = testConfig()
 	delete(config, "image")
@@ -19,7 +19,9 @@ type Config struct {
 	Profile             string            `mapstructure:"profile"`
 	InitSleep           string            `mapstructure:"init_sleep"`
 	PublishProperties   map[string]string `mapstructure:"publish_properties"`
+	PublishRemoteName   string            `mapstructure:"publish_remote_name"`
 	LaunchConfig        map[string]string `mapstructure:"launch_config"`
+	VirtualMachine      bool              `mapstructure:"virtual_machine"`
 
 	ctx interpolate.Context
 }
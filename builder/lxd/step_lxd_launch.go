@@ -29,6 +29,10 @@ func (s *stepLxdLaunch) Run(ctx context.Context, state multistep.StateBag) multi
 		launch_args = append(launch_args, "--config", fmt.Sprintf("%s=%s", k, v))
 	}
 
+	if config.VirtualMachine {
+		launch_args = append(launch_args, "--vm")
+	}
+
 	ui.Say("Creating container...")
 	_, err := LXDCommand(launch_args...)
 	if err != nil {
@@ -30,10 +30,15 @@ func (s *stepPublish) Run(ctx context.Context, state multistep.StateBag) multist
 		return multistep.ActionHalt
 	}
 
-	publish_args := []string{
-		"publish", name, "--alias", config.OutputImage,
+	publish_args := []string{"publish", name}
+
+	// Publish straight to the image server instead of the local image store
+	if config.PublishRemoteName != "" {
+		publish_args = append(publish_args, config.PublishRemoteName+":")
 	}
 
+	publish_args = append(publish_args, "--alias", config.OutputImage)
+
 	for k, v := range config.PublishProperties {
 		publish_args = append(publish_args, fmt.Sprintf("%s=%s", k, v))
 	}
@@ -0,0 +1,49 @@
+package nutanix
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+type Artifact struct {
+	// The UUID of the image
+	imageUUID string
+
+	// The name of the image
+	name string
+
+	// The client for making API calls
+	client *client
+}
+
+func (*Artifact) BuilderId() string {
+	return BuilderId
+}
+
+func (*Artifact) Files() []string {
+	// No files with Nutanix
+	return nil
+}
+
+func (a *Artifact) Id() string {
+	return a.imageUUID
+}
+
+func (a *Artifact) String() string {
+	return fmt.Sprintf("An image was created: '%s' (UUID: %s)", a.name, a.imageUUID)
+}
+
+func (a *Artifact) State(name string) interface{} {
+	return nil
+}
+
+func (a *Artifact) Destroy() error {
+	log.Printf("Deleting image: %s (%s)", a.imageUUID, a.name)
+	taskUUID, err := a.client.DeleteImage(context.TODO(), a.imageUUID)
+	if err != nil {
+		return err
+	}
+	return waitForTask(context.TODO(), a.client, taskUUID, 10*time.Minute)
+}
@@ -0,0 +1,24 @@
+package nutanix
+
+import (
+	"testing"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+func TestArtifact_Impl(t *testing.T) {
+	var raw interface{}
+	raw = &Artifact{}
+	if _, ok := raw.(packer.Artifact); !ok {
+		t.Fatalf("Artifact should be artifact")
+	}
+}
+
+func TestArtifactString(t *testing.T) {
+	a := &Artifact{"0b2d4ab8-4bfa-4c1f-9e57-7bb1bd1e6a43", "packer-foobar", nil}
+	expected := "An image was created: 'packer-foobar' (UUID: 0b2d4ab8-4bfa-4c1f-9e57-7bb1bd1e6a43)"
+
+	if a.String() != expected {
+		t.Fatalf("artifact string should match: %v", expected)
+	}
+}
@@ -0,0 +1,113 @@
+package nutanix
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+func testConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"prism_host":        "prism.example.com",
+		"prism_username":    "admin",
+		"prism_password":    "secret",
+		"cluster_name":      "cluster",
+		"subnet_name":       "vlan0",
+		"source_image_name": "centos-7",
+		"ssh_username":      "root",
+	}
+}
+
+func TestBuilder_ImplementsBuilder(t *testing.T) {
+	var raw interface{}
+	raw = &Builder{}
+	if _, ok := raw.(packer.Builder); !ok {
+		t.Fatalf("Builder should be a builder")
+	}
+}
+
+func TestBuilderPrepare_Defaults(t *testing.T) {
+	var b Builder
+	warnings, err := b.Prepare(testConfig())
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	if b.config.PrismPort != 9440 {
+		t.Errorf("bad prism port: %d", b.config.PrismPort)
+	}
+
+	if b.config.CpuCount != 1 {
+		t.Errorf("bad cpus: %d", b.config.CpuCount)
+	}
+
+	if b.config.MemorySize != 2048 {
+		t.Errorf("bad memory: %d", b.config.MemorySize)
+	}
+
+	if b.config.DiskSize != 0 {
+		t.Errorf("the disk of the source image should keep its size: %d", b.config.DiskSize)
+	}
+
+	if b.config.ShutdownTimeout != 5*time.Minute {
+		t.Errorf("bad shutdown timeout: %s", b.config.ShutdownTimeout)
+	}
+}
+
+func TestBuilderPrepare_InvalidKey(t *testing.T) {
+	var b Builder
+	config := testConfig()
+
+	// Add a random key
+	config["i_should_not_be_valid"] = true
+	warnings, err := b.Prepare(config)
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestBuilderPrepare_Required(t *testing.T) {
+	for _, key := range []string{"prism_host", "prism_password", "cluster_name", "subnet_name"} {
+		var b Builder
+		config := testConfig()
+		delete(config, key)
+		if _, err := b.Prepare(config); err == nil {
+			t.Errorf("%s: should have error", key)
+		}
+	}
+}
+
+func TestBuilderPrepare_Source(t *testing.T) {
+	var b Builder
+	config := testConfig()
+
+	// Neither an image nor an ISO
+	delete(config, "source_image_name")
+	if _, err := b.Prepare(config); err == nil {
+		t.Fatal("should have error")
+	}
+
+	// An ISO gets a blank disk
+	config["iso_image_name"] = "centos-7-iso"
+	b = Builder{}
+	if _, err := b.Prepare(config); err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+	if b.config.DiskSize != 40960 {
+		t.Errorf("bad disk size: %d", b.config.DiskSize)
+	}
+
+	// Both
+	config["source_image_name"] = "centos-7"
+	b = Builder{}
+	if _, err := b.Prepare(config); err == nil {
+		t.Fatal("should have error")
+	}
+}
@@ -0,0 +1,355 @@
+package nutanix
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/packer/version"
+)
+
+// client is a minimal client for the parts of version 3 of the Prism
+// Central API that the builder uses.
+type client struct {
+	endpoint string
+	username string
+	password string
+	http     *http.Client
+}
+
+func newClient(host string, port int, username, password string, insecure bool) *client {
+	return &client{
+		endpoint: fmt.Sprintf("https://%s/api/nutanix/v3/",
+			net.JoinHostPort(host, strconv.Itoa(port))),
+		username: username,
+		password: password,
+		http: &http.Client{
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: insecure},
+			},
+		},
+	}
+}
+
+type reference struct {
+	Kind string `json:"kind"`
+	UUID string `json:"uuid"`
+}
+
+type diskAddress struct {
+	AdapterType string `json:"adapter_type"`
+	DeviceIndex int    `json:"device_index"`
+}
+
+type deviceProperties struct {
+	DeviceType  string       `json:"device_type"`
+	DiskAddress *diskAddress `json:"disk_address,omitempty"`
+}
+
+type vmDisk struct {
+	UUID                string           `json:"uuid,omitempty"`
+	DataSourceReference *reference       `json:"data_source_reference,omitempty"`
+	DeviceProperties    deviceProperties `json:"device_properties"`
+	DiskSizeMib         int              `json:"disk_size_mib,omitempty"`
+}
+
+type ipEndpoint struct {
+	IP string `json:"ip"`
+}
+
+type vmNic struct {
+	SubnetReference *reference   `json:"subnet_reference,omitempty"`
+	IPEndpointList  []ipEndpoint `json:"ip_endpoint_list,omitempty"`
+}
+
+type bootConfig struct {
+	BootDeviceOrderList []string `json:"boot_device_order_list"`
+}
+
+type cloudInit struct {
+	UserData string `json:"user_data"`
+}
+
+type guestCustomization struct {
+	CloudInit *cloudInit `json:"cloud_init"`
+}
+
+type vmResources struct {
+	NumSockets         int                 `json:"num_sockets"`
+	NumVcpusPerSocket  int                 `json:"num_vcpus_per_socket"`
+	MemorySizeMib      int                 `json:"memory_size_mib"`
+	PowerState         string              `json:"power_state"`
+	DiskList           []vmDisk            `json:"disk_list"`
+	NicList            []vmNic             `json:"nic_list"`
+	BootConfig         *bootConfig         `json:"boot_config,omitempty"`
+	GuestCustomization *guestCustomization `json:"guest_customization,omitempty"`
+}
+
+type vmSpec struct {
+	Name             string      `json:"name"`
+	Description      string      `json:"description,omitempty"`
+	Resources        vmResources `json:"resources"`
+	ClusterReference *reference  `json:"cluster_reference"`
+}
+
+type vm struct {
+	Metadata struct {
+		UUID string `json:"uuid"`
+	} `json:"metadata"`
+	Status struct {
+		State     string      `json:"state"`
+		Resources vmResources `json:"resources"`
+	} `json:"status"`
+}
+
+// ip returns the first IP address of the NICs of the VM, or "" until the
+// guest has one.
+func (v *vm) ip() string {
+	for _, nic := range v.Status.Resources.NicList {
+		for _, endpoint := range nic.IPEndpointList {
+			if endpoint.IP != "" {
+				return endpoint.IP
+			}
+		}
+	}
+	return ""
+}
+
+// diskUUID returns the UUID of the first disk of the VM, which isn't a
+// CD-ROM.
+func (v *vm) diskUUID() string {
+	for _, disk := range v.Status.Resources.DiskList {
+		if disk.DeviceProperties.DeviceType == "DISK" {
+			return disk.UUID
+		}
+	}
+	return ""
+}
+
+type task struct {
+	UUID            string `json:"uuid"`
+	Status          string `json:"status"`
+	ProgressMessage string `json:"progress_message"`
+	ErrorDetail     string `json:"error_detail"`
+}
+
+// intentResponse is the response to the requests that change an entity,
+// which the API carries out asynchronously in a task.
+type intentResponse struct {
+	Metadata struct {
+		UUID string `json:"uuid"`
+	} `json:"metadata"`
+	Status struct {
+		ExecutionContext struct {
+			TaskUUID string `json:"task_uuid"`
+		} `json:"execution_context"`
+	} `json:"status"`
+}
+
+// ClusterUUID returns the UUID of the cluster with the given name.
+func (c *client) ClusterUUID(ctx context.Context, name string) (string, error) {
+	return c.uuidByName(ctx, "cluster", name)
+}
+
+// SubnetUUID returns the UUID of the subnet with the given name.
+func (c *client) SubnetUUID(ctx context.Context, name string) (string, error) {
+	return c.uuidByName(ctx, "subnet", name)
+}
+
+// ImageUUID returns the UUID of the image with the given name.
+func (c *client) ImageUUID(ctx context.Context, name string) (string, error) {
+	return c.uuidByName(ctx, "image", name)
+}
+
+// CreateVM creates a VM, and returns its UUID and the UUID of the task
+// creating it.
+func (c *client) CreateVM(ctx context.Context, spec *vmSpec) (string, string, error) {
+	body := map[string]interface{}{
+		"spec":     spec,
+		"metadata": map[string]string{"kind": "vm"},
+	}
+	var resp intentResponse
+	if err := c.do(ctx, "POST", "vms", body, &resp); err != nil {
+		return "", "", err
+	}
+	return resp.Metadata.UUID, resp.Status.ExecutionContext.TaskUUID, nil
+}
+
+func (c *client) GetVM(ctx context.Context, uuid string) (*vm, error) {
+	v := new(vm)
+	if err := c.do(ctx, "GET", "vms/"+uuid, nil, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// PowerOffVM turns the VM off, without shutting its guest down, and returns
+// the UUID of the task. The API only updates a VM from its whole spec, so
+// the current one is sent back with another power state.
+func (c *client) PowerOffVM(ctx context.Context, uuid string) (string, error) {
+	var v map[string]interface{}
+	if err := c.do(ctx, "GET", "vms/"+uuid, nil, &v); err != nil {
+		return "", err
+	}
+	delete(v, "status")
+
+	spec, _ := v["spec"].(map[string]interface{})
+	resources, ok := spec["resources"].(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf("VM %s has no spec", uuid)
+	}
+	resources["power_state"] = "OFF"
+
+	var resp intentResponse
+	if err := c.do(ctx, "PUT", "vms/"+uuid, v, &resp); err != nil {
+		return "", err
+	}
+	return resp.Status.ExecutionContext.TaskUUID, nil
+}
+
+// DeleteVM deletes the VM and its disks, and returns the UUID of the task.
+func (c *client) DeleteVM(ctx context.Context, uuid string) (string, error) {
+	var resp intentResponse
+	if err := c.do(ctx, "DELETE", "vms/"+uuid, nil, &resp); err != nil {
+		return "", err
+	}
+	return resp.Status.ExecutionContext.TaskUUID, nil
+}
+
+// CreateImage creates a disk image from a disk of a VM, and returns its UUID
+// and the UUID of the task creating it.
+func (c *client) CreateImage(ctx context.Context, name, description, diskUUID string) (string, string, error) {
+	body := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"name":        name,
+			"description": description,
+			"resources": map[string]interface{}{
+				"image_type": "DISK_IMAGE",
+				"data_source_reference": &reference{
+					Kind: "vm_disk",
+					UUID: diskUUID,
+				},
+			},
+		},
+		"metadata": map[string]string{"kind": "image"},
+	}
+	var resp intentResponse
+	if err := c.do(ctx, "POST", "images", body, &resp); err != nil {
+		return "", "", err
+	}
+	return resp.Metadata.UUID, resp.Status.ExecutionContext.TaskUUID, nil
+}
+
+// DeleteImage deletes the image, and returns the UUID of the task.
+func (c *client) DeleteImage(ctx context.Context, uuid string) (string, error) {
+	var resp intentResponse
+	if err := c.do(ctx, "DELETE", "images/"+uuid, nil, &resp); err != nil {
+		return "", err
+	}
+	return resp.Status.ExecutionContext.TaskUUID, nil
+}
+
+func (c *client) GetTask(ctx context.Context, uuid string) (*task, error) {
+	t := new(task)
+	if err := c.do(ctx, "GET", "tasks/"+uuid, nil, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// uuidByName returns the UUID of the only entity of the kind with the given
+// name.
+func (c *client) uuidByName(ctx context.Context, kind, name string) (string, error) {
+	body := map[string]interface{}{
+		"kind":   kind,
+		"filter": "name==" + name,
+		"length": 2,
+	}
+	var resp struct {
+		Entities []struct {
+			Metadata struct {
+				UUID string `json:"uuid"`
+			} `json:"metadata"`
+		} `json:"entities"`
+	}
+	if err := c.do(ctx, "POST", kind+"s/list", body, &resp); err != nil {
+		return "", err
+	}
+
+	switch len(resp.Entities) {
+	case 0:
+		return "", fmt.Errorf("%s %q not found", kind, name)
+	case 1:
+		return resp.Entities[0].Metadata.UUID, nil
+	default:
+		return "", fmt.Errorf("more than one %s is named %q", kind, name)
+	}
+}
+
+// do sends a request with the JSON of body, if it isn't nil, and decodes the
+// JSON response into result.
+func (c *client) do(ctx context.Context, method, path string, body, result interface{}) error {
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(method, c.endpoint+path, &buf)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.SetBasicAuth(c.username, c.password)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", fmt.Sprintf("Packer/%s", version.FormattedVersion()))
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s %s: %s", method, req.URL.Path, apiError(resp.Status, respBody))
+	}
+
+	if result == nil || len(respBody) == 0 {
+		return nil
+	}
+	return json.Unmarshal(respBody, result)
+}
+
+// apiError returns the messages of an error response of the API, which
+// lists them, or the response itself when it isn't one.
+func apiError(status string, body []byte) string {
+	var resp struct {
+		MessageList []struct {
+			Message string `json:"message"`
+			Reason  string `json:"reason"`
+		} `json:"message_list"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil || len(resp.MessageList) == 0 {
+		return fmt.Sprintf("%s: %s", status, strings.TrimSpace(string(body)))
+	}
+
+	messages := make([]string, 0, len(resp.MessageList))
+	for _, m := range resp.MessageList {
+		messages = append(messages, m.Message)
+	}
+	return fmt.Sprintf("%s: %s", status, strings.Join(messages, "; "))
+}
@@ -0,0 +1,167 @@
+package nutanix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// testClient returns a client for a fake API that handles requests with
+// handler, checking that they are authenticated.
+func testClient(t *testing.T, handler http.HandlerFunc) (*client, func()) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, _ := r.BasicAuth(); username != "admin" || password != "secret" {
+			t.Errorf("bad credentials: %q, %q", username, password)
+		}
+		handler(w, r)
+	}))
+
+	c := newClient("127.0.0.1", 9440, "admin", "secret", true)
+	c.endpoint = ts.URL + "/api/nutanix/v3/"
+	return c, ts.Close
+}
+
+func TestNewClient(t *testing.T) {
+	c := newClient("prism.example.com", 9440, "admin", "secret", false)
+	if c.endpoint != "https://prism.example.com:9440/api/nutanix/v3/" {
+		t.Fatalf("bad endpoint: %s", c.endpoint)
+	}
+}
+
+func TestClient_ImageUUID(t *testing.T) {
+	c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/nutanix/v3/images/list" {
+			t.Errorf("bad request: %s %s", r.Method, r.URL.Path)
+		}
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		switch body["filter"] {
+		case "name==centos-7":
+			fmt.Fprint(w, `{"entities": [{"metadata": {"uuid": "image-uuid"}}]}`)
+		case "name==twice":
+			fmt.Fprint(w, `{"entities": [{"metadata": {"uuid": "a"}}, {"metadata": {"uuid": "b"}}]}`)
+		default:
+			fmt.Fprint(w, `{"entities": []}`)
+		}
+	})
+	defer done()
+
+	uuid, err := c.ImageUUID(context.Background(), "centos-7")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if uuid != "image-uuid" {
+		t.Fatalf("bad uuid: %s", uuid)
+	}
+
+	if _, err := c.ImageUUID(context.Background(), "missing"); err == nil {
+		t.Fatal("should have error")
+	}
+	if _, err := c.ImageUUID(context.Background(), "twice"); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestClient_PowerOffVM(t *testing.T) {
+	c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/nutanix/v3/vms/vm-uuid" {
+			t.Errorf("bad request: %s %s", r.Method, r.URL.Path)
+		}
+		switch r.Method {
+		case "GET":
+			fmt.Fprint(w, `{
+				"metadata": {"kind": "vm", "uuid": "vm-uuid", "spec_version": 3},
+				"spec": {"name": "packer", "resources": {"power_state": "ON", "memory_size_mib": 2048}},
+				"status": {"state": "COMPLETE"}
+			}`)
+		case "PUT":
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			if _, ok := body["status"]; ok {
+				t.Errorf("the status should not be sent")
+			}
+			resources := body["spec"].(map[string]interface{})["resources"].(map[string]interface{})
+			if resources["power_state"] != "OFF" || resources["memory_size_mib"] != 2048.0 {
+				t.Errorf("bad resources: %#v", resources)
+			}
+			if body["metadata"].(map[string]interface{})["spec_version"] != 3.0 {
+				t.Errorf("the spec version should be sent back")
+			}
+			fmt.Fprint(w, `{"status": {"execution_context": {"task_uuid": "task-uuid"}}}`)
+		}
+	})
+	defer done()
+
+	taskUUID, err := c.PowerOffVM(context.Background(), "vm-uuid")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if taskUUID != "task-uuid" {
+		t.Fatalf("bad task uuid: %s", taskUUID)
+	}
+}
+
+func TestClient_Error(t *testing.T) {
+	c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"state": "ERROR", "message_list": [{"reason": "ENTITY_NOT_FOUND", "message": "VM vm-uuid not found"}]}`)
+	})
+	defer done()
+
+	_, err := c.GetVM(context.Background(), "vm-uuid")
+	if err == nil {
+		t.Fatal("should have error")
+	}
+	if !strings.Contains(err.Error(), "404 Not Found: VM vm-uuid not found") {
+		t.Fatalf("bad error: %s", err)
+	}
+}
+
+func TestWaitForTask(t *testing.T) {
+	c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/nutanix/v3/tasks/ok":
+			fmt.Fprint(w, `{"uuid": "ok", "status": "SUCCEEDED"}`)
+		case "/api/nutanix/v3/tasks/failed":
+			fmt.Fprint(w, `{"uuid": "failed", "status": "FAILED", "error_detail": "out of space"}`)
+		}
+	})
+	defer done()
+
+	if err := waitForTask(context.Background(), c, "ok", 0); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	err := waitForTask(context.Background(), c, "failed", 0)
+	if err == nil || !strings.Contains(err.Error(), "out of space") {
+		t.Fatalf("bad error: %v", err)
+	}
+}
+
+func TestVM(t *testing.T) {
+	var v vm
+	err := json.Unmarshal([]byte(`{"status": {"resources": {
+		"disk_list": [
+			{"uuid": "cdrom-uuid", "device_properties": {"device_type": "CDROM"}},
+			{"uuid": "disk-uuid", "device_properties": {"device_type": "DISK"}}
+		],
+		"nic_list": [
+			{"ip_endpoint_list": []},
+			{"ip_endpoint_list": [{"ip": "10.0.0.12"}]}
+		]
+	}}}`), &v)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if v.diskUUID() != "disk-uuid" {
+		t.Errorf("bad disk uuid: %s", v.diskUUID())
+	}
+	if v.ip() != "10.0.0.12" {
+		t.Errorf("bad ip: %s", v.ip())
+	}
+}
@@ -0,0 +1,175 @@
+package nutanix
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/uuid"
+	"github.com/hashicorp/packer/helper/communicator"
+	"github.com/hashicorp/packer/helper/config"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/template/interpolate"
+)
+
+type Config struct {
+	common.PackerConfig `mapstructure:",squash"`
+	Comm                communicator.Config `mapstructure:",squash"`
+
+	PrismHost     string `mapstructure:"prism_host"`
+	PrismPort     int    `mapstructure:"prism_port"`
+	PrismUsername string `mapstructure:"prism_username"`
+	PrismPassword string `mapstructure:"prism_password"`
+	Insecure      bool   `mapstructure:"insecure_skip_tls_verify"`
+
+	ClusterName     string `mapstructure:"cluster_name"`
+	SubnetName      string `mapstructure:"subnet_name"`
+	SourceImageName string `mapstructure:"source_image_name"`
+	ISOImageName    string `mapstructure:"iso_image_name"`
+
+	VMName       string `mapstructure:"vm_name"`
+	CpuCount     int    `mapstructure:"cpus"`
+	MemorySize   int    `mapstructure:"memory"`
+	DiskSize     int    `mapstructure:"disk_size"`
+	UserData     string `mapstructure:"user_data"`
+	UserDataFile string `mapstructure:"user_data_file"`
+
+	ImageName        string `mapstructure:"image_name"`
+	ImageDescription string `mapstructure:"image_description"`
+
+	ShutdownCommand string        `mapstructure:"shutdown_command"`
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+	TaskTimeout     time.Duration `mapstructure:"task_timeout"`
+	IPWaitTimeout   time.Duration `mapstructure:"ip_wait_timeout"`
+
+	ctx interpolate.Context
+}
+
+func NewConfig(raws ...interface{}) (*Config, []string, error) {
+	c := new(Config)
+
+	err := config.Decode(c, &config.DecodeOpts{
+		Interpolate:        true,
+		InterpolateContext: &c.ctx,
+	}, raws...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Defaults
+	if c.PrismPort == 0 {
+		c.PrismPort = 9440
+	}
+
+	if c.PrismUsername == "" {
+		c.PrismUsername = os.Getenv("NUTANIX_USERNAME")
+	}
+
+	if c.PrismPassword == "" {
+		c.PrismPassword = os.Getenv("NUTANIX_PASSWORD")
+	}
+
+	if c.VMName == "" {
+		// Default to packer-[time-ordered-uuid]
+		c.VMName = fmt.Sprintf("packer-%s", uuid.TimeOrderedUUID())
+	}
+
+	if c.CpuCount < 1 {
+		c.CpuCount = 1
+	}
+
+	if c.MemorySize < 1 {
+		c.MemorySize = 2048
+	}
+
+	// A VM installed from an ISO needs a blank disk to be installed on
+	if c.DiskSize == 0 && c.ISOImageName != "" {
+		c.DiskSize = 40960
+	}
+
+	if c.ImageName == "" {
+		def, err := interpolate.Render("packer-{{timestamp}}", nil)
+		if err != nil {
+			panic(err)
+		}
+
+		// Default to packer-{{ unix timestamp (utc) }}
+		c.ImageName = def
+	}
+
+	if c.ShutdownCommand == "" {
+		c.ShutdownCommand = "shutdown -P now"
+	}
+
+	if c.ShutdownTimeout == 0 {
+		c.ShutdownTimeout = 5 * time.Minute
+	}
+
+	if c.TaskTimeout == 0 {
+		c.TaskTimeout = 30 * time.Minute
+	}
+
+	if c.IPWaitTimeout == 0 {
+		c.IPWaitTimeout = 30 * time.Minute
+	}
+
+	var errs *packer.MultiError
+	if es := c.Comm.Prepare(&c.ctx); len(es) > 0 {
+		errs = packer.MultiErrorAppend(errs, es...)
+	}
+
+	if c.PrismHost == "" {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("prism_host must be specified"))
+	}
+
+	if c.PrismUsername == "" || c.PrismPassword == "" {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("prism_username and prism_password must be specified"))
+	}
+
+	if c.ClusterName == "" {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("cluster_name must be specified"))
+	}
+
+	if c.SubnetName == "" {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("subnet_name must be specified"))
+	}
+
+	if c.SourceImageName == "" && c.ISOImageName == "" {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("one of source_image_name or iso_image_name is required"))
+	} else if c.SourceImageName != "" && c.ISOImageName != "" {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("only one of source_image_name or iso_image_name can be specified"))
+	}
+
+	if c.UserData != "" && c.UserDataFile != "" {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("only one of user_data or user_data_file can be specified"))
+	} else if c.UserDataFile != "" {
+		contents, err := ioutil.ReadFile(c.UserDataFile)
+		if err != nil {
+			errs = packer.MultiErrorAppend(
+				errs, fmt.Errorf("user_data_file not found: %s", c.UserDataFile))
+		}
+		c.UserData = string(contents)
+	}
+
+	if errs != nil && len(errs.Errors) > 0 {
+		return nil, nil, errs
+	}
+
+	packer.LogSecretFilter.Set(c.PrismPassword)
+	return c, nil, nil
+}
+
+func commHost(state multistep.StateBag) (string, error) {
+	return state.Get("vm_ip").(string), nil
+}
@@ -0,0 +1,50 @@
+package nutanix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// stepCreateImage saves the disk of the VM as an image of the image service.
+type stepCreateImage struct{}
+
+func (s *stepCreateImage) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	client := state.Get("client").(*client)
+	ui := state.Get("ui").(packer.Ui)
+	c := state.Get("config").(*Config)
+	vmUUID := state.Get("vm_uuid").(string)
+
+	v, err := client.GetVM(ctx, vmUUID)
+	if err == nil && v.diskUUID() == "" {
+		err = errors.New("the VM has no disk")
+	}
+	if err != nil {
+		err := fmt.Errorf("Error creating image: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	ui.Say(fmt.Sprintf("Creating image: %s", c.ImageName))
+	imageUUID, taskUUID, err := client.CreateImage(ctx, c.ImageName, c.ImageDescription, v.diskUUID())
+	if err == nil {
+		err = waitForTask(ctx, client, taskUUID, c.TaskTimeout)
+	}
+	if err != nil {
+		err := fmt.Errorf("Error creating image: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	state.Put("image_uuid", imageUUID)
+	return multistep.ActionContinue
+}
+
+func (s *stepCreateImage) Cleanup(state multistep.StateBag) {
+	// no cleanup
+}
@@ -0,0 +1,152 @@
+package nutanix
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// stepCreateVM creates the VM from the source image, or with a blank disk
+// and the ISO image, powers it on and waits for its IP address.
+type stepCreateVM struct {
+	vmUUID string
+}
+
+func (s *stepCreateVM) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	client := state.Get("client").(*client)
+	ui := state.Get("ui").(packer.Ui)
+	c := state.Get("config").(*Config)
+
+	spec, err := vmSpecFromConfig(ctx, client, c)
+	if err != nil {
+		err := fmt.Errorf("Error creating VM: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	ui.Say(fmt.Sprintf("Creating VM %s...", c.VMName))
+	vmUUID, taskUUID, err := client.CreateVM(ctx, spec)
+	if err == nil {
+		// We use this in cleanup
+		s.vmUUID = vmUUID
+		err = waitForTask(ctx, client, taskUUID, c.TaskTimeout)
+	}
+	if err != nil {
+		err := fmt.Errorf("Error creating VM: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	state.Put("vm_uuid", vmUUID)
+
+	ui.Say("Waiting for the IP address of the VM...")
+	v, err := waitForVM(ctx, client, vmUUID, c.IPWaitTimeout, func(v *vm) bool {
+		return v.ip() != ""
+	})
+	if err != nil {
+		err := fmt.Errorf("Error waiting for the IP address of the VM: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	ui.Message(fmt.Sprintf("IP address: %s", v.ip()))
+	state.Put("vm_ip", v.ip())
+
+	return multistep.ActionContinue
+}
+
+func (s *stepCreateVM) Cleanup(state multistep.StateBag) {
+	// If the VM UUID isn't there, we probably never created it
+	if s.vmUUID == "" {
+		return
+	}
+
+	client := state.Get("client").(*client)
+	ui := state.Get("ui").(packer.Ui)
+	c := state.Get("config").(*Config)
+
+	ui.Say("Deleting VM...")
+	taskUUID, err := client.DeleteVM(context.TODO(), s.vmUUID)
+	if err == nil {
+		err = waitForTask(context.TODO(), client, taskUUID, c.TaskTimeout)
+	}
+	if err != nil {
+		ui.Error(fmt.Sprintf(
+			"Error deleting VM. Please delete it manually: %s", err))
+	}
+}
+
+// vmSpecFromConfig returns the spec of the VM, looking up the UUIDs of the
+// entities that the config names.
+func vmSpecFromConfig(ctx context.Context, client *client, c *Config) (*vmSpec, error) {
+	clusterUUID, err := client.ClusterUUID(ctx, c.ClusterName)
+	if err != nil {
+		return nil, err
+	}
+	subnetUUID, err := client.SubnetUUID(ctx, c.SubnetName)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := &vmSpec{
+		Name:        c.VMName,
+		Description: "Packer build VM",
+		Resources: vmResources{
+			NumSockets:        c.CpuCount,
+			NumVcpusPerSocket: 1,
+			MemorySizeMib:     c.MemorySize,
+			PowerState:        "ON",
+			NicList: []vmNic{
+				{SubnetReference: &reference{Kind: "subnet", UUID: subnetUUID}},
+			},
+		},
+		ClusterReference: &reference{Kind: "cluster", UUID: clusterUUID},
+	}
+
+	disk := vmDisk{
+		DeviceProperties: deviceProperties{
+			DeviceType:  "DISK",
+			DiskAddress: &diskAddress{AdapterType: "SCSI", DeviceIndex: 0},
+		},
+		DiskSizeMib: c.DiskSize,
+	}
+
+	if c.SourceImageName != "" {
+		imageUUID, err := client.ImageUUID(ctx, c.SourceImageName)
+		if err != nil {
+			return nil, err
+		}
+		disk.DataSourceReference = &reference{Kind: "image", UUID: imageUUID}
+		spec.Resources.DiskList = []vmDisk{disk}
+	} else {
+		isoUUID, err := client.ImageUUID(ctx, c.ISOImageName)
+		if err != nil {
+			return nil, err
+		}
+		cdrom := vmDisk{
+			DataSourceReference: &reference{Kind: "image", UUID: isoUUID},
+			DeviceProperties: deviceProperties{
+				DeviceType:  "CDROM",
+				DiskAddress: &diskAddress{AdapterType: "IDE", DeviceIndex: 0},
+			},
+		}
+		spec.Resources.DiskList = []vmDisk{disk, cdrom}
+		spec.Resources.BootConfig = &bootConfig{
+			BootDeviceOrderList: []string{"CDROM", "DISK"},
+		}
+	}
+
+	if c.UserData != "" {
+		spec.Resources.GuestCustomization = &guestCustomization{
+			CloudInit: &cloudInit{
+				UserData: base64.StdEncoding.EncodeToString([]byte(c.UserData)),
+			},
+		}
+	}
+
+	return spec, nil
+}
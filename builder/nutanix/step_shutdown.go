@@ -0,0 +1,56 @@
+package nutanix
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// stepShutdown shuts the VM down with shutdown_command so that its disk is
+// consistent when the image is created from it. The VM is powered off
+// through the API when the command doesn't stop it in time.
+type stepShutdown struct{}
+
+func (s *stepShutdown) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	client := state.Get("client").(*client)
+	comm := state.Get("communicator").(packer.Communicator)
+	c := state.Get("config").(*Config)
+	ui := state.Get("ui").(packer.Ui)
+	vmUUID := state.Get("vm_uuid").(string)
+
+	ui.Say("Gracefully shutting down VM...")
+	log.Printf("Executing shutdown command: %s", c.ShutdownCommand)
+	cmd := &packer.RemoteCmd{Command: c.ShutdownCommand}
+	if err := comm.Start(ctx, cmd); err != nil {
+		err := fmt.Errorf("Failed to send shutdown command: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	off := func(v *vm) bool { return v.Status.Resources.PowerState == "OFF" }
+	if _, err := waitForVM(ctx, client, vmUUID, c.ShutdownTimeout, off); err == nil {
+		return multistep.ActionContinue
+	}
+
+	ui.Say("VM didn't shut down in time, powering it off...")
+	taskUUID, err := client.PowerOffVM(ctx, vmUUID)
+	if err == nil {
+		err = waitForTask(ctx, client, taskUUID, c.TaskTimeout)
+	}
+	if err != nil {
+		err := fmt.Errorf("Error powering off VM: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *stepShutdown) Cleanup(state multistep.StateBag) {
+	// no cleanup
+}
@@ -0,0 +1,75 @@
+package nutanix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/packer/common/retry"
+)
+
+// pollInterval is the time between two checks of the state of a task or VM.
+var pollInterval = 5 * time.Second
+
+// errTaskFailed wraps the errors of the tasks that failed, which aren't
+// retried.
+type errTaskFailed struct {
+	error
+}
+
+// waitForTask waits until the task has succeeded.
+func waitForTask(ctx context.Context, c *client, uuid string, timeout time.Duration) error {
+	err := retry.Config{
+		StartTimeout: timeout,
+		RetryDelay:   func() time.Duration { return pollInterval },
+		ShouldRetry: func(err error) bool {
+			_, failed := err.(errTaskFailed)
+			return !failed
+		},
+	}.Run(ctx, func(ctx context.Context) error {
+		t, err := c.GetTask(ctx, uuid)
+		if err != nil {
+			return err
+		}
+		switch t.Status {
+		case "SUCCEEDED":
+			return nil
+		case "FAILED", "ABORTED":
+			return errTaskFailed{errors.New(t.ErrorDetail)}
+		default:
+			return fmt.Errorf("task is %s", t.Status)
+		}
+	})
+	if err, ok := err.(errTaskFailed); ok {
+		return fmt.Errorf("task %s failed: %s", uuid, err.error)
+	}
+	if err != nil {
+		return fmt.Errorf("timeout while waiting for task %s: %s", uuid, err)
+	}
+	return nil
+}
+
+// waitForVM waits until done returns true for the VM, and returns the VM.
+func waitForVM(ctx context.Context, c *client, uuid string, timeout time.Duration,
+	done func(*vm) bool) (*vm, error) {
+	var v *vm
+	err := retry.Config{
+		StartTimeout: timeout,
+		RetryDelay:   func() time.Duration { return pollInterval },
+	}.Run(ctx, func(ctx context.Context) error {
+		var err error
+		v, err = c.GetVM(ctx, uuid)
+		if err != nil {
+			return err
+		}
+		if !done(v) {
+			return fmt.Errorf("VM is %s", v.Status.Resources.PowerState)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("timeout while waiting for the VM: %s", err)
+	}
+	return v, nil
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/hashicorp/packer/packer"
 	"github.com/hashicorp/packer/template/interpolate"
 	ocicommon "github.com/oracle/oci-go-sdk/common"
+	ociauth "github.com/oracle/oci-go-sdk/common/auth"
 )
 
 type Config struct {
@@ -28,6 +29,10 @@ type Config struct {
 	AccessCfgFile        string `mapstructure:"access_cfg_file"`
 	AccessCfgFileAccount string `mapstructure:"access_cfg_file_account"`
 
+	// Authenticate as the instance Packer runs on instead of with an API
+	// signing key.
+	UseInstancePrincipals bool `mapstructure:"use_instance_principals"`
+
 	// Access config overrides
 	UserID       string `mapstructure:"user_ocid"`
 	TenancyID    string `mapstructure:"tenancy_ocid"`
@@ -80,85 +85,121 @@ func NewConfig(raws ...interface{}) (*Config, error) {
 		return nil, fmt.Errorf("Failed to mapstructure Config: %+v", err)
 	}
 
-	// Determine where the SDK config is located
-	if c.AccessCfgFile == "" {
-		c.AccessCfgFile, err = getDefaultOCISettingsPath()
-		if err != nil {
-			log.Println("Default OCI settings file not found")
-		}
+	var errs *packer.MultiError
+	if es := c.Comm.Prepare(&c.ctx); len(es) > 0 {
+		errs = packer.MultiErrorAppend(errs, es...)
 	}
 
-	if c.AccessCfgFileAccount == "" {
-		c.AccessCfgFileAccount = "DEFAULT"
-	}
+	var tenancyOCID string
+	if c.UseInstancePrincipals {
+		// Instance principals authenticate as the instance Packer runs on,
+		// so none of the API signing key settings can be used with them.
+		keyOptions := []struct{ key, value string }{
+			{"access_cfg_file", c.AccessCfgFile},
+			{"access_cfg_file_account", c.AccessCfgFileAccount},
+			{"user_ocid", c.UserID},
+			{"tenancy_ocid", c.TenancyID},
+			{"fingerprint", c.Fingerprint},
+			{"key_file", c.KeyFile},
+			{"pass_phrase", c.PassPhrase},
+		}
+		for _, o := range keyOptions {
+			if o.value != "" {
+				errs = packer.MultiErrorAppend(errs, fmt.Errorf(
+					"'%s' can't be specified with 'use_instance_principals'", o.key))
+			}
+		}
 
-	var keyContent []byte
-	if c.KeyFile != "" {
-		path, err := packer.ExpandUser(c.KeyFile)
-		if err != nil {
-			return nil, err
+		if errs == nil {
+			var configProvider ocicommon.ConfigurationProvider
+			var err error
+			if c.Region != "" {
+				configProvider, err = ociauth.InstancePrincipalConfigurationProviderForRegion(ocicommon.Region(c.Region))
+			} else {
+				configProvider, err = ociauth.InstancePrincipalConfigurationProvider()
+			}
+			if err != nil {
+				return nil, err
+			}
+			tenancyOCID, _ = configProvider.TenancyOCID()
+			c.ConfigProvider = configProvider
+		}
+	} else {
+		// Determine where the SDK config is located
+		if c.AccessCfgFile == "" {
+			c.AccessCfgFile, err = getDefaultOCISettingsPath()
+			if err != nil {
+				log.Println("Default OCI settings file not found")
+			}
 		}
 
-		// Read API signing key
-		keyContent, err = ioutil.ReadFile(path)
-		if err != nil {
-			return nil, err
+		if c.AccessCfgFileAccount == "" {
+			c.AccessCfgFileAccount = "DEFAULT"
 		}
-	}
 
-	fileProvider, _ := ocicommon.ConfigurationProviderFromFileWithProfile(c.AccessCfgFile, c.AccessCfgFileAccount, c.PassPhrase)
-	if c.Region == "" {
-		var region string
-		if fileProvider != nil {
-			region, _ = fileProvider.Region()
+		var keyContent []byte
+		if c.KeyFile != "" {
+			path, err := packer.ExpandUser(c.KeyFile)
+			if err != nil {
+				return nil, err
+			}
+
+			// Read API signing key
+			keyContent, err = ioutil.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
 		}
-		if region == "" {
-			c.Region = "us-phoenix-1"
+
+		fileProvider, _ := ocicommon.ConfigurationProviderFromFileWithProfile(c.AccessCfgFile, c.AccessCfgFileAccount, c.PassPhrase)
+		if c.Region == "" {
+			var region string
+			if fileProvider != nil {
+				region, _ = fileProvider.Region()
+			}
+			if region == "" {
+				c.Region = "us-phoenix-1"
+			}
 		}
-	}
 
-	providers := []ocicommon.ConfigurationProvider{
-		NewRawConfigurationProvider(c.TenancyID, c.UserID, c.Region, c.Fingerprint, string(keyContent), &c.PassPhrase),
-	}
+		providers := []ocicommon.ConfigurationProvider{
+			NewRawConfigurationProvider(c.TenancyID, c.UserID, c.Region, c.Fingerprint, string(keyContent), &c.PassPhrase),
+		}
 
-	if fileProvider != nil {
-		providers = append(providers, fileProvider)
-	}
+		if fileProvider != nil {
+			providers = append(providers, fileProvider)
+		}
 
-	// Load API access configuration from SDK
-	configProvider, err := ocicommon.ComposingConfigurationProvider(providers)
-	if err != nil {
-		return nil, err
-	}
+		// Load API access configuration from SDK
+		configProvider, err := ocicommon.ComposingConfigurationProvider(providers)
+		if err != nil {
+			return nil, err
+		}
 
-	var errs *packer.MultiError
-	if es := c.Comm.Prepare(&c.ctx); len(es) > 0 {
-		errs = packer.MultiErrorAppend(errs, es...)
-	}
+		if userOCID, _ := configProvider.UserOCID(); userOCID == "" {
+			errs = packer.MultiErrorAppend(
+				errs, errors.New("'user_ocid' must be specified"))
+		}
 
-	if userOCID, _ := configProvider.UserOCID(); userOCID == "" {
-		errs = packer.MultiErrorAppend(
-			errs, errors.New("'user_ocid' must be specified"))
-	}
+		tenancyOCID, _ = configProvider.TenancyOCID()
+		if tenancyOCID == "" {
+			errs = packer.MultiErrorAppend(
+				errs, errors.New("'tenancy_ocid' must be specified"))
+		}
 
-	tenancyOCID, _ := configProvider.TenancyOCID()
-	if tenancyOCID == "" {
-		errs = packer.MultiErrorAppend(
-			errs, errors.New("'tenancy_ocid' must be specified"))
-	}
+		if fingerprint, _ := configProvider.KeyFingerprint(); fingerprint == "" {
+			errs = packer.MultiErrorAppend(
+				errs, errors.New("'fingerprint' must be specified"))
+		}
 
-	if fingerprint, _ := configProvider.KeyFingerprint(); fingerprint == "" {
-		errs = packer.MultiErrorAppend(
-			errs, errors.New("'fingerprint' must be specified"))
-	}
+		if _, err := configProvider.PrivateRSAKey(); err != nil {
+			errs = packer.MultiErrorAppend(
+				errs, errors.New("'key_file' must be specified"))
+		}
 
-	if _, err := configProvider.PrivateRSAKey(); err != nil {
-		errs = packer.MultiErrorAppend(
-			errs, errors.New("'key_file' must be specified"))
+		c.ConfigProvider = configProvider
 	}
 
-	c.ConfigProvider = configProvider
-
 	if c.AvailabilityDomain == "" {
 		errs = packer.MultiErrorAppend(
 			errs, errors.New("'availability_domain' must be specified"))
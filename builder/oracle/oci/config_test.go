@@ -91,6 +91,26 @@ func TestConfig(t *testing.T) {
 		}
 	})
 
+	t.Run("InstancePrincipalsWithAccessConfig", func(t *testing.T) {
+		raw := testConfig(cfgFile)
+		raw["use_instance_principals"] = true
+		raw["user_ocid"] = "ocid1..."
+		raw["key_file"] = keyFile.Name()
+
+		_, errs := NewConfig(raw)
+
+		expectedErrors := []string{
+			"'access_cfg_file'", "'user_ocid'", "'key_file'",
+		}
+
+		s := errs.Error()
+		for _, expected := range expectedErrors {
+			if !strings.Contains(s, expected) {
+				t.Errorf("Expected %q to contain '%s'", s, expected)
+			}
+		}
+	})
+
 	t.Run("AccessConfigTemplateOnly", func(t *testing.T) {
 		raw := testConfig(cfgFile)
 		delete(raw, "access_cfg_file")
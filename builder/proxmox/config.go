@@ -45,6 +45,16 @@ type Config struct {
 	ISOFile string       `mapstructure:"iso_file"`
 	Agent   bool         `mapstructure:"qemu_agent"`
 
+	CloneVM   string `mapstructure:"clone_vm"`
+	FullClone bool   `mapstructure:"full_clone"`
+
+	CloudInitUser         string `mapstructure:"cloud_init_user"`
+	CloudInitPassword     string `mapstructure:"cloud_init_password"`
+	CloudInitSSHKeys      string `mapstructure:"cloud_init_ssh_keys"`
+	CloudInitIPConfig     string `mapstructure:"cloud_init_ipconfig"`
+	CloudInitNameserver   string `mapstructure:"cloud_init_nameserver"`
+	CloudInitSearchdomain string `mapstructure:"cloud_init_searchdomain"`
+
 	TemplateName        string `mapstructure:"template_name"`
 	TemplateDescription string `mapstructure:"template_description"`
 	UnmountISO          bool   `mapstructure:"unmount_iso"`
@@ -71,6 +81,8 @@ func NewConfig(raws ...interface{}) (*Config, []string, error) {
 	c := new(Config)
 	// Agent defaults to true
 	c.Agent = true
+	// FullClone defaults to true
+	c.FullClone = true
 
 	var md mapstructure.Metadata
 	err := config.Decode(c, &config.DecodeOpts{
@@ -170,9 +182,15 @@ func NewConfig(raws ...interface{}) (*Config, []string, error) {
 	if c.ProxmoxURL, err = url.Parse(c.ProxmoxURLRaw); err != nil {
 		errs = packer.MultiErrorAppend(errs, errors.New(fmt.Sprintf("Could not parse proxmox_url: %s", err)))
 	}
-	if c.ISOFile == "" {
+	if c.ISOFile == "" && c.CloneVM == "" {
 		errs = packer.MultiErrorAppend(errs, errors.New("iso_file must be specified"))
 	}
+	if c.ISOFile != "" && c.CloneVM != "" {
+		errs = packer.MultiErrorAppend(errs, errors.New("only one of iso_file or clone_vm can be specified"))
+	}
+	if c.hasCloudInit() && c.CloneVM == "" {
+		errs = packer.MultiErrorAppend(errs, errors.New("cloud_init options can only be used with clone_vm"))
+	}
 	if c.Node == "" {
 		errs = packer.MultiErrorAppend(errs, errors.New("node must be specified"))
 	}
@@ -195,5 +213,18 @@ func NewConfig(raws ...interface{}) (*Config, []string, error) {
 	}
 
 	packer.LogSecretFilter.Set(c.Password)
+	if c.CloudInitPassword != "" {
+		packer.LogSecretFilter.Set(c.CloudInitPassword)
+	}
 	return c, nil, nil
 }
+
+// hasCloudInit returns whether any of the cloud-init options are set.
+func (c *Config) hasCloudInit() bool {
+	return c.CloudInitUser != "" ||
+		c.CloudInitPassword != "" ||
+		c.CloudInitSSHKeys != "" ||
+		c.CloudInitIPConfig != "" ||
+		c.CloudInitNameserver != "" ||
+		c.CloudInitSearchdomain != ""
+}
@@ -150,3 +150,54 @@ func TestAgentSetToFalse(t *testing.T) {
 		t.Errorf("Expected Agent to be false, got %t", b.config.Agent)
 	}
 }
+
+func TestCloneVM(t *testing.T) {
+	const config = `{
+		"builders": [
+			{
+				"type": "proxmox",
+				"proxmox_url": "https://my-proxmox.my-domain:8006/api2/json",
+				"username": "apiuser@pve",
+				"password": "supersecret",
+				"clone_vm": "ubuntu-base",
+				"cloud_init_user": "ubuntu",
+				"cloud_init_ipconfig": "ip=dhcp",
+				"ssh_username": "ubuntu",
+				"node": "my-proxmox"
+			}
+		]
+	}`
+
+	tpl, err := template.Parse(strings.NewReader(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := &Builder{}
+	warn, err := b.Prepare(tpl.Builders["proxmox"].Config)
+	if err != nil {
+		t.Fatal(err, warn)
+	}
+
+	if b.config.CloneVM != "ubuntu-base" {
+		t.Errorf("Expected CloneVM to be 'ubuntu-base', got %s", b.config.CloneVM)
+	}
+	if b.config.FullClone != true {
+		t.Errorf("Expected FullClone to be true, got %t", b.config.FullClone)
+	}
+
+	// iso_file and clone_vm are exclusive
+	cfg := tpl.Builders["proxmox"].Config
+	cfg["iso_file"] = "local:iso/Fedora-Server-dvd-x86_64-29-1.2.iso"
+	b = &Builder{}
+	if _, err := b.Prepare(cfg); err == nil {
+		t.Error("Expected error with both iso_file and clone_vm")
+	}
+
+	// cloud-init needs a clone
+	delete(cfg, "clone_vm")
+	b = &Builder{}
+	if _, err := b.Prepare(cfg); err == nil {
+		t.Error("Expected error with cloud_init options without clone_vm")
+	}
+}
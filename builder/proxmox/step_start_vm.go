@@ -11,6 +11,8 @@ import (
 )
 
 // stepStartVM takes the given configuration and starts a VM on the given Proxmox node.
+// The VM is either created from scratch with the ISO attached, or cloned from the
+// clone_vm template or VM.
 //
 // It sets the vmRef state which is used throughout the later steps to reference the VM
 // in API calls.
@@ -26,7 +28,6 @@ func (s *stepStartVM) Run(ctx context.Context, state multistep.StateBag) multist
 		agent = 0
 	}
 
-	ui.Say("Creating VM")
 	config := proxmox.ConfigQemu{
 		Name:         c.VMName,
 		Agent:        agent,
@@ -38,6 +39,13 @@ func (s *stepStartVM) Run(ctx context.Context, state multistep.StateBag) multist
 		QemuIso:      c.ISOFile,
 		QemuNetworks: generateProxmoxNetworkAdapters(c.NICs),
 		QemuDisks:    generateProxmoxDisks(c.Disks),
+
+		CIuser:       c.CloudInitUser,
+		CIpassword:   c.CloudInitPassword,
+		Sshkeys:      c.CloudInitSSHKeys,
+		Ipconfig0:    c.CloudInitIPConfig,
+		Nameserver:   c.CloudInitNameserver,
+		Searchdomain: c.CloudInitSearchdomain,
 	}
 
 	if c.VMID == 0 {
@@ -61,18 +69,44 @@ func (s *stepStartVM) Run(ctx context.Context, state multistep.StateBag) multist
 	vmRef := proxmox.NewVmRef(c.VMID)
 	vmRef.SetNode(c.Node)
 
-	err := config.CreateVm(vmRef, client)
-	if err != nil {
-		state.Put("error", err)
-		ui.Error(err.Error())
-		return multistep.ActionHalt
+	if c.CloneVM != "" {
+		sourceVmRef, err := client.GetVmRefByName(c.CloneVM)
+		if err != nil {
+			err := fmt.Errorf("Error finding VM to clone: %s", err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+
+		fullClone := 1
+		if c.FullClone == false {
+			fullClone = 0
+		}
+		config.FullClone = &fullClone
+
+		ui.Say(fmt.Sprintf("Cloning VM %s", c.CloneVM))
+		err = config.CloneVm(sourceVmRef, vmRef, client)
+		if err != nil {
+			err := fmt.Errorf("Error cloning VM: %s", err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+	} else {
+		ui.Say("Creating VM")
+		err := config.CreateVm(vmRef, client)
+		if err != nil {
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
 	}
 
 	// Store the vm id for later
 	state.Put("vmRef", vmRef)
 
 	ui.Say("Starting VM")
-	_, err = client.StartVm(vmRef)
+	_, err := client.StartVm(vmRef)
 	if err != nil {
 		err := fmt.Errorf("Error starting VM: %s", err)
 		state.Put("error", err)
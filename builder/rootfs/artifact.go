@@ -0,0 +1,37 @@
+package rootfs
+
+import (
+	"fmt"
+	"log"
+	"os"
+)
+
+// Artifact is the tarball that the builder built.
+type Artifact struct {
+	tarball string
+}
+
+func (*Artifact) BuilderId() string {
+	return BuilderId
+}
+
+func (a *Artifact) Files() []string {
+	return []string{a.tarball}
+}
+
+func (a *Artifact) Id() string {
+	return "Tarball"
+}
+
+func (a *Artifact) String() string {
+	return fmt.Sprintf("Rootfs tarball: %s", a.tarball)
+}
+
+func (a *Artifact) State(name string) interface{} {
+	return nil
+}
+
+func (a *Artifact) Destroy() error {
+	log.Printf("Deleting %s", a.tarball)
+	return os.Remove(a.tarball)
+}
@@ -0,0 +1,125 @@
+package rootfs
+
+import (
+	"testing"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+func testConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"source_url":              "http://dl-cdn.alpinelinux.org/alpine/v3.10/releases/x86_64/alpine-minirootfs-3.10.3-x86_64.tar.gz",
+		"source_checksum":         "9eafcb9d3f6c16b9a4b0e3b1b8b4f1c3a8e0e04e42ec1e3ad43fe2ec7c58c0e6",
+		packer.BuildNameConfigKey: "foo",
+	}
+}
+
+func TestBuilder_ImplementsBuilder(t *testing.T) {
+	var raw interface{}
+	raw = &Builder{}
+	if _, ok := raw.(packer.Builder); !ok {
+		t.Fatalf("Builder should be a builder")
+	}
+}
+
+func TestBuilderPrepare_Defaults(t *testing.T) {
+	var b Builder
+	warns, err := b.Prepare(testConfig())
+	if len(warns) > 0 {
+		t.Fatalf("bad: %#v", warns)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	if b.config.SourceChecksumType != "sha256" {
+		t.Errorf("bad checksum type: %s", b.config.SourceChecksumType)
+	}
+
+	if len(b.config.ChrootMounts) != 4 {
+		t.Errorf("bad chroot mounts: %#v", b.config.ChrootMounts)
+	}
+
+	if b.config.Compression != "gzip" {
+		t.Errorf("bad compression: %s", b.config.Compression)
+	}
+
+	if b.config.OutputDir != "output-foo" {
+		t.Errorf("bad output dir: %s", b.config.OutputDir)
+	}
+
+	if b.config.OutputFilename != "rootfs.tar.gz" {
+		t.Errorf("bad output filename: %s", b.config.OutputFilename)
+	}
+}
+
+func TestBuilderPrepare_InvalidKey(t *testing.T) {
+	var b Builder
+	config := testConfig()
+
+	// Add a random key
+	config["i_should_not_be_valid"] = true
+	warns, err := b.Prepare(config)
+	if len(warns) > 0 {
+		t.Fatalf("bad: %#v", warns)
+	}
+	if err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestBuilderPrepare_SourceChecksum(t *testing.T) {
+	var b Builder
+	config := testConfig()
+
+	delete(config, "source_checksum")
+	if _, err := b.Prepare(config); err == nil {
+		t.Fatal("should have error")
+	}
+
+	config["source_checksum_type"] = "none"
+	b = Builder{}
+	if _, err := b.Prepare(config); err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+}
+
+func TestBuilderPrepare_Compression(t *testing.T) {
+	cases := map[string]string{
+		"none":  "rootfs.tar",
+		"gzip":  "rootfs.tar.gz",
+		"bzip2": "rootfs.tar.bz2",
+		"xz":    "rootfs.tar.xz",
+		"zstd":  "rootfs.tar.zst",
+		"lz4":   "",
+	}
+
+	for compression, filename := range cases {
+		var b Builder
+		config := testConfig()
+		config["compression"] = compression
+		_, err := b.Prepare(config)
+		if filename == "" {
+			if err == nil {
+				t.Errorf("%s: should have error", compression)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: should not have error: %s", compression, err)
+			continue
+		}
+		if b.config.OutputFilename != filename {
+			t.Errorf("%s: bad output filename: %s", compression, b.config.OutputFilename)
+		}
+	}
+}
+
+func TestBuilderPrepare_ChrootMounts(t *testing.T) {
+	var b Builder
+	config := testConfig()
+	config["chroot_mounts"] = [][]string{{"bind", "/dev"}}
+	if _, err := b.Prepare(config); err == nil {
+		t.Fatal("should have error")
+	}
+}
@@ -0,0 +1,29 @@
+package rootfs
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/packer/builder/amazon/chroot"
+)
+
+// run runs a command through the command wrapper, and returns its output.
+func run(wrappedCommand chroot.CommandWrapper, command string) (string, error) {
+	command, err := wrappedCommand(command)
+	if err != nil {
+		return "", fmt.Errorf("Error creating command: %s", err)
+	}
+
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	cmd := chroot.ShellCommand(command)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	log.Printf("Executing: %s", command)
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s\nStderr: %s", err, stderr.String())
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
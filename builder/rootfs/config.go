@@ -0,0 +1,129 @@
+package rootfs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/helper/config"
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/template/interpolate"
+)
+
+// compressions are the tar flags of each compression, and the extensions
+// of the tarballs they compress.
+var compressions = map[string]struct {
+	flag      string
+	extension string
+}{
+	"none":  {"", ".tar"},
+	"gzip":  {"--gzip", ".tar.gz"},
+	"bzip2": {"--bzip2", ".tar.bz2"},
+	"xz":    {"--xz", ".tar.xz"},
+	"zstd":  {"--zstd", ".tar.zst"},
+}
+
+type Config struct {
+	common.PackerConfig `mapstructure:",squash"`
+
+	SourceURL          string     `mapstructure:"source_url"`
+	SourceChecksum     string     `mapstructure:"source_checksum"`
+	SourceChecksumType string     `mapstructure:"source_checksum_type"`
+	ChrootMounts       [][]string `mapstructure:"chroot_mounts"`
+	CommandWrapper     string     `mapstructure:"command_wrapper"`
+	Compression        string     `mapstructure:"compression"`
+	Exclude            []string   `mapstructure:"exclude"`
+	OutputDir          string     `mapstructure:"output_directory"`
+	OutputFilename     string     `mapstructure:"output_filename"`
+
+	ctx interpolate.Context
+}
+
+func NewConfig(raws ...interface{}) (*Config, []string, error) {
+	c := new(Config)
+
+	err := config.Decode(c, &config.DecodeOpts{
+		Interpolate:        true,
+		InterpolateContext: &c.ctx,
+		InterpolateFilter: &interpolate.RenderFilter{
+			Exclude: []string{
+				"command_wrapper",
+			},
+		},
+	}, raws...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var errs *packer.MultiError
+
+	// Defaults
+	if c.SourceChecksumType == "" {
+		c.SourceChecksumType = "sha256"
+	}
+
+	if len(c.ChrootMounts) == 0 {
+		c.ChrootMounts = [][]string{
+			{"proc", "proc", "/proc"},
+			{"sysfs", "sysfs", "/sys"},
+			{"bind", "/dev", "/dev"},
+			{"devpts", "devpts", "/dev/pts"},
+		}
+	}
+
+	if c.CommandWrapper == "" {
+		c.CommandWrapper = "{{.Command}}"
+	}
+
+	if c.Compression == "" {
+		c.Compression = "gzip"
+	}
+
+	if c.OutputDir == "" {
+		c.OutputDir = fmt.Sprintf("output-%s", c.PackerBuildName)
+	}
+
+	if c.OutputFilename == "" {
+		if compression, ok := compressions[c.Compression]; ok {
+			c.OutputFilename = "rootfs" + compression.extension
+		}
+	}
+
+	if c.SourceURL == "" {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("source_url must be specified"))
+	}
+
+	if c.SourceChecksumType != "none" && c.SourceChecksum == "" {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("source_checksum must be specified, unless source_checksum_type is none"))
+	}
+
+	if _, ok := compressions[c.Compression]; !ok {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("compression must be one of 'none', 'gzip', 'bzip2', 'xz' or 'zstd'"))
+	}
+
+	for _, mounts := range c.ChrootMounts {
+		if len(mounts) != 3 {
+			errs = packer.MultiErrorAppend(
+				errs, errors.New("Each chroot_mounts entry should be three elements."))
+			break
+		}
+	}
+
+	if !c.PackerForce {
+		if _, err := os.Stat(c.OutputDir); err == nil {
+			errs = packer.MultiErrorAppend(
+				errs,
+				fmt.Errorf("Output directory '%s' already exists. It must not exist.", c.OutputDir))
+		}
+	}
+
+	if errs != nil && len(errs.Errors) > 0 {
+		return nil, nil, errs
+	}
+
+	return c, nil, nil
+}
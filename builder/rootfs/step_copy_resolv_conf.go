@@ -0,0 +1,85 @@
+package rootfs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/packer/builder/amazon/chroot"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// This step copies the resolv.conf of the host into the chroot, so that
+// provisioners can reach the network, and restores the one of the rootfs
+// once they have run.
+//
+// Uses:
+//   mount_path string
+//   ui     packer.Ui
+//   wrappedCommand chroot.CommandWrapper
+//
+// Produces:
+//   resolv_conf_cleanup chroot.Cleanup - To restore before the tarball is created
+type stepCopyResolvConf struct {
+	resolvConf string
+	backup     bool
+}
+
+func (s *stepCopyResolvConf) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	mountPath := state.Get("mount_path").(string)
+	ui := state.Get("ui").(packer.Ui)
+	wrappedCommand := state.Get("wrappedCommand").(chroot.CommandWrapper)
+
+	state.Put("resolv_conf_cleanup", s)
+
+	resolvConf := filepath.Join(mountPath, "etc", "resolv.conf")
+	if _, err := os.Lstat(resolvConf); err == nil {
+		if _, err := run(wrappedCommand, fmt.Sprintf("mv %s %s.packer-bak", resolvConf, resolvConf)); err != nil {
+			err := fmt.Errorf("Error moving resolv.conf aside: %s", err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+		s.backup = true
+	}
+	s.resolvConf = resolvConf
+
+	if _, err := run(wrappedCommand, fmt.Sprintf("cp /etc/resolv.conf %s", resolvConf)); err != nil {
+		err := fmt.Errorf("Error copying resolv.conf: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *stepCopyResolvConf) Cleanup(state multistep.StateBag) {
+	ui := state.Get("ui").(packer.Ui)
+	if err := s.CleanupFunc(state); err != nil {
+		ui.Error(err.Error())
+	}
+}
+
+func (s *stepCopyResolvConf) CleanupFunc(state multistep.StateBag) error {
+	if s.resolvConf == "" {
+		return nil
+	}
+
+	wrappedCommand := state.Get("wrappedCommand").(chroot.CommandWrapper)
+
+	if _, err := run(wrappedCommand, fmt.Sprintf("rm -f %s", s.resolvConf)); err != nil {
+		return fmt.Errorf("Error removing resolv.conf: %s", err)
+	}
+	if s.backup {
+		command := fmt.Sprintf("mv %s.packer-bak %s", s.resolvConf, s.resolvConf)
+		if _, err := run(wrappedCommand, command); err != nil {
+			return fmt.Errorf("Error restoring resolv.conf: %s", err)
+		}
+	}
+
+	s.resolvConf = ""
+	return nil
+}
@@ -0,0 +1,67 @@
+package rootfs
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/packer/builder/amazon/chroot"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// This step archives the chroot into the tarball.
+//
+// Uses:
+//   config *Config
+//   mount_path string
+//   ui     packer.Ui
+//   wrappedCommand chroot.CommandWrapper
+//
+// Produces:
+//   tarball_path string - The path of the tarball
+type stepCreateTarball struct{}
+
+func (s *stepCreateTarball) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	config := state.Get("config").(*Config)
+	mountPath := state.Get("mount_path").(string)
+	ui := state.Get("ui").(packer.Ui)
+	wrappedCommand := state.Get("wrappedCommand").(chroot.CommandWrapper)
+
+	tarball, err := filepath.Abs(filepath.Join(config.OutputDir, config.OutputFilename))
+	if err != nil {
+		err := fmt.Errorf("Error creating the tarball: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	ui.Say(fmt.Sprintf("Creating tarball %s...", tarball))
+	if _, err := run(wrappedCommand, tarCommand(config, mountPath, tarball)); err != nil {
+		err := fmt.Errorf("Error creating the tarball: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	state.Put("tarball_path", tarball)
+	return multistep.ActionContinue
+}
+
+func (s *stepCreateTarball) Cleanup(state multistep.StateBag) {}
+
+// tarCommand returns the command to archive the chroot at root into the
+// tarball. The paths in the tarball start with ./, which the exclude
+// patterns match.
+func tarCommand(config *Config, root, tarball string) string {
+	args := []string{"tar", "--numeric-owner"}
+	if flag := compressions[config.Compression].flag; flag != "" {
+		args = append(args, flag)
+	}
+	for _, pattern := range config.Exclude {
+		args = append(args, fmt.Sprintf("--exclude='%s'", pattern))
+	}
+	args = append(args, "-cpf", tarball, "-C", root, ".")
+	return strings.Join(args, " ")
+}
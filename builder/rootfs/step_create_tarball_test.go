@@ -0,0 +1,23 @@
+package rootfs
+
+import (
+	"testing"
+)
+
+func TestTarCommand(t *testing.T) {
+	config := &Config{
+		Compression: "xz",
+		Exclude:     []string{"./tmp/*", "./var/cache/apt/*"},
+	}
+
+	expected := "tar --numeric-owner --xz --exclude='./tmp/*' --exclude='./var/cache/apt/*' -cpf /out/rootfs.tar.xz -C /chroot ."
+	if got := tarCommand(config, "/chroot", "/out/rootfs.tar.xz"); got != expected {
+		t.Fatalf("bad command:\n%s\nexpected:\n%s", got, expected)
+	}
+
+	config = &Config{Compression: "none"}
+	expected = "tar --numeric-owner -cpf /out/rootfs.tar -C /chroot ."
+	if got := tarCommand(config, "/chroot", "/out/rootfs.tar"); got != expected {
+		t.Fatalf("bad command:\n%s\nexpected:\n%s", got, expected)
+	}
+}
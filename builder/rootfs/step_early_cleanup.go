@@ -0,0 +1,38 @@
+package rootfs
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/packer/builder/amazon/chroot"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// stepEarlyCleanup restores resolv.conf and unmounts the chroot_mounts
+// before the tarball is created, so that the tarball has neither.
+type stepEarlyCleanup struct{}
+
+func (s *stepEarlyCleanup) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	ui := state.Get("ui").(packer.Ui)
+	cleanupKeys := []string{
+		"resolv_conf_cleanup",
+		"mount_extra_cleanup",
+	}
+
+	for _, key := range cleanupKeys {
+		c := state.Get(key).(chroot.Cleanup)
+		log.Printf("Running cleanup func: %s", key)
+		if err := c.CleanupFunc(state); err != nil {
+			err := fmt.Errorf("Error cleaning up: %s", err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *stepEarlyCleanup) Cleanup(state multistep.StateBag) {}
@@ -0,0 +1,66 @@
+package rootfs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/packer/builder/amazon/chroot"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/packer/tmp"
+)
+
+// This step extracts the source tarball into a temporary directory, which
+// becomes the chroot, keeping the owners of its files.
+//
+// Uses:
+//   source_path string
+//   ui     packer.Ui
+//   wrappedCommand chroot.CommandWrapper
+//
+// Produces:
+//   mount_path string - The directory of the chroot
+type stepExtractRootfs struct {
+	root string
+}
+
+func (s *stepExtractRootfs) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	sourcePath := state.Get("source_path").(string)
+	ui := state.Get("ui").(packer.Ui)
+	wrappedCommand := state.Get("wrappedCommand").(chroot.CommandWrapper)
+
+	root, err := tmp.Dir("packer-rootfs")
+	if err != nil {
+		err := fmt.Errorf("Error creating the chroot directory: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	s.root = root
+
+	ui.Say("Extracting the source tarball...")
+	command := fmt.Sprintf("tar --numeric-owner -xpf %s -C %s", sourcePath, root)
+	if _, err := run(wrappedCommand, command); err != nil {
+		err := fmt.Errorf("Error extracting the source tarball: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	state.Put("mount_path", root)
+	return multistep.ActionContinue
+}
+
+func (s *stepExtractRootfs) Cleanup(state multistep.StateBag) {
+	if s.root == "" {
+		return
+	}
+
+	ui := state.Get("ui").(packer.Ui)
+	wrappedCommand := state.Get("wrappedCommand").(chroot.CommandWrapper)
+
+	// Files owned by root need the command wrapper to be removed
+	if _, err := run(wrappedCommand, fmt.Sprintf("rm -rf %s", s.root)); err != nil {
+		ui.Error(fmt.Sprintf("Error removing the chroot directory: %s", err))
+	}
+}
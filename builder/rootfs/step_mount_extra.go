@@ -0,0 +1,83 @@
+package rootfs
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/packer/builder/amazon/chroot"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// This step mounts the chroot_mounts, such as /proc, within the chroot.
+//
+// Uses:
+//   config *Config
+//   mount_path string
+//   ui     packer.Ui
+//   wrappedCommand chroot.CommandWrapper
+//
+// Produces:
+//   mount_extra_cleanup chroot.Cleanup - To unmount before the tarball is created
+type stepMountExtra struct {
+	mounts []string
+}
+
+func (s *stepMountExtra) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	config := state.Get("config").(*Config)
+	mountPath := state.Get("mount_path").(string)
+	ui := state.Get("ui").(packer.Ui)
+	wrappedCommand := state.Get("wrappedCommand").(chroot.CommandWrapper)
+
+	state.Put("mount_extra_cleanup", s)
+
+	ui.Say("Mounting additional paths within the chroot...")
+	for _, mountInfo := range config.ChrootMounts {
+		innerPath := mountPath + mountInfo[2]
+
+		if err := os.MkdirAll(innerPath, 0755); err != nil {
+			err := fmt.Errorf("Error creating mount directory: %s", err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+
+		flags := "-t " + mountInfo[0]
+		if mountInfo[0] == "bind" {
+			flags = "--bind"
+		}
+
+		ui.Message(fmt.Sprintf("Mounting: %s", mountInfo[2]))
+		command := fmt.Sprintf("mount %s %s %s", flags, mountInfo[1], innerPath)
+		if _, err := run(wrappedCommand, command); err != nil {
+			err := fmt.Errorf("Error mounting: %s", err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+		s.mounts = append(s.mounts, innerPath)
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *stepMountExtra) Cleanup(state multistep.StateBag) {
+	ui := state.Get("ui").(packer.Ui)
+	if err := s.CleanupFunc(state); err != nil {
+		ui.Error(err.Error())
+	}
+}
+
+func (s *stepMountExtra) CleanupFunc(state multistep.StateBag) error {
+	wrappedCommand := state.Get("wrappedCommand").(chroot.CommandWrapper)
+
+	for len(s.mounts) > 0 {
+		path := s.mounts[len(s.mounts)-1]
+		if _, err := run(wrappedCommand, fmt.Sprintf("umount %s", path)); err != nil {
+			return fmt.Errorf("Error unmounting %s: %s", path, err)
+		}
+		s.mounts = s.mounts[:len(s.mounts)-1]
+	}
+	return nil
+}
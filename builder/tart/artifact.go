@@ -0,0 +1,43 @@
+package tart
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Artifact is the local VM of tart, and the images of it that were pushed.
+type Artifact struct {
+	vmName string
+	images []string
+	driver Driver
+}
+
+func (*Artifact) BuilderId() string {
+	return BuilderId
+}
+
+func (*Artifact) Files() []string {
+	// The VM belongs to tart
+	return nil
+}
+
+func (a *Artifact) Id() string {
+	return a.vmName
+}
+
+func (a *Artifact) String() string {
+	if len(a.images) == 0 {
+		return fmt.Sprintf("VM created: %s", a.vmName)
+	}
+	return fmt.Sprintf("VM created: %s, pushed to: %s", a.vmName, strings.Join(a.images, ", "))
+}
+
+func (a *Artifact) State(name string) interface{} {
+	return nil
+}
+
+// Destroy deletes the local VM. The pushed images are left in their
+// registries.
+func (a *Artifact) Destroy() error {
+	return a.driver.Delete(a.vmName)
+}
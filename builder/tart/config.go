@@ -0,0 +1,98 @@
+package tart
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/helper/communicator"
+	"github.com/hashicorp/packer/helper/config"
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/template/interpolate"
+)
+
+type Config struct {
+	common.PackerConfig `mapstructure:",squash"`
+	Comm                communicator.Config `mapstructure:",squash"`
+
+	TartPath        string        `mapstructure:"tart_path"`
+	SourceVMName    string        `mapstructure:"source_vm_name"`
+	VMName          string        `mapstructure:"vm_name"`
+	CpuCount        int           `mapstructure:"cpus"`
+	MemorySize      int           `mapstructure:"memory"`
+	DiskSize        int           `mapstructure:"disk_size"`
+	Headless        bool          `mapstructure:"headless"`
+	IPWaitTimeout   time.Duration `mapstructure:"ip_wait_timeout"`
+	ShutdownCommand string        `mapstructure:"shutdown_command"`
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+	PushImages      []string      `mapstructure:"push_images"`
+	LoginServer     string        `mapstructure:"login_server"`
+	LoginUsername   string        `mapstructure:"login_username"`
+	LoginPassword   string        `mapstructure:"login_password"`
+
+	ctx interpolate.Context
+}
+
+func NewConfig(raws ...interface{}) (*Config, []string, error) {
+	c := new(Config)
+
+	err := config.Decode(c, &config.DecodeOpts{
+		Interpolate:        true,
+		InterpolateContext: &c.ctx,
+	}, raws...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var errs *packer.MultiError
+
+	// Defaults
+	if c.TartPath == "" {
+		c.TartPath = "tart"
+	}
+
+	if c.VMName == "" {
+		c.VMName = fmt.Sprintf("packer-%s", c.PackerBuildName)
+	}
+
+	if c.IPWaitTimeout == 0 {
+		c.IPWaitTimeout = 5 * time.Minute
+	}
+
+	if c.ShutdownCommand == "" {
+		c.ShutdownCommand = "sudo shutdown -h now"
+	}
+
+	if c.ShutdownTimeout == 0 {
+		c.ShutdownTimeout = 5 * time.Minute
+	}
+
+	if es := c.Comm.Prepare(&c.ctx); len(es) > 0 {
+		errs = packer.MultiErrorAppend(errs, es...)
+	}
+
+	if c.SourceVMName == "" {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("source_vm_name must be specified"))
+	}
+
+	if c.SourceVMName != "" && c.SourceVMName == c.VMName {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("vm_name must differ from source_vm_name"))
+	}
+
+	if c.LoginUsername != "" {
+		if c.LoginServer == "" || c.LoginPassword == "" {
+			errs = packer.MultiErrorAppend(
+				errs, errors.New("login_server and login_password must be specified with login_username"))
+		}
+	}
+
+	if errs != nil && len(errs.Errors) > 0 {
+		return nil, nil, errs
+	}
+
+	packer.LogSecretFilter.Set(c.LoginPassword)
+	return c, nil, nil
+}
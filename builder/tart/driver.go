@@ -0,0 +1,159 @@
+package tart
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A driver is able to talk to tart and perform certain operations with it.
+type Driver interface {
+	// Clone creates a new VM from a copy of a local VM, or of an image of an
+	// OCI registry, which tart pulls.
+	Clone(src, name string) error
+
+	// Set changes the hardware of a stopped VM. Zero values are left
+	// unchanged.
+	Set(name string, cpus, memory, diskSize int) error
+
+	// Run boots a VM, and returns a channel that receives the error of tart
+	// once the VM has stopped, and is closed then.
+	Run(name string, headless bool) (<-chan error, error)
+
+	// IPAddress waits up to timeout for the IP address of a running VM.
+	IPAddress(name string, timeout time.Duration) (string, error)
+
+	// Stop shuts a running VM down, forcefully if it doesn't in time.
+	Stop(name string) error
+
+	// Delete removes a VM.
+	Delete(name string) error
+
+	// Login stores the credentials of a registry.
+	Login(host, username, password string) error
+
+	// Push uploads a VM to an OCI registry, under each of the names.
+	Push(name string, remoteNames []string) error
+
+	// Verify checks to make sure that this driver should function
+	// properly. If there is any indication the driver can't function,
+	// this will return an error.
+	Verify() error
+}
+
+// TartDriver is a Driver that runs the tart command line tool.
+type TartDriver struct {
+	TartPath string
+}
+
+func (d *TartDriver) Clone(src, name string) error {
+	_, err := d.tart(nil, "clone", src, name)
+	return err
+}
+
+func (d *TartDriver) Set(name string, cpus, memory, diskSize int) error {
+	args := []string{"set", name}
+	if cpus > 0 {
+		args = append(args, "--cpu", strconv.Itoa(cpus))
+	}
+	if memory > 0 {
+		args = append(args, "--memory", strconv.Itoa(memory))
+	}
+	if diskSize > 0 {
+		args = append(args, "--disk-size", strconv.Itoa(diskSize))
+	}
+	if len(args) == 2 {
+		return nil
+	}
+
+	_, err := d.tart(nil, args...)
+	return err
+}
+
+func (d *TartDriver) Run(name string, headless bool) (<-chan error, error) {
+	args := []string{"run", name}
+	if headless {
+		args = append(args, "--no-graphics")
+	}
+
+	var stderr bytes.Buffer
+	log.Printf("Executing tart: %#v", args)
+	cmd := exec.Command(d.TartPath, args...)
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		err := cmd.Wait()
+		if _, ok := err.(*exec.ExitError); ok {
+			err = fmt.Errorf("tart error: %s", strings.TrimSpace(stderr.String()))
+		}
+		log.Printf("tart run exited: %v", err)
+		done <- err
+		close(done)
+	}()
+	return done, nil
+}
+
+func (d *TartDriver) IPAddress(name string, timeout time.Duration) (string, error) {
+	return d.tart(nil, "ip", name, "--wait", strconv.Itoa(int(timeout.Seconds())))
+}
+
+func (d *TartDriver) Stop(name string) error {
+	_, err := d.tart(nil, "stop", name)
+	return err
+}
+
+func (d *TartDriver) Delete(name string) error {
+	_, err := d.tart(nil, "delete", name)
+	return err
+}
+
+func (d *TartDriver) Login(host, username, password string) error {
+	_, err := d.tart(strings.NewReader(password), "login", host,
+		"--username", username, "--password-stdin")
+	return err
+}
+
+func (d *TartDriver) Push(name string, remoteNames []string) error {
+	args := append([]string{"push", name}, remoteNames...)
+	_, err := d.tart(nil, args...)
+	return err
+}
+
+func (d *TartDriver) Verify() error {
+	if _, err := exec.LookPath(d.TartPath); err != nil {
+		return fmt.Errorf("tart not found: %s", err)
+	}
+	return nil
+}
+
+func (d *TartDriver) tart(stdin io.Reader, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	log.Printf("Executing tart: %#v", args)
+	cmd := exec.Command(d.TartPath, args...)
+	cmd.Stdin = stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+
+	stdoutString := strings.TrimSpace(stdout.String())
+	stderrString := strings.TrimSpace(stderr.String())
+
+	if _, ok := err.(*exec.ExitError); ok {
+		err = fmt.Errorf("tart error: %s", stderrString)
+	}
+
+	log.Printf("stdout: %s", stdoutString)
+	log.Printf("stderr: %s", stderrString)
+
+	return stdoutString, err
+}
@@ -0,0 +1,122 @@
+package tart
+
+import (
+	"time"
+)
+
+type DriverMock struct {
+	CloneCalled bool
+	CloneSrc    string
+	CloneName   string
+	CloneErr    error
+
+	SetCalled   bool
+	SetName     string
+	SetCpus     int
+	SetMemory   int
+	SetDiskSize int
+	SetErr      error
+
+	RunCalled   bool
+	RunName     string
+	RunHeadless bool
+	RunDone     chan error
+	RunErr      error
+
+	IPAddressCalled  bool
+	IPAddressName    string
+	IPAddressTimeout time.Duration
+	IPAddressResult  string
+	IPAddressErr     error
+
+	StopCalled bool
+	StopName   string
+	StopErr    error
+
+	DeleteCalled bool
+	DeleteName   string
+	DeleteErr    error
+
+	LoginCalled   bool
+	LoginHost     string
+	LoginUsername string
+	LoginPassword string
+	LoginErr      error
+
+	PushCalled      bool
+	PushName        string
+	PushRemoteNames []string
+	PushErr         error
+
+	VerifyCalled bool
+	VerifyErr    error
+}
+
+func (d *DriverMock) Clone(src, name string) error {
+	d.CloneCalled = true
+	d.CloneSrc = src
+	d.CloneName = name
+	return d.CloneErr
+}
+
+func (d *DriverMock) Set(name string, cpus, memory, diskSize int) error {
+	d.SetCalled = true
+	d.SetName = name
+	d.SetCpus = cpus
+	d.SetMemory = memory
+	d.SetDiskSize = diskSize
+	return d.SetErr
+}
+
+func (d *DriverMock) Run(name string, headless bool) (<-chan error, error) {
+	d.RunCalled = true
+	d.RunName = name
+	d.RunHeadless = headless
+	if d.RunDone == nil {
+		d.RunDone = make(chan error, 1)
+	}
+	return d.RunDone, d.RunErr
+}
+
+func (d *DriverMock) IPAddress(name string, timeout time.Duration) (string, error) {
+	d.IPAddressCalled = true
+	d.IPAddressName = name
+	d.IPAddressTimeout = timeout
+	return d.IPAddressResult, d.IPAddressErr
+}
+
+// Stop stops the VM that Run started.
+func (d *DriverMock) Stop(name string) error {
+	d.StopCalled = true
+	d.StopName = name
+	if d.StopErr == nil && d.RunDone != nil {
+		close(d.RunDone)
+	}
+	return d.StopErr
+}
+
+func (d *DriverMock) Delete(name string) error {
+	d.DeleteCalled = true
+	d.DeleteName = name
+	return d.DeleteErr
+}
+
+func (d *DriverMock) Login(host, username, password string) error {
+	d.LoginCalled = true
+	d.LoginHost = host
+	d.LoginUsername = username
+	d.LoginPassword = password
+	return d.LoginErr
+}
+
+func (d *DriverMock) Push(name string, remoteNames []string) error {
+	d.PushCalled = true
+	d.PushName = name
+	d.PushRemoteNames = remoteNames
+	return d.PushErr
+}
+
+func (d *DriverMock) Verify() error {
+	d.VerifyCalled = true
+	return d.VerifyErr
+}
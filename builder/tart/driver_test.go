@@ -0,0 +1,13 @@
+package tart
+
+import (
+	"testing"
+)
+
+func TestTartDriver_Impl(t *testing.T) {
+	var _ Driver = new(TartDriver)
+}
+
+func TestDriverMock_Impl(t *testing.T) {
+	var _ Driver = new(DriverMock)
+}
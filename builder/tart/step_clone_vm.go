@@ -0,0 +1,63 @@
+package tart
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// stepCloneVM creates the VM from a copy of the source VM or image, and
+// sets its hardware. The VM is deleted in cleanup, unless the build
+// succeeded.
+type stepCloneVM struct {
+	vmName string
+}
+
+func (s *stepCloneVM) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	config := state.Get("config").(*Config)
+	driver := state.Get("driver").(Driver)
+	ui := state.Get("ui").(packer.Ui)
+
+	ui.Say(fmt.Sprintf("Cloning %s into %s...", config.SourceVMName, config.VMName))
+	if err := driver.Clone(config.SourceVMName, config.VMName); err != nil {
+		err := fmt.Errorf("Error cloning VM: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	// We use this in cleanup
+	s.vmName = config.VMName
+
+	err := driver.Set(config.VMName, config.CpuCount, config.MemorySize, config.DiskSize)
+	if err != nil {
+		err := fmt.Errorf("Error setting the hardware of the VM: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *stepCloneVM) Cleanup(state multistep.StateBag) {
+	if s.vmName == "" {
+		return
+	}
+
+	_, cancelled := state.GetOk(multistep.StateCancelled)
+	_, halted := state.GetOk(multistep.StateHalted)
+	if !cancelled && !halted {
+		return
+	}
+
+	driver := state.Get("driver").(Driver)
+	ui := state.Get("ui").(packer.Ui)
+
+	ui.Say("Deleting VM...")
+	if err := driver.Delete(s.vmName); err != nil {
+		ui.Error(fmt.Sprintf("Error deleting VM. Please delete it manually: %s", err))
+	}
+}
@@ -0,0 +1,49 @@
+package tart
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// stepPush pushes the VM to the OCI registries of push_images, logging into
+// login_server first when credentials are specified.
+type stepPush struct{}
+
+func (s *stepPush) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	config := state.Get("config").(*Config)
+	driver := state.Get("driver").(Driver)
+	ui := state.Get("ui").(packer.Ui)
+
+	if len(config.PushImages) == 0 {
+		return multistep.ActionContinue
+	}
+
+	if config.LoginUsername != "" {
+		ui.Say(fmt.Sprintf("Logging into %s...", config.LoginServer))
+		err := driver.Login(config.LoginServer, config.LoginUsername, config.LoginPassword)
+		if err != nil {
+			err := fmt.Errorf("Error logging in: %s", err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+	}
+
+	ui.Say(fmt.Sprintf("Pushing VM to %s...", strings.Join(config.PushImages, ", ")))
+	if err := driver.Push(config.VMName, config.PushImages); err != nil {
+		err := fmt.Errorf("Error pushing VM: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *stepPush) Cleanup(state multistep.StateBag) {
+	// no cleanup
+}
@@ -0,0 +1,79 @@
+package tart
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// stepRunVM boots the VM and waits for its IP address, unless ssh_host is
+// specified.
+//
+// Produces:
+//   vm_done <-chan error - Receives the exit of tart run once the VM stops
+//   vm_ip string         - The IP address of the VM
+type stepRunVM struct {
+	done <-chan error
+}
+
+func (s *stepRunVM) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	config := state.Get("config").(*Config)
+	driver := state.Get("driver").(Driver)
+	ui := state.Get("ui").(packer.Ui)
+
+	ui.Say("Starting VM...")
+	done, err := driver.Run(config.VMName, config.Headless)
+	if err != nil {
+		err := fmt.Errorf("Error starting VM: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	// We use this in cleanup
+	s.done = done
+	state.Put("vm_done", done)
+
+	if config.Comm.SSHHost != "" {
+		return multistep.ActionContinue
+	}
+
+	ui.Say("Waiting for the IP address of the VM...")
+	ip, err := driver.IPAddress(config.VMName, config.IPWaitTimeout)
+	if err != nil {
+		err := fmt.Errorf("Error waiting for the IP address of the VM: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	ui.Message(fmt.Sprintf("IP address: %s", ip))
+
+	state.Put("vm_ip", ip)
+	return multistep.ActionContinue
+}
+
+func (s *stepRunVM) Cleanup(state multistep.StateBag) {
+	if s.done == nil {
+		return
+	}
+
+	// The VM is stopped already, unless the build failed
+	select {
+	case <-s.done:
+		return
+	default:
+	}
+
+	config := state.Get("config").(*Config)
+	driver := state.Get("driver").(Driver)
+	ui := state.Get("ui").(packer.Ui)
+
+	ui.Say("Stopping VM...")
+	if err := driver.Stop(config.VMName); err != nil {
+		ui.Error(fmt.Sprintf("Error stopping VM: %s", err))
+		return
+	}
+	<-s.done
+}
@@ -0,0 +1,56 @@
+package tart
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// stepShutdown shuts the guest down with shutdown_command, and stops the VM
+// when it doesn't shut down in time.
+type stepShutdown struct{}
+
+func (s *stepShutdown) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	comm := state.Get("communicator").(packer.Communicator)
+	config := state.Get("config").(*Config)
+	done := state.Get("vm_done").(<-chan error)
+	driver := state.Get("driver").(Driver)
+	ui := state.Get("ui").(packer.Ui)
+
+	ui.Say("Gracefully shutting down VM...")
+	log.Printf("Executing shutdown command: %s", config.ShutdownCommand)
+	cmd := &packer.RemoteCmd{Command: config.ShutdownCommand}
+	if err := comm.Start(ctx, cmd); err != nil {
+		err := fmt.Errorf("Failed to send shutdown command: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	select {
+	case <-done:
+		return multistep.ActionContinue
+	case <-time.After(config.ShutdownTimeout):
+	case <-ctx.Done():
+		return multistep.ActionHalt
+	}
+
+	ui.Say("VM didn't shut down in time, stopping it...")
+	if err := driver.Stop(config.VMName); err != nil {
+		err := fmt.Errorf("Error stopping VM: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	<-done
+
+	return multistep.ActionContinue
+}
+
+func (s *stepShutdown) Cleanup(state multistep.StateBag) {
+	// no cleanup
+}
@@ -0,0 +1,110 @@
+package tart
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+func testState(t *testing.T) multistep.StateBag {
+	state := new(multistep.BasicStateBag)
+	state.Put("config", &Config{
+		SourceVMName: "ghcr.io/cirruslabs/macos-monterey-base:latest",
+		VMName:       "packer-foo",
+		CpuCount:     4,
+	})
+	state.Put("driver", new(DriverMock))
+	state.Put("ui", &packer.BasicUi{
+		Reader: new(bytes.Buffer),
+		Writer: new(bytes.Buffer),
+	})
+	return state
+}
+
+func TestStepCloneVM(t *testing.T) {
+	state := testState(t)
+	step := new(stepCloneVM)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	driver := state.Get("driver").(*DriverMock)
+	if driver.CloneName != "packer-foo" || driver.SetName != "packer-foo" || driver.SetCpus != 4 {
+		t.Fatalf("bad driver calls: %#v", driver)
+	}
+
+	// The VM of a build that succeeded is kept
+	step.Cleanup(state)
+	if driver.DeleteCalled {
+		t.Fatal("the VM should be kept")
+	}
+
+	state.Put(multistep.StateHalted, true)
+	step.Cleanup(state)
+	if driver.DeleteName != "packer-foo" {
+		t.Fatal("the VM should be deleted")
+	}
+}
+
+func TestStepRunVM(t *testing.T) {
+	state := testState(t)
+	step := new(stepRunVM)
+	driver := state.Get("driver").(*DriverMock)
+	driver.IPAddressResult = "192.168.64.3"
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if ip := state.Get("vm_ip").(string); ip != "192.168.64.3" {
+		t.Fatalf("bad ip: %s", ip)
+	}
+
+	// The VM is still running
+	step.Cleanup(state)
+	if !driver.StopCalled {
+		t.Fatal("the VM should be stopped")
+	}
+}
+
+func TestStepRunVM_Stopped(t *testing.T) {
+	state := testState(t)
+	step := new(stepRunVM)
+	driver := state.Get("driver").(*DriverMock)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	close(driver.RunDone)
+	step.Cleanup(state)
+	if driver.StopCalled {
+		t.Fatal("the VM should not be stopped")
+	}
+}
+
+func TestStepPush(t *testing.T) {
+	state := testState(t)
+	config := state.Get("config").(*Config)
+	config.PushImages = []string{"ghcr.io/acme/macos:latest", "ghcr.io/acme/macos:1.0"}
+	config.LoginServer = "ghcr.io"
+	config.LoginUsername = "packer"
+	config.LoginPassword = "secret"
+	step := new(stepPush)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	driver := state.Get("driver").(*DriverMock)
+	if driver.LoginHost != "ghcr.io" {
+		t.Fatalf("bad login host: %s", driver.LoginHost)
+	}
+	if !reflect.DeepEqual(driver.PushRemoteNames, config.PushImages) {
+		t.Fatalf("bad remote names: %#v", driver.PushRemoteNames)
+	}
+}
@@ -0,0 +1,58 @@
+package utm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Artifact is the bundle of the VM, exported to the output directory or
+// kept in UTM.
+type Artifact struct {
+	bundle     string
+	registered bool
+	driver     Driver
+	vmName     string
+}
+
+func (*Artifact) BuilderId() string {
+	return BuilderId
+}
+
+func (a *Artifact) Files() []string {
+	// The VM kept in UTM belongs to UTM
+	if a.registered {
+		return nil
+	}
+
+	var files []string
+	filepath.Walk(a.bundle, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files
+}
+
+func (a *Artifact) Id() string {
+	return a.vmName
+}
+
+func (a *Artifact) String() string {
+	if a.registered {
+		return fmt.Sprintf("VM registered with UTM: %s", a.vmName)
+	}
+	return fmt.Sprintf("VM exported to: %s", a.bundle)
+}
+
+func (a *Artifact) State(name string) interface{} {
+	return nil
+}
+
+func (a *Artifact) Destroy() error {
+	if a.registered {
+		return a.driver.Delete(a.vmName)
+	}
+	return os.RemoveAll(a.bundle)
+}
@@ -0,0 +1,102 @@
+// The utm package contains a packer.Builder implementation that builds
+// virtual machines with UTM, which runs ARM64 Linux and macOS guests on
+// Apple Silicon Macs with QEMU or the Virtualization framework.
+package utm
+
+import (
+	"context"
+	"errors"
+	"runtime"
+
+	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/helper/communicator"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// The unique ID for this builder
+const BuilderId = "packer.utm"
+
+type Builder struct {
+	config Config
+	runner multistep.Runner
+}
+
+func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
+	c, warnings, errs := NewConfig(raws...)
+	if errs != nil {
+		return warnings, errs
+	}
+	b.config = *c
+
+	return warnings, nil
+}
+
+func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (packer.Artifact, error) {
+	if runtime.GOOS != "darwin" {
+		return nil, errors.New("The utm builder only works on macOS.")
+	}
+
+	driver := &UtmctlDriver{UtmctlPath: b.config.UtmctlPath}
+	if err := driver.Verify(); err != nil {
+		return nil, err
+	}
+
+	// Setup the state bag and initial state for the steps
+	state := new(multistep.BasicStateBag)
+	state.Put("config", &b.config)
+	state.Put("driver", driver)
+	state.Put("hook", hook)
+	state.Put("ui", ui)
+
+	// Build the steps
+	var steps []multistep.Step
+	if !b.config.KeepRegistered {
+		steps = append(steps, &common.StepOutputDir{
+			Force: b.config.PackerForce,
+			Path:  b.config.OutputDir,
+		})
+	}
+	steps = append(steps,
+		new(stepCloneVM),
+		new(stepStartVM),
+		&communicator.StepConnect{
+			Config:    &b.config.Comm,
+			Host:      communicator.CommHost(b.config.Comm.SSHHost, "vm_ip"),
+			SSHConfig: b.config.Comm.SSHConfigFunc(),
+		},
+		new(common.StepProvision),
+		&common.StepCleanupTempKeys{
+			Comm: &b.config.Comm,
+		},
+		new(stepShutdown),
+		new(stepExport),
+	)
+
+	// Run!
+	b.runner = common.NewRunner(steps, b.config.PackerConfig, ui)
+	b.runner.Run(ctx, state)
+
+	// If there was an error, return that
+	if rawErr, ok := state.GetOk("error"); ok {
+		return nil, rawErr.(error)
+	}
+
+	// If we were interrupted or cancelled, then just exit.
+	if _, ok := state.GetOk(multistep.StateCancelled); ok {
+		return nil, errors.New("Build was cancelled.")
+	}
+
+	if _, ok := state.GetOk(multistep.StateHalted); ok {
+		return nil, errors.New("Build was halted.")
+	}
+
+	artifact := &Artifact{
+		bundle:     state.Get("bundle_path").(string),
+		registered: b.config.KeepRegistered,
+		driver:     driver,
+		vmName:     b.config.VMName,
+	}
+
+	return artifact, nil
+}
@@ -0,0 +1,110 @@
+package utm
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/helper/communicator"
+	"github.com/hashicorp/packer/helper/config"
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/template/interpolate"
+)
+
+type Config struct {
+	common.PackerConfig `mapstructure:",squash"`
+	Comm                communicator.Config `mapstructure:",squash"`
+
+	UtmctlPath      string        `mapstructure:"utmctl_path"`
+	DocumentsDir    string        `mapstructure:"utm_documents_directory"`
+	SourceVMName    string        `mapstructure:"source_vm_name"`
+	VMName          string        `mapstructure:"vm_name"`
+	IPWaitTimeout   time.Duration `mapstructure:"ip_wait_timeout"`
+	ShutdownCommand string        `mapstructure:"shutdown_command"`
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+	KeepRegistered  bool          `mapstructure:"keep_registered"`
+	OutputDir       string        `mapstructure:"output_directory"`
+
+	ctx interpolate.Context
+}
+
+func NewConfig(raws ...interface{}) (*Config, []string, error) {
+	c := new(Config)
+
+	err := config.Decode(c, &config.DecodeOpts{
+		Interpolate:        true,
+		InterpolateContext: &c.ctx,
+	}, raws...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var errs *packer.MultiError
+
+	// Defaults
+	if c.UtmctlPath == "" {
+		c.UtmctlPath = "/Applications/UTM.app/Contents/MacOS/utmctl"
+	}
+
+	if c.DocumentsDir == "" {
+		c.DocumentsDir = filepath.Join(os.Getenv("HOME"),
+			"Library", "Containers", "com.utmapp.UTM", "Data", "Documents")
+	}
+
+	if c.VMName == "" {
+		c.VMName = fmt.Sprintf("packer-%s", c.PackerBuildName)
+	}
+
+	if c.IPWaitTimeout == 0 {
+		c.IPWaitTimeout = 10 * time.Minute
+	}
+
+	if c.ShutdownCommand == "" {
+		c.ShutdownCommand = "sudo shutdown -h now"
+	}
+
+	if c.ShutdownTimeout == 0 {
+		c.ShutdownTimeout = 5 * time.Minute
+	}
+
+	if c.OutputDir == "" {
+		c.OutputDir = fmt.Sprintf("output-%s", c.PackerBuildName)
+	}
+
+	if es := c.Comm.Prepare(&c.ctx); len(es) > 0 {
+		errs = packer.MultiErrorAppend(errs, es...)
+	}
+
+	if c.SourceVMName == "" {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("source_vm_name must be specified"))
+	}
+
+	if c.SourceVMName != "" && c.SourceVMName == c.VMName {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("vm_name must differ from source_vm_name"))
+	}
+
+	if !c.KeepRegistered && !c.PackerForce {
+		if _, err := os.Stat(c.OutputDir); err == nil {
+			errs = packer.MultiErrorAppend(
+				errs,
+				fmt.Errorf("Output directory '%s' already exists. It must not exist.", c.OutputDir))
+		}
+	}
+
+	if errs != nil && len(errs.Errors) > 0 {
+		return nil, nil, errs
+	}
+
+	return c, nil, nil
+}
+
+// bundlePath returns the path of the bundle of the VM, which UTM names
+// after it.
+func (c *Config) bundlePath() string {
+	return filepath.Join(c.DocumentsDir, c.VMName+".utm")
+}
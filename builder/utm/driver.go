@@ -0,0 +1,133 @@
+package utm
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net"
+	"os/exec"
+	"strings"
+)
+
+// A driver is able to talk to UTM and perform certain operations with it.
+type Driver interface {
+	// Clone creates a new VM from a copy of a VM.
+	Clone(src, name string) error
+
+	// Start boots a VM.
+	Start(name string) error
+
+	// Stop turns a VM off, without shutting its guest down.
+	Stop(name string) error
+
+	// Delete removes a VM and its files.
+	Delete(name string) error
+
+	// Status returns the status of a VM, such as "started" or "stopped".
+	Status(name string) (string, error)
+
+	// IPAddress returns the first IPv4 address of the guest of a VM, which
+	// UTM reads from the QEMU guest agent.
+	IPAddress(name string) (string, error)
+
+	// Export copies the bundle of a VM, which UTM keeps in its documents
+	// directory, to a directory.
+	Export(bundle, dst string) error
+
+	// Verify checks to make sure that this driver should function
+	// properly. If there is any indication the driver can't function,
+	// this will return an error.
+	Verify() error
+}
+
+// UtmctlDriver is a Driver that runs the utmctl command line tool of UTM.
+type UtmctlDriver struct {
+	UtmctlPath string
+}
+
+func (d *UtmctlDriver) Clone(src, name string) error {
+	_, err := d.utmctl("clone", src, "--name", name)
+	return err
+}
+
+func (d *UtmctlDriver) Start(name string) error {
+	_, err := d.utmctl("start", name)
+	return err
+}
+
+func (d *UtmctlDriver) Stop(name string) error {
+	_, err := d.utmctl("stop", name, "--force")
+	return err
+}
+
+func (d *UtmctlDriver) Delete(name string) error {
+	_, err := d.utmctl("delete", name)
+	return err
+}
+
+func (d *UtmctlDriver) Status(name string) (string, error) {
+	return d.utmctl("status", name)
+}
+
+func (d *UtmctlDriver) IPAddress(name string) (string, error) {
+	stdout, err := d.utmctl("ip-address", name)
+	if err != nil {
+		return "", err
+	}
+	return parseIPAddress(stdout)
+}
+
+func (d *UtmctlDriver) Export(bundle, dst string) error {
+	var stderr bytes.Buffer
+
+	// ditto keeps the sparse disks of the bundle sparse
+	log.Printf("Executing ditto: %s %s", bundle, dst)
+	cmd := exec.Command("ditto", bundle, dst)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("ditto error: %s", strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+func (d *UtmctlDriver) Verify() error {
+	if _, err := exec.LookPath(d.UtmctlPath); err != nil {
+		return fmt.Errorf("utmctl not found: %s", err)
+	}
+	_, err := d.utmctl("version")
+	return err
+}
+
+func (d *UtmctlDriver) utmctl(args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	log.Printf("Executing utmctl: %#v", args)
+	cmd := exec.Command(d.UtmctlPath, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+
+	stdoutString := strings.TrimSpace(stdout.String())
+	stderrString := strings.TrimSpace(stderr.String())
+
+	if _, ok := err.(*exec.ExitError); ok {
+		err = fmt.Errorf("utmctl error: %s", stderrString)
+	}
+
+	log.Printf("stdout: %s", stdoutString)
+	log.Printf("stderr: %s", stderrString)
+
+	return stdoutString, err
+}
+
+// parseIPAddress returns the first IPv4 address of the output of utmctl
+// ip-address, which lists an address per line, IPv6 ones included.
+func parseIPAddress(stdout string) (string, error) {
+	for _, line := range strings.Split(stdout, "\n") {
+		ip := net.ParseIP(strings.TrimSpace(line))
+		if ip != nil && ip.To4() != nil {
+			return ip.String(), nil
+		}
+	}
+	return "", fmt.Errorf("no IPv4 address found")
+}
@@ -0,0 +1,87 @@
+package utm
+
+type DriverMock struct {
+	CloneCalled bool
+	CloneSrc    string
+	CloneName   string
+	CloneErr    error
+
+	StartCalled bool
+	StartName   string
+	StartErr    error
+
+	StopCalled bool
+	StopName   string
+	StopErr    error
+
+	DeleteCalled bool
+	DeleteName   string
+	DeleteErr    error
+
+	StatusCalled bool
+	StatusName   string
+	StatusResult string
+	StatusErr    error
+
+	IPAddressCalled bool
+	IPAddressName   string
+	IPAddressResult string
+	IPAddressErr    error
+
+	ExportCalled bool
+	ExportBundle string
+	ExportDst    string
+	ExportErr    error
+
+	VerifyCalled bool
+	VerifyErr    error
+}
+
+func (d *DriverMock) Clone(src, name string) error {
+	d.CloneCalled = true
+	d.CloneSrc = src
+	d.CloneName = name
+	return d.CloneErr
+}
+
+func (d *DriverMock) Start(name string) error {
+	d.StartCalled = true
+	d.StartName = name
+	return d.StartErr
+}
+
+func (d *DriverMock) Stop(name string) error {
+	d.StopCalled = true
+	d.StopName = name
+	return d.StopErr
+}
+
+func (d *DriverMock) Delete(name string) error {
+	d.DeleteCalled = true
+	d.DeleteName = name
+	return d.DeleteErr
+}
+
+func (d *DriverMock) Status(name string) (string, error) {
+	d.StatusCalled = true
+	d.StatusName = name
+	return d.StatusResult, d.StatusErr
+}
+
+func (d *DriverMock) IPAddress(name string) (string, error) {
+	d.IPAddressCalled = true
+	d.IPAddressName = name
+	return d.IPAddressResult, d.IPAddressErr
+}
+
+func (d *DriverMock) Export(bundle, dst string) error {
+	d.ExportCalled = true
+	d.ExportBundle = bundle
+	d.ExportDst = dst
+	return d.ExportErr
+}
+
+func (d *DriverMock) Verify() error {
+	d.VerifyCalled = true
+	return d.VerifyErr
+}
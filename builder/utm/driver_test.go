@@ -0,0 +1,28 @@
+package utm
+
+import (
+	"testing"
+)
+
+func TestUtmctlDriver_Impl(t *testing.T) {
+	var _ Driver = new(UtmctlDriver)
+}
+
+func TestDriverMock_Impl(t *testing.T) {
+	var _ Driver = new(DriverMock)
+}
+
+func TestParseIPAddress(t *testing.T) {
+	stdout := "fe80::5054:ff:fe12:3456\n192.168.64.5\n192.168.64.6"
+	ip, err := parseIPAddress(stdout)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if ip != "192.168.64.5" {
+		t.Fatalf("bad ip: %s", ip)
+	}
+
+	if _, err := parseIPAddress("fe80::5054:ff:fe12:3456"); err == nil {
+		t.Fatal("should have error")
+	}
+}
@@ -0,0 +1,57 @@
+package utm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// stepCloneVM creates the VM from a copy of the source VM. The VM is
+// deleted in cleanup, unless it's kept registered with UTM after a build
+// that succeeded.
+type stepCloneVM struct {
+	vmName string
+}
+
+func (s *stepCloneVM) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	config := state.Get("config").(*Config)
+	driver := state.Get("driver").(Driver)
+	ui := state.Get("ui").(packer.Ui)
+
+	ui.Say(fmt.Sprintf("Cloning %s into %s...", config.SourceVMName, config.VMName))
+	if err := driver.Clone(config.SourceVMName, config.VMName); err != nil {
+		err := fmt.Errorf("Error cloning VM: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	// We use this in cleanup
+	s.vmName = config.VMName
+
+	return multistep.ActionContinue
+}
+
+func (s *stepCloneVM) Cleanup(state multistep.StateBag) {
+	if s.vmName == "" {
+		return
+	}
+
+	config := state.Get("config").(*Config)
+	driver := state.Get("driver").(Driver)
+	ui := state.Get("ui").(packer.Ui)
+
+	_, cancelled := state.GetOk(multistep.StateCancelled)
+	_, halted := state.GetOk(multistep.StateHalted)
+	if config.KeepRegistered && !cancelled && !halted {
+		ui.Say("Keeping VM registered with UTM (keep_registered = true)")
+		return
+	}
+
+	ui.Say("Deleting VM...")
+	if err := driver.Delete(s.vmName); err != nil {
+		ui.Error(fmt.Sprintf("Error deleting VM. Please delete it manually: %s", err))
+	}
+}
@@ -0,0 +1,44 @@
+package utm
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// stepExport copies the bundle of the VM into the output directory, unless
+// the VM is kept registered with UTM.
+//
+// Produces:
+//   bundle_path string - The path of the bundle of the artifact
+type stepExport struct{}
+
+func (s *stepExport) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	config := state.Get("config").(*Config)
+	driver := state.Get("driver").(Driver)
+	ui := state.Get("ui").(packer.Ui)
+
+	if config.KeepRegistered {
+		state.Put("bundle_path", config.bundlePath())
+		return multistep.ActionContinue
+	}
+
+	dst := filepath.Join(config.OutputDir, config.VMName+".utm")
+	ui.Say(fmt.Sprintf("Exporting VM to %s...", dst))
+	if err := driver.Export(config.bundlePath(), dst); err != nil {
+		err := fmt.Errorf("Error exporting VM: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	state.Put("bundle_path", dst)
+	return multistep.ActionContinue
+}
+
+func (s *stepExport) Cleanup(state multistep.StateBag) {
+	// no cleanup
+}
@@ -0,0 +1,65 @@
+package utm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/packer/common/retry"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// stepShutdown shuts the guest down with shutdown_command, and stops the VM
+// when it doesn't shut down in time.
+type stepShutdown struct{}
+
+func (s *stepShutdown) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	comm := state.Get("communicator").(packer.Communicator)
+	config := state.Get("config").(*Config)
+	driver := state.Get("driver").(Driver)
+	ui := state.Get("ui").(packer.Ui)
+
+	ui.Say("Gracefully shutting down VM...")
+	log.Printf("Executing shutdown command: %s", config.ShutdownCommand)
+	cmd := &packer.RemoteCmd{Command: config.ShutdownCommand}
+	if err := comm.Start(ctx, cmd); err != nil {
+		err := fmt.Errorf("Failed to send shutdown command: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	err := retry.Config{
+		StartTimeout: config.ShutdownTimeout,
+		RetryDelay:   func() time.Duration { return pollInterval },
+	}.Run(ctx, func(ctx context.Context) error {
+		status, err := driver.Status(config.VMName)
+		if err != nil {
+			return err
+		}
+		if status != "stopped" {
+			return errors.New("VM is " + status)
+		}
+		return nil
+	})
+	if err == nil {
+		return multistep.ActionContinue
+	}
+
+	ui.Say("VM didn't shut down in time, stopping it...")
+	if err := driver.Stop(config.VMName); err != nil {
+		err := fmt.Errorf("Error stopping VM: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *stepShutdown) Cleanup(state multistep.StateBag) {
+	// no cleanup
+}
@@ -0,0 +1,84 @@
+package utm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/packer/common/retry"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// pollInterval is the time between two checks of the state of the VM.
+var pollInterval = 2 * time.Second
+
+// stepStartVM boots the VM and waits for the IP address of its guest,
+// unless ssh_host is specified, such as for guests without the agent.
+//
+// Produces:
+//   vm_ip string - The IP address of the guest
+type stepStartVM struct {
+	vmName string
+}
+
+func (s *stepStartVM) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	config := state.Get("config").(*Config)
+	driver := state.Get("driver").(Driver)
+	ui := state.Get("ui").(packer.Ui)
+
+	ui.Say("Starting VM...")
+	if err := driver.Start(config.VMName); err != nil {
+		err := fmt.Errorf("Error starting VM: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	// We use this in cleanup
+	s.vmName = config.VMName
+
+	if config.Comm.SSHHost != "" {
+		return multistep.ActionContinue
+	}
+
+	ui.Say("Waiting for the IP address of the VM...")
+	var ip string
+	err := retry.Config{
+		StartTimeout: config.IPWaitTimeout,
+		RetryDelay:   func() time.Duration { return pollInterval },
+	}.Run(ctx, func(ctx context.Context) error {
+		var err error
+		ip, err = driver.IPAddress(config.VMName)
+		return err
+	})
+	if err != nil {
+		err := fmt.Errorf("Error waiting for the IP address of the VM: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	ui.Message(fmt.Sprintf("IP address: %s", ip))
+
+	state.Put("vm_ip", ip)
+	return multistep.ActionContinue
+}
+
+func (s *stepStartVM) Cleanup(state multistep.StateBag) {
+	if s.vmName == "" {
+		return
+	}
+
+	driver := state.Get("driver").(Driver)
+	ui := state.Get("ui").(packer.Ui)
+
+	// The VM is stopped already, unless the build failed
+	if status, err := driver.Status(s.vmName); err == nil && status == "stopped" {
+		return
+	}
+
+	ui.Say("Stopping VM...")
+	if err := driver.Stop(s.vmName); err != nil {
+		ui.Error(fmt.Sprintf("Error stopping VM: %s", err))
+	}
+}
@@ -0,0 +1,85 @@
+package utm
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+func testState(t *testing.T) multistep.StateBag {
+	state := new(multistep.BasicStateBag)
+	state.Put("config", &Config{
+		SourceVMName: "ubuntu-base",
+		VMName:       "packer-foo",
+		DocumentsDir: "/Documents",
+		OutputDir:    "output-foo",
+	})
+	state.Put("driver", new(DriverMock))
+	state.Put("ui", &packer.BasicUi{
+		Reader: new(bytes.Buffer),
+		Writer: new(bytes.Buffer),
+	})
+	return state
+}
+
+func TestStepCloneVM(t *testing.T) {
+	state := testState(t)
+	step := new(stepCloneVM)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	driver := state.Get("driver").(*DriverMock)
+	if driver.CloneSrc != "ubuntu-base" || driver.CloneName != "packer-foo" {
+		t.Fatalf("bad clone: %s, %s", driver.CloneSrc, driver.CloneName)
+	}
+
+	step.Cleanup(state)
+	if driver.DeleteName != "packer-foo" {
+		t.Fatalf("the VM should be deleted")
+	}
+}
+
+func TestStepCloneVM_KeepRegistered(t *testing.T) {
+	state := testState(t)
+	state.Get("config").(*Config).KeepRegistered = true
+	step := new(stepCloneVM)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	driver := state.Get("driver").(*DriverMock)
+	step.Cleanup(state)
+	if driver.DeleteCalled {
+		t.Fatalf("the VM should be kept")
+	}
+
+	// A VM of a failed build is deleted all the same
+	state.Put(multistep.StateHalted, true)
+	step.Cleanup(state)
+	if !driver.DeleteCalled {
+		t.Fatalf("the VM should be deleted")
+	}
+}
+
+func TestStepExport(t *testing.T) {
+	state := testState(t)
+	step := new(stepExport)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	driver := state.Get("driver").(*DriverMock)
+	if driver.ExportBundle != "/Documents/packer-foo.utm" {
+		t.Fatalf("bad bundle: %s", driver.ExportBundle)
+	}
+	if bundle := state.Get("bundle_path").(string); bundle != "output-foo/packer-foo.utm" {
+		t.Fatalf("bad bundle path: %s", bundle)
+	}
+}
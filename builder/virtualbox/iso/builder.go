@@ -212,6 +212,7 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			ChecksumType: b.config.ISOChecksumType,
 			Description:  "ISO",
 			Extension:    b.config.TargetExtension,
+			Headers:      b.config.ISOHTTPHeaders,
 			ResultKey:    "iso_path",
 			TargetPath:   b.config.TargetPath,
 			Url:          b.config.ISOUrls,
@@ -223,6 +224,7 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 		&common.StepCreateFloppy{
 			Files:       b.config.FloppyConfig.FloppyFiles,
 			Directories: b.config.FloppyConfig.FloppyDirectories,
+			Content:     b.config.FloppyConfig.FloppyContent,
 		},
 		&common.StepHTTPServer{
 			HTTPDir:     b.config.HTTPDir,
@@ -2,7 +2,9 @@ package common
 
 import (
 	"fmt"
+	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/hashicorp/packer/helper/multistep"
 	"github.com/hashicorp/packer/packer"
@@ -16,6 +18,22 @@ const (
 	ArtifactConfFormat         = "artifact.conf.format"
 	ArtifactConfKeepRegistered = "artifact.conf.keep_registered"
 	ArtifactConfSkipExport     = "artifact.conf.skip_export"
+
+	// ArtifactFileRoles is the state of the artifact that maps each of its
+	// files to its role, so post-processors can pick the file they need.
+	ArtifactFileRoles = "artifact.file_roles"
+)
+
+// The roles of the files of an artifact.
+const (
+	FileRoleVMX      = "vmx"
+	FileRoleDisk     = "disk"
+	FileRoleNVRAM    = "nvram"
+	FileRoleOVF      = "ovf"
+	FileRoleOVA      = "ova"
+	FileRoleManifest = "manifest"
+	FileRoleLog      = "log"
+	FileRoleOther    = "other"
 )
 
 // Artifact is the result of running the VMware builder, namely a set
@@ -25,6 +43,7 @@ type artifact struct {
 	id        string
 	dir       OutputDir
 	f         []string
+	roles     map[string]string
 	config    map[string]string
 }
 
@@ -45,6 +64,9 @@ func (a *artifact) String() string {
 }
 
 func (a *artifact) State(name string) interface{} {
+	if name == ArtifactFileRoles {
+		return a.roles
+	}
 	return a.config[name]
 }
 
@@ -76,6 +98,11 @@ func NewArtifact(remoteType string, format string, exportOutputPath string, vmNa
 		builderId = BuilderIdESX
 	}
 
+	roles := make(map[string]string, len(files))
+	for _, file := range files {
+		roles[file] = fileRole(file)
+	}
+
 	config := make(map[string]string)
 	config[ArtifactConfKeepRegistered] = strconv.FormatBool(keepRegistered)
 	config[ArtifactConfFormat] = format
@@ -86,6 +113,28 @@ func NewArtifact(remoteType string, format string, exportOutputPath string, vmNa
 		id:        vmName,
 		dir:       dir,
 		f:         files,
+		roles:     roles,
 		config:    config,
 	}, nil
 }
+
+// fileRole returns the role of a file of the artifact from its extension.
+func fileRole(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".vmx":
+		return FileRoleVMX
+	case ".vmdk":
+		return FileRoleDisk
+	case ".nvram":
+		return FileRoleNVRAM
+	case ".ovf":
+		return FileRoleOVF
+	case ".ova":
+		return FileRoleOVA
+	case ".mf":
+		return FileRoleManifest
+	case ".log":
+		return FileRoleLog
+	}
+	return FileRoleOther
+}
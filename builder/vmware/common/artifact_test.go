@@ -1,11 +1,60 @@
 package common
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 
+	"github.com/hashicorp/packer/helper/multistep"
 	"github.com/hashicorp/packer/packer"
 )
 
 func TestLocalArtifact_impl(t *testing.T) {
 	var _ packer.Artifact = new(artifact)
 }
+
+func TestNewArtifact_fileRoles(t *testing.T) {
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	names := []string{"packer.vmx", "disk.vmdk", "packer.nvram", "vmware.log", "packer.ova", "packer.vmsd"}
+	for _, name := range names {
+		if err := ioutil.WriteFile(filepath.Join(td, name), nil, 0644); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+
+	dir := new(LocalOutputDir)
+	dir.SetOutputDir(td)
+	state := new(multistep.BasicStateBag)
+	state.Put("dir", dir)
+
+	a, err := NewArtifact("", "ova", td, "packer", false, false, state)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(a.Files()) != len(names) {
+		t.Fatalf("bad: %#v", a.Files())
+	}
+
+	roles := a.State(ArtifactFileRoles).(map[string]string)
+	expected := map[string]string{
+		filepath.Join(td, "packer.vmx"):   FileRoleVMX,
+		filepath.Join(td, "disk.vmdk"):    FileRoleDisk,
+		filepath.Join(td, "packer.nvram"): FileRoleNVRAM,
+		filepath.Join(td, "vmware.log"):   FileRoleLog,
+		filepath.Join(td, "packer.ova"):   FileRoleOVA,
+		filepath.Join(td, "packer.vmsd"):  FileRoleOther,
+	}
+	if !reflect.DeepEqual(roles, expected) {
+		t.Fatalf("bad: %#v", roles)
+	}
+	if a.State(ArtifactConfFormat) != "ova" {
+		t.Fatalf("bad: %#v", a.State(ArtifactConfFormat))
+	}
+}
@@ -0,0 +1,33 @@
+package common
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/packer/template/interpolate"
+)
+
+// CDROMConfig configures ISO images, such as driver disks, that are attached
+// to the VM in addition to the installation ISO while it is being built.
+type CDROMConfig struct {
+	AdditionalISOPaths []string `mapstructure:"additional_iso_paths"`
+}
+
+func (c *CDROMConfig) Prepare(ctx *interpolate.Context) []error {
+	var errs []error
+
+	for i, isoPath := range c.AdditionalISOPaths {
+		path, err := filepath.Abs(isoPath)
+		if err == nil {
+			_, err = os.Stat(path)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("Bad additional ISO '%s': %s", isoPath, err))
+			continue
+		}
+		c.AdditionalISOPaths[i] = path
+	}
+
+	return errs
+}
@@ -0,0 +1,30 @@
+package common
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCDROMConfigPrepare(t *testing.T) {
+	var c CDROMConfig
+	if errs := c.Prepare(testConfigTemplate(t)); len(errs) != 0 {
+		t.Fatalf("err: %#v", errs)
+	}
+
+	c = CDROMConfig{
+		AdditionalISOPaths: []string{"cdrom_config.go"},
+	}
+	if errs := c.Prepare(testConfigTemplate(t)); len(errs) != 0 {
+		t.Fatalf("err: %#v", errs)
+	}
+	if !filepath.IsAbs(c.AdditionalISOPaths[0]) {
+		t.Fatalf("path should be absolute: %s", c.AdditionalISOPaths[0])
+	}
+
+	c = CDROMConfig{
+		AdditionalISOPaths: []string{"i-dont-exist.iso"},
+	}
+	if errs := c.Prepare(testConfigTemplate(t)); len(errs) != 1 {
+		t.Fatalf("bad: %#v", errs)
+	}
+}
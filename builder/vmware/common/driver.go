@@ -2,8 +2,10 @@ package common
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"net"
@@ -23,22 +25,44 @@ type Driver interface {
 	// Clone clones the VMX and the disk to the destination path. The
 	// destination is a path to the VMX file. The disk will be copied
 	// to that same directory.
-	Clone(dst string, src string, cloneType bool) error
+	Clone(ctx context.Context, dst string, src string, cloneType bool) error
 
 	// CompactDisk compacts a virtual disk.
-	CompactDisk(string) error
+	CompactDisk(context.Context, string) error
 
 	// CreateDisk creates a virtual disk with the given size.
-	CreateDisk(string, string, string, string) error
+	CreateDisk(context.Context, string, string, string, string) error
+
+	// CopyDisk copies the virtual disk at the second path to the first
+	// one, converting it to the given disk type.
+	CopyDisk(context.Context, string, string, string) error
+
+	// ExpandDisk grows a virtual disk to the given size.
+	ExpandDisk(context.Context, string, string) error
 
 	// Checks if the VMX file at the given path is running.
-	IsRunning(string) (bool, error)
+	IsRunning(context.Context, string) (bool, error)
 
 	// Start starts a VM specified by the path to the VMX given.
-	Start(string, bool) error
+	Start(context.Context, string, bool) error
 
 	// Stop stops a VM specified by the path to the VMX given.
-	Stop(string) error
+	Stop(context.Context, string) error
+
+	// Suspend suspends a VM specified by the path to the VMX given.
+	Suspend(context.Context, string) error
+
+	// CreateSnapshot takes a snapshot with the given name of the VM
+	// specified by the path to the VMX given.
+	CreateSnapshot(context.Context, string, string) error
+
+	// DeleteSnapshot deletes the snapshot with the given name of the VM
+	// specified by the path to the VMX given.
+	DeleteSnapshot(context.Context, string, string) error
+
+	// RevertToSnapshot reverts the VM specified by the path to the VMX
+	// given to the snapshot with the given name.
+	RevertToSnapshot(context.Context, string, string) error
 
 	// SuppressMessages modifies the VMX or surrounding directory so that
 	// VMware doesn't show any annoying messages.
@@ -84,7 +108,32 @@ type Driver interface {
 func NewDriver(dconfig *DriverConfig, config *SSHConfig, vmName string) (Driver, error) {
 	drivers := []Driver{}
 
-	if dconfig.RemoteType != "" {
+	retry := RunRetry{
+		Retries: dconfig.VMRunRetries,
+		Delay:   dconfig.VMRunRetryDelay,
+	}
+
+	if dconfig.RemoteType != "" && dconfig.RemoteAPI == "vsphere" {
+		drivers = []Driver{
+			&VSphereDriver{
+				Host:           dconfig.RemoteHost,
+				Port:           dconfig.RemotePort,
+				Username:       dconfig.RemoteUser,
+				Password:       dconfig.RemotePassword,
+				Datastore:      dconfig.RemoteDatastore,
+				CacheDatastore: dconfig.RemoteCacheDatastore,
+				CacheDirectory: dconfig.RemoteCacheDirectory,
+				Datacenter:     dconfig.RemoteDatacenter,
+				Cluster:        dconfig.RemoteCluster,
+				ResourcePool:   dconfig.RemoteResourcePool,
+				Folder:         dconfig.RemoteFolder,
+				Insecure:       dconfig.InsecureConnection,
+				VMName:         vmName,
+				CommConfig:     config.Comm,
+			},
+		}
+
+	} else if dconfig.RemoteType != "" {
 		drivers = []Driver{
 			&ESX5Driver{
 				Host:           dconfig.RemoteHost,
@@ -106,13 +155,19 @@ func NewDriver(dconfig *DriverConfig, config *SSHConfig, vmName string) (Driver,
 			drivers = []Driver{
 				&Fusion6Driver{
 					Fusion5Driver: Fusion5Driver{
-						AppPath:   dconfig.FusionAppPath,
-						SSHConfig: config,
+						AppPath:          dconfig.FusionAppPath,
+						VdiskManagerPath: dconfig.VDiskManagerPath,
+						VmrunPath:        dconfig.VMRunPath,
+						RunRetry:         retry,
+						SSHConfig:        config,
 					},
 				},
 				&Fusion5Driver{
-					AppPath:   dconfig.FusionAppPath,
-					SSHConfig: config,
+					AppPath:          dconfig.FusionAppPath,
+					VdiskManagerPath: dconfig.VDiskManagerPath,
+					VmrunPath:        dconfig.VMRunPath,
+					RunRetry:         retry,
+					SSHConfig:        config,
 				},
 			}
 		case "linux":
@@ -121,19 +176,31 @@ func NewDriver(dconfig *DriverConfig, config *SSHConfig, vmName string) (Driver,
 			drivers = []Driver{
 				&Workstation10Driver{
 					Workstation9Driver: Workstation9Driver{
-						SSHConfig: config,
+						VdiskManagerPath: dconfig.VDiskManagerPath,
+						VmrunPath:        dconfig.VMRunPath,
+						RunRetry:         retry,
+						SSHConfig:        config,
 					},
 				},
 				&Workstation9Driver{
-					SSHConfig: config,
+					VdiskManagerPath: dconfig.VDiskManagerPath,
+					VmrunPath:        dconfig.VMRunPath,
+					RunRetry:         retry,
+					SSHConfig:        config,
 				},
 				&Player6Driver{
 					Player5Driver: Player5Driver{
-						SSHConfig: config,
+						VdiskManagerPath: dconfig.VDiskManagerPath,
+						VmrunPath:        dconfig.VMRunPath,
+						RunRetry:         retry,
+						SSHConfig:        config,
 					},
 				},
 				&Player5Driver{
-					SSHConfig: config,
+					VdiskManagerPath: dconfig.VDiskManagerPath,
+					VmrunPath:        dconfig.VMRunPath,
+					RunRetry:         retry,
+					SSHConfig:        config,
 				},
 			}
 		default:
@@ -141,6 +208,20 @@ func NewDriver(dconfig *DriverConfig, config *SSHConfig, vmName string) (Driver,
 		}
 	}
 
+	if dconfig.Driver != "" {
+		var forced []Driver
+		for _, driver := range drivers {
+			if driverName(driver) == dconfig.Driver {
+				forced = append(forced, driver)
+			}
+		}
+		if len(forced) == 0 {
+			return nil, fmt.Errorf("driver %s is not available on %s",
+				dconfig.Driver, runtime.GOOS)
+		}
+		drivers = forced
+	}
+
 	errs := ""
 	for _, driver := range drivers {
 		err := driver.Verify()
@@ -150,7 +231,7 @@ func NewDriver(dconfig *DriverConfig, config *SSHConfig, vmName string) (Driver,
 		if err == nil {
 			return driver, nil
 		}
-		errs += "* " + err.Error() + "\n"
+		errs += fmt.Sprintf("* %s: %s\n", driverName(driver), err)
 	}
 
 	return nil, fmt.Errorf(
@@ -159,13 +240,137 @@ func NewDriver(dconfig *DriverConfig, config *SSHConfig, vmName string) (Driver,
 			"to continue:\n%s", errs)
 }
 
-func runAndLog(cmd *exec.Cmd) (string, string, error) {
+// driverName returns the name used by the driver configuration option to
+// select the given driver.
+func driverName(driver Driver) string {
+	switch driver.(type) {
+	case *Fusion6Driver:
+		return "fusion6"
+	case *Fusion5Driver:
+		return "fusion5"
+	case *Workstation10Driver:
+		return "workstation10"
+	case *Workstation9Driver:
+		return "workstation9"
+	case *Player6Driver:
+		return "player6"
+	case *Player5Driver:
+		return "player5"
+	case *ESX5Driver:
+		return "esx5"
+	case *VSphereDriver:
+		return "vsphere"
+	}
+	return fmt.Sprintf("%T", driver)
+}
+
+// RunRetry controls how the local drivers retry their commands when they
+// fail with a transient error. NewDriver sets it from the driver config.
+type RunRetry struct {
+	// Retries is how many times a command is run again.
+	Retries int
+
+	// Delay is the time to wait before the first retry, which doubles
+	// with every retry after it.
+	Delay time.Duration
+}
+
+// transientErrorRe matches the errors of vmrun and vmware-vdiskmanager
+// that usually go away when the command is run again.
+var transientErrorRe = regexp.MustCompile(`(?i)(the operation was canceled|service is not running|unable to connect to host)`)
+
+// commandRunner runs the external commands of the drivers, such as vmrun
+// and vmware-vdiskmanager. Tests replace runner to check the commands and
+// fake their output without VMware installed.
+type commandRunner interface {
+	Run(cmd *exec.Cmd) error
+}
+
+type execRunner struct{}
+
+func (execRunner) Run(cmd *exec.Cmd) error {
+	return cmd.Run()
+}
+
+var runner commandRunner = execRunner{}
+
+// runAndLog runs the command, retrying it with a backoff when it fails with
+// a transient error. The command is only used as a template: every attempt
+// runs a copy created with exec.CommandContext, so that cancelling ctx kills
+// the process.
+//
+// Every attempt reads Stdin from the start, so it has to be an io.Seeker
+// when commands are retried.
+func (r RunRetry) runAndLog(ctx context.Context, cmd *exec.Cmd) (string, string, error) {
+	stdin, seekable := cmd.Stdin.(io.Seeker)
+	if cmd.Stdin != nil && !seekable && r.Retries > 0 {
+		return "", "", fmt.Errorf("the input of %s can't be read again to retry it", cmd.Path)
+	}
+
+	var offset int64
+	if seekable {
+		var err error
+		if offset, err = stdin.Seek(0, io.SeekCurrent); err != nil {
+			return "", "", err
+		}
+	}
+
+	delay := r.Delay
+	for try := 0; ; try++ {
+		if seekable && try > 0 {
+			if _, err := stdin.Seek(offset, io.SeekStart); err != nil {
+				return "", "", err
+			}
+		}
+
+		run := exec.CommandContext(ctx, cmd.Path, cmd.Args[1:]...)
+		run.Env = cmd.Env
+		run.Dir = cmd.Dir
+		run.Stdin = cmd.Stdin
+		run.ExtraFiles = cmd.ExtraFiles
+
+		stdout, stderr, err := runAndLogOnce(run)
+		if err == nil || try >= r.Retries || !transientErrorRe.MatchString(err.Error()) {
+			return stdout, stderr, err
+		}
+
+		log.Printf("Transient VMware error, retrying in %s (%d/%d): %s",
+			delay, try+1, r.Retries, err)
+		select {
+		case <-ctx.Done():
+			return stdout, stderr, err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// sensitiveFlags are the flags of vmrun that take a password.
+var sensitiveFlags = map[string]bool{
+	"-gp": true,
+	"-vp": true,
+}
+
+// redactArgs returns a copy of the arguments of a command with the values
+// of sensitive flags replaced, so that they can be logged.
+func redactArgs(args []string) []string {
+	redacted := make([]string, len(args))
+	copy(redacted, args)
+	for i := 1; i < len(redacted); i++ {
+		if sensitiveFlags[redacted[i-1]] {
+			redacted[i] = "<sensitive>"
+		}
+	}
+	return redacted
+}
+
+func runAndLogOnce(cmd *exec.Cmd) (string, string, error) {
 	var stdout, stderr bytes.Buffer
 
-	log.Printf("Executing: %s %s", cmd.Path, strings.Join(cmd.Args[1:], " "))
+	log.Printf("Executing: %s %s", cmd.Path, strings.Join(redactArgs(cmd.Args[1:]), " "))
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	err := cmd.Run()
+	err := runner.Run(cmd)
 
 	stdoutString := strings.TrimSpace(stdout.String())
 	stderrString := strings.TrimSpace(stderr.String())
@@ -374,59 +579,144 @@ func (d *VmwareDriver) GuestIP(state multistep.StateBag) (string, error) {
 		}
 
 		// open up the lease and read its contents
-		fh, err := os.Open(dhcpLeasesPath)
+		dhcpBytes, err := ioutil.ReadFile(dhcpLeasesPath)
 		if err != nil {
 			log.Printf("Error while reading DHCP lease path file %s: %s", dhcpLeasesPath, err.Error())
 			continue
 		}
-		defer fh.Close()
 
-		dhcpBytes, err := ioutil.ReadAll(fh)
-		if err != nil {
-			return "", err
+		curIp := findLeaseIP(string(dhcpBytes), MACAddress)
+		if curIp != "" {
+			return curIp, nil
 		}
+	}
 
-		// start grepping through the file looking for fields that we care about
-		var lastIp string
-		var lastLeaseEnd time.Time
+	return "", fmt.Errorf("None of the found device(s) %v has a DHCP lease for MAC %s", devices, MACAddress)
+}
 
-		var curIp string
-		var curLeaseEnd time.Time
+// vdiskManagerFailureRe matches the line vmware-vdiskmanager prints when it
+// fails, such as "Failed to create disk: The file already exists (0x...)".
+var vdiskManagerFailureRe = regexp.MustCompile(`(?m)^Failed to .*$`)
 
-		ipLineRe := regexp.MustCompile(`^lease (.+?) {$`)
-		endTimeLineRe := regexp.MustCompile(`^\s*ends \d (.+?);$`)
-		macLineRe := regexp.MustCompile(`^\s*hardware ethernet (.+?);$`)
+// runVdiskManager runs vmware-vdiskmanager. Its output is mostly progress
+// reports, so only the line describing the failure is kept in the error.
+func (r RunRetry) runVdiskManager(ctx context.Context, cmd *exec.Cmd) error {
+	stdout, _, err := r.runAndLog(ctx, cmd)
+	if err != nil {
+		if failure := vdiskManagerFailureRe.FindString(stdout); failure != "" {
+			return fmt.Errorf("VMware error: %s", strings.TrimSpace(failure))
+		}
+		return err
+	}
+	return nil
+}
 
-		for _, line := range strings.Split(string(dhcpBytes), "\n") {
-			// Need to trim off CR character when running in windows
-			line = strings.TrimRight(line, "\r")
+// vdiskAdapterType maps a disk_adapter_type to the adapter type accepted by
+// vmware-vdiskmanager. The bus of the disk is set in the VMX, so SATA, NVMe
+// and the other SCSI controllers use lsilogic disks.
+func vdiskAdapterType(adapterType string) string {
+	switch strings.ToLower(adapterType) {
+	case "ide", "buslogic":
+		return strings.ToLower(adapterType)
+	}
+	return "lsilogic"
+}
 
-			matches := ipLineRe.FindStringSubmatch(line)
-			if matches != nil {
-				lastIp = matches[1]
-				continue
-			}
+// vmrunGuestIPTimeout is how long to wait for VMware Tools to report the IP
+// address of the guest.
+const vmrunGuestIPTimeout = 10 * time.Second
 
-			matches = endTimeLineRe.FindStringSubmatch(line)
-			if matches != nil {
-				lastLeaseEnd, _ = time.Parse("2006/01/02 15:04:05", matches[1])
-				continue
-			}
+// vmrunGuestIP asks VMware Tools in the guest for its IP address using
+// `vmrun getGuestIPAddress`. This fails if the tools aren't running.
+func vmrunGuestIP(vd vmrunDriver, vmxPath string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), vmrunGuestIPTimeout)
+	defer cancel()
 
-			// If the mac address matches and this lease ends farther in the
-			// future than the last match we might have, then choose it.
-			matches = macLineRe.FindStringSubmatch(line)
-			if matches != nil && strings.EqualFold(matches[1], MACAddress) && curLeaseEnd.Before(lastLeaseEnd) {
-				curIp = lastIp
-				curLeaseEnd = lastLeaseEnd
-			}
+	vmrunPath, hostType := vd.vmrun()
+	cmd := exec.Command(vmrunPath, "-T", hostType, "getGuestIPAddress", vmxPath, "-wait")
+	stdout, _, err := vd.runAndLog(ctx, cmd)
+	if err != nil {
+		return "", err
+	}
+	return parseGuestIPAddress(stdout)
+}
+
+// parseGuestIPAddress validates the output of `vmrun getGuestIPAddress`.
+func parseGuestIPAddress(output string) (string, error) {
+	ip := strings.TrimSpace(output)
+	if net.ParseIP(ip) == nil {
+		return "", fmt.Errorf("vmrun returned an invalid IP address: %s", ip)
+	}
+	return ip, nil
+}
+
+// guestIPWithTools looks up the IP address of the guest through VMware Tools,
+// falling back to the DHCP leases of the host if the tools are absent.
+func guestIPWithTools(d *VmwareDriver, vd vmrunDriver, state multistep.StateBag) (string, error) {
+	if vmxPath, ok := state.GetOk("vmx_path"); ok {
+		ip, err := vmrunGuestIP(vd, vmxPath.(string))
+		if err == nil {
+			log.Printf("GuestIP found using vmrun getGuestIPAddress: %s", ip)
+			return ip, nil
 		}
-		if curIp != "" {
-			return curIp, nil
+		log.Printf("GuestIP lookup through VMware Tools failed, falling back to DHCP leases: %s", err)
+	}
+
+	ip, err := d.GuestIP(state)
+	if err == nil {
+		log.Printf("GuestIP found using DHCP leases: %s", ip)
+	}
+	return ip, err
+}
+
+// findLeaseIP returns the IP address of the lease for the given MAC address
+// in the contents of a vmnet DHCP leases file. If there are several, the one
+// that ends the farthest in the future is returned.
+func findLeaseIP(leases string, mac string) string {
+	var lastIp string
+	var lastLeaseEnd time.Time
+
+	var curIp string
+	var curLeaseEnd time.Time
+
+	ipLineRe := regexp.MustCompile(`^lease (.+?) {$`)
+	endTimeLineRe := regexp.MustCompile(`^\s*ends \d (.+?);$`)
+	endNeverLineRe := regexp.MustCompile(`^\s*ends never;$`)
+	macLineRe := regexp.MustCompile(`^\s*hardware ethernet (.+?);$`)
+
+	for _, line := range strings.Split(leases, "\n") {
+		// Need to trim off CR character when running in windows
+		line = strings.TrimRight(line, "\r")
+
+		matches := ipLineRe.FindStringSubmatch(line)
+		if matches != nil {
+			lastIp = matches[1]
+			lastLeaseEnd = time.Time{}
+			continue
+		}
+
+		matches = endTimeLineRe.FindStringSubmatch(line)
+		if matches != nil {
+			lastLeaseEnd, _ = time.Parse("2006/01/02 15:04:05", matches[1])
+			continue
+		}
+
+		// Leases that never expire beat any other lease.
+		if endNeverLineRe.MatchString(line) {
+			lastLeaseEnd = time.Unix(1<<62, 0)
+			continue
+		}
+
+		// If the mac address matches and this lease ends farther in the
+		// future than the last match we might have, then choose it.
+		matches = macLineRe.FindStringSubmatch(line)
+		if matches != nil && strings.EqualFold(matches[1], mac) && curLeaseEnd.Before(lastLeaseEnd) {
+			curIp = lastIp
+			curLeaseEnd = lastLeaseEnd
 		}
 	}
 
-	return "", fmt.Errorf("None of the found device(s) %v has a DHCP lease for MAC %s", devices, MACAddress)
+	return curIp
 }
 
 func (d *VmwareDriver) HostAddress(state multistep.StateBag) (string, error) {
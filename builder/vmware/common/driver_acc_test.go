@@ -0,0 +1,142 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/template/interpolate"
+)
+
+// accDriverNames are the drivers that can be tested on each OS. The ones
+// that aren't installed are skipped.
+var accDriverNames = map[string][]string{
+	"darwin":  {"fusion6", "fusion5"},
+	"linux":   {"workstation10", "workstation9", "player6", "player5"},
+	"windows": {"workstation10", "workstation9", "player6", "player5"},
+}
+
+func TestDriver_acc(t *testing.T) {
+	if os.Getenv("PACKER_ACC") == "" {
+		t.Skip("This test is only run with PACKER_ACC=1 due to the requirement of access to the VMware binaries.")
+	}
+
+	for _, name := range accDriverNames[runtime.GOOS] {
+		t.Run(name, func(t *testing.T) {
+			config := &DriverConfig{Driver: name}
+			if errs := config.Prepare(&interpolate.Context{}); len(errs) > 0 {
+				t.Fatalf("bad: %#v", errs)
+			}
+			driver, err := NewDriver(config, &SSHConfig{}, "packer-acc")
+			if err != nil {
+				t.Skipf("driver %s is not available: %s", name, err)
+			}
+			testDriverContract(t, driver)
+		})
+	}
+}
+
+// testDriverContract boots a VM with a small disk and ISO, and checks that
+// the driver creates the disk and starts and stops the VM.
+func testDriverContract(t *testing.T, driver Driver) {
+	ctx := context.Background()
+
+	td, err := ioutil.TempDir("", "packer-acc")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	diskPath := filepath.Join(td, "disk.vmdk")
+	if err := driver.CreateDisk(ctx, diskPath, "64M", "lsilogic", "1"); err != nil {
+		t.Fatalf("CreateDisk: %s", err)
+	}
+	if _, err := os.Stat(diskPath); err != nil {
+		t.Fatalf("CreateDisk didn't create the disk: %s", err)
+	}
+
+	vmxPath := filepath.Join(td, "packer-acc.vmx")
+	vmxData := map[string]string{
+		".encoding":         "UTF-8",
+		"config.version":    "8",
+		"virtualhw.version": "9",
+		"displayname":       "packer-acc",
+		"guestos":           "other",
+		"memsize":           "64",
+		"msg.autoanswer":    "TRUE",
+		"scsi0.present":     "TRUE",
+		"scsi0.virtualdev":  "lsilogic",
+		"scsi0:0.present":   "TRUE",
+		"scsi0:0.filename":  "disk.vmdk",
+		"ide1:0.present":    "TRUE",
+		"ide1:0.devicetype": "cdrom-image",
+		"ide1:0.filename":   testAccISO(t, td),
+		"ethernet0.present": "FALSE",
+		"floppy0.present":   "FALSE",
+		"sound.present":     "FALSE",
+		"usb.present":       "FALSE",
+	}
+	if err := WriteVMX(vmxPath, vmxData); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if err := driver.Start(ctx, vmxPath, true); err != nil {
+		t.Fatalf("Start: %s", err)
+	}
+	defer driver.Stop(ctx, vmxPath)
+
+	if running, err := driver.IsRunning(ctx, vmxPath); err != nil || !running {
+		t.Fatalf("IsRunning should be true after Start: %t %v", running, err)
+	}
+
+	if err := driver.Stop(ctx, vmxPath); err != nil {
+		t.Fatalf("Stop: %s", err)
+	}
+
+	// vmrun can return before the VM has exited
+	for start := time.Now(); time.Since(start) < time.Minute; time.Sleep(time.Second) {
+		running, err := driver.IsRunning(ctx, vmxPath)
+		if err != nil {
+			t.Fatalf("IsRunning: %s", err)
+		}
+		if !running {
+			return
+		}
+	}
+	t.Fatal("IsRunning should be false after Stop")
+}
+
+// testAccISO creates a small ISO to boot the VM from, skipping the test
+// when no tool to create it is installed.
+func testAccISO(t *testing.T, dir string) string {
+	state := new(multistep.BasicStateBag)
+	state.Put("ui", &packer.BasicUi{
+		Reader: new(bytes.Buffer),
+		Writer: new(bytes.Buffer),
+	})
+
+	step := &common.StepCreateCD{
+		Content: map[string]string{"README": "packer acceptance test"},
+		Label:   "packer-acc",
+	}
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Skipf("can't create an ISO: %s", state.Get("error"))
+	}
+
+	// Keep the ISO with the VM, the step deletes its own copy
+	isoPath := filepath.Join(dir, "packer-acc.iso")
+	err := copyFile(isoPath, state.Get("cd_path").(string))
+	step.Cleanup(state)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	return isoPath
+}
@@ -14,17 +14,37 @@ import (
 )
 
 type DriverConfig struct {
+	Driver                  string `mapstructure:"driver"`
 	FusionAppPath           string `mapstructure:"fusion_app_path"`
 	RemoteType              string `mapstructure:"remote_type"`
+	RemoteAPI               string `mapstructure:"remote_api"`
 	RemoteDatastore         string `mapstructure:"remote_datastore"`
 	RemoteCacheDatastore    string `mapstructure:"remote_cache_datastore"`
 	RemoteCacheDirectory    string `mapstructure:"remote_cache_directory"`
+	RemoteDatacenter        string `mapstructure:"remote_datacenter"`
+	RemoteCluster           string `mapstructure:"remote_cluster"`
+	RemoteResourcePool      string `mapstructure:"remote_resource_pool"`
+	RemoteFolder            string `mapstructure:"remote_folder"`
 	RemoteHost              string `mapstructure:"remote_host"`
 	RemotePort              int    `mapstructure:"remote_port"`
 	RemoteUser              string `mapstructure:"remote_username"`
 	RemotePassword          string `mapstructure:"remote_password"`
 	RemotePrivateKey        string `mapstructure:"remote_private_key_file"`
 	SkipValidateCredentials bool   `mapstructure:"skip_validate_credentials"`
+	InsecureConnection      bool   `mapstructure:"insecure_connection"`
+
+	// Override the paths of the VMware tools for installs that aren't in
+	// the standard locations.
+	VMRunPath        string `mapstructure:"vmrun_path"`
+	VDiskManagerPath string `mapstructure:"vdiskmanager_path"`
+	OVFToolPath      string `mapstructure:"ovftool_path"`
+
+	// Transient VMware errors, such as the authorization service not
+	// running, are retried this many times, doubling the delay each time.
+	VMRunRetries       int    `mapstructure:"vmrun_retries"`
+	RawVMRunRetryDelay string `mapstructure:"vmrun_retry_delay"`
+
+	VMRunRetryDelay time.Duration ``
 }
 
 func (c *DriverConfig) Prepare(ctx *interpolate.Context) []error {
@@ -33,6 +53,14 @@ func (c *DriverConfig) Prepare(ctx *interpolate.Context) []error {
 	}
 	if c.FusionAppPath == "" {
 		c.FusionAppPath = "/Applications/VMware Fusion.app"
+
+		// Only the Tech Preview may be installed, in which case use it.
+		techPreview := "/Applications/VMware Fusion Tech Preview.app"
+		if _, err := os.Stat(c.FusionAppPath); os.IsNotExist(err) {
+			if _, err := os.Stat(techPreview); err == nil {
+				c.FusionAppPath = techPreview
+			}
+		}
 	}
 	if c.RemoteUser == "" {
 		c.RemoteUser = "root"
@@ -46,11 +74,66 @@ func (c *DriverConfig) Prepare(ctx *interpolate.Context) []error {
 	if c.RemoteCacheDirectory == "" {
 		c.RemoteCacheDirectory = "packer_cache"
 	}
+	if c.RemoteAPI == "" {
+		c.RemoteAPI = "ssh"
+	}
 	if c.RemotePort == 0 {
 		c.RemotePort = 22
+		if c.RemoteAPI == "vsphere" {
+			c.RemotePort = 443
+		}
 	}
 
-	return nil
+	var errs []error
+	if c.VMRunRetries == 0 {
+		c.VMRunRetries = 2
+	}
+	if c.VMRunRetries < 0 {
+		errs = append(errs, fmt.Errorf("vmrun_retries must not be negative"))
+	}
+	if c.RawVMRunRetryDelay == "" {
+		c.RawVMRunRetryDelay = "2s"
+	}
+	var err error
+	c.VMRunRetryDelay, err = time.ParseDuration(c.RawVMRunRetryDelay)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("Failed parsing vmrun_retry_delay: %s", err))
+	}
+
+	if c.RemoteAPI != "ssh" && c.RemoteAPI != "vsphere" {
+		errs = append(errs, fmt.Errorf("remote_api must be one of ssh or vsphere, got %s", c.RemoteAPI))
+	}
+	if c.RemoteAPI != "vsphere" && (c.RemoteDatacenter != "" || c.RemoteCluster != "" ||
+		c.RemoteResourcePool != "" || c.RemoteFolder != "") {
+		errs = append(errs, fmt.Errorf("remote_datacenter, remote_cluster, remote_resource_pool "+
+			"and remote_folder can only be used with remote_api = \"vsphere\""))
+	}
+	if c.RemoteAPI != "vsphere" && c.InsecureConnection {
+		errs = append(errs, fmt.Errorf("insecure_connection can only be used with remote_api = \"vsphere\""))
+	}
+	if c.RemoteCluster != "" && c.RemoteResourcePool != "" {
+		errs = append(errs, fmt.Errorf("remote_cluster can't be used together with remote_resource_pool"))
+	}
+
+	switch c.Driver {
+	case "", "fusion5", "fusion6", "workstation9", "workstation10", "player5", "player6":
+	default:
+		errs = append(errs, fmt.Errorf("driver must be one of fusion5, fusion6, "+
+			"workstation9, workstation10, player5 or player6, got %s", c.Driver))
+	}
+	if c.Driver != "" && c.RemoteType != "" {
+		errs = append(errs, fmt.Errorf("driver can't be used together with remote_type"))
+	}
+	if (c.VMRunPath != "" || c.VDiskManagerPath != "") && c.RemoteType != "" {
+		errs = append(errs, fmt.Errorf("vmrun_path and vdiskmanager_path can't be used together with remote_type"))
+	}
+	if c.OVFToolPath != "" {
+		if _, err := os.Stat(c.OVFToolPath); err != nil {
+			errs = append(errs, fmt.Errorf("ovftool_path is invalid: %s", err))
+		}
+	}
+
+	return errs
 }
 
 func (c *DriverConfig) Validate(SkipExport bool) error {
@@ -68,7 +151,7 @@ func (c *DriverConfig) Validate(SkipExport bool) error {
 	// check that password is valid by sending a dummy ovftool command
 	// now, so that we don't fail for a simple mistake after a long
 	// build
-	ovftool := GetOVFTool()
+	ovftool := c.ovfTool()
 	ovfToolArgs := []string{"--noSSLVerify", "--verifyOnly", fmt.Sprintf("vi://%s:%s@%s",
 		url.QueryEscape(c.RemoteUser),
 		url.QueryEscape(c.RemotePassword),
@@ -104,3 +187,11 @@ func (c *DriverConfig) Validate(SkipExport bool) error {
 
 	return nil
 }
+
+// ovfTool returns the path of ovftool, preferring ovftool_path when set.
+func (c *DriverConfig) ovfTool() string {
+	if c.OVFToolPath != "" {
+		return c.OVFToolPath
+	}
+	return GetOVFTool()
+}
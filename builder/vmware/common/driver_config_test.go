@@ -2,6 +2,7 @@ package common
 
 import (
 	"testing"
+	"time"
 )
 
 func TestDriverConfigPrepare(t *testing.T) {
@@ -28,3 +29,168 @@ func TestDriverConfigPrepare(t *testing.T) {
 		t.Fatalf("bad value: %s", c.FusionAppPath)
 	}
 }
+
+func TestDriverConfigPrepare_RemoteAPI(t *testing.T) {
+	var c *DriverConfig
+
+	// Test the default
+	c = new(DriverConfig)
+	errs := c.Prepare(testConfigTemplate(t))
+	if len(errs) > 0 {
+		t.Fatalf("bad: %#v", errs)
+	}
+	if c.RemoteAPI != "ssh" {
+		t.Fatalf("bad value: %s", c.RemoteAPI)
+	}
+	if c.RemotePort != 22 {
+		t.Fatalf("bad value: %d", c.RemotePort)
+	}
+
+	// Test the vSphere API
+	c = new(DriverConfig)
+	c.RemoteAPI = "vsphere"
+	errs = c.Prepare(testConfigTemplate(t))
+	if len(errs) > 0 {
+		t.Fatalf("bad: %#v", errs)
+	}
+	if c.RemotePort != 443 {
+		t.Fatalf("bad value: %d", c.RemotePort)
+	}
+
+	// Test with a bad one
+	c = new(DriverConfig)
+	c.RemoteAPI = "telnet"
+	errs = c.Prepare(testConfigTemplate(t))
+	if len(errs) == 0 {
+		t.Fatal("should have error")
+	}
+}
+
+func TestDriverConfigPrepare_RemotePlacement(t *testing.T) {
+	good := []DriverConfig{
+		{RemoteAPI: "vsphere", RemoteDatacenter: "dc1", RemoteCluster: "cluster1", RemoteFolder: "packer"},
+		{RemoteAPI: "vsphere", RemoteResourcePool: "cluster1/Resources/packer"},
+	}
+	for _, c := range good {
+		if errs := c.Prepare(testConfigTemplate(t)); len(errs) > 0 {
+			t.Fatalf("bad: %#v", errs)
+		}
+	}
+
+	bad := []DriverConfig{
+		{RemoteCluster: "cluster1"},
+		{RemoteAPI: "ssh", RemoteFolder: "packer"},
+		{RemoteAPI: "vsphere", RemoteCluster: "cluster1", RemoteResourcePool: "packer"},
+	}
+	for _, c := range bad {
+		if errs := c.Prepare(testConfigTemplate(t)); len(errs) == 0 {
+			t.Fatalf("should have error: %#v", c)
+		}
+	}
+}
+
+func TestDriverConfigPrepare_InsecureConnection(t *testing.T) {
+	c := DriverConfig{RemoteAPI: "vsphere", InsecureConnection: true}
+	if errs := c.Prepare(testConfigTemplate(t)); len(errs) > 0 {
+		t.Fatalf("bad: %#v", errs)
+	}
+
+	c = DriverConfig{RemoteAPI: "ssh", InsecureConnection: true}
+	if errs := c.Prepare(testConfigTemplate(t)); len(errs) == 0 {
+		t.Fatalf("should have error: %#v", c)
+	}
+}
+
+func TestDriverConfigPrepare_Driver(t *testing.T) {
+	var c *DriverConfig
+
+	// Test with a good one
+	c = new(DriverConfig)
+	c.Driver = "workstation10"
+	errs := c.Prepare(testConfigTemplate(t))
+	if len(errs) > 0 {
+		t.Fatalf("bad: %#v", errs)
+	}
+
+	// Test with a bad one
+	c = new(DriverConfig)
+	c.Driver = "workstation1"
+	errs = c.Prepare(testConfigTemplate(t))
+	if len(errs) == 0 {
+		t.Fatal("should have error")
+	}
+
+	// Test together with a remote type
+	c = new(DriverConfig)
+	c.Driver = "player6"
+	c.RemoteType = "esx5"
+	errs = c.Prepare(testConfigTemplate(t))
+	if len(errs) == 0 {
+		t.Fatal("should have error")
+	}
+}
+
+func TestDriverConfigPrepare_VMRunRetries(t *testing.T) {
+	var c *DriverConfig
+
+	// Test the defaults
+	c = new(DriverConfig)
+	errs := c.Prepare(testConfigTemplate(t))
+	if len(errs) > 0 {
+		t.Fatalf("bad: %#v", errs)
+	}
+	if c.VMRunRetries != 2 {
+		t.Fatalf("bad value: %d", c.VMRunRetries)
+	}
+	if c.VMRunRetryDelay != 2*time.Second {
+		t.Fatalf("bad value: %s", c.VMRunRetryDelay)
+	}
+
+	// Test a bad retry count
+	c = new(DriverConfig)
+	c.VMRunRetries = -1
+	errs = c.Prepare(testConfigTemplate(t))
+	if len(errs) == 0 {
+		t.Fatal("should have error")
+	}
+
+	// Test a bad delay
+	c = new(DriverConfig)
+	c.RawVMRunRetryDelay = "bad"
+	errs = c.Prepare(testConfigTemplate(t))
+	if len(errs) == 0 {
+		t.Fatal("should have error")
+	}
+}
+
+func TestDriverConfigPrepare_ToolPaths(t *testing.T) {
+	var c *DriverConfig
+
+	// Test a missing ovftool
+	c = new(DriverConfig)
+	c.OVFToolPath = "/i/dont/exist/ovftool"
+	errs := c.Prepare(testConfigTemplate(t))
+	if len(errs) == 0 {
+		t.Fatal("should have error")
+	}
+
+	// Test an existing ovftool
+	c = new(DriverConfig)
+	c.OVFToolPath = "driver_config_test.go"
+	errs = c.Prepare(testConfigTemplate(t))
+	if len(errs) > 0 {
+		t.Fatalf("bad: %#v", errs)
+	}
+	if c.ovfTool() != "driver_config_test.go" {
+		t.Fatalf("bad value: %s", c.ovfTool())
+	}
+
+	// Test vmrun_path with remote_type
+	c = new(DriverConfig)
+	c.VMRunPath = "/usr/local/bin/vmrun"
+	c.RemoteType = "esx5"
+	errs = c.Prepare(testConfigTemplate(t))
+	if len(errs) == 0 {
+		t.Fatal("should have error")
+	}
+}
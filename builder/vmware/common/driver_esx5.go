@@ -17,6 +17,7 @@ import (
 	"strings"
 	"time"
 
+	packernet "github.com/hashicorp/packer/common/net"
 	"github.com/hashicorp/packer/communicator/ssh"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/multistep"
@@ -41,12 +42,17 @@ type ESX5Driver struct {
 	VMName         string
 	CommConfig     communicator.Config
 
-	comm      packer.Communicator
-	outputDir string
-	vmId      string
+	comm        packer.Communicator
+	outputDir   string
+	vmId        string
+	vncPortLock *packernet.PortLock
 }
 
-func (d *ESX5Driver) Clone(dst, src string, linked bool) error {
+func (d *ESX5Driver) Clone(ctx context.Context, dst, src string, linked bool) error {
+	if linked {
+		return errors.New("Linked clones are not supported with ESXi, " +
+			"vmkfstools can only create full copies of the source disks.")
+	}
 
 	linesToArray := func(lines string) []string { return strings.Split(strings.Trim(lines, "\r\n"), "\n") }
 
@@ -104,17 +110,38 @@ func (d *ESX5Driver) Clone(dst, src string, linked bool) error {
 	return nil
 }
 
-func (d *ESX5Driver) CompactDisk(diskPathLocal string) error {
+func (d *ESX5Driver) CompactDisk(ctx context.Context, diskPathLocal string) error {
 	diskPath := d.datastorePath(diskPathLocal)
 	return d.sh("vmkfstools", "--punchzero", strconv.Quote(diskPath))
 }
 
-func (d *ESX5Driver) CreateDisk(diskPathLocal string, size string, adapter_type string, typeId string) error {
+func (d *ESX5Driver) CopyDisk(ctx context.Context, diskPathLocal string, source string, typeId string) error {
+	return errors.New("Copying a local disk is not supported with ESXi")
+}
+
+func (d *ESX5Driver) ExpandDisk(ctx context.Context, diskPathLocal string, size string) error {
+	diskPath := strconv.Quote(d.datastorePath(diskPathLocal))
+	return d.sh("vmkfstools", "-X", size, diskPath)
+}
+
+func (d *ESX5Driver) CreateDisk(ctx context.Context, diskPathLocal string, size string, adapter_type string, typeId string) error {
 	diskPath := strconv.Quote(d.datastorePath(diskPathLocal))
-	return d.sh("vmkfstools", "-c", size, "-d", typeId, "-a", adapter_type, diskPath)
+	return d.sh("vmkfstools", "-c", size, "-d", typeId, "-a", vmkfstoolsAdapterType(adapter_type), diskPath)
+}
+
+// vmkfstoolsAdapterType maps a disk_adapter_type to the adapter type accepted
+// by vmkfstools.
+func vmkfstoolsAdapterType(adapterType string) string {
+	switch strings.ToLower(adapterType) {
+	case "ide", "buslogic", "pvscsi":
+		return strings.ToLower(adapterType)
+	case "lsisas1068":
+		return "lsisas"
+	}
+	return "lsilogic"
 }
 
-func (d *ESX5Driver) IsRunning(string) (bool, error) {
+func (d *ESX5Driver) IsRunning(ctx context.Context, _ string) (bool, error) {
 	state, err := d.run(nil, "vim-cmd", "vmsvc/power.getstate", d.vmId)
 	if err != nil {
 		return false, err
@@ -130,12 +157,16 @@ func (d *ESX5Driver) ReloadVM() error {
 	}
 }
 
-func (d *ESX5Driver) Start(vmxPathLocal string, headless bool) error {
+func (d *ESX5Driver) Start(ctx context.Context, vmxPathLocal string, headless bool) error {
 	for i := 0; i < 20; i++ {
 		//intentionally not checking for error since poweron may fail specially after initial VM registration
 		d.sh("vim-cmd", "vmsvc/power.on", d.vmId)
-		time.Sleep((time.Duration(i) * time.Second) + 1)
-		running, err := d.IsRunning(vmxPathLocal)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After((time.Duration(i) * time.Second) + 1):
+		}
+		running, err := d.IsRunning(ctx, vmxPathLocal)
 		if err != nil {
 			return err
 		}
@@ -146,10 +177,34 @@ func (d *ESX5Driver) Start(vmxPathLocal string, headless bool) error {
 	return errors.New("Retry limit exceeded")
 }
 
-func (d *ESX5Driver) Stop(vmxPathLocal string) error {
+func (d *ESX5Driver) Stop(ctx context.Context, vmxPathLocal string) error {
 	return d.sh("vim-cmd", "vmsvc/power.off", d.vmId)
 }
 
+func (d *ESX5Driver) Suspend(ctx context.Context, vmxPathLocal string) error {
+	return d.sh("vim-cmd", "vmsvc/power.suspend", d.vmId)
+}
+
+func (d *ESX5Driver) CreateSnapshot(ctx context.Context, vmxPathLocal string, name string) error {
+	return d.sh("vim-cmd", "vmsvc/snapshot.create", d.vmId, strconv.Quote(name))
+}
+
+func (d *ESX5Driver) DeleteSnapshot(ctx context.Context, vmxPathLocal string, name string) error {
+	id, err := d.snapshotId(name)
+	if err != nil {
+		return err
+	}
+	return d.sh("vim-cmd", "vmsvc/snapshot.remove", d.vmId, id)
+}
+
+func (d *ESX5Driver) RevertToSnapshot(ctx context.Context, vmxPathLocal string, name string) error {
+	id, err := d.snapshotId(name)
+	if err != nil {
+		return err
+	}
+	return d.sh("vim-cmd", "vmsvc/snapshot.revert", d.vmId, id, "1")
+}
+
 func (d *ESX5Driver) Register(vmxPathLocal string) error {
 	vmxPath := filepath.ToSlash(filepath.Join(d.outputDir, filepath.Base(vmxPathLocal)))
 	if err := d.upload(vmxPath, vmxPathLocal); err != nil {
@@ -171,6 +226,26 @@ func (d *ESX5Driver) Unregister(vmxPathLocal string) error {
 	return d.sh("vim-cmd", "vmsvc/unregister", d.vmId)
 }
 
+// ConvertToTemplate re-registers the VM from a .vmtx file, which is how
+// vSphere stores templates.
+func (d *ESX5Driver) ConvertToTemplate(vmxPathLocal string) error {
+	vmxPath := filepath.ToSlash(filepath.Join(d.outputDir, filepath.Base(vmxPathLocal)))
+	vmtxPath := strings.TrimSuffix(vmxPath, filepath.Ext(vmxPath)) + ".vmtx"
+
+	if err := d.sh("vim-cmd", "vmsvc/unregister", d.vmId); err != nil {
+		return err
+	}
+	if err := d.sh("mv", strconv.Quote(vmxPath), strconv.Quote(vmtxPath)); err != nil {
+		return err
+	}
+	r, err := d.run(nil, "vim-cmd", "solo/registervm", strconv.Quote(vmtxPath))
+	if err != nil {
+		return err
+	}
+	d.vmId = strings.TrimRight(r, "\n")
+	return nil
+}
+
 func (d *ESX5Driver) Destroy() error {
 	return d.sh("vim-cmd", "vmsvc/destroy", d.vmId)
 }
@@ -399,6 +474,15 @@ func (d *ESX5Driver) VNCAddress(ctx context.Context, _ string, portMin, portMax
 			log.Printf("Port %d in use", port)
 			continue
 		}
+
+		// Other builds on this host may be probing the same ESXi host, so
+		// hold a lock on the port until the VNC configuration is cleaned up.
+		lock, err := packernet.LockPort(d.Host, port)
+		if err != nil {
+			log.Printf("Port %d is locked by another build: %s", port, err)
+			continue
+		}
+
 		address := fmt.Sprintf("%s:%d", d.Host, port)
 		log.Printf("Trying address: %s...", address)
 		l, err := net.DialTimeout("tcp", address, vncTimeout)
@@ -409,12 +493,14 @@ func (d *ESX5Driver) VNCAddress(ctx context.Context, _ string, portMin, portMax
 					log.Printf("Timeout connecting to: %s (check firewall rules)", address)
 				} else {
 					vncPort = port
+					d.vncPortLock = lock
 					break
 				}
 			}
 		} else {
 			defer l.Close()
 		}
+		lock.Unlock()
 	}
 
 	if vncPort == 0 {
@@ -426,6 +512,17 @@ func (d *ESX5Driver) VNCAddress(ctx context.Context, _ string, portMin, portMax
 	return d.Host, vncPort, nil
 }
 
+// ReleaseVNCPort releases the lock on the VNC port found by VNCAddress.
+func (d *ESX5Driver) ReleaseVNCPort() {
+	if d.vncPortLock == nil {
+		return
+	}
+	if err := d.vncPortLock.Unlock(); err != nil {
+		log.Printf("failed to unlock VNC port lockfile: %v", err)
+	}
+	d.vncPortLock = nil
+}
+
 // UpdateVMX, adds the VNC port to the VMX data.
 func (ESX5Driver) UpdateVMX(_, password string, port int, data map[string]string) {
 	// Do not set remotedisplay.vnc.ip - this breaks ESXi.
@@ -648,6 +745,43 @@ func (d *ESX5Driver) checkGuestIPHackEnabled() error {
 	return nil
 }
 
+func (d *ESX5Driver) snapshotId(name string) (string, error) {
+	r, err := d.run(nil, "vim-cmd", "vmsvc/snapshot.get", d.vmId)
+	if err != nil {
+		return "", err
+	}
+
+	id := parseSnapshotId(r, name)
+	if id == "" {
+		return "", fmt.Errorf("Snapshot %s not found", name)
+	}
+	return id, nil
+}
+
+// parseSnapshotId finds the id of the snapshot with the given name in the
+// output of `vim-cmd vmsvc/snapshot.get`, which lists the name of each
+// snapshot followed by its id.
+func parseSnapshotId(output string, name string) string {
+	var current string
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key := strings.TrimLeft(strings.TrimSpace(parts[0]), "-|")
+		value := strings.TrimSpace(parts[1])
+		switch key {
+		case "Snapshot Name":
+			current = value
+		case "Snapshot Id":
+			if current == name {
+				return value
+			}
+		}
+	}
+	return ""
+}
+
 func (d *ESX5Driver) mkdir(path string) error {
 	return d.sh("mkdir", "-p", strconv.Quote(path))
 }
@@ -5,6 +5,7 @@ import (
 	"net"
 	"testing"
 
+	packernet "github.com/hashicorp/packer/common/net"
 	"github.com/hashicorp/packer/helper/communicator"
 	"github.com/hashicorp/packer/helper/config"
 	"github.com/hashicorp/packer/helper/multistep"
@@ -14,6 +15,31 @@ func TestESX5Driver_implDriver(t *testing.T) {
 	var _ Driver = new(ESX5Driver)
 }
 
+func TestESX5Driver_implVNCPortReleaser(t *testing.T) {
+	var _ VNCPortReleaser = new(ESX5Driver)
+}
+
+func TestESX5Driver_ReleaseVNCPort(t *testing.T) {
+	lock, err := packernet.LockPort("esxi.example.com", 5999)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	driver := ESX5Driver{Host: "esxi.example.com", vncPortLock: lock}
+	state := new(multistep.BasicStateBag)
+	state.Put("driver", &driver)
+	new(StepConfigureVNC).Cleanup(state)
+
+	lock, err = packernet.LockPort("esxi.example.com", 5999)
+	if err != nil {
+		t.Fatalf("port should be released: %s", err)
+	}
+	lock.Unlock()
+
+	// Releasing twice is fine
+	driver.ReleaseVNCPort()
+}
+
 func TestESX5Driver_UpdateVMX(t *testing.T) {
 	var driver ESX5Driver
 	data := make(map[string]string)
@@ -89,3 +115,46 @@ func TestESX5Driver_CommHost(t *testing.T) {
 		t.Errorf("bad vm_address: %s", address.(string))
 	}
 }
+
+func TestParseSnapshotId(t *testing.T) {
+	output := `Get Snapshot:
+|-ROOT
+--Snapshot Name        : base
+--Snapshot Id        : 1
+--Snapshot Desciption  :
+--Snapshot Created On  : 10/15/2018 10:00:00
+--Snapshot State       : powered on
+--|-CHILD
+----Snapshot Name        : installed
+----Snapshot Id        : 2
+----Snapshot Desciption  :
+----Snapshot Created On  : 10/15/2018 11:00:00
+----Snapshot State       : powered on
+`
+	if id := parseSnapshotId(output, "installed"); id != "2" {
+		t.Fatalf("bad id: %s", id)
+	}
+	if id := parseSnapshotId(output, "base"); id != "1" {
+		t.Fatalf("bad id: %s", id)
+	}
+	if id := parseSnapshotId(output, "missing"); id != "" {
+		t.Fatalf("bad id: %s", id)
+	}
+}
+
+func TestVmkfstoolsAdapterType(t *testing.T) {
+	cases := map[string]string{
+		"":           "lsilogic",
+		"scsi":       "lsilogic",
+		"sata":       "lsilogic",
+		"IDE":        "ide",
+		"pvscsi":     "pvscsi",
+		"lsisas1068": "lsisas",
+	}
+
+	for adapter, expected := range cases {
+		if actual := vmkfstoolsAdapterType(adapter); actual != expected {
+			t.Fatalf("bad adapter type for %q: %s", adapter, actual)
+		}
+	}
+}
@@ -1,7 +1,7 @@
 package common
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -16,85 +16,91 @@ import (
 // Fusion5Driver is a driver that can run VMware Fusion 5.
 type Fusion5Driver struct {
 	VmwareDriver
+	RunRetry
 
 	// This is the path to the "VMware Fusion.app"
 	AppPath string
 
+	// These override the paths of the tools inside AppPath
+	VdiskManagerPath string
+	VmrunPath        string
+
 	// SSHConfig are the SSH settings for the Fusion VM
 	SSHConfig *SSHConfig
 }
 
-func (d *Fusion5Driver) Clone(dst, src string, linked bool) error {
-	return errors.New("Cloning is not supported with Fusion 5. Please use Fusion 6+.")
+func (d *Fusion5Driver) Clone(ctx context.Context, dst, src string, linked bool) error {
+	return copyVM(dst, src, linked)
 }
 
-func (d *Fusion5Driver) CompactDisk(diskPath string) error {
+func (d *Fusion5Driver) CompactDisk(ctx context.Context, diskPath string) error {
 	defragCmd := exec.Command(d.vdiskManagerPath(), "-d", diskPath)
-	if _, _, err := runAndLog(defragCmd); err != nil {
+	if err := d.runVdiskManager(ctx, defragCmd); err != nil {
 		return err
 	}
 
 	shrinkCmd := exec.Command(d.vdiskManagerPath(), "-k", diskPath)
-	if _, _, err := runAndLog(shrinkCmd); err != nil {
+	if err := d.runVdiskManager(ctx, shrinkCmd); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (d *Fusion5Driver) CreateDisk(output string, size string, adapter_type string, type_id string) error {
-	cmd := exec.Command(d.vdiskManagerPath(), "-c", "-s", size, "-a", adapter_type, "-t", type_id, output)
-	if _, _, err := runAndLog(cmd); err != nil {
+func (d *Fusion5Driver) CreateDisk(ctx context.Context, output string, size string, adapter_type string, type_id string) error {
+	cmd := exec.Command(d.vdiskManagerPath(), "-c", "-s", size, "-a", vdiskAdapterType(adapter_type), "-t", type_id, output)
+	if err := d.runVdiskManager(ctx, cmd); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (d *Fusion5Driver) IsRunning(vmxPath string) (bool, error) {
-	vmxPath, err := filepath.Abs(vmxPath)
-	if err != nil {
-		return false, err
-	}
-
-	cmd := exec.Command(d.vmrunPath(), "-T", "fusion", "list")
-	stdout, _, err := runAndLog(cmd)
-	if err != nil {
-		return false, err
-	}
+func (d *Fusion5Driver) CopyDisk(ctx context.Context, output string, source string, type_id string) error {
+	cmd := exec.Command(d.vdiskManagerPath(), "-r", source, "-t", type_id, output)
+	return d.runVdiskManager(ctx, cmd)
+}
 
-	for _, line := range strings.Split(stdout, "\n") {
-		if line == vmxPath {
-			return true, nil
-		}
-	}
+func (d *Fusion5Driver) ExpandDisk(ctx context.Context, diskPath string, size string) error {
+	cmd := exec.Command(d.vdiskManagerPath(), "-x", size, diskPath)
+	return d.runVdiskManager(ctx, cmd)
+}
 
-	return false, nil
+func (d *Fusion5Driver) IsRunning(ctx context.Context, vmxPath string) (bool, error) {
+	return vmrunIsRunning(ctx, d, vmxPath)
 }
 
 func (d *Fusion5Driver) CommHost(state multistep.StateBag) (string, error) {
 	return CommHost(d.SSHConfig)(state)
 }
 
-func (d *Fusion5Driver) Start(vmxPath string, headless bool) error {
+func (d *Fusion5Driver) vmrun() (string, string) {
+	return d.vmrunPath(), "fusion"
+}
+
+func (d *Fusion5Driver) GuestIP(state multistep.StateBag) (string, error) {
+	return guestIPWithTools(&d.VmwareDriver, d, state)
+}
+
+func (d *Fusion5Driver) Start(ctx context.Context, vmxPath string, headless bool) error {
 	guiArgument := "gui"
 	if headless == true {
 		guiArgument = "nogui"
 	}
 
 	cmd := exec.Command(d.vmrunPath(), "-T", "fusion", "start", vmxPath, guiArgument)
-	if _, _, err := runAndLog(cmd); err != nil {
+	if _, _, err := d.runAndLog(ctx, cmd); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (d *Fusion5Driver) Stop(vmxPath string) error {
+func (d *Fusion5Driver) Stop(ctx context.Context, vmxPath string) error {
 	cmd := exec.Command(d.vmrunPath(), "-T", "fusion", "stop", vmxPath, "hard")
-	if _, _, err := runAndLog(cmd); err != nil {
+	if _, _, err := d.runAndLog(ctx, cmd); err != nil {
 		// Check if the VM is running. If its not, it was already stopped
-		running, rerr := d.IsRunning(vmxPath)
+		running, rerr := d.IsRunning(ctx, vmxPath)
 		if rerr == nil && !running {
 			return nil
 		}
@@ -105,6 +111,42 @@ func (d *Fusion5Driver) Stop(vmxPath string) error {
 	return nil
 }
 
+func (d *Fusion5Driver) CreateSnapshot(ctx context.Context, vmxPath string, name string) error {
+	cmd := exec.Command(d.vmrunPath(), "-T", "fusion", "snapshot", vmxPath, name)
+	if _, _, err := d.runAndLog(ctx, cmd); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (d *Fusion5Driver) DeleteSnapshot(ctx context.Context, vmxPath string, name string) error {
+	cmd := exec.Command(d.vmrunPath(), "-T", "fusion", "deleteSnapshot", vmxPath, name)
+	if _, _, err := d.runAndLog(ctx, cmd); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (d *Fusion5Driver) RevertToSnapshot(ctx context.Context, vmxPath string, name string) error {
+	cmd := exec.Command(d.vmrunPath(), "-T", "fusion", "revertToSnapshot", vmxPath, name)
+	if _, _, err := d.runAndLog(ctx, cmd); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (d *Fusion5Driver) Suspend(ctx context.Context, vmxPath string) error {
+	cmd := exec.Command(d.vmrunPath(), "-T", "fusion", "suspend", vmxPath)
+	if _, _, err := d.runAndLog(ctx, cmd); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (d *Fusion5Driver) SuppressMessages(vmxPath string) error {
 	dir := filepath.Dir(vmxPath)
 	base := filepath.Base(vmxPath)
@@ -173,10 +215,16 @@ func (d *Fusion5Driver) Verify() error {
 }
 
 func (d *Fusion5Driver) vdiskManagerPath() string {
+	if d.VdiskManagerPath != "" {
+		return d.VdiskManagerPath
+	}
 	return filepath.Join(d.AppPath, "Contents", "Library", "vmware-vdiskmanager")
 }
 
 func (d *Fusion5Driver) vmrunPath() string {
+	if d.VmrunPath != "" {
+		return d.VmrunPath
+	}
 	return filepath.Join(d.AppPath, "Contents", "Library", "vmrun")
 }
 
@@ -0,0 +1,25 @@
+package common
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestFusion5Driver_toolPaths(t *testing.T) {
+	d := &Fusion5Driver{AppPath: "/Applications/VMware Fusion.app"}
+	if d.vmrunPath() != filepath.Join(d.AppPath, "Contents", "Library", "vmrun") {
+		t.Fatalf("bad vmrun path: %s", d.vmrunPath())
+	}
+	if d.vdiskManagerPath() != filepath.Join(d.AppPath, "Contents", "Library", "vmware-vdiskmanager") {
+		t.Fatalf("bad vdiskmanager path: %s", d.vdiskManagerPath())
+	}
+
+	d.VmrunPath = "/usr/local/bin/vmrun"
+	d.VdiskManagerPath = "/usr/local/bin/vmware-vdiskmanager"
+	if d.vmrunPath() != d.VmrunPath {
+		t.Fatalf("bad vmrun path: %s", d.vmrunPath())
+	}
+	if d.vdiskManagerPath() != d.VdiskManagerPath {
+		t.Fatalf("bad vdiskmanager path: %s", d.vdiskManagerPath())
+	}
+}
@@ -2,7 +2,9 @@ package common
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
 	"os/exec"
@@ -13,12 +15,20 @@ import (
 
 const VMWARE_FUSION_VERSION = "6"
 
-// Fusion6Driver is a driver that can run VMware Fusion 6.
+// fusionTechPreview is the version reported by Fusion Tech Preview builds.
+const fusionTechPreview = "e.x.p"
+
+// Fusion6Driver is a driver that can run VMware Fusion 6 and later,
+// including the Tech Preview releases.
 type Fusion6Driver struct {
 	Fusion5Driver
+
+	// version is the major version detected by Verify, or "e.x.p" for
+	// the Tech Preview.
+	version string
 }
 
-func (d *Fusion6Driver) Clone(dst, src string, linked bool) error {
+func (d *Fusion6Driver) Clone(ctx context.Context, dst, src string, linked bool) error {
 
 	var cloneType string
 	if linked {
@@ -27,11 +37,26 @@ func (d *Fusion6Driver) Clone(dst, src string, linked bool) error {
 		cloneType = "full"
 	}
 
+	// Cloning through vmrun is only available in Fusion Professional, so
+	// check what this vmrun is capable of before trying. Without clone
+	// support, full clones fall back to copying the files of the source VM.
+	full, linkedOk, ok := d.cloneCapabilities()
+	if ok && !full && !linked {
+		log.Printf("vmrun can't clone, copying the source VM instead")
+		return copyVM(dst, src, linked)
+	}
+	if ok && linked && (!full || !linkedOk) {
+		return fmt.Errorf(
+			"%s clones are not supported with your version of Fusion (%s). Packer "+
+				"only supports them with Fusion %s Professional or above.",
+			strings.Title(cloneType), d.version, VMWARE_FUSION_VERSION)
+	}
+
 	cmd := exec.Command(d.vmrunPath(),
 		"-T", "fusion",
 		"clone", src, dst,
 		cloneType)
-	if _, _, err := runAndLog(cmd); err != nil {
+	if _, _, err := d.runAndLog(ctx, cmd); err != nil {
 		if strings.Contains(err.Error(), "parameters was invalid") {
 			return fmt.Errorf(
 				"Clone is not supported with your version of Fusion. Packer "+
@@ -66,22 +91,19 @@ func (d *Fusion6Driver) Verify() error {
 		return err
 	}
 
-	// Example: VMware Fusion e.x.p build-6048684 Release
-	techPreviewRe := regexp.MustCompile(`(?i)VMware [a-z0-9-]+ e\.x\.p `)
-	matches := techPreviewRe.FindStringSubmatch(stderr.String())
-	if matches != nil {
-		log.Printf("Detected VMware version: e.x.p (Tech Preview)")
-		return nil
-	}
-
-	// Example: VMware Fusion 7.1.3 build-3204469 Release
-	versionRe := regexp.MustCompile(`(?i)VMware [a-z0-9-]+ (\d+)\.`)
-	matches = versionRe.FindStringSubmatch(stderr.String())
-	if matches == nil {
-		return fmt.Errorf(
-			"Couldn't find VMware version in output: %s", stderr.String())
+	version, err := fusionVersion(stderr.String())
+	if err != nil {
+		// Fall back to the version the application bundle advertises.
+		plist, perr := ioutil.ReadFile(filepath.Join(d.AppPath, "Contents", "Info.plist"))
+		if perr != nil {
+			return err
+		}
+		if version = fusionPlistVersion(string(plist)); version == "" {
+			return err
+		}
 	}
-	log.Printf("Detected VMware version: %s", matches[1])
+	d.version = version
+	log.Printf("Detected VMware version: %s", version)
 
 	libpath := filepath.Join("/", "Library", "Preferences", "VMware Fusion")
 
@@ -111,9 +133,88 @@ func (d *Fusion6Driver) Verify() error {
 		return ReadNetworkingConfig(fd)
 	}
 
-	return compareVersions(matches[1], VMWARE_FUSION_VERSION, "Fusion Professional")
+	if version == fusionTechPreview {
+		return nil
+	}
+	return compareVersions(version, VMWARE_FUSION_VERSION, "Fusion Professional")
+}
+
+func (d *Fusion6Driver) productVersion() (string, string) {
+	return "Fusion", d.version
+}
+
+// cloneCapabilities reports whether the vmrun shipped with this Fusion can
+// create full and linked clones, as listed in its usage output. The last
+// value is false if the usage output couldn't be read.
+func (d *Fusion6Driver) cloneCapabilities() (bool, bool, bool) {
+	// vmrun exits with a non-zero status when printing its usage, so the
+	// error is expected here.
+	out, _ := exec.Command(d.vmrunPath()).Output()
+	if len(out) == 0 {
+		return false, false, false
+	}
+	full, linked := vmrunCloneSupport(string(out))
+	return full, linked, true
 }
 
 func (d *Fusion6Driver) GetVmwareDriver() VmwareDriver {
 	return d.Fusion5Driver.VmwareDriver
 }
+
+// fusionVersion returns the major version from the output of
+// `vmware-vmx -v`, or "e.x.p" for the Tech Preview.
+func fusionVersion(output string) (string, error) {
+	// Example: VMware Fusion e.x.p build-6048684 Release
+	techPreviewRe := regexp.MustCompile(`(?i)VMware [a-z0-9-]+ e\.x\.p `)
+	if techPreviewRe.MatchString(output) {
+		return fusionTechPreview, nil
+	}
+
+	// Example: VMware Fusion 7.1.3 build-3204469 Release
+	// Example: VMware Fusion Pro 12.0.0 build-16880131 Release
+	versionRe := regexp.MustCompile(`(?i)VMware [a-z0-9-]+ (?:Pro )?(\d+)\.`)
+	matches := versionRe.FindStringSubmatch(output)
+	if matches == nil {
+		return "", fmt.Errorf(
+			"Couldn't find VMware version in output: %s", output)
+	}
+	return matches[1], nil
+}
+
+// fusionPlistVersion returns the major version from the Info.plist of the
+// Fusion application bundle, or an empty string if it isn't found.
+func fusionPlistVersion(plist string) string {
+	re := regexp.MustCompile(`<key>CFBundleShortVersionString</key>\s*<string>(e\.x\.p|\d+)[^<]*</string>`)
+	matches := re.FindStringSubmatch(plist)
+	if matches == nil {
+		return ""
+	}
+	return matches[1]
+}
+
+// vmrunCloneSupport reports whether the given vmrun usage output lists the
+// clone command, and whether that command can create linked clones. The
+// arguments of a command are listed on the lines indented below it.
+func vmrunCloneSupport(usage string) (bool, bool) {
+	indent := func(line string) int {
+		return len(line) - len(strings.TrimLeft(line, " \t"))
+	}
+
+	lines := strings.Split(usage, "\n")
+	for i, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[0] != "clone" {
+			continue
+		}
+
+		args := line
+		for _, next := range lines[i+1:] {
+			if strings.TrimSpace(next) == "" || indent(next) <= indent(line) {
+				break
+			}
+			args += next
+		}
+		return true, strings.Contains(args, "linked")
+	}
+	return false, false
+}
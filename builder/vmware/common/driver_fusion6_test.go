@@ -0,0 +1,107 @@
+package common
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestFusionVersion(t *testing.T) {
+	cases := map[string]string{
+		"VMware Fusion 7.1.3 build-3204469 Release":       "7",
+		"VMware Fusion 13.5.0 build-22583790 Release":     "13",
+		"VMware Fusion e.x.p build-6048684 Release":       "e.x.p",
+		"VMware Fusion Pro 12.0.0 build-16880131 Release": "12",
+	}
+
+	for output, expected := range cases {
+		actual, err := fusionVersion(output)
+		if err != nil {
+			t.Fatalf("%s: err: %s", output, err)
+		}
+		if actual != expected {
+			t.Fatalf("%s: expected %s, got %s", output, expected, actual)
+		}
+	}
+
+	if _, err := fusionVersion("garbage"); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestFusionPlistVersion(t *testing.T) {
+	plist := `<dict>
+	<key>CFBundleShortVersionString</key>
+	<string>8.5.10</string>
+</dict>`
+	if v := fusionPlistVersion(plist); v != "8" {
+		t.Fatalf("bad version: %s", v)
+	}
+
+	if v := fusionPlistVersion("<dict></dict>"); v != "" {
+		t.Fatalf("bad version: %s", v)
+	}
+}
+
+func TestVmrunCloneSupport(t *testing.T) {
+	pro := `GUEST OS COMMANDS
+   clone                    Path to vmx file     Create a copy of the VM
+                            Path to destination vmx file
+                            full|linked
+`
+	full, linked := vmrunCloneSupport(pro)
+	if !full || !linked {
+		t.Fatalf("bad: %t %t", full, linked)
+	}
+
+	standard := `POWER COMMANDS
+   start                    Path to vmx file     Start a VM
+`
+	full, linked = vmrunCloneSupport(standard)
+	if full || linked {
+		t.Fatalf("bad: %t %t", full, linked)
+	}
+}
+
+func TestFusion6DriverClone_withoutCloneSupport(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("vmrun is faked with a shell script")
+	}
+
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	// A vmrun whose usage doesn't list the clone command
+	vmrun := filepath.Join(td, "vmrun")
+	script := "#!/bin/sh\necho 'POWER COMMANDS'\necho '   start    Path to vmx file     Start a VM'\nexit 255\n"
+	if err := ioutil.WriteFile(vmrun, []byte(script), 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	src := filepath.Join(td, "src", "source.vmx")
+	if err := os.MkdirAll(filepath.Dir(src), 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := WriteVMX(src, map[string]string{"displayname": "source"}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	d := &Fusion6Driver{Fusion5Driver: Fusion5Driver{VmrunPath: vmrun}}
+	dst := filepath.Join(td, "dst", "clone.vmx")
+	if err := d.Clone(context.Background(), dst, src, false); err != nil {
+		t.Fatalf("full clones should fall back to copying: %s", err)
+	}
+	if _, err := os.Stat(dst); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if err := d.Clone(context.Background(), filepath.Join(td, "linked", "clone.vmx"), src, true); err == nil {
+		t.Fatal("linked clones should have error")
+	}
+}
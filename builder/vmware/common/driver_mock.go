@@ -1,6 +1,7 @@
 package common
 
 import (
+	"context"
 	"net"
 	"sync"
 
@@ -27,6 +28,17 @@ type DriverMock struct {
 	CreateDiskTypeId      string
 	CreateDiskErr         error
 
+	CopyDiskCalled bool
+	CopyDiskOutput string
+	CopyDiskSource string
+	CopyDiskTypeId string
+	CopyDiskErr    error
+
+	ExpandDiskCalled bool
+	ExpandDiskPath   string
+	ExpandDiskSize   string
+	ExpandDiskErr    error
+
 	IsRunningCalled bool
 	IsRunningPath   string
 	IsRunningResult bool
@@ -66,6 +78,25 @@ type DriverMock struct {
 	StopPath   string
 	StopErr    error
 
+	SuspendCalled bool
+	SuspendPath   string
+	SuspendErr    error
+
+	CreateSnapshotCalled bool
+	CreateSnapshotPath   string
+	CreateSnapshotName   string
+	CreateSnapshotErr    error
+
+	DeleteSnapshotCalled bool
+	DeleteSnapshotPath   string
+	DeleteSnapshotName   string
+	DeleteSnapshotErr    error
+
+	RevertToSnapshotCalled bool
+	RevertToSnapshotPath   string
+	RevertToSnapshotName   string
+	RevertToSnapshotErr    error
+
 	SuppressMessagesCalled bool
 	SuppressMessagesPath   string
 	SuppressMessagesErr    error
@@ -108,7 +139,7 @@ func (m NetworkMapperMock) DeviceIntoName(device string) (string, error) {
 	return "", nil
 }
 
-func (d *DriverMock) Clone(dst string, src string, linked bool) error {
+func (d *DriverMock) Clone(ctx context.Context, dst string, src string, linked bool) error {
 	d.CloneCalled = true
 	d.CloneDst = dst
 	d.CloneSrc = src
@@ -116,13 +147,13 @@ func (d *DriverMock) Clone(dst string, src string, linked bool) error {
 	return d.CloneErr
 }
 
-func (d *DriverMock) CompactDisk(path string) error {
+func (d *DriverMock) CompactDisk(ctx context.Context, path string) error {
 	d.CompactDiskCalled = true
 	d.CompactDiskPath = path
 	return d.CompactDiskErr
 }
 
-func (d *DriverMock) CreateDisk(output string, size string, adapterType string, typeId string) error {
+func (d *DriverMock) CreateDisk(ctx context.Context, output string, size string, adapterType string, typeId string) error {
 	d.CreateDiskCalled = true
 	d.CreateDiskOutput = output
 	d.CreateDiskSize = size
@@ -131,7 +162,22 @@ func (d *DriverMock) CreateDisk(output string, size string, adapterType string,
 	return d.CreateDiskErr
 }
 
-func (d *DriverMock) IsRunning(path string) (bool, error) {
+func (d *DriverMock) CopyDisk(ctx context.Context, output string, source string, typeId string) error {
+	d.CopyDiskCalled = true
+	d.CopyDiskOutput = output
+	d.CopyDiskSource = source
+	d.CopyDiskTypeId = typeId
+	return d.CopyDiskErr
+}
+
+func (d *DriverMock) ExpandDisk(ctx context.Context, path string, size string) error {
+	d.ExpandDiskCalled = true
+	d.ExpandDiskPath = path
+	d.ExpandDiskSize = size
+	return d.ExpandDiskErr
+}
+
+func (d *DriverMock) IsRunning(ctx context.Context, path string) (bool, error) {
 	d.Lock()
 	defer d.Unlock()
 
@@ -198,19 +244,51 @@ func (d *DriverMock) GuestIP(state multistep.StateBag) (string, error) {
 	return d.GuestIPResult, d.GuestIPErr
 }
 
-func (d *DriverMock) Start(path string, headless bool) error {
+func (d *DriverMock) Start(ctx context.Context, path string, headless bool) error {
 	d.StartCalled = true
 	d.StartPath = path
 	d.StartHeadless = headless
 	return d.StartErr
 }
 
-func (d *DriverMock) Stop(path string) error {
+func (d *DriverMock) Stop(ctx context.Context, path string) error {
+	d.Lock()
+	defer d.Unlock()
+
+	// A stopped VM isn't running anymore
+	d.IsRunningResult = false
 	d.StopCalled = true
 	d.StopPath = path
 	return d.StopErr
 }
 
+func (d *DriverMock) Suspend(ctx context.Context, path string) error {
+	d.SuspendCalled = true
+	d.SuspendPath = path
+	return d.SuspendErr
+}
+
+func (d *DriverMock) CreateSnapshot(ctx context.Context, path string, name string) error {
+	d.CreateSnapshotCalled = true
+	d.CreateSnapshotPath = path
+	d.CreateSnapshotName = name
+	return d.CreateSnapshotErr
+}
+
+func (d *DriverMock) DeleteSnapshot(ctx context.Context, path string, name string) error {
+	d.DeleteSnapshotCalled = true
+	d.DeleteSnapshotPath = path
+	d.DeleteSnapshotName = name
+	return d.DeleteSnapshotErr
+}
+
+func (d *DriverMock) RevertToSnapshot(ctx context.Context, path string, name string) error {
+	d.RevertToSnapshotCalled = true
+	d.RevertToSnapshotPath = path
+	d.RevertToSnapshotName = name
+	return d.RevertToSnapshotErr
+}
+
 func (d *DriverMock) SuppressMessages(path string) error {
 	d.SuppressMessagesCalled = true
 	d.SuppressMessagesPath = path
@@ -1,13 +1,12 @@
 package common
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
 	"os"
 	"os/exec"
-	"path/filepath"
-	"strings"
 
 	"github.com/hashicorp/packer/helper/multistep"
 )
@@ -15,6 +14,7 @@ import (
 // Player5Driver is a driver that can run VMware Player 5 on Linux.
 type Player5Driver struct {
 	VmwareDriver
+	RunRetry
 
 	AppPath          string
 	VdiskManagerPath string
@@ -25,31 +25,31 @@ type Player5Driver struct {
 	SSHConfig *SSHConfig
 }
 
-func (d *Player5Driver) Clone(dst, src string, linked bool) error {
-	return errors.New("Cloning is not supported with VMWare Player version 5. Please use VMWare Player version 6, or greater.")
+func (d *Player5Driver) Clone(ctx context.Context, dst, src string, linked bool) error {
+	return copyVM(dst, src, linked)
 }
 
-func (d *Player5Driver) CompactDisk(diskPath string) error {
+func (d *Player5Driver) CompactDisk(ctx context.Context, diskPath string) error {
 	if d.QemuImgPath != "" {
-		return d.qemuCompactDisk(diskPath)
+		return d.qemuCompactDisk(ctx, diskPath)
 	}
 
 	defragCmd := exec.Command(d.VdiskManagerPath, "-d", diskPath)
-	if _, _, err := runAndLog(defragCmd); err != nil {
+	if err := d.runVdiskManager(ctx, defragCmd); err != nil {
 		return err
 	}
 
 	shrinkCmd := exec.Command(d.VdiskManagerPath, "-k", diskPath)
-	if _, _, err := runAndLog(shrinkCmd); err != nil {
+	if err := d.runVdiskManager(ctx, shrinkCmd); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (d *Player5Driver) qemuCompactDisk(diskPath string) error {
+func (d *Player5Driver) qemuCompactDisk(ctx context.Context, diskPath string) error {
 	cmd := exec.Command(d.QemuImgPath, "convert", "-f", "vmdk", "-O", "vmdk", "-o", "compat6", diskPath, diskPath+".new")
-	if _, _, err := runAndLog(cmd); err != nil {
+	if _, _, err := d.runAndLog(ctx, cmd); err != nil {
 		return err
 	}
 
@@ -64,62 +64,94 @@ func (d *Player5Driver) qemuCompactDisk(diskPath string) error {
 	return nil
 }
 
-func (d *Player5Driver) CreateDisk(output string, size string, adapter_type string, type_id string) error {
+func (d *Player5Driver) CreateDisk(ctx context.Context, output string, size string, adapter_type string, type_id string) error {
 	var cmd *exec.Cmd
 	if d.QemuImgPath != "" {
 		cmd = exec.Command(d.QemuImgPath, "create", "-f", "vmdk", "-o", "compat6", output, size)
 	} else {
-		cmd = exec.Command(d.VdiskManagerPath, "-c", "-s", size, "-a", adapter_type, "-t", type_id, output)
+		cmd = exec.Command(d.VdiskManagerPath, "-c", "-s", size, "-a", vdiskAdapterType(adapter_type), "-t", type_id, output)
 	}
-	if _, _, err := runAndLog(cmd); err != nil {
+	if _, _, err := d.runAndLog(ctx, cmd); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (d *Player5Driver) IsRunning(vmxPath string) (bool, error) {
-	vmxPath, err := filepath.Abs(vmxPath)
-	if err != nil {
-		return false, err
+func (d *Player5Driver) CopyDisk(ctx context.Context, output string, source string, type_id string) error {
+	if d.QemuImgPath != "" {
+		cmd := exec.Command(d.QemuImgPath, "convert", "-O", "vmdk", "-o", "compat6", source, output)
+		_, _, err := d.runAndLog(ctx, cmd)
+		return err
 	}
 
-	cmd := exec.Command(d.VmrunPath, "-T", "player", "list")
-	stdout, _, err := runAndLog(cmd)
-	if err != nil {
-		return false, err
-	}
+	cmd := exec.Command(d.VdiskManagerPath, "-r", source, "-t", type_id, output)
+	return d.runVdiskManager(ctx, cmd)
+}
 
-	for _, line := range strings.Split(stdout, "\n") {
-		if line == vmxPath {
-			return true, nil
-		}
+func (d *Player5Driver) ExpandDisk(ctx context.Context, diskPath string, size string) error {
+	if d.VdiskManagerPath == "" {
+		return errors.New("Expanding disks with VMware Player requires 'vmware-vdiskmanager'")
 	}
 
-	return false, nil
+	cmd := exec.Command(d.VdiskManagerPath, "-x", size, diskPath)
+	return d.runVdiskManager(ctx, cmd)
+}
+
+func (d *Player5Driver) IsRunning(ctx context.Context, vmxPath string) (bool, error) {
+	return vmrunIsRunning(ctx, d, vmxPath)
 }
 
 func (d *Player5Driver) CommHost(state multistep.StateBag) (string, error) {
 	return CommHost(d.SSHConfig)(state)
 }
 
-func (d *Player5Driver) Start(vmxPath string, headless bool) error {
+func (d *Player5Driver) vmrun() (string, string) {
+	return d.VmrunPath, "player"
+}
+
+func (d *Player5Driver) GuestIP(state multistep.StateBag) (string, error) {
+	return guestIPWithTools(&d.VmwareDriver, d, state)
+}
+
+func (d *Player5Driver) Start(ctx context.Context, vmxPath string, headless bool) error {
 	guiArgument := "gui"
 	if headless {
 		guiArgument = "nogui"
 	}
 
 	cmd := exec.Command(d.VmrunPath, "-T", "player", "start", vmxPath, guiArgument)
-	if _, _, err := runAndLog(cmd); err != nil {
+	if _, _, err := d.runAndLog(ctx, cmd); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (d *Player5Driver) Stop(vmxPath string) error {
+func (d *Player5Driver) Stop(ctx context.Context, vmxPath string) error {
 	cmd := exec.Command(d.VmrunPath, "-T", "player", "stop", vmxPath, "hard")
-	if _, _, err := runAndLog(cmd); err != nil {
+	if _, _, err := d.runAndLog(ctx, cmd); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (d *Player5Driver) CreateSnapshot(ctx context.Context, vmxPath string, name string) error {
+	return errors.New("Snapshots are not supported with VMware Player. Please use VMware Workstation.")
+}
+
+func (d *Player5Driver) DeleteSnapshot(ctx context.Context, vmxPath string, name string) error {
+	return errors.New("Snapshots are not supported with VMware Player. Please use VMware Workstation.")
+}
+
+func (d *Player5Driver) RevertToSnapshot(ctx context.Context, vmxPath string, name string) error {
+	return errors.New("Snapshots are not supported with VMware Player. Please use VMware Workstation.")
+}
+
+func (d *Player5Driver) Suspend(ctx context.Context, vmxPath string) error {
+	cmd := exec.Command(d.VmrunPath, "-T", "player", "suspend", vmxPath)
+	if _, _, err := d.runAndLog(ctx, cmd); err != nil {
 		return err
 	}
 
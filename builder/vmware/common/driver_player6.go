@@ -1,6 +1,8 @@
 package common
 
 import (
+	"context"
+	"log"
 	"os/exec"
 )
 
@@ -13,7 +15,7 @@ type Player6Driver struct {
 	Player5Driver
 }
 
-func (d *Player6Driver) Clone(dst, src string, linked bool) error {
+func (d *Player6Driver) Clone(ctx context.Context, dst, src string, linked bool) error {
 	// TODO(rasa) check if running player+, not just player
 
 	var cloneType string
@@ -28,8 +30,14 @@ func (d *Player6Driver) Clone(dst, src string, linked bool) error {
 		"clone", src, dst,
 		cloneType)
 
-	if _, _, err := runAndLog(cmd); err != nil {
-		return err
+	if _, _, err := d.runAndLog(ctx, cmd); err != nil {
+		// Only Player Pro ships a vmrun that can clone, so fall back to
+		// copying the files of the source VM.
+		if linked {
+			return err
+		}
+		log.Printf("vmrun clone failed, copying the source VM instead: %s", err)
+		return copyVM(dst, src, linked)
 	}
 
 	return nil
@@ -1,6 +1,6 @@
 // +build !windows
 
-// These functions are compatible with WS 9 and 10 on *NIX
+// These functions are compatible with Player 5 and 6 on *NIX
 package common
 
 import (
@@ -15,19 +15,19 @@ import (
 )
 
 func playerFindVdiskManager() (string, error) {
-	return exec.LookPath("vmware-vdiskmanager")
+	return findBinary("vmware-vdiskmanager", workstationProgramFilePaths())
 }
 
 func playerFindQemuImg() (string, error) {
-	return exec.LookPath("qemu-img")
+	return findBinary("qemu-img", workstationProgramFilePaths())
 }
 
 func playerFindVMware() (string, error) {
-	return exec.LookPath("vmplayer")
+	return findBinary("vmplayer", workstationProgramFilePaths())
 }
 
 func playerFindVmrun() (string, error) {
-	return exec.LookPath("vmrun")
+	return findBinary("vmrun", workstationProgramFilePaths())
 }
 
 func playerToolsIsoPath(flavor string) string {
@@ -114,9 +114,10 @@ func playerVerifyVersion(version string) error {
 		return fmt.Errorf("The VMWare Player version %s driver is only supported on Linux, and Windows, at the moment. Your OS: %s", version, runtime.GOOS)
 	}
 
-	//TODO(pmyjavec) there is a better way to find this, how?
-	//the default will suffice for now.
-	vmxpath := "/usr/lib/vmware/bin/vmware-vmx"
+	vmxpath, err := workstationFindVmx()
+	if err != nil {
+		return err
+	}
 
 	var stderr bytes.Buffer
 	cmd := exec.Command(vmxpath, "-v")
@@ -124,14 +125,18 @@ func playerVerifyVersion(version string) error {
 	if err := cmd.Run(); err != nil {
 		return err
 	}
+	return playerTestVersion(version, stderr.String())
+}
 
-	versionRe := regexp.MustCompile(`(?i)VMware Player (\d+)\.`)
-	matches := versionRe.FindStringSubmatch(stderr.String())
+func playerTestVersion(wanted, versionOutput string) error {
+	// Player 12 and later were renamed to "VMware Workstation Player"
+	versionRe := regexp.MustCompile(`(?i)VMware (?:Workstation )?Player (\d+)\.`)
+	matches := versionRe.FindStringSubmatch(versionOutput)
 	if matches == nil {
 		return fmt.Errorf(
-			"Could not find VMWare Player version in output: %s", stderr.String())
+			"Could not find VMWare Player version in output: %s", versionOutput)
 	}
 	log.Printf("Detected VMWare Player version: %s", matches[1])
 
-	return compareVersions(matches[1], version, "Player")
+	return compareVersions(matches[1], wanted, "Player")
 }
@@ -0,0 +1,30 @@
+// +build !windows
+
+package common
+
+import (
+	"testing"
+)
+
+func TestPlayerVersion_player6(t *testing.T) {
+	input := `VMware Player Information:
+VMware Player 6.0.7 build-2844087 Release`
+	if err := playerTestVersion("6", input); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPlayerVersion_workstationPlayer(t *testing.T) {
+	input := `VMware Workstation Player Information:
+VMware Workstation Player 15.1.0 build-13591040 Release`
+	if err := playerTestVersion("6", input); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPlayerVersion_tooOld(t *testing.T) {
+	input := `VMware Player 5.0.4 build-1945795 Release`
+	if err := playerTestVersion("6", input); err == nil {
+		t.Fatal("should error for an unsupported version")
+	}
+}
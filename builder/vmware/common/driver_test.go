@@ -0,0 +1,264 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testLeases = `# All times in this file are in UTC (GMT), not your local timezone.
+lease 192.168.1.10 {
+	starts 1 2018/10/15 10:00:00;
+	ends 1 2018/10/15 10:30:00;
+	hardware ethernet 00:0c:29:12:34:56;
+}
+lease 192.168.1.11 {
+	starts 1 2018/10/15 11:00:00;
+	ends 1 2018/10/15 11:30:00;
+	hardware ethernet 00:0C:29:12:34:56;
+}
+lease 192.168.1.12 {
+	starts 1 2018/10/15 12:00:00;
+	ends 1 2018/10/15 12:30:00;
+	hardware ethernet 00:0c:29:ab:cd:ef;
+}
+`
+
+func TestFindLeaseIP(t *testing.T) {
+	// The most recent lease of the MAC wins, matching case-insensitively
+	if ip := findLeaseIP(testLeases, "00:0c:29:12:34:56"); ip != "192.168.1.11" {
+		t.Fatalf("bad ip: %s", ip)
+	}
+
+	if ip := findLeaseIP(testLeases, "00:0c:29:00:00:00"); ip != "" {
+		t.Fatalf("bad ip: %s", ip)
+	}
+}
+
+func TestFindLeaseIP_never(t *testing.T) {
+	leases := testLeases + `lease 192.168.1.20 {
+	starts 1 2018/10/15 09:00:00;
+	ends never;
+	hardware ethernet 00:0c:29:12:34:56;
+}
+`
+	if ip := findLeaseIP(leases, "00:0c:29:12:34:56"); ip != "192.168.1.20" {
+		t.Fatalf("bad ip: %s", ip)
+	}
+}
+
+func TestFindLeaseIP_windows(t *testing.T) {
+	leases := "lease 192.168.1.30 {\r\n\tends 1 2018/10/15 10:30:00;\r\n\thardware ethernet 00:0c:29:12:34:56;\r\n}\r\n"
+	if ip := findLeaseIP(leases, "00:0c:29:12:34:56"); ip != "192.168.1.30" {
+		t.Fatalf("bad ip: %s", ip)
+	}
+}
+
+func TestParseGuestIPAddress(t *testing.T) {
+	ip, err := parseGuestIPAddress("192.168.1.40\n")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if ip != "192.168.1.40" {
+		t.Fatalf("bad ip: %s", ip)
+	}
+
+	if _, err := parseGuestIPAddress("unknown\n"); err == nil {
+		t.Fatal("should error")
+	}
+}
+
+func TestVdiskAdapterType(t *testing.T) {
+	cases := map[string]string{
+		"":         "lsilogic",
+		"scsi":     "lsilogic",
+		"sata":     "lsilogic",
+		"nvme":     "lsilogic",
+		"ide":      "ide",
+		"BusLogic": "buslogic",
+	}
+
+	for adapter, expected := range cases {
+		if actual := vdiskAdapterType(adapter); actual != expected {
+			t.Fatalf("bad adapter type for %q: %s", adapter, actual)
+		}
+	}
+}
+
+func TestRunAndLog_retry(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+
+	retry := RunRetry{Retries: 2, Delay: time.Millisecond}
+
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	// Fails with a transient error the first time only
+	marker := filepath.Join(td, "marker")
+	script := fmt.Sprintf(`if [ ! -e %s ]; then : > %s; echo "Error: The operation was canceled" >&2; exit 1; fi; echo ok`,
+		marker, marker)
+	stdout, _, err := retry.runAndLog(context.Background(), exec.Command("sh", "-c", script))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if strings.TrimSpace(stdout) != "ok" {
+		t.Fatalf("bad stdout: %q", stdout)
+	}
+
+	// Other errors aren't retried
+	script = fmt.Sprintf(`echo run >> %s; echo "Error: The file is already in use" >&2; exit 1`,
+		marker)
+	if _, _, err = retry.runAndLog(context.Background(), exec.Command("sh", "-c", script)); err == nil {
+		t.Fatal("should have error")
+	}
+	runs, err := ioutil.ReadFile(marker)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if string(runs) != "run\n" {
+		t.Fatalf("should run once: %q", runs)
+	}
+}
+
+func TestRunAndLog_stdin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	// Fails with a transient error the first time only, after reading stdin
+	marker := filepath.Join(td, "marker")
+	script := fmt.Sprintf(`read line; if [ ! -e %s ]; then : > %s; echo "Error: The operation was canceled" >&2; exit 1; fi; echo "$line"`,
+		marker, marker)
+
+	retry := RunRetry{Retries: 1, Delay: time.Millisecond}
+	cmd := exec.Command("sh", "-c", script)
+	cmd.Stdin = strings.NewReader("input\n")
+	stdout, _, err := retry.runAndLog(context.Background(), cmd)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if strings.TrimSpace(stdout) != "input" {
+		t.Fatalf("stdin should be read again on retry: %q", stdout)
+	}
+
+	// A reader that can't be rewound can't be retried
+	cmd = exec.Command("sh", "-c", "cat")
+	cmd.Stdin = ioutil.NopCloser(strings.NewReader("input\n"))
+	if _, _, err := retry.runAndLog(context.Background(), cmd); err == nil {
+		t.Fatal("should have error")
+	}
+
+	// It's fine without retries
+	cmd = exec.Command("sh", "-c", "cat")
+	cmd.Stdin = ioutil.NopCloser(strings.NewReader("input\n"))
+	stdout, _, err = (RunRetry{}).runAndLog(context.Background(), cmd)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if strings.TrimSpace(stdout) != "input" {
+		t.Fatalf("bad stdout: %q", stdout)
+	}
+}
+
+func TestRunAndLog_cancel(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sleep")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, _, err := (RunRetry{}).runAndLog(ctx, exec.Command("sleep", "10")); err == nil {
+		t.Fatal("should have error")
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Fatal("command should be killed when the context is cancelled")
+	}
+}
+
+func TestRedactArgs(t *testing.T) {
+	args := []string{"-T", "ws", "-gu", "packer", "-gp", "secret", "runScriptInGuest", "-gp"}
+	expected := []string{"-T", "ws", "-gu", "packer", "-gp", "<sensitive>", "runScriptInGuest", "-gp"}
+	if redacted := redactArgs(args); !reflect.DeepEqual(redacted, expected) {
+		t.Fatalf("bad: %#v", redacted)
+	}
+	if args[5] != "secret" {
+		t.Fatal("should not modify the arguments")
+	}
+}
+
+func TestCopyDisk(t *testing.T) {
+	r, restore := withRunnerMock()
+	defer restore()
+
+	driver := &Workstation9Driver{VdiskManagerPath: "vmware-vdiskmanager"}
+	err := driver.CopyDisk(context.Background(), "/vms/ubuntu/disk.vmdk", "/appliances/disk.vmdk", "0")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	expected := []string{"-r", "/appliances/disk.vmdk", "-t", "0", "/vms/ubuntu/disk.vmdk"}
+	if !reflect.DeepEqual(r.Commands[0], expected) {
+		t.Fatalf("bad command: %#v", r.Commands[0])
+	}
+}
+
+func TestExpandDisk(t *testing.T) {
+	r, restore := withRunnerMock()
+	defer restore()
+
+	driver := &Workstation9Driver{VdiskManagerPath: "vmware-vdiskmanager"}
+	err := driver.ExpandDisk(context.Background(), "/vms/ubuntu/disk.vmdk", "40000M")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	expected := []string{"-x", "40000M", "/vms/ubuntu/disk.vmdk"}
+	if !reflect.DeepEqual(r.Commands[0], expected) {
+		t.Fatalf("bad command: %#v", r.Commands[0])
+	}
+}
+
+func TestRunVdiskManager_fixture(t *testing.T) {
+	r, restore := withRunnerMock(
+		runnerResponse{Stdout: testFixture(t, "vdiskmanager-create.txt")},
+		runnerResponse{Stdout: testFixture(t, "vdiskmanager-create-exists.txt"), Failed: true},
+	)
+	defer restore()
+
+	driver := &Workstation9Driver{VdiskManagerPath: "vmware-vdiskmanager"}
+	err := driver.CreateDisk(context.Background(), "/vms/ubuntu/disk.vmdk", "40000M", "nvme", "1")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	expected := []string{"-c", "-s", "40000M", "-a", "lsilogic", "-t", "1", "/vms/ubuntu/disk.vmdk"}
+	if !reflect.DeepEqual(r.Commands[0], expected) {
+		t.Fatalf("bad command: %#v", r.Commands[0])
+	}
+
+	err = driver.CreateDisk(context.Background(), "/vms/ubuntu/disk.vmdk", "40000M", "nvme", "1")
+	if err == nil {
+		t.Fatal("should error")
+	}
+	if err.Error() != "VMware error: Failed to create disk: The file already exists (0x20000000000d)." {
+		t.Fatalf("bad error: %s", err)
+	}
+}
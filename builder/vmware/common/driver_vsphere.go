@@ -0,0 +1,983 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/packer/common/bootcommand"
+	"github.com/hashicorp/packer/helper/communicator"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer/tmp"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// VSphereDriver talks to an ESXi hypervisor through the vSphere API
+// instead of shelling out over SSH. This means that SSH does not need to
+// be enabled on the host. Like the ESX5 driver, this driver can only
+// manage one machine at a time.
+//
+// The host can also be a vCenter server, in which case the VM is placed in
+// the given datacenter, cluster or resource pool and folder.
+type VSphereDriver struct {
+	base VmwareDriver
+
+	Host           string
+	Port           int
+	Username       string
+	Password       string
+	Datastore      string
+	CacheDatastore string
+	CacheDirectory string
+	Datacenter     string
+	Cluster        string
+	ResourcePool   string
+	Folder         string
+	Insecure       bool
+	VMName         string
+	CommConfig     communicator.Config
+
+	client     *govmomi.Client
+	finder     *find.Finder
+	datacenter *object.Datacenter
+	datastores map[string]*object.Datastore
+	outputDir  string
+	vm         *object.VirtualMachine
+}
+
+// Clone copies the files of the source VM on the datastore, like the ESX5
+// driver does. The source can also be the .vmtx file of a template.
+func (d *VSphereDriver) Clone(ctx context.Context, dst, src string, linked bool) error {
+	if linked {
+		return errors.New("Linked clones are not supported with ESXi, " +
+			"the vSphere API can only create full copies of the source disks.")
+	}
+
+	d.SetOutputDir(path.Dir(filepath.ToSlash(dst)))
+	srcVmx := d.datastorePath(src)
+	dstVmx := d.datastorePath(dst)
+
+	log.Printf("Source: %s\n", srcVmx)
+	log.Printf("Dest: %s\n", dstVmx)
+
+	if err := d.MkdirAll(); err != nil {
+		return fmt.Errorf("Failed to create the destination directory %s: %s", d.outputDir, err)
+	}
+
+	if err := d.copyFile(ctx, dstVmx, srcVmx); err != nil {
+		return fmt.Errorf("Failed to copy the vmx file %s: %s", srcVmx, err)
+	}
+
+	files, err := d.listFiles(path.Dir(srcVmx))
+	if err != nil {
+		return fmt.Errorf("Failed to get the file list to copy: %s", err)
+	}
+	for _, f := range files {
+		info := f.GetFileInfo()
+		switch path.Ext(info.Path) {
+		case ".vmdk", ".vmx", ".vmxf", ".vmtx":
+			continue
+		}
+		if info.FileSize == 0 {
+			continue
+		}
+		srcFile := path.Join(path.Dir(srcVmx), info.Path)
+		if err := d.copyFile(ctx, path.Join(d.outputDir, info.Path), srcFile); err != nil {
+			return fmt.Errorf("Failing to copy %s to %s: %s", srcFile, d.outputDir, err)
+		}
+	}
+
+	// Read the disks from the vmx, the disks of a template are only listed
+	// there
+	tempDir, err := tmp.Dir("packer-vmx")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+	localVmx := filepath.Join(tempDir, path.Base(dstVmx))
+	ds, rel, err := d.splitPath(srcVmx)
+	if err != nil {
+		return err
+	}
+	if err := ds.DownloadFile(ctx, rel, localVmx, &soap.DefaultDownload); err != nil {
+		return fmt.Errorf("Failing to get the vmdk list to clone: %s", err)
+	}
+	vmxData, err := ReadVMX(localVmx)
+	if err != nil {
+		return fmt.Errorf("Failing to get the vmdk list to clone: %s", err)
+	}
+
+	m := object.NewVirtualDiskManager(d.client.Client)
+	for key, disk := range vmxData {
+		if !strings.HasSuffix(key, ".filename") || path.Ext(disk) != ".vmdk" {
+			continue
+		}
+		srcDisk := path.Join(path.Dir(srcVmx), disk)
+		if path.IsAbs(disk) {
+			srcDisk = disk
+		}
+		destDisk := path.Join(d.outputDir, path.Base(disk))
+
+		srcName, err := d.datastoreName(srcDisk)
+		if err != nil {
+			return err
+		}
+		destName, err := d.datastoreName(destDisk)
+		if err != nil {
+			return err
+		}
+		spec := &types.VirtualDiskSpec{
+			AdapterType: string(cloneDiskAdapterType(vmxData, key)),
+			DiskType:    string(types.VirtualDiskTypeThin),
+		}
+		task, err := m.CopyVirtualDisk(ctx, srcName, d.datacenter, destName, d.datacenter, spec, false)
+		if err == nil {
+			err = task.Wait(ctx)
+		}
+		if err != nil {
+			return fmt.Errorf("Failing to clone disk %s: %s", srcDisk, err)
+		}
+	}
+	log.Printf("Successfully cloned %s to %s\n", src, dst)
+	return nil
+}
+
+func (d *VSphereDriver) CompactDisk(ctx context.Context, diskPathLocal string) error {
+	return errors.New("Compacting disks is not supported by the vSphere API driver, set skip_compaction to true")
+}
+
+func (d *VSphereDriver) CopyDisk(ctx context.Context, diskPathLocal string, source string, typeId string) error {
+	return errors.New("Copying a local disk is not supported by the vSphere API driver")
+}
+
+func (d *VSphereDriver) CreateDisk(ctx context.Context, diskPathLocal string, size string, adapter_type string, typeId string) error {
+	capacity, err := vsphereDiskCapacityKb(size)
+	if err != nil {
+		return err
+	}
+	diskType, err := vsphereDiskType(typeId)
+	if err != nil {
+		return err
+	}
+
+	spec := &types.FileBackedVirtualDiskSpec{
+		VirtualDiskSpec: types.VirtualDiskSpec{
+			AdapterType: string(vsphereDiskAdapterType(adapter_type)),
+			DiskType:    string(diskType),
+		},
+		CapacityKb: capacity,
+	}
+
+	name, err := d.datastoreName(d.datastorePath(diskPathLocal))
+	if err != nil {
+		return err
+	}
+
+	m := object.NewVirtualDiskManager(d.client.Client)
+	task, err := m.CreateVirtualDisk(ctx, name, d.datacenter, spec)
+	if err != nil {
+		return err
+	}
+	return task.Wait(ctx)
+}
+
+func (d *VSphereDriver) ExpandDisk(ctx context.Context, diskPathLocal string, size string) error {
+	return errors.New("Expanding disks is not supported by the vSphere API driver, use remote_api = \"ssh\"")
+}
+
+func (d *VSphereDriver) IsRunning(ctx context.Context, _ string) (bool, error) {
+	if d.vm == nil {
+		return false, nil
+	}
+	state, err := d.vm.PowerState(ctx)
+	if err != nil {
+		return false, err
+	}
+	return state == types.VirtualMachinePowerStatePoweredOn, nil
+}
+
+func (d *VSphereDriver) ReloadVM() error {
+	if d.vm == nil {
+		return nil
+	}
+	req := types.Reload{This: d.vm.Reference()}
+	_, err := methods.Reload(context.TODO(), d.client.Client, &req)
+	return err
+}
+
+func (d *VSphereDriver) Start(ctx context.Context, vmxPathLocal string, headless bool) error {
+	if d.vm == nil {
+		return errors.New("Unable to start a VM that is not registered")
+	}
+
+	task, err := d.vm.PowerOn(ctx)
+	if err != nil {
+		return err
+	}
+	if err := task.Wait(ctx); err != nil {
+		return err
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	return d.vm.WaitForPowerState(waitCtx, types.VirtualMachinePowerStatePoweredOn)
+}
+
+func (d *VSphereDriver) Stop(ctx context.Context, vmxPathLocal string) error {
+	if d.vm == nil {
+		return nil
+	}
+	task, err := d.vm.PowerOff(ctx)
+	if err != nil {
+		return err
+	}
+	return task.Wait(ctx)
+}
+
+func (d *VSphereDriver) Suspend(ctx context.Context, vmxPathLocal string) error {
+	if d.vm == nil {
+		return errors.New("Unable to suspend a VM that is not registered")
+	}
+	task, err := d.vm.Suspend(ctx)
+	if err != nil {
+		return err
+	}
+	return task.Wait(ctx)
+}
+
+func (d *VSphereDriver) CreateSnapshot(ctx context.Context, vmxPathLocal string, name string) error {
+	if d.vm == nil {
+		return errors.New("Unable to snapshot a VM that is not registered")
+	}
+	task, err := d.vm.CreateSnapshot(ctx, name, "", true, false)
+	if err != nil {
+		return err
+	}
+	return task.Wait(ctx)
+}
+
+func (d *VSphereDriver) DeleteSnapshot(ctx context.Context, vmxPathLocal string, name string) error {
+	if d.vm == nil {
+		return errors.New("Unable to delete a snapshot of a VM that is not registered")
+	}
+	task, err := d.vm.RemoveSnapshot(ctx, name, false, nil)
+	if err != nil {
+		return err
+	}
+	return task.Wait(ctx)
+}
+
+func (d *VSphereDriver) RevertToSnapshot(ctx context.Context, vmxPathLocal string, name string) error {
+	if d.vm == nil {
+		return errors.New("Unable to revert a VM that is not registered")
+	}
+	task, err := d.vm.RevertToSnapshot(ctx, name, true)
+	if err != nil {
+		return err
+	}
+	return task.Wait(ctx)
+}
+
+func (d *VSphereDriver) Register(vmxPathLocal string) error {
+	vmxPath := filepath.ToSlash(filepath.Join(d.outputDir, filepath.Base(vmxPathLocal)))
+	if err := d.upload(vmxPath, vmxPathLocal); err != nil {
+		return err
+	}
+
+	name, err := d.datastoreName(vmxPath)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.TODO()
+	folder, err := d.vmFolder(ctx)
+	if err != nil {
+		return err
+	}
+	pool, host, err := d.placement(ctx)
+	if err != nil {
+		return err
+	}
+
+	task, err := folder.RegisterVM(ctx, name, "", false, pool, host)
+	if err != nil {
+		return err
+	}
+	info, err := task.WaitForResult(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	d.vm = object.NewVirtualMachine(d.client.Client, info.Result.(types.ManagedObjectReference))
+	return nil
+}
+
+// vmFolder returns the folder to register the VM in, which is the root VM
+// folder of the datacenter unless Folder is set.
+func (d *VSphereDriver) vmFolder(ctx context.Context) (*object.Folder, error) {
+	if d.Folder != "" {
+		folder, err := d.finder.Folder(ctx, d.Folder)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to find folder %s: %s", d.Folder, err)
+		}
+		return folder, nil
+	}
+
+	folders, err := d.datacenter.Folders(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return folders.VmFolder, nil
+}
+
+// placement returns the resource pool to register the VM in, and the host
+// to run it on. The host is left to DRS when a cluster or resource pool is
+// given.
+func (d *VSphereDriver) placement(ctx context.Context) (*object.ResourcePool, *object.HostSystem, error) {
+	if d.Cluster != "" {
+		cluster, err := d.finder.ClusterComputeResource(ctx, d.Cluster)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Unable to find cluster %s: %s", d.Cluster, err)
+		}
+		pool, err := cluster.ResourcePool(ctx)
+		return pool, nil, err
+	}
+
+	if d.ResourcePool != "" {
+		pool, err := d.finder.ResourcePool(ctx, d.ResourcePool)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Unable to find resource pool %s: %s", d.ResourcePool, err)
+		}
+		return pool, nil, nil
+	}
+
+	pool, err := d.finder.DefaultResourcePool(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	host, err := d.finder.DefaultHostSystem(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pool, host, nil
+}
+
+// Customize applies the guest customization spec with the given name, as
+// saved in vCenter, to the VM. The customization runs when the VM boots.
+func (d *VSphereDriver) Customize(ctx context.Context, specName string) error {
+	if d.vm == nil {
+		return errors.New("Unable to customize a VM that is not registered")
+	}
+	if d.client.ServiceContent.CustomizationSpecManager == nil {
+		return errors.New("Guest customization specs require the host to be managed by vCenter")
+	}
+
+	m := object.NewCustomizationSpecManager(d.client.Client)
+	item, err := m.GetCustomizationSpec(ctx, specName)
+	if err != nil {
+		return fmt.Errorf("Unable to find customization spec %s: %s", specName, err)
+	}
+
+	task, err := d.vm.Customize(ctx, item.Spec)
+	if err != nil {
+		return err
+	}
+	return task.Wait(ctx)
+}
+
+// SendUSBCode types a key on the console of the VM with PutUsbScanCodes,
+// which needs vSphere 6.5 or later.
+func (d *VSphereDriver) SendUSBCode(ctx context.Context, code uint16, modifiers bootcommand.USBModifiers) error {
+	if d.vm == nil {
+		return errors.New("Unable to type on a VM that is not registered")
+	}
+
+	req := types.PutUsbScanCodes{
+		This: d.vm.Reference(),
+		Spec: types.UsbScanCodeSpec{
+			KeyEvents: []types.UsbScanCodeSpecKeyEvent{{
+				// The usage ID goes in the upper 16 bits, followed by the
+				// flags for a key press
+				UsbHidCode: int32(code)<<16 | 7,
+				Modifiers: &types.UsbScanCodeSpecModifierType{
+					LeftControl:  &modifiers.LeftCtrl,
+					LeftShift:    &modifiers.LeftShift,
+					LeftAlt:      &modifiers.LeftAlt,
+					LeftGui:      &modifiers.LeftSuper,
+					RightControl: &modifiers.RightCtrl,
+					RightShift:   &modifiers.RightShift,
+					RightAlt:     &modifiers.RightAlt,
+					RightGui:     &modifiers.RightSuper,
+				},
+			}},
+		},
+	}
+	_, err := methods.PutUsbScanCodes(ctx, d.client.Client, &req)
+	return err
+}
+
+func (d *VSphereDriver) SuppressMessages(vmxPath string) error {
+	return nil
+}
+
+func (d *VSphereDriver) Unregister(vmxPathLocal string) error {
+	if d.vm == nil {
+		return nil
+	}
+	return d.vm.Unregister(context.TODO())
+}
+
+// ConvertToTemplate marks the VM as a template, which requires the host to
+// be managed by vCenter.
+func (d *VSphereDriver) ConvertToTemplate(vmxPathLocal string) error {
+	if d.vm == nil {
+		return nil
+	}
+	return d.vm.MarkAsTemplate(context.TODO())
+}
+
+func (d *VSphereDriver) Destroy() error {
+	if d.vm == nil {
+		return nil
+	}
+	task, err := d.vm.Destroy(context.TODO())
+	if err != nil {
+		return err
+	}
+	return task.Wait(context.TODO())
+}
+
+func (d *VSphereDriver) IsDestroyed() (bool, error) {
+	exists, err := d.DirExists()
+	return !exists, err
+}
+
+func (d *VSphereDriver) UploadISO(localPath string, checksum string, checksumType string) (string, error) {
+	finalPath := d.CachePath(localPath)
+	if err := d.mkdir(path.Dir(finalPath)); err != nil {
+		return "", err
+	}
+
+	// There is no way to compute a checksum through the API, so the best we
+	// can do is to compare the size of the cached file with the local one.
+	fi, err := os.Stat(localPath)
+	if err != nil {
+		return "", err
+	}
+	if size, err := d.fileSize(finalPath); err == nil && size == fi.Size() {
+		log.Println("Cached file has the same size, no upload needed.")
+		return finalPath, nil
+	}
+
+	if err := d.upload(finalPath, localPath); err != nil {
+		return "", err
+	}
+
+	return finalPath, nil
+}
+
+func (d *VSphereDriver) RemoveCache(localPath string) error {
+	finalPath := d.CachePath(localPath)
+	log.Printf("Removing remote cache path %s (local %s)", finalPath, localPath)
+	return d.Remove(finalPath)
+}
+
+func (d *VSphereDriver) ToolsIsoPath(string) string {
+	return ""
+}
+
+func (d *VSphereDriver) ToolsInstall() error {
+	if d.vm == nil {
+		return errors.New("Unable to mount the tools installer on a VM that is not registered")
+	}
+	return d.vm.MountToolsInstaller(context.TODO())
+}
+
+func (d *VSphereDriver) Verify() error {
+	// The network mapping is handled by ESX, see the ESX5 driver.
+	d.base.NetworkMapper = nil
+	d.base.DhcpLeasesPath = func(device string) string {
+		log.Printf("Unexpected error, vSphere driver attempted to call DhcpLeasesPath(%#v)\n", device)
+		return ""
+	}
+	d.base.DhcpConfPath = func(device string) string {
+		log.Printf("Unexpected error, vSphere driver attempted to call DhcpConfPath(%#v)\n", device)
+		return ""
+	}
+	d.base.VmnetnatConfPath = func(device string) string {
+		log.Printf("Unexpected error, vSphere driver attempted to call VmnetnatConfPath(%#v)\n", device)
+		return ""
+	}
+
+	checks := []func() error{
+		d.connect,
+		d.checkDatastores,
+	}
+
+	for _, check := range checks {
+		if err := check(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *VSphereDriver) HostIP(multistep.StateBag) (string, error) {
+	conn, err := net.Dial("tcp", net.JoinHostPort(d.Host, strconv.Itoa(d.Port)))
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	host, _, err := net.SplitHostPort(conn.LocalAddr().String())
+	return host, err
+}
+
+func (d *VSphereDriver) GuestIP(state multistep.StateBag) (string, error) {
+	if d.vm == nil {
+		return "", errors.New("Unable to look up the IP of a VM that is not registered")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return d.vm.WaitForIP(ctx)
+}
+
+func (d *VSphereDriver) HostAddress(state multistep.StateBag) (string, error) {
+	host, err := d.HostIP(state)
+	if err != nil {
+		return "", fmt.Errorf("Unable to determine host address for ESXi: %v", err)
+	}
+
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return "", fmt.Errorf("Unable to enumerate host interfaces : %v", err)
+	}
+
+	for _, intf := range interfaces {
+		addrs, err := intf.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			if ip, _, err := net.ParseCIDR(addr.String()); err == nil && ip.String() == host {
+				return intf.HardwareAddr.String(), nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("Unable to locate interface matching host address in ESXi: %v", host)
+}
+
+func (d *VSphereDriver) GuestAddress(multistep.StateBag) (string, error) {
+	if d.vm == nil {
+		return "", errors.New("Unable to look up the MAC address of a VM that is not registered")
+	}
+
+	devices, err := d.vm.Device(context.TODO())
+	if err != nil {
+		return "", err
+	}
+
+	for _, device := range devices.SelectByType((*types.VirtualEthernetCard)(nil)) {
+		if nic, ok := device.(types.BaseVirtualEthernetCard); ok {
+			if mac := nic.GetVirtualEthernetCard().MacAddress; mac != "" {
+				return mac, nil
+			}
+		}
+	}
+
+	return "", errors.New("Unable to find a network adapter on the VM")
+}
+
+func (d *VSphereDriver) VNCAddress(ctx context.Context, _ string, portMin, portMax int) (string, int, error) {
+	for port := portMin; port <= portMax; port++ {
+		address := net.JoinHostPort(d.Host, strconv.Itoa(port))
+		log.Printf("Trying address: %s...", address)
+		l, err := net.DialTimeout("tcp", address, 1*time.Second)
+		if err == nil {
+			l.Close()
+			log.Printf("Port %d in use", port)
+			continue
+		}
+		if e, ok := err.(*net.OpError); ok && e.Timeout() {
+			log.Printf("Timeout connecting to: %s (check firewall rules)", address)
+			continue
+		}
+		return d.Host, port, nil
+	}
+
+	return d.Host, 0, fmt.Errorf("Unable to find available VNC port between %d and %d",
+		portMin, portMax)
+}
+
+// UpdateVMX, adds the VNC port to the VMX data.
+func (VSphereDriver) UpdateVMX(_, password string, port int, data map[string]string) {
+	// Do not set remotedisplay.vnc.ip - this breaks ESXi.
+	data["remotedisplay.vnc.enabled"] = "TRUE"
+	data["remotedisplay.vnc.port"] = fmt.Sprintf("%d", port)
+	if len(password) > 0 {
+		data["remotedisplay.vnc.password"] = password
+	}
+}
+
+func (d *VSphereDriver) CommHost(state multistep.StateBag) (string, error) {
+	if address, ok := state.GetOk("vm_address"); ok {
+		return address.(string), nil
+	}
+
+	if address := d.CommConfig.Host(); address != "" {
+		state.Put("vm_address", address)
+		return address, nil
+	}
+
+	address, err := d.GuestIP(state)
+	if err != nil {
+		return "", fmt.Errorf("No interface on the VM has an IP address ready: %s", err)
+	}
+	state.Put("vm_address", address)
+	return address, nil
+}
+
+//-------------------------------------------------------------------
+// OutputDir implementation
+//-------------------------------------------------------------------
+
+func (d *VSphereDriver) DirExists() (bool, error) {
+	_, err := d.stat(d.outputDir)
+	return err == nil, nil
+}
+
+func (d *VSphereDriver) ListFiles() ([]string, error) {
+	infos, err := d.listFiles(d.outputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]string, 0, 10)
+	for _, f := range infos {
+		files = append(files, path.Join(d.outputDir, f.GetFileInfo().Path))
+	}
+
+	return files, nil
+}
+
+func (d *VSphereDriver) MkdirAll() error {
+	return d.mkdir(d.outputDir)
+}
+
+func (d *VSphereDriver) Remove(path string) error {
+	name, err := d.datastoreName(path)
+	if err != nil {
+		return err
+	}
+
+	m := object.NewFileManager(d.client.Client)
+	task, err := m.DeleteDatastoreFile(context.TODO(), name, d.datacenter)
+	if err != nil {
+		return err
+	}
+	return task.Wait(context.TODO())
+}
+
+func (d *VSphereDriver) RemoveAll() error {
+	return d.Remove(d.outputDir)
+}
+
+func (d *VSphereDriver) SetOutputDir(path string) {
+	d.outputDir = d.datastorePath(path)
+}
+
+func (d *VSphereDriver) String() string {
+	return d.outputDir
+}
+
+// datastorePath returns the path of a file on the output datastore, in the
+// same /vmfs/volumes form used by the ESX5 driver so that the rest of the
+// builder can treat both drivers the same way.
+func (d *VSphereDriver) datastorePath(path string) string {
+	dirPath := filepath.Dir(path)
+	return filepath.ToSlash(filepath.Join("/vmfs/volumes", d.Datastore, dirPath, filepath.Base(path)))
+}
+
+func (d *VSphereDriver) CachePath(path string) string {
+	return filepath.ToSlash(filepath.Join("/vmfs/volumes", d.CacheDatastore, d.CacheDirectory, filepath.Base(path)))
+}
+
+// splitPath turns a /vmfs/volumes path into the datastore it lives on and
+// the path relative to that datastore.
+func (d *VSphereDriver) splitPath(p string) (*object.Datastore, string, error) {
+	name, rel, err := splitVolumePath(p)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if ds, ok := d.datastores[name]; ok {
+		return ds, rel, nil
+	}
+
+	ds, err := d.finder.Datastore(context.TODO(), name)
+	if err != nil {
+		return nil, "", err
+	}
+	d.datastores[name] = ds
+	return ds, rel, nil
+}
+
+// datastoreName turns a /vmfs/volumes path into a "[datastore] path" name
+// as expected by the vSphere API.
+func (d *VSphereDriver) datastoreName(p string) (string, error) {
+	ds, rel, err := d.splitPath(p)
+	if err != nil {
+		return "", err
+	}
+	return ds.Path(rel), nil
+}
+
+func (d *VSphereDriver) connect() error {
+	u, err := url.Parse(fmt.Sprintf("https://%s/sdk", net.JoinHostPort(d.Host, strconv.Itoa(d.Port))))
+	if err != nil {
+		return err
+	}
+	u.User = url.UserPassword(d.Username, d.Password)
+
+	ctx := context.TODO()
+	client, err := govmomi.NewClient(ctx, u, d.Insecure)
+	if err != nil {
+		return err
+	}
+
+	about := client.ServiceContent.About
+	log.Printf("Connected to %s %s %s", about.Name, about.Version, about.Build)
+
+	d.client = client
+	d.finder = find.NewFinder(client.Client, true)
+	d.datastores = make(map[string]*object.Datastore)
+
+	if d.Datacenter != "" {
+		d.datacenter, err = d.finder.Datacenter(ctx, d.Datacenter)
+	} else {
+		d.datacenter, err = d.finder.DefaultDatacenter(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	d.finder.SetDatacenter(d.datacenter)
+	return nil
+}
+
+func (d *VSphereDriver) checkDatastores() error {
+	for _, name := range []string{d.Datastore, d.CacheDatastore} {
+		if _, _, err := d.splitPath(path.Join("/vmfs/volumes", name)); err != nil {
+			return fmt.Errorf("Unable to find datastore %s: %s", name, err)
+		}
+	}
+	return nil
+}
+
+func (d *VSphereDriver) mkdir(path string) error {
+	if _, err := d.stat(path); err == nil {
+		return nil
+	}
+
+	name, err := d.datastoreName(path)
+	if err != nil {
+		return err
+	}
+
+	m := object.NewFileManager(d.client.Client)
+	return m.MakeDirectory(context.TODO(), name, d.datacenter, true)
+}
+
+func (d *VSphereDriver) stat(path string) (types.BaseFileInfo, error) {
+	ds, rel, err := d.splitPath(path)
+	if err != nil {
+		return nil, err
+	}
+	if rel == "" {
+		return nil, nil
+	}
+	return ds.Stat(context.TODO(), rel)
+}
+
+func (d *VSphereDriver) fileSize(path string) (int64, error) {
+	info, err := d.stat(path)
+	if err != nil {
+		return 0, err
+	}
+	if info == nil {
+		return 0, fmt.Errorf("%s is not a file", path)
+	}
+	return info.GetFileInfo().FileSize, nil
+}
+
+// listFiles returns the files of a datastore directory, without the
+// subdirectories.
+func (d *VSphereDriver) listFiles(dir string) ([]types.BaseFileInfo, error) {
+	ds, rel, err := d.splitPath(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.TODO()
+	browser, err := ds.Browser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	task, err := browser.SearchDatastore(ctx, ds.Path(rel), &types.HostDatastoreBrowserSearchSpec{
+		MatchPattern: []string{"*"},
+		Details: &types.FileQueryFlags{
+			FileType: true,
+			FileSize: true,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	info, err := task.WaitForResult(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []types.BaseFileInfo
+	for _, f := range info.Result.(types.HostDatastoreBrowserSearchResults).File {
+		if _, ok := f.(*types.FolderFileInfo); ok {
+			continue
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// copyFile copies a file between two datastore paths.
+func (d *VSphereDriver) copyFile(ctx context.Context, dst, src string) error {
+	srcName, err := d.datastoreName(src)
+	if err != nil {
+		return err
+	}
+	dstName, err := d.datastoreName(dst)
+	if err != nil {
+		return err
+	}
+
+	m := object.NewFileManager(d.client.Client)
+	task, err := m.CopyDatastoreFile(ctx, srcName, d.datacenter, dstName, d.datacenter, true)
+	if err != nil {
+		return err
+	}
+	return task.Wait(ctx)
+}
+
+func (d *VSphereDriver) upload(dst, src string) error {
+	ds, rel, err := d.splitPath(dst)
+	if err != nil {
+		return err
+	}
+	return ds.UploadFile(context.TODO(), src, rel, &soap.DefaultUpload)
+}
+
+func (d *VSphereDriver) Download(src, dst string) error {
+	ds, rel, err := d.splitPath(d.datastorePath(src))
+	if err != nil {
+		return err
+	}
+	return ds.DownloadFile(context.TODO(), rel, dst, &soap.DefaultDownload)
+}
+
+func (d *VSphereDriver) GetVmwareDriver() VmwareDriver {
+	return d.base
+}
+
+// splitVolumePath splits a /vmfs/volumes/<datastore>/<path> path into the
+// datastore name and the path relative to it.
+func splitVolumePath(p string) (string, string, error) {
+	const prefix = "/vmfs/volumes/"
+	if !strings.HasPrefix(p, prefix) {
+		return "", "", fmt.Errorf("%s is not a datastore path", p)
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(p, prefix), "/", 2)
+	if parts[0] == "" {
+		return "", "", fmt.Errorf("%s is not a datastore path", p)
+	}
+	if len(parts) == 1 {
+		return parts[0], "", nil
+	}
+	return parts[0], parts[1], nil
+}
+
+// vsphereDiskCapacityKb converts a disk size as passed to CreateDisk
+// (for example "40000M") into kilobytes.
+func vsphereDiskCapacityKb(size string) (int64, error) {
+	units := map[string]int64{
+		"K": 1,
+		"M": 1024,
+		"G": 1024 * 1024,
+	}
+
+	multiplier := int64(1024)
+	number := size
+	if n := len(size); n > 0 {
+		if m, ok := units[strings.ToUpper(size[n-1:])]; ok {
+			multiplier = m
+			number = size[:n-1]
+		}
+	}
+
+	value, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || value <= 0 {
+		return 0, fmt.Errorf("Invalid disk size: %s", size)
+	}
+	return value * multiplier, nil
+}
+
+// vsphereDiskType maps the vmkfstools disk type names used by
+// disk_type_id onto the vSphere API disk types.
+func vsphereDiskType(typeId string) (types.VirtualDiskType, error) {
+	switch typeId {
+	case "", "thin":
+		return types.VirtualDiskTypeThin, nil
+	case "zeroedthick":
+		return types.VirtualDiskTypePreallocated, nil
+	case "eagerzeroedthick":
+		return types.VirtualDiskTypeEagerZeroedThick, nil
+	}
+	return "", fmt.Errorf("Unsupported disk type for the vSphere API driver: %s", typeId)
+}
+
+// cloneDiskAdapterType returns the adapter type of the disk with the given
+// vmx key, such as scsi0:0.filename, as the vSphere API adapter type.
+func cloneDiskAdapterType(vmxData map[string]string, key string) types.VirtualDiskAdapterType {
+	bus := strings.SplitN(key, ":", 2)[0]
+	if strings.HasPrefix(bus, "scsi") {
+		return vsphereDiskAdapterType(vmxData[bus+".virtualdev"])
+	}
+	return vsphereDiskAdapterType(strings.TrimRight(bus, "0123456789"))
+}
+
+// vsphereDiskAdapterType maps disk_adapter_type onto the vSphere API adapter
+// types. Anything that is not IDE or BusLogic is created as LSI Logic, which
+// is also what the ESXi UI does for SCSI variants the API does not know about.
+func vsphereDiskAdapterType(adapter string) types.VirtualDiskAdapterType {
+	switch strings.ToLower(adapter) {
+	case "ide":
+		return types.VirtualDiskAdapterTypeIde
+	case "buslogic":
+		return types.VirtualDiskAdapterTypeBusLogic
+	}
+	return types.VirtualDiskAdapterTypeLsiLogic
+}
@@ -0,0 +1,104 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestVSphereDriver_implDriver(t *testing.T) {
+	var _ Driver = new(VSphereDriver)
+}
+
+func TestVSphereDriver_implOutputDir(t *testing.T) {
+	var _ OutputDir = new(VSphereDriver)
+}
+
+func TestVSphereDriver_implVNCAddressFinder(t *testing.T) {
+	var _ VNCAddressFinder = new(VSphereDriver)
+}
+
+func TestVSphereDriver_implRemoteDriver(t *testing.T) {
+	var _ RemoteDriver = new(VSphereDriver)
+}
+
+func TestVSphereDriver_datastorePath(t *testing.T) {
+	driver := VSphereDriver{Datastore: "datastore1"}
+	driver.SetOutputDir("output-vmware-iso")
+
+	if out := driver.String(); out != "/vmfs/volumes/datastore1/output-vmware-iso" {
+		t.Fatalf("bad output dir: %s", out)
+	}
+}
+
+func TestSplitVolumePath(t *testing.T) {
+	cases := []struct {
+		Input     string
+		Datastore string
+		Path      string
+		Err       bool
+	}{
+		{"/vmfs/volumes/datastore1/packer/disk.vmdk", "datastore1", "packer/disk.vmdk", false},
+		{"/vmfs/volumes/datastore1", "datastore1", "", false},
+		{"/vmfs/volumes/", "", "", true},
+		{"/tmp/disk.vmdk", "", "", true},
+	}
+
+	for _, tc := range cases {
+		ds, p, err := splitVolumePath(tc.Input)
+		if (err != nil) != tc.Err {
+			t.Fatalf("%s: bad error: %s", tc.Input, err)
+		}
+		if ds != tc.Datastore || p != tc.Path {
+			t.Fatalf("%s: bad result: %s, %s", tc.Input, ds, p)
+		}
+	}
+}
+
+func TestVSphereDiskCapacityKb(t *testing.T) {
+	cases := map[string]int64{
+		"40000M": 40000 * 1024,
+		"1G":     1024 * 1024,
+		"512k":   512,
+		"100":    100 * 1024,
+	}
+
+	for input, expected := range cases {
+		actual, err := vsphereDiskCapacityKb(input)
+		if err != nil {
+			t.Fatalf("%s: err: %s", input, err)
+		}
+		if actual != expected {
+			t.Fatalf("%s: expected %d, got %d", input, expected, actual)
+		}
+	}
+
+	for _, input := range []string{"", "M", "-1M", "tenG"} {
+		if _, err := vsphereDiskCapacityKb(input); err == nil {
+			t.Fatalf("%s: should have error", input)
+		}
+	}
+}
+
+func TestVSphereDiskType(t *testing.T) {
+	cases := map[string]types.VirtualDiskType{
+		"":                 types.VirtualDiskTypeThin,
+		"thin":             types.VirtualDiskTypeThin,
+		"zeroedthick":      types.VirtualDiskTypePreallocated,
+		"eagerzeroedthick": types.VirtualDiskTypeEagerZeroedThick,
+	}
+
+	for input, expected := range cases {
+		actual, err := vsphereDiskType(input)
+		if err != nil {
+			t.Fatalf("%s: err: %s", input, err)
+		}
+		if actual != expected {
+			t.Fatalf("%s: expected %s, got %s", input, expected, actual)
+		}
+	}
+
+	if _, err := vsphereDiskType("2gbsparse"); err == nil {
+		t.Fatal("should have error")
+	}
+}
@@ -1,6 +1,7 @@
 package common
 
 import (
+	"context"
 	"os/exec"
 )
 
@@ -11,9 +12,12 @@ const VMWARE_WS_VERSION = "10"
 
 type Workstation10Driver struct {
 	Workstation9Driver
+
+	// version is the major version detected by Verify.
+	version string
 }
 
-func (d *Workstation10Driver) Clone(dst, src string, linked bool) error {
+func (d *Workstation10Driver) Clone(ctx context.Context, dst, src string, linked bool) error {
 
 	var cloneType string
 	if linked {
@@ -27,7 +31,7 @@ func (d *Workstation10Driver) Clone(dst, src string, linked bool) error {
 		"clone", src, dst,
 		cloneType)
 
-	if _, _, err := runAndLog(cmd); err != nil {
+	if _, _, err := d.runAndLog(ctx, cmd); err != nil {
 		return err
 	}
 
@@ -39,7 +43,13 @@ func (d *Workstation10Driver) Verify() error {
 		return err
 	}
 
-	return workstationVerifyVersion(VMWARE_WS_VERSION)
+	version, err := workstationVerifyVersion(VMWARE_WS_VERSION)
+	d.version = version
+	return err
+}
+
+func (d *Workstation10Driver) productVersion() (string, string) {
+	return "Workstation", d.version
 }
 
 func (d *Workstation10Driver) GetVmwareDriver() VmwareDriver {
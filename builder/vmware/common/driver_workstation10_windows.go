@@ -9,7 +9,7 @@ import (
 	"syscall"
 )
 
-func workstationVerifyVersion(version string) error {
+func workstationVerifyVersion(version string) (string, error) {
 	key := `SOFTWARE\Wow6432Node\VMware, Inc.\VMware Workstation`
 	subkey := "ProductVersion"
 	productVersion, err := readRegString(syscall.HKEY_LOCAL_MACHINE, key, subkey)
@@ -19,17 +19,17 @@ func workstationVerifyVersion(version string) error {
 		productVersion, err = readRegString(syscall.HKEY_LOCAL_MACHINE, key, subkey)
 		if err != nil {
 			log.Printf(`Unable to read registry key %s\%s`, key, subkey)
-			return err
+			return "", err
 		}
 	}
 
 	versionRe := regexp.MustCompile(`^(\d+)\.`)
 	matches := versionRe.FindStringSubmatch(productVersion)
 	if matches == nil {
-		return fmt.Errorf(
+		return "", fmt.Errorf(
 			`Could not find a VMware WS version in registry key %s\%s: '%s'`, key, subkey, productVersion)
 	}
 	log.Printf("Detected VMware WS version: %s", matches[1])
 
-	return compareVersions(matches[1], version, "Workstation")
+	return matches[1], compareVersions(matches[1], version, "Workstation")
 }
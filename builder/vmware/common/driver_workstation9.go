@@ -1,13 +1,11 @@
 package common
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"log"
 	"os"
 	"os/exec"
-	"path/filepath"
-	"strings"
 
 	"github.com/hashicorp/packer/helper/multistep"
 )
@@ -15,6 +13,7 @@ import (
 // Workstation9Driver is a driver that can run VMware Workstation 9
 type Workstation9Driver struct {
 	VmwareDriver
+	RunRetry
 
 	AppPath          string
 	VdiskManagerPath string
@@ -24,75 +23,112 @@ type Workstation9Driver struct {
 	SSHConfig *SSHConfig
 }
 
-func (d *Workstation9Driver) Clone(dst, src string, linked bool) error {
-	return errors.New("Cloning is not supported with VMware WS version 9. Please use VMware WS version 10, or greater.")
+func (d *Workstation9Driver) Clone(ctx context.Context, dst, src string, linked bool) error {
+	return copyVM(dst, src, linked)
 }
 
-func (d *Workstation9Driver) CompactDisk(diskPath string) error {
+func (d *Workstation9Driver) CompactDisk(ctx context.Context, diskPath string) error {
 	defragCmd := exec.Command(d.VdiskManagerPath, "-d", diskPath)
-	if _, _, err := runAndLog(defragCmd); err != nil {
+	if err := d.runVdiskManager(ctx, defragCmd); err != nil {
 		return err
 	}
 
 	shrinkCmd := exec.Command(d.VdiskManagerPath, "-k", diskPath)
-	if _, _, err := runAndLog(shrinkCmd); err != nil {
+	if err := d.runVdiskManager(ctx, shrinkCmd); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (d *Workstation9Driver) CreateDisk(output string, size string, adapter_type string, type_id string) error {
-	cmd := exec.Command(d.VdiskManagerPath, "-c", "-s", size, "-a", adapter_type, "-t", type_id, output)
-	if _, _, err := runAndLog(cmd); err != nil {
+func (d *Workstation9Driver) CreateDisk(ctx context.Context, output string, size string, adapter_type string, type_id string) error {
+	cmd := exec.Command(d.VdiskManagerPath, "-c", "-s", size, "-a", vdiskAdapterType(adapter_type), "-t", type_id, output)
+	if err := d.runVdiskManager(ctx, cmd); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (d *Workstation9Driver) IsRunning(vmxPath string) (bool, error) {
-	vmxPath, err := filepath.Abs(vmxPath)
-	if err != nil {
-		return false, err
-	}
-
-	cmd := exec.Command(d.VmrunPath, "-T", "ws", "list")
-	stdout, _, err := runAndLog(cmd)
-	if err != nil {
-		return false, err
-	}
+func (d *Workstation9Driver) CopyDisk(ctx context.Context, output string, source string, type_id string) error {
+	cmd := exec.Command(d.VdiskManagerPath, "-r", source, "-t", type_id, output)
+	return d.runVdiskManager(ctx, cmd)
+}
 
-	for _, line := range strings.Split(stdout, "\n") {
-		if line == vmxPath {
-			return true, nil
-		}
-	}
+func (d *Workstation9Driver) ExpandDisk(ctx context.Context, diskPath string, size string) error {
+	cmd := exec.Command(d.VdiskManagerPath, "-x", size, diskPath)
+	return d.runVdiskManager(ctx, cmd)
+}
 
-	return false, nil
+func (d *Workstation9Driver) IsRunning(ctx context.Context, vmxPath string) (bool, error) {
+	return vmrunIsRunning(ctx, d, vmxPath)
 }
 
 func (d *Workstation9Driver) CommHost(state multistep.StateBag) (string, error) {
 	return CommHost(d.SSHConfig)(state)
 }
 
-func (d *Workstation9Driver) Start(vmxPath string, headless bool) error {
+func (d *Workstation9Driver) vmrun() (string, string) {
+	return d.VmrunPath, "ws"
+}
+
+func (d *Workstation9Driver) GuestIP(state multistep.StateBag) (string, error) {
+	return guestIPWithTools(&d.VmwareDriver, d, state)
+}
+
+func (d *Workstation9Driver) Start(ctx context.Context, vmxPath string, headless bool) error {
 	guiArgument := "gui"
 	if headless {
 		guiArgument = "nogui"
 	}
 
 	cmd := exec.Command(d.VmrunPath, "-T", "ws", "start", vmxPath, guiArgument)
-	if _, _, err := runAndLog(cmd); err != nil {
+	if _, _, err := d.runAndLog(ctx, cmd); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (d *Workstation9Driver) Stop(vmxPath string) error {
+func (d *Workstation9Driver) Stop(ctx context.Context, vmxPath string) error {
 	cmd := exec.Command(d.VmrunPath, "-T", "ws", "stop", vmxPath, "hard")
-	if _, _, err := runAndLog(cmd); err != nil {
+	if _, _, err := d.runAndLog(ctx, cmd); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (d *Workstation9Driver) CreateSnapshot(ctx context.Context, vmxPath string, name string) error {
+	cmd := exec.Command(d.VmrunPath, "-T", "ws", "snapshot", vmxPath, name)
+	if _, _, err := d.runAndLog(ctx, cmd); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (d *Workstation9Driver) DeleteSnapshot(ctx context.Context, vmxPath string, name string) error {
+	cmd := exec.Command(d.VmrunPath, "-T", "ws", "deleteSnapshot", vmxPath, name)
+	if _, _, err := d.runAndLog(ctx, cmd); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (d *Workstation9Driver) RevertToSnapshot(ctx context.Context, vmxPath string, name string) error {
+	cmd := exec.Command(d.VmrunPath, "-T", "ws", "revertToSnapshot", vmxPath, name)
+	if _, _, err := d.runAndLog(ctx, cmd); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (d *Workstation9Driver) Suspend(ctx context.Context, vmxPath string) error {
+	cmd := exec.Command(d.VmrunPath, "-T", "ws", "suspend", vmxPath)
+	if _, _, err := d.runAndLog(ctx, cmd); err != nil {
 		return err
 	}
 
@@ -13,6 +13,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"strings"
 )
 
 func workstationCheckLicense() error {
@@ -29,15 +30,66 @@ func workstationCheckLicense() error {
 }
 
 func workstationFindVdiskManager() (string, error) {
-	return exec.LookPath("vmware-vdiskmanager")
+	return findBinary("vmware-vdiskmanager", workstationProgramFilePaths())
 }
 
 func workstationFindVMware() (string, error) {
-	return exec.LookPath("vmware")
+	return findBinary("vmware", workstationProgramFilePaths())
 }
 
 func workstationFindVmrun() (string, error) {
-	return exec.LookPath("vmrun")
+	return findBinary("vmrun", workstationProgramFilePaths())
+}
+
+// workstationFindVmx locates the vmware-vmx binary, which is never on the
+// PATH and lives in the library directory of the installation.
+func workstationFindVmx() (string, error) {
+	paths := []string{"/usr/lib/vmware/bin", "/usr/local/lib/vmware/bin"}
+	if os.Getenv("VMWARE_HOME") != "" {
+		paths = append([]string{filepath.Join(os.Getenv("VMWARE_HOME"), "bin")}, paths...)
+	}
+
+	for _, path := range paths {
+		path = filepath.Join(path, "vmware-vmx")
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+
+	return "", fmt.Errorf("vmware-vmx not found in any of: %s", strings.Join(paths, ", "))
+}
+
+// workstationProgramFilePaths returns a list of paths that are eligible
+// to contain the VMware binaries when they can't be found on the PATH.
+func workstationProgramFilePaths() []string {
+	paths := make([]string, 0, 3)
+	if os.Getenv("VMWARE_HOME") != "" {
+		paths = append(paths, os.Getenv("VMWARE_HOME"))
+	}
+
+	return append(paths, "/usr/bin", "/usr/local/bin")
+}
+
+// findBinary looks for the named binary on the PATH and then falls back
+// to searching the given list of directories.
+func findBinary(file string, paths []string) (string, error) {
+	path, err := exec.LookPath(file)
+	if err == nil {
+		return path, nil
+	}
+
+	for _, path := range paths {
+		path = filepath.Join(path, file)
+		log.Printf("Searching for file '%s'", path)
+
+		if _, err := os.Stat(path); err == nil {
+			log.Printf("Found file '%s'", path)
+			return path, nil
+		}
+	}
+
+	return "", fmt.Errorf("%s not found on the PATH or in any of: %s",
+		file, strings.Join(paths, ", "))
 }
 
 // return the base path to vmware's config on the host
@@ -119,32 +171,35 @@ func workstationToolsIsoPath(flavor string) string {
 	return "/usr/lib/vmware/isoimages/" + flavor + ".iso"
 }
 
-func workstationVerifyVersion(version string) error {
+func workstationVerifyVersion(version string) (string, error) {
 	if runtime.GOOS != "linux" {
-		return fmt.Errorf("The VMware WS version %s driver is only supported on Linux, and Windows, at the moment. Your OS: %s", version, runtime.GOOS)
+		return "", fmt.Errorf("The VMware WS version %s driver is only supported on Linux, and Windows, at the moment. Your OS: %s", version, runtime.GOOS)
 	}
 
-	//TODO(pmyjavec) there is a better way to find this, how?
-	//the default will suffice for now.
-	vmxpath := "/usr/lib/vmware/bin/vmware-vmx"
+	vmxpath, err := workstationFindVmx()
+	if err != nil {
+		return "", err
+	}
 
 	var stderr bytes.Buffer
 	cmd := exec.Command(vmxpath, "-v")
 	cmd.Stderr = &stderr
 	if err := cmd.Run(); err != nil {
-		return err
+		return "", err
 	}
 	return workstationTestVersion(version, stderr.String())
 }
 
-func workstationTestVersion(wanted, versionOutput string) error {
+// workstationTestVersion returns the major version found in the output of
+// vmware-vmx -v, and an error if it is older than the wanted version.
+func workstationTestVersion(wanted, versionOutput string) (string, error) {
 	versionRe := regexp.MustCompile(`(?i)VMware Workstation (\d+)\.`)
 	matches := versionRe.FindStringSubmatch(versionOutput)
 	if matches == nil {
-		return fmt.Errorf(
+		return "", fmt.Errorf(
 			"Could not find VMware WS version in output: %s", wanted)
 	}
 	log.Printf("Detected VMware WS version: %s", matches[1])
 
-	return compareVersions(matches[1], wanted, "Workstation")
+	return matches[1], compareVersions(matches[1], wanted, "Workstation")
 }
@@ -3,13 +3,56 @@
 package common
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
 func TestWorkstationVersion_ws14(t *testing.T) {
 	input := `VMware Workstation Information:
 VMware Workstation 14.1.1 build-7528167 Release`
-	if err := workstationTestVersion("10", input); err != nil {
+	version, err := workstationTestVersion("10", input)
+	if err != nil {
 		t.Fatal(err)
 	}
+	if version != "14" {
+		t.Fatalf("bad version: %s", version)
+	}
+}
+
+func TestFindBinary_fallbackPaths(t *testing.T) {
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	expected := filepath.Join(td, "packer-fake-vmrun")
+	if err := ioutil.WriteFile(expected, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	path, err := findBinary("packer-fake-vmrun", []string{"/nonexistent", td})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if path != expected {
+		t.Fatalf("bad path: %s", path)
+	}
+
+	if _, err := findBinary("packer-missing-vmrun", []string{td}); err == nil {
+		t.Fatal("should error for a missing binary")
+	}
+}
+
+func TestWorkstationProgramFilePaths_vmwareHome(t *testing.T) {
+	old := os.Getenv("VMWARE_HOME")
+	defer os.Setenv("VMWARE_HOME", old)
+
+	os.Setenv("VMWARE_HOME", "/opt/vmware")
+	paths := workstationProgramFilePaths()
+	if len(paths) == 0 || paths[0] != "/opt/vmware" {
+		t.Fatalf("VMWARE_HOME should be searched first: %#v", paths)
+	}
 }
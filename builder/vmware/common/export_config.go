@@ -12,6 +12,13 @@ type ExportConfig struct {
 	SkipExport     bool     `mapstructure:"skip_export"`
 	KeepRegistered bool     `mapstructure:"keep_registered"`
 	SkipCompaction bool     `mapstructure:"skip_compaction"`
+
+	// Mark the VM kept on the ESXi host as a template.
+	ConvertToTemplate bool `mapstructure:"convert_to_template"`
+
+	// Run in the guest before shutdown to zero its free disk space, so that
+	// compaction can reclaim it.
+	ZeroFillCommand string `mapstructure:"zero_fill_command"`
 }
 
 func (c *ExportConfig) Prepare(ctx *interpolate.Context) []error {
@@ -22,5 +29,9 @@ func (c *ExportConfig) Prepare(ctx *interpolate.Context) []error {
 				errs, fmt.Errorf("format must be one of ova, ovf, or vmx"))
 		}
 	}
+	if c.ConvertToTemplate && !c.KeepRegistered {
+		errs = append(
+			errs, fmt.Errorf("convert_to_template requires keep_registered to be true"))
+	}
 	return errs
 }
@@ -0,0 +1,19 @@
+package common
+
+import (
+	"testing"
+)
+
+func TestExportConfigPrepare_ConvertToTemplate(t *testing.T) {
+	c := &ExportConfig{ConvertToTemplate: true}
+	errs := c.Prepare(testConfigTemplate(t))
+	if len(errs) == 0 {
+		t.Fatal("should have error without keep_registered")
+	}
+
+	c = &ExportConfig{ConvertToTemplate: true, KeepRegistered: true}
+	errs = c.Prepare(testConfigTemplate(t))
+	if len(errs) > 0 {
+		t.Fatalf("err: %#v", errs)
+	}
+}
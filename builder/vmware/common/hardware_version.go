@@ -0,0 +1,90 @@
+package common
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// maxHardwareVersions maps the major version of a local VMware product to
+// the newest virtual hardware version it can run. See
+// https://kb.vmware.com/s/article/1003746.
+var maxHardwareVersions = map[string]map[int]int{
+	"Fusion": {
+		6:  10,
+		7:  11,
+		8:  12,
+		10: 14,
+		11: 16,
+		12: 19,
+		13: 20,
+	},
+	"Workstation": {
+		10: 10,
+		11: 11,
+		12: 12,
+		14: 14,
+		15: 16,
+		16: 18,
+		17: 20,
+	},
+}
+
+// productVersioner is implemented by the drivers that know the major version
+// of the VMware product once Verify has run.
+type productVersioner interface {
+	productVersion() (string, string)
+}
+
+// MaxHardwareVersion returns the newest virtual hardware version that the
+// given release of a VMware product supports. The last value is false if
+// the release is unknown. Releases newer than the known ones are assumed to
+// support at least what the newest known release does.
+func MaxHardwareVersion(product, version string) (int, bool) {
+	versions, ok := maxHardwareVersions[product]
+	if !ok {
+		return 0, false
+	}
+
+	major, err := strconv.Atoi(version)
+	if err != nil {
+		return 0, false
+	}
+
+	max, newest := 0, 0
+	for release, hw := range versions {
+		if release <= major && release > newest {
+			max, newest = hw, release
+		}
+	}
+	return max, newest != 0
+}
+
+// VerifyHardwareVersion returns an error if the driver's VMware product can't
+// run virtual machines with the given virtual hardware version. Drivers that
+// don't report their product version, such as the remote ones, are not
+// checked.
+func VerifyHardwareVersion(driver Driver, version string) error {
+	hw, err := strconv.Atoi(version)
+	if err != nil {
+		return fmt.Errorf("Virtual hardware version '%s' is not numeric", version)
+	}
+
+	pv, ok := driver.(productVersioner)
+	if !ok {
+		return nil
+	}
+
+	product, productVersion := pv.productVersion()
+	max, ok := MaxHardwareVersion(product, productVersion)
+	if !ok {
+		return nil
+	}
+
+	if hw > max {
+		return fmt.Errorf(
+			"Virtual hardware version %d is not supported by VMware %s %s, "+
+				"which supports up to version %d", hw, product, productVersion, max)
+	}
+
+	return nil
+}
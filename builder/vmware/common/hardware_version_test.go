@@ -0,0 +1,49 @@
+package common
+
+import (
+	"testing"
+)
+
+func TestMaxHardwareVersion(t *testing.T) {
+	cases := []struct {
+		product string
+		version string
+		max     int
+		ok      bool
+	}{
+		{"Fusion", "8", 12, true},
+		{"Fusion", "9", 12, true},
+		{"Fusion", "5", 0, false},
+		{"Fusion", "e.x.p", 0, false},
+		{"Workstation", "15", 16, true},
+		{"Workstation", "99", 20, true},
+		{"Player", "12", 0, false},
+	}
+
+	for _, tc := range cases {
+		max, ok := MaxHardwareVersion(tc.product, tc.version)
+		if max != tc.max || ok != tc.ok {
+			t.Errorf("%s %s: got %d %t, expected %d %t",
+				tc.product, tc.version, max, ok, tc.max, tc.ok)
+		}
+	}
+}
+
+func TestVerifyHardwareVersion(t *testing.T) {
+	driver := &Workstation10Driver{version: "12"}
+
+	if err := VerifyHardwareVersion(driver, "11"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := VerifyHardwareVersion(driver, "14"); err == nil {
+		t.Fatal("should error for a version newer than the product supports")
+	}
+	if err := VerifyHardwareVersion(driver, "foo"); err == nil {
+		t.Fatal("should error for a non-numeric version")
+	}
+
+	// Drivers that don't report a product version aren't checked
+	if err := VerifyHardwareVersion(new(DriverMock), "99"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
@@ -12,13 +12,15 @@ import (
 type HWConfig struct {
 
 	// cpu information
-	CpuCount   int `mapstructure:"cpus"`
-	MemorySize int `mapstructure:"memory"`
-	CoreCount  int `mapstructure:"cores"`
+	CpuCount       int `mapstructure:"cpus"`
+	MemorySize     int `mapstructure:"memory"`
+	CoreCount      int `mapstructure:"cores"`
+	CoresPerSocket int `mapstructure:"cores_per_socket"`
 
 	// network type and adapter
-	Network            string `mapstructure:"network"`
-	NetworkAdapterType string `mapstructure:"network_adapter_type"`
+	Network            string           `mapstructure:"network"`
+	NetworkAdapterType string           `mapstructure:"network_adapter_type"`
+	NetworkAdapters    []NetworkAdapter `mapstructure:"network_adapters"`
 
 	// device presence
 	Sound bool `mapstructure:"sound"`
@@ -27,23 +29,48 @@ type HWConfig struct {
 	// communication ports
 	Serial   string `mapstructure:"serial"`
 	Parallel string `mapstructure:"parallel"`
+
+	// firmware type, either bios, efi or efi-secure
+	Firmware string `mapstructure:"firmware"`
+
+	// virtual TPM device
+	VTPM bool `mapstructure:"vtpm"`
+
+	// nested virtualization
+	VHVEnabled bool `mapstructure:"vhv_enabled"`
 }
 
 func (c *HWConfig) Prepare(ctx *interpolate.Context) []error {
 	var errs []error
 
 	// Hardware and cpu options
-	if c.CpuCount < 0 {
-		errs = append(errs, fmt.Errorf("An invalid number of cpus was specified (cpus < 0): %d", c.CpuCount))
+	var cpuErrs []error
+	c.CoreCount, cpuErrs = PrepareCPUs(c.CpuCount, c.CoreCount, c.CoresPerSocket, c.MemorySize)
+	errs = append(errs, cpuErrs...)
+
+	if c.NetworkAdapterType != "" && !ValidNetworkAdapterType(c.NetworkAdapterType) {
+		errs = append(errs, fmt.Errorf("An invalid network_adapter_type was specified: %s", c.NetworkAdapterType))
 	}
 
-	if c.MemorySize < 0 {
-		errs = append(errs, fmt.Errorf("An invalid amount of memory was specified (memory < 0): %d", c.MemorySize))
+	if len(c.NetworkAdapters) > 0 {
+		if c.Network != "" || c.NetworkAdapterType != "" {
+			errs = append(errs, fmt.Errorf("network and network_adapter_type can't be used with network_adapters"))
+		}
+		errs = append(errs, PrepareNetworkAdapters(c.NetworkAdapters)...)
+
+		// The first adapter is the one Packer connects to the guest through
+		c.Network = c.NetworkAdapters[0].Network
+		c.NetworkAdapterType = c.NetworkAdapters[0].AdapterType
 	}
 
-	// Hardware and cpu options
-	if c.CoreCount < 0 {
-		errs = append(errs, fmt.Errorf("An invalid number of cores was specified (cores < 0): %d", c.CoreCount))
+	switch c.Firmware {
+	case "", "bios", "efi", "efi-secure":
+	default:
+		errs = append(errs, fmt.Errorf("firmware must be one of bios, efi or efi-secure: %s", c.Firmware))
+	}
+
+	if c.VTPM && !strings.HasPrefix(c.Firmware, "efi") {
+		errs = append(errs, fmt.Errorf("vtpm requires firmware to be efi or efi-secure"))
 	}
 
 	// Peripherals
@@ -66,6 +93,49 @@ func (c *HWConfig) Prepare(ctx *interpolate.Context) []error {
 	return errs
 }
 
+// PrepareCPUs validates the number of cpus, cores per socket and the memory
+// size of a VM, where zero means the default. cores_per_socket is an alias
+// of cores, and the resulting number of cores per socket is returned.
+func PrepareCPUs(cpus, cores, coresPerSocket, memory int) (int, []error) {
+	var errs []error
+
+	if cpus < 0 {
+		errs = append(errs, fmt.Errorf("An invalid number of cpus was specified (cpus < 0): %d", cpus))
+	}
+
+	if memory < 0 {
+		errs = append(errs, fmt.Errorf("An invalid amount of memory was specified (memory < 0): %d", memory))
+	}
+
+	if coresPerSocket != 0 {
+		if cores != 0 && cores != coresPerSocket {
+			errs = append(errs, fmt.Errorf("cores and cores_per_socket can't both be specified"))
+		}
+		cores = coresPerSocket
+	}
+
+	if cores < 0 {
+		errs = append(errs, fmt.Errorf("An invalid number of cores was specified (cores < 0): %d", cores))
+	}
+
+	// The cpus are spread evenly over the sockets
+	if cpus > 0 && cores > 0 && cpus%cores != 0 {
+		errs = append(errs, fmt.Errorf("The number of cpus (%d) must be a multiple of the cores per socket (%d)", cpus, cores))
+	}
+
+	return cores, errs
+}
+
+// ValidNetworkAdapterType returns whether the network adapter type is one of
+// the virtual devices VMware supports.
+func ValidNetworkAdapterType(adapterType string) bool {
+	switch strings.ToLower(adapterType) {
+	case "e1000", "e1000e", "vmxnet", "vmxnet3", "vlance":
+		return true
+	}
+	return false
+}
+
 /* parallel port */
 type ParallelUnion struct {
 	Union  interface{}
@@ -182,6 +252,21 @@ func (c *HWConfig) HasSerial() bool {
 	return c.Serial != ""
 }
 
+// SerialLogPath returns the host file that the serial port writes to, or an
+// empty string if the serial port isn't connected to a file. VMware resolves
+// relative paths against the directory of the VM.
+func (c *HWConfig) SerialLogPath(vmDir string) string {
+	serial, err := c.ReadSerial()
+	if err != nil || serial == nil || serial.File == nil {
+		return ""
+	}
+
+	if filepath.IsAbs(serial.File.Filename) {
+		return serial.File.Filename
+	}
+	return filepath.Join(vmDir, serial.File.Filename)
+}
+
 func (c *HWConfig) ReadSerial() (*SerialUnion, error) {
 	var defaultSerialPort string
 	if runtime.GOOS == "windows" {
@@ -239,7 +324,9 @@ func (c *HWConfig) ReadSerial() (*SerialUnion, error) {
 
 		res.Filename = filepath.FromSlash(comp[0])
 
-		res.Yield = map[bool]string{true: strings.ToUpper(comp[1]), false: "FALSE"}[len(comp) > 1]
+		if len(comp) > 1 {
+			res.Yield = strings.ToUpper(comp[1])
+		}
 		if res.Yield != "TRUE" && res.Yield != "FALSE" {
 			return nil, fmt.Errorf("Unexpected format for yield in serial port file: %s -> %s", c.Serial, res.Yield)
 		}
@@ -1,6 +1,7 @@
 package common
 
 import (
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -52,6 +53,64 @@ func TestHWConfigPrepare(t *testing.T) {
 	}
 }
 
+func TestHWConfigPrepare_CPUs(t *testing.T) {
+	c := &HWConfig{CpuCount: 4, CoresPerSocket: 2, MemorySize: 4096}
+	if errs := c.Prepare(testConfigTemplate(t)); len(errs) > 0 {
+		t.Fatalf("err: %#v", errs)
+	}
+	if c.CoreCount != 2 {
+		t.Fatalf("bad core count: %d", c.CoreCount)
+	}
+
+	bad := []*HWConfig{
+		{CpuCount: -1},
+		{MemorySize: -1},
+		{CoreCount: -1},
+		{CpuCount: 4, CoreCount: 3},
+		{CpuCount: 4, CoreCount: 2, CoresPerSocket: 4},
+	}
+	for _, c := range bad {
+		if errs := c.Prepare(testConfigTemplate(t)); len(errs) == 0 {
+			t.Fatalf("should have error: %#v", c)
+		}
+	}
+}
+
+func TestHWConfigPrepare_NetworkAdapterType(t *testing.T) {
+	c := &HWConfig{NetworkAdapterType: "vmxnet3"}
+	if errs := c.Prepare(testConfigTemplate(t)); len(errs) > 0 {
+		t.Fatalf("err: %#v", errs)
+	}
+
+	c = &HWConfig{NetworkAdapterType: "rtl8139"}
+	if errs := c.Prepare(testConfigTemplate(t)); len(errs) == 0 {
+		t.Fatal("should have error")
+	}
+}
+
+func TestHWConfigPrepare_NetworkAdapters(t *testing.T) {
+	c := &HWConfig{
+		NetworkAdapters: []NetworkAdapter{
+			{Network: "vmnet2", AdapterType: "vmxnet3"},
+			{Network: "bridged"},
+		},
+	}
+	if errs := c.Prepare(testConfigTemplate(t)); len(errs) > 0 {
+		t.Fatalf("err: %#v", errs)
+	}
+	if c.Network != "vmnet2" || c.NetworkAdapterType != "vmxnet3" {
+		t.Fatalf("bad: %s %s", c.Network, c.NetworkAdapterType)
+	}
+
+	c = &HWConfig{
+		Network:         "nat",
+		NetworkAdapters: []NetworkAdapter{{Network: "bridged"}},
+	}
+	if errs := c.Prepare(testConfigTemplate(t)); len(errs) == 0 {
+		t.Fatal("should have error")
+	}
+}
+
 func TestHWConfigParallel_File(t *testing.T) {
 	c := new(HWConfig)
 
@@ -322,3 +381,19 @@ func TestHWConfigSerial_None(t *testing.T) {
 		t.Errorf("serial port shouldn't exist")
 	}
 }
+
+func TestHWConfigSerialLogPath(t *testing.T) {
+	cases := map[string]string{
+		"none":                        "",
+		"FILE:serial.log":             filepath.Join("output", "serial.log"),
+		"FILE:/tmp/serial.log":        filepath.FromSlash("/tmp/serial.log"),
+		"PIPE:/tmp/serial,client,app": "",
+	}
+
+	for serial, expected := range cases {
+		c := &HWConfig{Serial: serial}
+		if actual := c.SerialLogPath("output"); actual != expected {
+			t.Fatalf("bad path for %s: %s", serial, actual)
+		}
+	}
+}
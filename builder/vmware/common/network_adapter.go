@@ -0,0 +1,105 @@
+package common
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// NetworkAdapter describes one of the network adapters of the VM.
+type NetworkAdapter struct {
+	Network     string `mapstructure:"network"`
+	AdapterType string `mapstructure:"adapter_type"`
+	MACAddress  string `mapstructure:"mac_address"`
+}
+
+// Prepare validates the adapter, the index is its position in
+// network_adapters.
+func (a *NetworkAdapter) Prepare(index int) []error {
+	if a.Network == "" {
+		a.Network = "nat"
+	}
+
+	var errs []error
+	if a.AdapterType != "" && !ValidNetworkAdapterType(a.AdapterType) {
+		errs = append(errs, fmt.Errorf(
+			"network_adapters[%d]: An invalid adapter_type was specified: %s", index, a.AdapterType))
+	}
+
+	if a.MACAddress != "" {
+		if err := validateStaticMAC(a.MACAddress); err != nil {
+			errs = append(errs, fmt.Errorf("network_adapters[%d]: %s", index, err))
+		}
+	}
+
+	return errs
+}
+
+// PrepareNetworkAdapters validates all of the adapters and makes sure no
+// static MAC address is assigned to more than one of them.
+func PrepareNetworkAdapters(adapters []NetworkAdapter) []error {
+	var errs []error
+	seen := make(map[string]int)
+	for i := range adapters {
+		errs = append(errs, adapters[i].Prepare(i)...)
+
+		hw, err := net.ParseMAC(adapters[i].MACAddress)
+		if err != nil {
+			continue
+		}
+		if j, ok := seen[hw.String()]; ok {
+			errs = append(errs, fmt.Errorf(
+				"network_adapters[%d]: mac_address %s is already used by network_adapters[%d]",
+				i, adapters[i].MACAddress, j))
+			continue
+		}
+		seen[hw.String()] = i
+	}
+
+	return errs
+}
+
+// VMXData returns the VMX settings for the adapter as ethernet<index>. The
+// network is either one of the generic types nat, bridged and hostonly, or
+// the name of a VMware network device.
+func (a *NetworkAdapter) VMXData(index int) map[string]string {
+	prefix := fmt.Sprintf("ethernet%d.", index)
+
+	data := map[string]string{
+		prefix + "present": "TRUE",
+	}
+
+	switch a.Network {
+	case "":
+	case "nat", "bridged", "hostonly":
+		data[prefix+"connectiontype"] = a.Network
+	default:
+		data[prefix+"connectiontype"] = "custom"
+		data[prefix+"vnet"] = a.Network
+	}
+
+	if a.AdapterType != "" {
+		data[prefix+"virtualdev"] = strings.ToLower(a.AdapterType)
+	}
+
+	if a.MACAddress != "" {
+		data[prefix+"addresstype"] = "static"
+		data[prefix+"address"] = a.MACAddress
+	}
+
+	return data
+}
+
+// validateStaticMAC makes sure VMware accepts the MAC address as a static
+// address, which must be in the range 00:50:56:00:00:00-00:50:56:3F:FF:FF.
+func validateStaticMAC(mac string) error {
+	hw, err := net.ParseMAC(mac)
+	if err != nil {
+		return fmt.Errorf("Invalid mac_address: %s", err)
+	}
+
+	if len(hw) != 6 || hw[0] != 0x00 || hw[1] != 0x50 || hw[2] != 0x56 || hw[3] > 0x3f {
+		return fmt.Errorf("mac_address must be between 00:50:56:00:00:00 and 00:50:56:3F:FF:FF, got: %s", mac)
+	}
+	return nil
+}
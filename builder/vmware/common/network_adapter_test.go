@@ -0,0 +1,81 @@
+package common
+
+import (
+	"testing"
+)
+
+func TestNetworkAdapterPrepare(t *testing.T) {
+	a := &NetworkAdapter{}
+	if errs := a.Prepare(0); len(errs) > 0 {
+		t.Fatalf("err: %#v", errs)
+	}
+	if a.Network != "nat" {
+		t.Fatalf("bad network: %s", a.Network)
+	}
+
+	a = &NetworkAdapter{AdapterType: "e1000e", MACAddress: "00:50:56:3f:00:01"}
+	if errs := a.Prepare(0); len(errs) > 0 {
+		t.Fatalf("err: %#v", errs)
+	}
+
+	bad := []NetworkAdapter{
+		{AdapterType: "rtl8139"},
+		{MACAddress: "not a mac"},
+		{MACAddress: "00:0c:29:00:00:01"},
+		{MACAddress: "00:50:56:40:00:01"},
+	}
+	for _, a := range bad {
+		if errs := a.Prepare(0); len(errs) == 0 {
+			t.Fatalf("should have error: %#v", a)
+		}
+	}
+}
+
+func TestPrepareNetworkAdapters(t *testing.T) {
+	adapters := []NetworkAdapter{
+		{MACAddress: "00:50:56:00:00:01"},
+		{MACAddress: "00:50:56:00:00:02"},
+		{},
+		{},
+	}
+	if errs := PrepareNetworkAdapters(adapters); len(errs) > 0 {
+		t.Fatalf("err: %#v", errs)
+	}
+
+	adapters = []NetworkAdapter{
+		{MACAddress: "00:50:56:00:00:01"},
+		{MACAddress: "00-50-56-00-00-01"},
+	}
+	if errs := PrepareNetworkAdapters(adapters); len(errs) != 1 {
+		t.Fatalf("should error for a duplicate mac_address: %#v", errs)
+	}
+}
+
+func TestNetworkAdapterVMXData(t *testing.T) {
+	a := &NetworkAdapter{Network: "bridged", AdapterType: "E1000E"}
+	data := a.VMXData(0)
+	if data["ethernet0.present"] != "TRUE" {
+		t.Fatalf("bad: %#v", data)
+	}
+	if data["ethernet0.connectiontype"] != "bridged" {
+		t.Fatalf("bad: %#v", data)
+	}
+	if data["ethernet0.virtualdev"] != "e1000e" {
+		t.Fatalf("bad: %#v", data)
+	}
+	if _, ok := data["ethernet0.vnet"]; ok {
+		t.Fatalf("bad: %#v", data)
+	}
+
+	a = &NetworkAdapter{Network: "vmnet2", MACAddress: "00:50:56:00:00:01"}
+	data = a.VMXData(2)
+	if data["ethernet2.connectiontype"] != "custom" || data["ethernet2.vnet"] != "vmnet2" {
+		t.Fatalf("bad: %#v", data)
+	}
+	if data["ethernet2.addresstype"] != "static" || data["ethernet2.address"] != "00:50:56:00:00:01" {
+		t.Fatalf("bad: %#v", data)
+	}
+	if _, ok := data["ethernet2.virtualdev"]; ok {
+		t.Fatalf("bad: %#v", data)
+	}
+}
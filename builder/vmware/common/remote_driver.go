@@ -17,6 +17,10 @@ type RemoteDriver interface {
 	// Removes a VM from inventory specified by the path to the VMX given.
 	Unregister(string) error
 
+	// Converts the registered VM into a template, keeping it in the
+	// inventory.
+	ConvertToTemplate(string) error
+
 	// Destroys a VM
 	Destroy() error
 
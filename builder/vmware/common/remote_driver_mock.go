@@ -16,6 +16,10 @@ type RemoteDriverMock struct {
 	UnregisterPath   string
 	UnregisterErr    error
 
+	ConvertToTemplateCalled bool
+	ConvertToTemplatePath   string
+	ConvertToTemplateErr    error
+
 	DestroyCalled bool
 	DestroyErr    error
 
@@ -47,6 +51,12 @@ func (d *RemoteDriverMock) Unregister(path string) error {
 	return d.UnregisterErr
 }
 
+func (d *RemoteDriverMock) ConvertToTemplate(path string) error {
+	d.ConvertToTemplateCalled = true
+	d.ConvertToTemplatePath = path
+	return d.ConvertToTemplateErr
+}
+
 func (d *RemoteDriverMock) Destroy() error {
 	d.DestroyCalled = true
 	return d.DestroyErr
@@ -13,6 +13,11 @@ type RunConfig struct {
 	VNCPortMin         int    `mapstructure:"vnc_port_min"`
 	VNCPortMax         int    `mapstructure:"vnc_port_max"`
 	VNCDisablePassword bool   `mapstructure:"vnc_disable_password"`
+	VNCPassword        string `mapstructure:"vnc_password"`
+
+	// How to type the boot command: over "vnc", or with USB scan codes
+	// through the "vsphere" API.
+	BootCommandTransport string `mapstructure:"boot_command_transport"`
 }
 
 func (c *RunConfig) Prepare(ctx *interpolate.Context) (errs []error) {
@@ -24,6 +29,10 @@ func (c *RunConfig) Prepare(ctx *interpolate.Context) (errs []error) {
 		c.VNCPortMax = 6000
 	}
 
+	if c.BootCommandTransport == "" {
+		c.BootCommandTransport = "vnc"
+	}
+
 	if c.VNCBindAddress == "" {
 		c.VNCBindAddress = "127.0.0.1"
 	}
@@ -34,6 +43,20 @@ func (c *RunConfig) Prepare(ctx *interpolate.Context) (errs []error) {
 	if c.VNCPortMin < 0 {
 		errs = append(errs, fmt.Errorf("vnc_port_min must be positive"))
 	}
+	if c.VNCPortMax > 65535 {
+		errs = append(errs, fmt.Errorf("vnc_port_max must be at most 65535"))
+	}
+
+	// VMware only uses the first 8 characters of the VNC password
+	if len(c.VNCPassword) > 8 {
+		errs = append(errs, fmt.Errorf("vnc_password must be at most 8 characters"))
+	}
+	if c.VNCPassword != "" && c.VNCDisablePassword {
+		errs = append(errs, fmt.Errorf("vnc_password can't be used with vnc_disable_password"))
+	}
+	if c.BootCommandTransport != "vnc" && c.BootCommandTransport != "vsphere" {
+		errs = append(errs, fmt.Errorf("boot_command_transport must be one of vnc or vsphere, got %s", c.BootCommandTransport))
+	}
 
 	return
 }
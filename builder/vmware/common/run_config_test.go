@@ -0,0 +1,63 @@
+package common
+
+import (
+	"testing"
+)
+
+func TestRunConfigPrepare(t *testing.T) {
+	var c RunConfig
+	if errs := c.Prepare(testConfigTemplate(t)); len(errs) > 0 {
+		t.Fatalf("err: %#v", errs)
+	}
+	if c.VNCPortMin != 5900 || c.VNCPortMax != 6000 {
+		t.Fatalf("bad ports: %d-%d", c.VNCPortMin, c.VNCPortMax)
+	}
+	if c.VNCBindAddress != "127.0.0.1" {
+		t.Fatalf("bad address: %s", c.VNCBindAddress)
+	}
+	if c.BootCommandTransport != "vnc" {
+		t.Fatalf("bad transport: %s", c.BootCommandTransport)
+	}
+}
+
+func TestRunConfigPrepare_BootCommandTransport(t *testing.T) {
+	c := RunConfig{BootCommandTransport: "vsphere"}
+	if errs := c.Prepare(testConfigTemplate(t)); len(errs) > 0 {
+		t.Fatalf("err: %#v", errs)
+	}
+
+	c = RunConfig{BootCommandTransport: "serial"}
+	if errs := c.Prepare(testConfigTemplate(t)); len(errs) == 0 {
+		t.Fatal("should have error")
+	}
+}
+
+func TestRunConfigPrepare_VNCPassword(t *testing.T) {
+	c := RunConfig{VNCPassword: "packer"}
+	if errs := c.Prepare(testConfigTemplate(t)); len(errs) > 0 {
+		t.Fatalf("err: %#v", errs)
+	}
+
+	bad := []RunConfig{
+		{VNCPassword: "toolongpassword"},
+		{VNCPassword: "packer", VNCDisablePassword: true},
+	}
+	for _, c := range bad {
+		if errs := c.Prepare(testConfigTemplate(t)); len(errs) == 0 {
+			t.Fatalf("should have error: %#v", c)
+		}
+	}
+}
+
+func TestRunConfigPrepare_VNCPorts(t *testing.T) {
+	bad := []RunConfig{
+		{VNCPortMin: 6000, VNCPortMax: 5900},
+		{VNCPortMin: -1, VNCPortMax: 5900},
+		{VNCPortMin: 5900, VNCPortMax: 70000},
+	}
+	for _, c := range bad {
+		if errs := c.Prepare(testConfigTemplate(t)); len(errs) == 0 {
+			t.Fatalf("should have error: %#v", c)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package common
+
+import (
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// runnerMock is a commandRunner that records the commands the drivers run
+// and answers them with canned output instead of running them.
+type runnerMock struct {
+	sync.Mutex
+
+	// Commands are the arguments of every command that was run, without
+	// the path of the command.
+	Commands [][]string
+
+	// Responses are the outputs of the commands in the order they are run.
+	// Commands without a response succeed without output.
+	Responses []runnerResponse
+}
+
+type runnerResponse struct {
+	Stdout string
+	Stderr string
+	Failed bool
+}
+
+func (r *runnerMock) Run(cmd *exec.Cmd) error {
+	r.Lock()
+	defer r.Unlock()
+
+	r.Commands = append(r.Commands, cmd.Args[1:])
+
+	var resp runnerResponse
+	if len(r.Responses) > 0 {
+		resp, r.Responses = r.Responses[0], r.Responses[1:]
+	}
+
+	if cmd.Stdout != nil {
+		io.WriteString(cmd.Stdout, resp.Stdout)
+	}
+	if cmd.Stderr != nil {
+		io.WriteString(cmd.Stderr, resp.Stderr)
+	}
+	if resp.Failed {
+		return &exec.ExitError{}
+	}
+	return nil
+}
+
+// withRunnerMock makes the drivers use a runnerMock with the given
+// responses until the returned function is called.
+func withRunnerMock(responses ...runnerResponse) (*runnerMock, func()) {
+	r := &runnerMock{Responses: responses}
+	old := runner
+	runner = r
+	return r, func() { runner = old }
+}
+
+// testFixture returns the contents of a file in test-fixtures.
+func testFixture(t *testing.T, name string) string {
+	data, err := ioutil.ReadFile(filepath.Join("test-fixtures", name))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	return string(data)
+}
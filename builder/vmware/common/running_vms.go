@@ -0,0 +1,80 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// RunningVMs returns the paths to the VMX files of the VMs that `vmrun list`
+// reports as running. Only the drivers for a local VMware installation
+// support this.
+func RunningVMs(ctx context.Context, driver Driver) ([]string, error) {
+	vd, ok := driver.(vmrunDriver)
+	if !ok {
+		return nil, fmt.Errorf("listing running VMs isn't supported by this VMware driver")
+	}
+	return vmrunList(ctx, vd)
+}
+
+func vmrunList(ctx context.Context, vd vmrunDriver) ([]string, error) {
+	vmrunPath, hostType := vd.vmrun()
+	cmd := exec.Command(vmrunPath, "-T", hostType, "list")
+	stdout, _, err := vd.runAndLog(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	return parseVmrunList(stdout), nil
+}
+
+// parseVmrunList parses the output of `vmrun list`, which is a
+// "Total running VMs: N" header followed by the path of each VMX.
+func parseVmrunList(out string) []string {
+	var vms []string
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "Total running VMs:") {
+			continue
+		}
+		vms = append(vms, line)
+	}
+	return vms
+}
+
+// vmrunIsRunning implements Driver.IsRunning for the drivers that use
+// vmrun.
+func vmrunIsRunning(ctx context.Context, vd vmrunDriver, vmxPath string) (bool, error) {
+	vms, err := vmrunList(ctx, vd)
+	if err != nil {
+		return false, err
+	}
+
+	foldCase := runtime.GOOS == "darwin" || runtime.GOOS == "windows"
+	vmxPath = canonicalPath(vmxPath, foldCase)
+	for _, vm := range vms {
+		if canonicalPath(vm, foldCase) == vmxPath {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// canonicalPath makes path absolute and resolves symlinks so that paths
+// to the same file compare equal. The default file systems of macOS and
+// Windows are case-insensitive, so there the case is folded too.
+func canonicalPath(path string, foldCase bool) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	path = filepath.Clean(path)
+	if foldCase {
+		path = strings.ToLower(path)
+	}
+	return path
+}
@@ -0,0 +1,94 @@
+package common
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestParseVmrunList(t *testing.T) {
+	out := "Total running VMs: 2\n/vms/foo/foo.vmx \n/vms/bar/bar.vmx\r\n\n"
+	vms := parseVmrunList(out)
+	expected := []string{"/vms/foo/foo.vmx", "/vms/bar/bar.vmx"}
+	if !reflect.DeepEqual(vms, expected) {
+		t.Fatalf("bad: %#v", vms)
+	}
+
+	if vms := parseVmrunList("Total running VMs: 0\n"); len(vms) != 0 {
+		t.Fatalf("bad: %#v", vms)
+	}
+}
+
+func TestCanonicalPath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires symlinks")
+	}
+
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	vmx := filepath.Join(td, "vm", "packer.vmx")
+	if err := os.MkdirAll(filepath.Dir(vmx), 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := ioutil.WriteFile(vmx, nil, 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	link := filepath.Join(td, "link")
+	if err := os.Symlink(filepath.Join(td, "vm"), link); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if canonicalPath(filepath.Join(link, "packer.vmx"), false) != canonicalPath(vmx, false) {
+		t.Fatal("symlinked paths should be equal")
+	}
+	if canonicalPath(filepath.Join(td, "vm", "..", "vm", "packer.vmx"), false) != canonicalPath(vmx, false) {
+		t.Fatal("unclean paths should be equal")
+	}
+	if canonicalPath("/VMs/Packer.vmx", true) != canonicalPath("/vms/packer.vmx", true) {
+		t.Fatal("paths should be equal when folding case")
+	}
+	if canonicalPath("/VMs/Packer.vmx", false) == canonicalPath("/vms/packer.vmx", false) {
+		t.Fatal("paths should differ when not folding case")
+	}
+}
+
+func TestVmrunList_fixture(t *testing.T) {
+	r, restore := withRunnerMock(
+		runnerResponse{Stdout: testFixture(t, "vmrun-list.txt")},
+		runnerResponse{Stdout: testFixture(t, "vmrun-list-empty.txt")},
+	)
+	defer restore()
+
+	driver := &Workstation9Driver{VmrunPath: "vmrun"}
+	vms, err := RunningVMs(context.Background(), driver)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	expected := []string{
+		"/vms/ubuntu/ubuntu.vmx",
+		"/vms/centos 7/centos.vmx",
+		"/vms/windows/windows.vmx",
+	}
+	if !reflect.DeepEqual(vms, expected) {
+		t.Fatalf("bad: %#v", vms)
+	}
+	if !reflect.DeepEqual(r.Commands[0], []string{"-T", "ws", "list"}) {
+		t.Fatalf("bad command: %#v", r.Commands[0])
+	}
+
+	running, err := driver.IsRunning(context.Background(), "/vms/ubuntu/ubuntu.vmx")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if running {
+		t.Fatal("should not be running")
+	}
+}
@@ -0,0 +1,55 @@
+package common
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/packer/template/interpolate"
+)
+
+// SharedFolder is a host directory shared with the guest.
+type SharedFolder struct {
+	Name     string `mapstructure:"name"`
+	HostPath string `mapstructure:"host_path"`
+}
+
+type SharedFolderConfig struct {
+	SharedFolders []SharedFolder `mapstructure:"shared_folders"`
+}
+
+func (c *SharedFolderConfig) Prepare(ctx *interpolate.Context) []error {
+	var errs []error
+
+	names := make(map[string]bool)
+	for i := range c.SharedFolders {
+		folder := &c.SharedFolders[i]
+
+		if folder.Name == "" {
+			errs = append(errs, fmt.Errorf("shared_folders[%d]: name must be specified", i))
+		} else if names[folder.Name] {
+			errs = append(errs, fmt.Errorf("shared_folders[%d]: duplicate name: %s", i, folder.Name))
+		}
+		names[folder.Name] = true
+
+		if folder.HostPath == "" {
+			errs = append(errs, fmt.Errorf("shared_folders[%d]: host_path must be specified", i))
+			continue
+		}
+
+		path, err := filepath.Abs(folder.HostPath)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("shared_folders[%d]: %s", i, err))
+			continue
+		}
+		folder.HostPath = path
+
+		if info, err := os.Stat(path); err != nil {
+			errs = append(errs, fmt.Errorf("shared_folders[%d]: host_path is invalid: %s", i, err))
+		} else if !info.IsDir() {
+			errs = append(errs, fmt.Errorf("shared_folders[%d]: host_path must be a directory: %s", i, path))
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,52 @@
+package common
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSharedFolderConfigPrepare(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Test the defaults
+	c := new(SharedFolderConfig)
+	if errs := c.Prepare(testConfigTemplate(t)); len(errs) > 0 {
+		t.Fatalf("err: %#v", errs)
+	}
+
+	// Test with a good one
+	c = &SharedFolderConfig{
+		SharedFolders: []SharedFolder{{Name: "artifacts", HostPath: dir}},
+	}
+	if errs := c.Prepare(testConfigTemplate(t)); len(errs) > 0 {
+		t.Fatalf("err: %#v", errs)
+	}
+	if !filepath.IsAbs(c.SharedFolders[0].HostPath) {
+		t.Fatalf("bad path: %s", c.SharedFolders[0].HostPath)
+	}
+
+	// Test bad ones
+	file := filepath.Join(dir, "file")
+	if err := ioutil.WriteFile(file, []byte("foo"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	bad := [][]SharedFolder{
+		{{HostPath: dir}},
+		{{Name: "artifacts"}},
+		{{Name: "artifacts", HostPath: filepath.Join(dir, "missing")}},
+		{{Name: "artifacts", HostPath: file}},
+		{{Name: "artifacts", HostPath: dir}, {Name: "artifacts", HostPath: dir}},
+	}
+	for _, folders := range bad {
+		c = &SharedFolderConfig{SharedFolders: folders}
+		if errs := c.Prepare(testConfigTemplate(t)); len(errs) == 0 {
+			t.Fatalf("should have error: %#v", folders)
+		}
+	}
+}
@@ -10,8 +10,14 @@ import (
 type ShutdownConfig struct {
 	ShutdownCommand    string `mapstructure:"shutdown_command"`
 	RawShutdownTimeout string `mapstructure:"shutdown_timeout"`
+	Suspend            bool   `mapstructure:"suspend"`
+
+	// How long the guest is given to stop when asked through VMware Tools
+	// before it is stopped the hard way.
+	RawStopTimeout string `mapstructure:"stop_timeout"`
 
 	ShutdownTimeout time.Duration ``
+	StopTimeout     time.Duration ``
 }
 
 func (c *ShutdownConfig) Prepare(ctx *interpolate.Context) []error {
@@ -26,5 +32,15 @@ func (c *ShutdownConfig) Prepare(ctx *interpolate.Context) []error {
 		errs = append(errs, fmt.Errorf("Failed parsing shutdown_timeout: %s", err))
 	}
 
+	if c.RawStopTimeout == "" {
+		c.RawStopTimeout = "30s"
+	}
+	c.StopTimeout, err = time.ParseDuration(c.RawStopTimeout)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("Failed parsing stop_timeout: %s", err))
+	} else if c.StopTimeout < 0 {
+		errs = append(errs, fmt.Errorf("stop_timeout must not be negative"))
+	}
+
 	return errs
 }
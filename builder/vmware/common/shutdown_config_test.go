@@ -43,3 +43,37 @@ func TestShutdownConfigPrepare_ShutdownTimeout(t *testing.T) {
 		t.Fatalf("bad: %s", c.ShutdownTimeout)
 	}
 }
+
+func TestShutdownConfigPrepare_StopTimeout(t *testing.T) {
+	var c *ShutdownConfig
+	var errs []error
+
+	// Test the default
+	c = testShutdownConfig()
+	errs = c.Prepare(testConfigTemplate(t))
+	if len(errs) > 0 {
+		t.Fatalf("err: %#v", errs)
+	}
+	if c.StopTimeout != 30*time.Second {
+		t.Fatalf("bad: %s", c.StopTimeout)
+	}
+
+	// Test with a bad value
+	c = testShutdownConfig()
+	c.RawStopTimeout = "-5s"
+	errs = c.Prepare(testConfigTemplate(t))
+	if len(errs) == 0 {
+		t.Fatalf("should have error")
+	}
+
+	// Test disabling the soft stop
+	c = testShutdownConfig()
+	c.RawStopTimeout = "0s"
+	errs = c.Prepare(testConfigTemplate(t))
+	if len(errs) > 0 {
+		t.Fatalf("err: %#v", errs)
+	}
+	if c.StopTimeout != 0 {
+		t.Fatalf("bad: %s", c.StopTimeout)
+	}
+}
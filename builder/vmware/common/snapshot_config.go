@@ -0,0 +1,28 @@
+package common
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/packer/template/interpolate"
+)
+
+type SnapshotConfig struct {
+	SnapshotName    string `mapstructure:"snapshot_name"`
+	SnapshotRetries int    `mapstructure:"snapshot_retries"`
+}
+
+func (c *SnapshotConfig) Prepare(ctx *interpolate.Context, dc *DriverConfig) []error {
+	var errs []error
+
+	if c.SnapshotRetries < 0 {
+		errs = append(errs, fmt.Errorf("snapshot_retries must not be negative"))
+	}
+	if c.SnapshotRetries > 0 && c.SnapshotName == "" {
+		errs = append(errs, fmt.Errorf("snapshot_retries requires snapshot_name to be set"))
+	}
+	if c.SnapshotRetries > 0 && (dc.Driver == "player5" || dc.Driver == "player6") {
+		errs = append(errs, fmt.Errorf("snapshot_retries is not supported with VMware Player"))
+	}
+
+	return errs
+}
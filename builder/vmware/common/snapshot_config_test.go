@@ -0,0 +1,52 @@
+package common
+
+import (
+	"testing"
+)
+
+func TestSnapshotConfigPrepare(t *testing.T) {
+	var c *SnapshotConfig
+	var errs []error
+
+	// Test the defaults
+	c = new(SnapshotConfig)
+	errs = c.Prepare(testConfigTemplate(t), new(DriverConfig))
+	if len(errs) > 0 {
+		t.Fatalf("err: %#v", errs)
+	}
+
+	// Test with a good one
+	c = new(SnapshotConfig)
+	c.SnapshotName = "installed"
+	c.SnapshotRetries = 2
+	errs = c.Prepare(testConfigTemplate(t), new(DriverConfig))
+	if len(errs) > 0 {
+		t.Fatalf("err: %#v", errs)
+	}
+
+	// Test retries without a snapshot
+	c = new(SnapshotConfig)
+	c.SnapshotRetries = 2
+	errs = c.Prepare(testConfigTemplate(t), new(DriverConfig))
+	if len(errs) == 0 {
+		t.Fatal("should have error")
+	}
+
+	// Test negative retries
+	c = new(SnapshotConfig)
+	c.SnapshotName = "installed"
+	c.SnapshotRetries = -1
+	errs = c.Prepare(testConfigTemplate(t), new(DriverConfig))
+	if len(errs) == 0 {
+		t.Fatal("should have error")
+	}
+
+	// Test retries with VMware Player
+	c = new(SnapshotConfig)
+	c.SnapshotName = "installed"
+	c.SnapshotRetries = 2
+	errs = c.Prepare(testConfigTemplate(t), &DriverConfig{Driver: "player6"})
+	if len(errs) == 0 {
+		t.Fatal("should have error")
+	}
+}
@@ -1,6 +1,7 @@
 package common
 
 import (
+	"errors"
 	"time"
 
 	"github.com/hashicorp/packer/helper/communicator"
@@ -14,6 +15,11 @@ type SSHConfig struct {
 	// TODO(@mitchellh): remove
 	SSHSkipRequestPty bool          `mapstructure:"ssh_skip_request_pty"`
 	SSHWaitTimeout    time.Duration `mapstructure:"ssh_wait_timeout"`
+
+	// Guest credentials for the vmrun communicator
+	VmrunUsername    string `mapstructure:"vmrun_username"`
+	VmrunPassword    string `mapstructure:"vmrun_password"`
+	VmrunInterpreter string `mapstructure:"vmrun_interpreter"`
 }
 
 func (c *SSHConfig) Prepare(ctx *interpolate.Context) []error {
@@ -25,5 +31,15 @@ func (c *SSHConfig) Prepare(ctx *interpolate.Context) []error {
 		c.Comm.SSHPty = false
 	}
 
-	return c.Comm.Prepare(ctx)
+	errs := c.Comm.Prepare(ctx)
+	if c.Comm.Type == "vmrun" {
+		if c.VmrunUsername == "" {
+			errs = append(errs, errors.New("vmrun_username must be specified for the vmrun communicator"))
+		}
+		if c.VmrunInterpreter == "" {
+			c.VmrunInterpreter = "/bin/sh"
+		}
+	}
+
+	return errs
 }
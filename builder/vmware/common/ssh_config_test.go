@@ -106,3 +106,23 @@ eHLc1nkCgYEApm/motCTPN32nINZ+Vvywbv64ZD+gtpeMNP3CLrbe1X9O+H52AXa
 3bfQ8hKYcSnTfE0gPtLDnqCIxTocaGLSHeG3TH9fTw+dA8FvWpUztI4=
 -----END RSA PRIVATE KEY-----
 `
+
+func TestSSHConfigPrepare_vmrun(t *testing.T) {
+	c := &SSHConfig{Comm: communicator.Config{Type: "vmrun"}}
+	errs := c.Prepare(testConfigTemplate(t))
+	if len(errs) == 0 {
+		t.Fatal("should have error")
+	}
+
+	c = &SSHConfig{
+		Comm:          communicator.Config{Type: "vmrun"},
+		VmrunUsername: "foo",
+	}
+	errs = c.Prepare(testConfigTemplate(t))
+	if len(errs) > 0 {
+		t.Fatalf("err: %#v", errs)
+	}
+	if c.VmrunInterpreter != "/bin/sh" {
+		t.Fatalf("bad interpreter: %s", c.VmrunInterpreter)
+	}
+}
@@ -0,0 +1,205 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+	"github.com/mitchellh/go-vnc"
+)
+
+// screenshotsDirName is the directory of the output directory that the
+// screenshots are saved to by default.
+const screenshotsDirName = "screenshots"
+
+// This step saves a screenshot of the console of the VM, which shows what
+// the guest was doing when a build times out or fails. When Debug is set
+// the screenshot is instead taken when the step runs in -debug mode, for
+// example just before the VM is shut down.
+//
+// The screen is read from the VNC server that the boot command is typed
+// with, so nothing has to run in the guest and it works while the guest is
+// still booting. Nothing is captured when VNC is disabled.
+//
+// The screenshots are saved to Dir, which defaults to the screenshots
+// directory of OutputDir.
+//
+// Uses:
+//   debug bool
+//   driver Driver
+//   ui packer.Ui
+//   vmx_path string
+//   vnc_ip string
+//   vnc_password string
+//   vnc_port int
+//
+// Produces:
+//   <nothing>
+type StepCaptureScreen struct {
+	Dir       string
+	OutputDir string
+	Debug     bool
+}
+
+func (s *StepCaptureScreen) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	if s.Debug && state.Get("debug").(bool) {
+		s.capture(ctx, state)
+	}
+	return multistep.ActionContinue
+}
+
+func (s *StepCaptureScreen) Cleanup(state multistep.StateBag) {
+	if s.Debug {
+		return
+	}
+
+	_, cancelled := state.GetOk(multistep.StateCancelled)
+	_, halted := state.GetOk(multistep.StateHalted)
+	if !cancelled && !halted {
+		return
+	}
+
+	s.capture(context.Background(), state)
+}
+
+func (s *StepCaptureScreen) capture(ctx context.Context, state multistep.StateBag) {
+	driver := state.Get("driver").(Driver)
+	ui := state.Get("ui").(packer.Ui)
+	vmxPath := state.Get("vmx_path").(string)
+
+	vncPort, ok := state.Get("vnc_port").(int)
+	if !ok {
+		log.Printf("Not capturing the screen, VNC is disabled")
+		return
+	}
+	vncIp := state.Get("vnc_ip").(string)
+	vncPassword, _ := state.Get("vnc_password").(string)
+
+	if running, _ := driver.IsRunning(ctx, vmxPath); !running {
+		log.Printf("Not capturing the screen, the VM isn't running")
+		return
+	}
+
+	dir := s.Dir
+	if dir == "" {
+		dir = filepath.Join(s.OutputDir, screenshotsDirName)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Printf("Error creating the screenshots directory: %s", err)
+		return
+	}
+	name := fmt.Sprintf("%s-screen-%s.png",
+		filepath.Base(strings.TrimSuffix(vmxPath, filepath.Ext(vmxPath))),
+		time.Now().Format("20060102-150405"))
+	outPath, err := filepath.Abs(filepath.Join(dir, name))
+	if err != nil {
+		outPath = filepath.Join(dir, name)
+	}
+
+	addr := fmt.Sprintf("%s:%d", vncIp, vncPort)
+	if err := vncCaptureScreen(ctx, addr, vncPassword, outPath); err != nil {
+		log.Printf("Error capturing the screen: %s", err)
+		return
+	}
+	ui.Message(fmt.Sprintf("Saved a screenshot of the VM console to %s", outPath))
+}
+
+// vncCaptureScreen saves a PNG of the framebuffer of the VNC server at the
+// given address to outPath. The connection is shared so that it doesn't
+// disconnect a VNC client that is already watching the console.
+func vncCaptureScreen(ctx context.Context, addr, password, outPath string) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	var d net.Dialer
+	nc, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer nc.Close()
+
+	// The handshake and the reads below don't take a context
+	if deadline, ok := ctx.Deadline(); ok {
+		nc.SetDeadline(deadline)
+	}
+
+	auth := []vnc.ClientAuth{new(vnc.ClientAuthNone)}
+	if password != "" {
+		auth = []vnc.ClientAuth{&vnc.PasswordAuth{Password: password}}
+	}
+
+	msgs := make(chan vnc.ServerMessage, 1)
+	c, err := vnc.Client(nc, &vnc.ClientConfig{Auth: auth, ServerMessageCh: msgs})
+	if err != nil {
+		return fmt.Errorf("Error handshaking with VNC: %s", err)
+	}
+	defer c.Close()
+
+	width, height := c.FrameBufferWidth, c.FrameBufferHeight
+	if err := c.FramebufferUpdateRequest(false, 0, 0, width, height); err != nil {
+		return err
+	}
+
+	var update *vnc.FramebufferUpdateMessage
+	for update == nil {
+		select {
+		case msg := <-msgs:
+			update, _ = msg.(*vnc.FramebufferUpdateMessage)
+		case <-ctx.Done():
+			return fmt.Errorf("Timeout waiting for the VNC framebuffer: %s", ctx.Err())
+		}
+	}
+
+	// Color map entries use the whole 16 bits of each channel
+	redMax, greenMax, blueMax := c.PixelFormat.RedMax, c.PixelFormat.GreenMax, c.PixelFormat.BlueMax
+	if !c.PixelFormat.TrueColor {
+		redMax, greenMax, blueMax = 0xffff, 0xffff, 0xffff
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, int(width), int(height)))
+	for _, rect := range update.Rectangles {
+		raw, ok := rect.Enc.(*vnc.RawEncoding)
+		if !ok {
+			continue
+		}
+		for i, col := range raw.Colors {
+			x := int(rect.X) + i%int(rect.Width)
+			y := int(rect.Y) + i/int(rect.Width)
+			img.Set(x, y, color.RGBA{
+				R: scaleColor(col.R, redMax),
+				G: scaleColor(col.G, greenMax),
+				B: scaleColor(col.B, blueMax),
+				A: 0xff,
+			})
+		}
+	}
+
+	f, err := os.Create(outPath)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// scaleColor scales a channel of a VNC color, which goes up to the max of
+// the pixel format, to 8 bits.
+func scaleColor(v, max uint16) uint8 {
+	if max == 0 {
+		return 0
+	}
+	return uint8(uint32(v) * 0xff / uint32(max))
+}
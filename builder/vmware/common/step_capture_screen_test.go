@@ -0,0 +1,200 @@
+package common
+
+import (
+	"context"
+	"encoding/binary"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/packer/helper/multistep"
+)
+
+// testVNCServer serves a 2x2 red framebuffer to each VNC client until the
+// test ends.
+func testVNCServer(t *testing.T) (string, int) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			nc, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go serveTestVNC(nc)
+		}
+	}()
+
+	addr := l.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port
+}
+
+func serveTestVNC(nc net.Conn) {
+	defer nc.Close()
+
+	buf := make([]byte, 12)
+	nc.Write([]byte("RFB 003.008\n"))
+	io.ReadFull(nc, buf[:12])
+
+	// Security types: only None
+	nc.Write([]byte{1, 1})
+	io.ReadFull(nc, buf[:1])
+	binary.Write(nc, binary.BigEndian, uint32(0))
+
+	// ClientInit, then ServerInit with 32 bit little endian true color
+	io.ReadFull(nc, buf[:1])
+	binary.Write(nc, binary.BigEndian, []uint16{2, 2})
+	nc.Write([]byte{32, 24, 0, 1, 0, 255, 0, 255, 0, 255, 16, 8, 0, 0, 0, 0})
+	binary.Write(nc, binary.BigEndian, uint32(0))
+
+	// FramebufferUpdateRequest, answered with a single raw rectangle
+	io.ReadFull(nc, buf[:10])
+	nc.Write([]byte{0, 0})
+	binary.Write(nc, binary.BigEndian, []uint16{1, 0, 0, 2, 2})
+	binary.Write(nc, binary.BigEndian, int32(0))
+	for i := 0; i < 4; i++ {
+		nc.Write([]byte{0, 0, 255, 0})
+	}
+
+	io.Copy(ioutil.Discard, nc)
+}
+
+func testCaptureScreenState(t *testing.T) multistep.StateBag {
+	state := testState(t)
+	state.Put("debug", false)
+	state.Put("vmx_path", "/vms/packer.vmx")
+
+	vncIp, vncPort := testVNCServer(t)
+	state.Put("vnc_ip", vncIp)
+	state.Put("vnc_port", vncPort)
+	state.Put("vnc_password", "")
+	return state
+}
+
+func testScreenshots(t *testing.T, dir string) []string {
+	matches, err := filepath.Glob(filepath.Join(dir, "packer-screen-*.png"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	return matches
+}
+
+func TestStepCaptureScreen_impl(t *testing.T) {
+	var _ multistep.Step = new(StepCaptureScreen)
+}
+
+func TestStepCaptureScreen_halted(t *testing.T) {
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	state := testCaptureScreenState(t)
+	step := &StepCaptureScreen{Dir: td}
+
+	driver := state.Get("driver").(*DriverMock)
+	driver.IsRunningResult = true
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	// Nothing is captured when the build succeeds
+	step.Cleanup(state)
+	if shots := testScreenshots(t, td); len(shots) != 0 {
+		t.Fatalf("bad: %#v", shots)
+	}
+
+	state.Put(multistep.StateHalted, true)
+	step.Cleanup(state)
+	shots := testScreenshots(t, td)
+	if len(shots) != 1 {
+		t.Fatalf("bad: %#v", shots)
+	}
+
+	f, err := os.Open(shots[0])
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if img.Bounds().Dx() != 2 || img.Bounds().Dy() != 2 {
+		t.Fatalf("bad: %#v", img.Bounds())
+	}
+	if r, g, b, _ := img.At(1, 1).RGBA(); r != 0xffff || g != 0 || b != 0 {
+		t.Fatalf("bad: %d %d %d", r, g, b)
+	}
+}
+
+func TestStepCaptureScreen_debug(t *testing.T) {
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	state := testCaptureScreenState(t)
+	state.Put("debug", true)
+	step := &StepCaptureScreen{OutputDir: td, Debug: true}
+
+	driver := state.Get("driver").(*DriverMock)
+	driver.IsRunningResult = true
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if shots := testScreenshots(t, filepath.Join(td, "screenshots")); len(shots) != 1 {
+		t.Fatalf("bad: %#v", shots)
+	}
+}
+
+func TestStepCaptureScreen_notRunning(t *testing.T) {
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	state := testCaptureScreenState(t)
+	state.Put(multistep.StateHalted, true)
+	step := &StepCaptureScreen{Dir: td}
+
+	step.Cleanup(state)
+	if shots := testScreenshots(t, td); len(shots) != 0 {
+		t.Fatalf("bad: %#v", shots)
+	}
+}
+
+func TestStepCaptureScreen_noVNC(t *testing.T) {
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	state := testState(t)
+	state.Put("debug", false)
+	state.Put("vmx_path", "/vms/packer.vmx")
+	state.Put(multistep.StateHalted, true)
+	step := &StepCaptureScreen{Dir: td}
+
+	driver := state.Get("driver").(*DriverMock)
+	driver.IsRunningResult = true
+
+	step.Cleanup(state)
+	if shots := testScreenshots(t, td); len(shots) != 0 {
+		t.Fatalf("bad: %#v", shots)
+	}
+}
@@ -15,6 +15,10 @@ import (
 // build.
 var KeepFileExtensions = []string{".nvram", ".vmdk", ".vmsd", ".vmx", ".vmxf"}
 
+// These are the extensions of the files that hold the state of a suspended
+// virtual machine, which it needs to resume.
+var SuspendFileExtensions = []string{".vmem", ".vmsn", ".vmss"}
+
 // This step removes unnecessary files from the final result.
 //
 // Uses:
@@ -23,9 +27,12 @@ var KeepFileExtensions = []string{".nvram", ".vmdk", ".vmsd", ".vmx", ".vmxf"}
 //
 // Produces:
 //   <nothing>
-type StepCleanFiles struct{}
+type StepCleanFiles struct {
+	// Keep the suspended state of the machine.
+	KeepSuspendState bool
+}
 
-func (StepCleanFiles) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+func (s StepCleanFiles) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
 	dir := state.Get("dir").(OutputDir)
 	ui := state.Get("ui").(packer.Ui)
 
@@ -36,12 +43,17 @@ func (StepCleanFiles) Run(ctx context.Context, state multistep.StateBag) multist
 		return multistep.ActionHalt
 	}
 
+	keepExtensions := KeepFileExtensions
+	if s.KeepSuspendState {
+		keepExtensions = append(append([]string{}, KeepFileExtensions...), SuspendFileExtensions...)
+	}
+
 	for _, path := range files {
 		// If the file isn't critical to the function of the
 		// virtual machine, we get rid of it.
 		keep := false
 		ext := filepath.Ext(path)
-		for _, goodExt := range KeepFileExtensions {
+		for _, goodExt := range keepExtensions {
 			if goodExt == ext {
 				keep = true
 				break
@@ -0,0 +1,52 @@
+package common
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/packer/helper/multistep"
+)
+
+func TestStepCleanFiles_impl(t *testing.T) {
+	var _ multistep.Step = new(StepCleanFiles)
+}
+
+func TestStepCleanFiles(t *testing.T) {
+	for _, keepSuspendState := range []bool{false, true} {
+		td, err := ioutil.TempDir("", "packer")
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		defer os.RemoveAll(td)
+
+		for _, name := range []string{"foo.vmx", "foo.vmdk", "foo.vmss", "foo.vmem", "vmware.log"} {
+			if err := ioutil.WriteFile(filepath.Join(td, name), nil, 0644); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+		}
+
+		state := testState(t)
+		state.Put("dir", &LocalOutputDir{dir: td})
+		step := StepCleanFiles{KeepSuspendState: keepSuspendState}
+		if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+			t.Fatalf("bad action: %#v", action)
+		}
+
+		exists := func(name string) bool {
+			_, err := os.Stat(filepath.Join(td, name))
+			return err == nil
+		}
+		if !exists("foo.vmx") || !exists("foo.vmdk") {
+			t.Fatal("the VMX and disk should have been kept")
+		}
+		if exists("vmware.log") {
+			t.Fatal("the log should have been deleted")
+		}
+		if exists("foo.vmss") != keepSuspendState || exists("foo.vmem") != keepSuspendState {
+			t.Fatalf("suspend state kept: %t, expected %t", exists("foo.vmss"), keepSuspendState)
+		}
+	}
+}
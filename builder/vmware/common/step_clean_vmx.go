@@ -22,17 +22,27 @@ import (
 type StepCleanVMX struct {
 	RemoveEthernetInterfaces bool
 	VNCEnabled               bool
+
+	// Skip leaves the VMX as is, for machines that are suspended.
+	Skip bool
 }
 
 func (s StepCleanVMX) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
 	ui := state.Get("ui").(packer.Ui)
 	vmxPath := state.Get("vmx_path").(string)
 
+	if s.Skip {
+		log.Println("Skipping VMX cleaning step...")
+		return multistep.ActionContinue
+	}
+
 	ui.Say("Cleaning VMX prior to finishing up...")
 
 	vmxData, err := ReadVMX(vmxPath)
 	if err != nil {
-		state.Put("error", fmt.Errorf("Error reading VMX: %s", err))
+		err := fmt.Errorf("Error reading VMX: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
 		return multistep.ActionHalt
 	}
 
@@ -122,7 +132,9 @@ func (s StepCleanVMX) Run(ctx context.Context, state multistep.StateBag) multist
 
 	// Rewrite the VMX
 	if err := WriteVMX(vmxPath, vmxData); err != nil {
-		state.Put("error", fmt.Errorf("Error writing VMX: %s", err))
+		err := fmt.Errorf("Error writing VMX: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
 		return multistep.ActionHalt
 	}
 
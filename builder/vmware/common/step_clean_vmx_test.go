@@ -30,6 +30,41 @@ func TestStepCleanVMX(t *testing.T) {
 	}
 }
 
+func TestStepCleanVMX_missingVMX(t *testing.T) {
+	state := testState(t)
+	step := &StepCleanVMX{
+		RemoveEthernetInterfaces: true,
+	}
+
+	vmxPath := testVMXFile(t)
+	os.Remove(vmxPath)
+	state.Put("vmx_path", vmxPath)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if _, ok := state.GetOk("error"); !ok {
+		t.Fatal("should have error")
+	}
+}
+
+func TestStepCleanVMX_skip(t *testing.T) {
+	state := testState(t)
+	step := &StepCleanVMX{
+		RemoveEthernetInterfaces: true,
+		Skip:                     true,
+	}
+
+	// The VMX isn't read when skipping
+	vmxPath := testVMXFile(t)
+	os.Remove(vmxPath)
+	state.Put("vmx_path", vmxPath)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+}
+
 func TestStepCleanVMX_floppyPath(t *testing.T) {
 	state := testState(t)
 	step := new(StepCleanVMX)
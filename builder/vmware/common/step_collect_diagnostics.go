@@ -0,0 +1,133 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// diagnosticsPatterns match the files of the output directory that explain
+// why a VM failed: the VMX, the logs of the VMX process and its core dumps.
+var diagnosticsPatterns = []string{
+	"*.vmx",
+	"vmware*.log",
+	"*.dmp",
+	"vmware*-core*",
+	"vmx-zdump.*",
+}
+
+// diagnosticsDirName is the directory of the output directory that the
+// diagnostics are saved to by default.
+const diagnosticsDirName = "diagnostics"
+
+// This step copies the VMX, vmware.log and any crash dumps of the VM into a
+// diagnostics directory when the build fails, and adds the path of that
+// directory to the error. The output directory is deleted on failure, except
+// for the diagnostics directory, so these files would otherwise be lost.
+//
+// It has to run after StepRegister, so that its cleanup happens after the VM
+// is stopped but before a remote VM is destroyed.
+//
+// The diagnostics are saved to Dir, which defaults to the diagnostics
+// directory of OutputDir.
+//
+// Uses:
+//   dir OutputDir
+//   driver Driver
+//   error error
+//   ui packer.Ui
+//   vmx_path string
+//
+// Produces:
+//   <nothing>
+type StepCollectDiagnostics struct {
+	Dir       string
+	OutputDir string
+}
+
+func (s *StepCollectDiagnostics) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	return multistep.ActionContinue
+}
+
+func (s *StepCollectDiagnostics) Cleanup(state multistep.StateBag) {
+	rawErr, ok := state.GetOk("error")
+	if !ok {
+		return
+	}
+
+	dir := state.Get("dir").(OutputDir)
+	driver := state.Get("driver").(Driver)
+	ui := state.Get("ui").(packer.Ui)
+	vmxPath := state.Get("vmx_path").(string)
+
+	files, err := dir.ListFiles()
+	if err != nil {
+		log.Printf("Error listing the output directory for diagnostics: %s", err)
+		return
+	}
+
+	var diagFiles []string
+	for _, file := range files {
+		if isDiagnosticsFile(file) {
+			diagFiles = append(diagFiles, file)
+		}
+	}
+	if len(diagFiles) == 0 {
+		log.Printf("No diagnostics found in the output directory")
+		return
+	}
+
+	diagDir := s.Dir
+	if diagDir == "" {
+		diagDir = filepath.Join(s.OutputDir, diagnosticsDirName)
+	}
+	name := fmt.Sprintf("%s-diagnostics-%s",
+		filepath.Base(strings.TrimSuffix(vmxPath, filepath.Ext(vmxPath))),
+		time.Now().Format("20060102-150405"))
+	diagDir, err = filepath.Abs(filepath.Join(diagDir, name))
+	if err != nil {
+		log.Printf("Error creating the diagnostics directory: %s", err)
+		return
+	}
+	if err := os.MkdirAll(diagDir, 0755); err != nil {
+		log.Printf("Error creating the diagnostics directory: %s", err)
+		return
+	}
+
+	ui.Say("Collecting diagnostics for the VM...")
+	for _, file := range diagFiles {
+		dst := filepath.Join(diagDir, path.Base(filepath.ToSlash(file)))
+		if remoteDriver, ok := driver.(RemoteDriver); ok {
+			err = remoteDriver.Download(file, dst)
+		} else {
+			err = copyFile(dst, file)
+		}
+		if err != nil {
+			log.Printf("Error collecting %s: %s", file, err)
+		}
+	}
+
+	ui.Message(fmt.Sprintf("Saved diagnostics for the VM to %s", diagDir))
+	state.Put("error", fmt.Errorf("%s\n\nDiagnostics for the VM were saved to %s",
+		rawErr, diagDir))
+}
+
+// isDiagnosticsFile returns whether the file of the output directory should
+// be collected as a diagnostic.
+func isDiagnosticsFile(file string) bool {
+	name := path.Base(filepath.ToSlash(file))
+	for _, pattern := range diagnosticsPatterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
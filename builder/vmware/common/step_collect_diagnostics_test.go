@@ -0,0 +1,105 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/packer/helper/multistep"
+)
+
+func TestStepCollectDiagnostics_impl(t *testing.T) {
+	var _ multistep.Step = new(StepCollectDiagnostics)
+}
+
+func TestStepCollectDiagnostics(t *testing.T) {
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	outputDir := filepath.Join(td, "output")
+	if err := os.Mkdir(outputDir, 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	for _, name := range []string{"packer.vmx", "vmware.log", "vmware-1.log", "vmware-vmx-1234.dmp", "disk.vmdk", "packer.nvram"} {
+		if err := ioutil.WriteFile(filepath.Join(outputDir, name), []byte(name), 0644); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+
+	dir := new(LocalOutputDir)
+	dir.SetOutputDir(outputDir)
+
+	state := testState(t)
+	state.Put("dir", dir)
+	state.Put("vmx_path", filepath.Join(outputDir, "packer.vmx"))
+	step := &StepCollectDiagnostics{Dir: td}
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	// Nothing is collected when the build succeeds
+	step.Cleanup(state)
+	if matches, _ := filepath.Glob(filepath.Join(td, "packer-diagnostics-*")); len(matches) != 0 {
+		t.Fatalf("bad: %#v", matches)
+	}
+
+	state.Put("error", errors.New("Timeout waiting for IP."))
+	step.Cleanup(state)
+
+	matches, _ := filepath.Glob(filepath.Join(td, "packer-diagnostics-*"))
+	if len(matches) != 1 {
+		t.Fatalf("bad: %#v", matches)
+	}
+	files, err := ioutil.ReadDir(matches[0])
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	var names []string
+	for _, f := range files {
+		names = append(names, f.Name())
+	}
+	expected := "packer.vmx vmware-1.log vmware-vmx-1234.dmp vmware.log"
+	if strings.Join(names, " ") != expected {
+		t.Fatalf("bad: %#v", names)
+	}
+
+	err = state.Get("error").(error)
+	if !strings.HasPrefix(err.Error(), "Timeout waiting for IP.") || !strings.Contains(err.Error(), matches[0]) {
+		t.Fatalf("bad error: %s", err)
+	}
+}
+
+func TestStepCollectDiagnostics_outputDir(t *testing.T) {
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	if err := ioutil.WriteFile(filepath.Join(td, "vmware.log"), []byte("log"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	dir := new(LocalOutputDir)
+	dir.SetOutputDir(td)
+
+	state := testState(t)
+	state.Put("dir", dir)
+	state.Put("vmx_path", filepath.Join(td, "packer.vmx"))
+	state.Put("error", errors.New("Timeout waiting for IP."))
+	step := &StepCollectDiagnostics{OutputDir: td}
+
+	step.Cleanup(state)
+	matches, _ := filepath.Glob(filepath.Join(td, "diagnostics", "packer-diagnostics-*", "vmware.log"))
+	if len(matches) != 1 {
+		t.Fatalf("bad: %#v", matches)
+	}
+}
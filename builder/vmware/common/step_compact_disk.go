@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/hashicorp/packer/helper/multistep"
 	"github.com/hashicorp/packer/packer"
@@ -35,14 +38,48 @@ func (s StepCompactDisk) Run(ctx context.Context, state multistep.StateBag) mult
 
 	ui.Say("Compacting all attached virtual disks...")
 	for i, diskFullPath := range diskFullPaths {
+		if _, cancelled := state.GetOk(multistep.StateCancelled); cancelled {
+			return multistep.ActionHalt
+		}
+
 		ui.Message(fmt.Sprintf("Compacting virtual disk %d", i+1))
-		if err := driver.CompactDisk(diskFullPath); err != nil {
-			state.Put("error", fmt.Errorf("Error compacting disk: %s", err))
+		before := vmdkSize(diskFullPath)
+		if err := driver.CompactDisk(ctx, diskFullPath); err != nil {
+			err := fmt.Errorf("Error compacting disk: %s", err)
+			state.Put("error", err)
+			ui.Error(err.Error())
 			return multistep.ActionHalt
 		}
+
+		// The size is only known for disks on the local filesystem
+		if after := vmdkSize(diskFullPath); before > 0 && after > 0 {
+			ui.Message(fmt.Sprintf("Compacted virtual disk %d from %d MB to %d MB",
+				i+1, before/(1024*1024), after/(1024*1024)))
+		}
 	}
 
 	return multistep.ActionContinue
 }
 
+// vmdkSize returns the size on disk of a local virtual disk, including the
+// extents of a disk split into 2GB files. It returns 0 if the disk can't be
+// found.
+func vmdkSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	size := info.Size()
+
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	extents, _ := filepath.Glob(base + "-s[0-9][0-9][0-9].vmdk")
+	flat, _ := filepath.Glob(base + "-flat.vmdk")
+	for _, extent := range append(extents, flat...) {
+		if info, err := os.Stat(extent); err == nil {
+			size += info.Size()
+		}
+	}
+	return size
+}
+
 func (StepCompactDisk) Cleanup(multistep.StateBag) {}
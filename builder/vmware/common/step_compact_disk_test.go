@@ -2,6 +2,10 @@ package common
 
 import (
 	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/hashicorp/packer/helper/multistep"
@@ -60,3 +64,48 @@ func TestStepCompactDisk_skip(t *testing.T) {
 		t.Fatal("should not have called")
 	}
 }
+
+func TestStepCompactDisk_error(t *testing.T) {
+	state := testState(t)
+	step := new(StepCompactDisk)
+
+	state.Put("disk_full_paths", []string{"foo"})
+
+	driver := state.Get("driver").(*DriverMock)
+	driver.CompactDiskErr = errors.New("error")
+
+	// Test the run
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if _, ok := state.GetOk("error"); !ok {
+		t.Fatal("should have error")
+	}
+}
+
+func TestVmdkSize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]int{
+		"disk.vmdk":      10,
+		"disk-s001.vmdk": 100,
+		"disk-s002.vmdk": 1000,
+		"disk-1.vmdk":    10000,
+	}
+	for name, size := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), make([]byte, size), 0644); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+
+	if size := vmdkSize(filepath.Join(dir, "disk.vmdk")); size != 1110 {
+		t.Fatalf("bad size: %d", size)
+	}
+	if size := vmdkSize(filepath.Join(dir, "missing.vmdk")); size != 0 {
+		t.Fatalf("bad size: %d", size)
+	}
+}
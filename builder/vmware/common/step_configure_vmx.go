@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/hashicorp/packer/helper/multistep"
@@ -12,9 +13,12 @@ import (
 )
 
 // This step configures a VMX by setting some default settings as well
-// as taking in custom data to set, attaching a floppy if it exists, etc.
+// as taking in custom data to set, attaching a floppy and ISOs if they
+// exist, etc.
 //
 // Uses:
+//   cd_path string
+//   floppy_path string
 //   vmx_path string
 //
 // Produces:
@@ -22,11 +26,20 @@ import (
 type StepConfigureVMX struct {
 	CustomData  map[string]string
 	DisplayName string
+	ISOPaths    []string
 	SkipFloppy  bool
 	VMName      string
+
+	// Skip leaves the VMX as is, for machines that are suspended.
+	Skip bool
 }
 
 func (s *StepConfigureVMX) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	if s.Skip {
+		log.Println("Skipping VMX configuration step...")
+		return multistep.ActionContinue
+	}
+
 	log.Printf("Configuring VMX...\n")
 
 	var err error
@@ -79,6 +92,26 @@ func (s *StepConfigureVMX) Run(ctx context.Context, state multistep.StateBag) mu
 			tmpBuildDevices = append(tmpBuildDevices, "floppy0")
 		}
 
+		// Attach the generated CD and any additional ISOs
+		var isoPaths []string
+		if cdPathRaw, ok := state.GetOk("cd_path"); ok {
+			isoPaths = append(isoPaths, cdPathRaw.(string))
+		}
+		isoPaths = append(isoPaths, s.ISOPaths...)
+
+		for _, isoPath := range isoPaths {
+			device, err := attachISO(vmxData, isoPath)
+			if err != nil {
+				state.Put("error", err)
+				ui.Error(err.Error())
+				return multistep.ActionHalt
+			}
+			log.Printf("Attaching %s as %s", isoPath, device)
+
+			// Add it to our list of build devices to later remove
+			tmpBuildDevices = append(tmpBuildDevices, device)
+		}
+
 		// Build the list back in our statebag
 		state.Put("temporaryDevices", tmpBuildDevices)
 	}
@@ -127,3 +160,39 @@ func (s *StepConfigureVMX) Run(ctx context.Context, state multistep.StateBag) mu
 
 func (s *StepConfigureVMX) Cleanup(state multistep.StateBag) {
 }
+
+// attachISO attaches the ISO image as a CD-ROM on the first free unit of
+// the IDE adapters, which every hardware version has. It only falls back to
+// the SATA adapter when they are full, which needs hardware version 10.
+func attachISO(vmxData map[string]string, isoPath string) (string, error) {
+	for _, device := range []string{"ide0:0", "ide0:1", "ide1:0", "ide1:1"} {
+		if strings.EqualFold(vmxData[device+".present"], "TRUE") {
+			continue
+		}
+
+		vmxData[device+".present"] = "TRUE"
+		vmxData[device+".devicetype"] = "cdrom-image"
+		vmxData[device+".filename"] = isoPath
+		return device, nil
+	}
+
+	if version, err := strconv.Atoi(vmxData["virtualhw.version"]); err == nil && version < 10 {
+		return "", fmt.Errorf("Error attaching %s: the IDE adapters are full, "+
+			"and hardware version %d has no SATA adapter", isoPath, version)
+	}
+
+	for unit := 0; unit < 30; unit++ {
+		device := fmt.Sprintf("sata0:%d", unit)
+		if strings.EqualFold(vmxData[device+".present"], "TRUE") {
+			continue
+		}
+
+		vmxData["sata0.present"] = "TRUE"
+		vmxData[device+".present"] = "TRUE"
+		vmxData[device+".devicetype"] = "cdrom-image"
+		vmxData[device+".filename"] = isoPath
+		return device, nil
+	}
+
+	return "", fmt.Errorf("Error attaching %s: no free unit on the IDE or SATA adapters", isoPath)
+}
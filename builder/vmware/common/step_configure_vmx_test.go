@@ -128,6 +128,93 @@ func TestStepConfigureVMX_floppyPath(t *testing.T) {
 
 }
 
+func TestStepConfigureVMX_isoPaths(t *testing.T) {
+	state := testState(t)
+	step := &StepConfigureVMX{
+		ISOPaths: []string{"drivers.iso"},
+	}
+
+	vmxPath := testVMXFile(t)
+	defer os.Remove(vmxPath)
+
+	// The disk is already on the first SATA unit
+	err := WriteVMX(vmxPath, map[string]string{
+		"displayName":     "PackerBuild",
+		"sata0.present":   "TRUE",
+		"sata0:0.present": "TRUE",
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	state.Put("cd_path", "cd.iso")
+	state.Put("vmx_path", vmxPath)
+
+	// Test the run
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if _, ok := state.GetOk("error"); ok {
+		t.Fatal("should NOT have error")
+	}
+
+	// Test the resulting data
+	vmxData, err := ReadVMX(vmxPath)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	cases := []struct {
+		Key   string
+		Value string
+	}{
+		{"ide0:0.present", "TRUE"},
+		{"ide0:0.devicetype", "cdrom-image"},
+		{"ide0:0.filename", "cd.iso"},
+		{"ide0:1.present", "TRUE"},
+		{"ide0:1.devicetype", "cdrom-image"},
+		{"ide0:1.filename", "drivers.iso"},
+	}
+
+	for _, tc := range cases {
+		if vmxData[tc.Key] != tc.Value {
+			t.Fatalf("bad: %s %#v", tc.Key, vmxData[tc.Key])
+		}
+	}
+
+	devices := state.Get("temporaryDevices").([]string)
+	if len(devices) != 2 || devices[0] != "ide0:0" || devices[1] != "ide0:1" {
+		t.Fatalf("bad devices: %#v", devices)
+	}
+}
+
+func TestAttachISO_ideFull(t *testing.T) {
+	vmxData := map[string]string{
+		"virtualhw.version": "9",
+		"ide0:0.present":    "TRUE",
+		"ide0:1.present":    "TRUE",
+		"ide1:0.present":    "TRUE",
+		"ide1:1.present":    "TRUE",
+	}
+
+	// Hardware version 9 has no SATA adapter
+	if _, err := attachISO(vmxData, "cd.iso"); err == nil {
+		t.Fatal("should have error")
+	}
+
+	vmxData["virtualhw.version"] = "10"
+	device, err := attachISO(vmxData, "cd.iso")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if device != "sata0:0" {
+		t.Fatalf("bad device: %s", device)
+	}
+	if vmxData["sata0.present"] != "TRUE" || vmxData["sata0:0.filename"] != "cd.iso" {
+		t.Fatalf("bad vmx data: %#v", vmxData)
+	}
+}
+
 func TestStepConfigureVMX_generatedAddresses(t *testing.T) {
 	state := testState(t)
 	step := new(StepConfigureVMX)
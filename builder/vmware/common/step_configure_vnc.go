@@ -19,6 +19,8 @@ import (
 //
 // Produces:
 //   vnc_port int - The port that VNC is configured to listen on.
+//   vnc_ip string - The address that VNC is configured to listen on.
+//   vnc_password string - The password of the VNC server.
 type StepConfigureVNC struct {
 	Enabled            bool
 	VNCBindAddress     string
@@ -26,6 +28,9 @@ type StepConfigureVNC struct {
 	VNCPortMax         int
 	VNCDisablePassword bool
 
+	// VNCPassword is used instead of a random password when set.
+	VNCPassword string
+
 	l *net.Listener
 }
 
@@ -104,7 +109,10 @@ func (s *StepConfigureVNC) Run(ctx context.Context, state multistep.StateBag) mu
 		return multistep.ActionHalt
 	}
 
-	vncPassword := VNCPassword(s.VNCDisablePassword)
+	vncPassword := s.VNCPassword
+	if vncPassword == "" {
+		vncPassword = VNCPassword(s.VNCDisablePassword)
+	}
 
 	log.Printf("Found available VNC port: %s:%d", vncBindAddress, vncPort)
 
@@ -133,10 +141,20 @@ func (*StepConfigureVNC) UpdateVMX(address, password string, port int, data map[
 	}
 }
 
-func (s *StepConfigureVNC) Cleanup(multistep.StateBag) {
+// VNCPortReleaser is implemented by the VNCAddressFinders that keep the VNC
+// port reserved until the VNC configuration is cleaned up.
+type VNCPortReleaser interface {
+	ReleaseVNCPort()
+}
+
+func (s *StepConfigureVNC) Cleanup(state multistep.StateBag) {
 	if s.l != nil {
 		if err := s.l.Close(); err != nil {
 			log.Printf("failed to unlock port lockfile: %v", err)
 		}
 	}
+
+	if releaser, ok := state.Get("driver").(VNCPortReleaser); ok {
+		releaser.ReleaseVNCPort()
+	}
 }
@@ -0,0 +1,63 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// vmrunDriver is implemented by the drivers that talk to a local VMware
+// installation through vmrun.
+type vmrunDriver interface {
+	// vmrun returns the path to vmrun and the host type to pass to it.
+	vmrun() (string, string)
+
+	// runAndLog runs a command, retrying it like the driver is configured
+	// to.
+	runAndLog(ctx context.Context, cmd *exec.Cmd) (string, string, error)
+}
+
+// StepConnectVmrun sets up the vmrun communicator, which talks to the guest
+// through VMware Tools rather than the network.
+//
+// Uses:
+//   driver Driver
+//   ui     packer.Ui
+//   vmx_path string
+//
+// Produces:
+//   communicator packer.Communicator
+type StepConnectVmrun struct {
+	Config *SSHConfig
+}
+
+func (s *StepConnectVmrun) Run(_ context.Context, state multistep.StateBag) multistep.StepAction {
+	driver := state.Get("driver").(Driver)
+	ui := state.Get("ui").(packer.Ui)
+	vmxPath := state.Get("vmx_path").(string)
+
+	vd, ok := driver.(vmrunDriver)
+	if !ok {
+		err := fmt.Errorf("The vmrun communicator isn't supported by this VMware driver.")
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	vmrunPath, hostType := vd.vmrun()
+	state.Put("communicator", &VmrunCommunicator{
+		VmrunPath:   vmrunPath,
+		HostType:    hostType,
+		VmxPath:     vmxPath,
+		Username:    s.Config.VmrunUsername,
+		Password:    s.Config.VmrunPassword,
+		Interpreter: s.Config.VmrunInterpreter,
+		run:         vd.runAndLog,
+	})
+	return multistep.ActionContinue
+}
+
+func (s *StepConnectVmrun) Cleanup(multistep.StateBag) {}
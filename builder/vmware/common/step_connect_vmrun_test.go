@@ -0,0 +1,51 @@
+package common
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/packer/helper/multistep"
+)
+
+func TestStepConnectVmrun_impl(t *testing.T) {
+	var _ multistep.Step = new(StepConnectVmrun)
+}
+
+func TestStepConnectVmrun(t *testing.T) {
+	state := testState(t)
+	step := &StepConnectVmrun{
+		Config: &SSHConfig{VmrunUsername: "foo", VmrunInterpreter: "/bin/sh"},
+	}
+
+	state.Put("driver", &Workstation9Driver{VmrunPath: "/usr/bin/vmrun"})
+	state.Put("vmx_path", "foo.vmx")
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if _, ok := state.GetOk("error"); ok {
+		t.Fatal("should NOT have error")
+	}
+
+	comm := state.Get("communicator").(*VmrunCommunicator)
+	if comm.VmrunPath != "/usr/bin/vmrun" || comm.HostType != "ws" {
+		t.Fatalf("bad communicator: %#v", comm)
+	}
+	if comm.VmxPath != "foo.vmx" || comm.Username != "foo" {
+		t.Fatalf("bad communicator: %#v", comm)
+	}
+}
+
+func TestStepConnectVmrun_unsupported(t *testing.T) {
+	state := testState(t)
+	step := &StepConnectVmrun{Config: &SSHConfig{}}
+
+	state.Put("vmx_path", "foo.vmx")
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if _, ok := state.GetOk("error"); !ok {
+		t.Fatal("should have error")
+	}
+}
@@ -0,0 +1,51 @@
+package common
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// This step converts the registered remote VM to a template. It runs once
+// the VM is shut down and exported, and fails the build if the conversion
+// fails.
+//
+// Uses:
+//   driver Driver
+//   ui packer.Ui
+//   vmx_path string
+//
+// Produces:
+//   <nothing>
+type StepConvertToTemplate struct {
+	Skip bool
+}
+
+func (s *StepConvertToTemplate) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	if s.Skip {
+		return multistep.ActionContinue
+	}
+
+	driver := state.Get("driver").(Driver)
+	ui := state.Get("ui").(packer.Ui)
+	vmxPath := state.Get("vmx_path").(string)
+
+	remoteDriver, ok := driver.(RemoteDriver)
+	if !ok {
+		return multistep.ActionContinue
+	}
+
+	ui.Say("Converting virtual machine to template...")
+	if err := remoteDriver.ConvertToTemplate(vmxPath); err != nil {
+		err := fmt.Errorf("Error converting VM to template: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *StepConvertToTemplate) Cleanup(state multistep.StateBag) {}
@@ -0,0 +1,68 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/packer/helper/multistep"
+)
+
+func TestStepConvertToTemplate_impl(t *testing.T) {
+	var _ multistep.Step = new(StepConvertToTemplate)
+}
+
+func TestStepConvertToTemplate(t *testing.T) {
+	state := testState(t)
+	step := new(StepConvertToTemplate)
+
+	driver := new(RemoteDriverMock)
+	state.Put("driver", driver)
+	state.Put("vmx_path", "foo")
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if _, ok := state.GetOk("error"); ok {
+		t.Fatal("should NOT have error")
+	}
+	if !driver.ConvertToTemplateCalled {
+		t.Fatal("convert to template should be called")
+	}
+	if driver.ConvertToTemplatePath != "foo" {
+		t.Fatal("should convert proper path")
+	}
+}
+
+func TestStepConvertToTemplate_error(t *testing.T) {
+	state := testState(t)
+	step := new(StepConvertToTemplate)
+
+	driver := new(RemoteDriverMock)
+	driver.ConvertToTemplateErr = errors.New("error")
+	state.Put("driver", driver)
+	state.Put("vmx_path", "foo")
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if _, ok := state.GetOk("error"); !ok {
+		t.Fatal("should have error")
+	}
+}
+
+func TestStepConvertToTemplate_skip(t *testing.T) {
+	state := testState(t)
+	step := &StepConvertToTemplate{Skip: true}
+
+	driver := new(RemoteDriverMock)
+	state.Put("driver", driver)
+	state.Put("vmx_path", "foo")
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if driver.ConvertToTemplateCalled {
+		t.Fatal("convert to template should not be called")
+	}
+}
@@ -0,0 +1,53 @@
+package common
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// This step tells how to watch the console of the VM in -debug mode when
+// it isn't headless, so that the boot command can be followed as it is
+// typed. It runs just before StepTypeBootCommand: the debug runner pauses
+// after it, which gives time to open the console before anything is typed.
+//
+// Uses:
+//   debug bool
+//   ui packer.Ui
+//   vnc_ip string
+//   vnc_port int
+//   vnc_password string
+//
+// Produces:
+//   <nothing>
+type StepDebugConsole struct {
+	Headless bool
+}
+
+func (s *StepDebugConsole) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	if !state.Get("debug").(bool) || s.Headless {
+		return multistep.ActionContinue
+	}
+
+	// The boot command is only typed when VNC is enabled
+	vncIp, ok := state.GetOk("vnc_ip")
+	if !ok {
+		return multistep.ActionContinue
+	}
+
+	ui := state.Get("ui").(packer.Ui)
+	ui.Say("The console of the VM is open in the VMware GUI, the boot command will be typed once you continue.")
+	message := fmt.Sprintf("The VM can also be viewed via VNC at vnc://%s:%d",
+		vncIp.(string), state.Get("vnc_port").(int))
+	if password, ok := state.GetOk("vnc_password"); ok && password.(string) != "" {
+		message += fmt.Sprintf(" with the password \"%s\"", password.(string))
+	}
+	ui.Message(message + ".\nPacker takes exclusive access to VNC to type the boot command, which\n" +
+		"disconnects other VNC viewers.")
+
+	return multistep.ActionContinue
+}
+
+func (s *StepDebugConsole) Cleanup(multistep.StateBag) {}
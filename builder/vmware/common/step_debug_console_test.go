@@ -0,0 +1,56 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+func TestStepDebugConsole_impl(t *testing.T) {
+	var _ multistep.Step = new(StepDebugConsole)
+}
+
+func TestStepDebugConsole(t *testing.T) {
+	state := testState(t)
+	state.Put("debug", true)
+	state.Put("vnc_ip", "127.0.0.1")
+	state.Put("vnc_port", 5901)
+	state.Put("vnc_password", "packer")
+	step := new(StepDebugConsole)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	out := state.Get("ui").(*packer.BasicUi).Writer.(*bytes.Buffer).String()
+	if !strings.Contains(out, `vnc://127.0.0.1:5901 with the password "packer"`) {
+		t.Fatalf("bad: %s", out)
+	}
+}
+
+func TestStepDebugConsole_skip(t *testing.T) {
+	for _, c := range []struct {
+		debug    bool
+		headless bool
+	}{
+		{false, false},
+		{true, true},
+	} {
+		state := testState(t)
+		state.Put("debug", c.debug)
+		state.Put("vnc_ip", "127.0.0.1")
+		state.Put("vnc_port", 5901)
+		step := &StepDebugConsole{Headless: c.headless}
+
+		if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+			t.Fatalf("bad action: %#v", action)
+		}
+		if out := state.Get("ui").(*packer.BasicUi).Writer.(*bytes.Buffer).String(); out != "" {
+			t.Fatalf("bad: %s", out)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"net/url"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
 	"strings"
 
@@ -14,10 +15,13 @@ import (
 	"github.com/hashicorp/packer/packer"
 )
 
-// This step exports a VM built on ESXi using ovftool
+// This step exports a VM using ovftool. VMs built on ESXi are exported
+// from the host, local VMs are exported from their VMX file when the format
+// is ova or ovf.
 //
 // Uses:
 //   display_name string
+//   vmx_path string
 type StepExport struct {
 	Format         string
 	SkipExport     bool
@@ -32,10 +36,47 @@ func GetOVFTool() string {
 		ovftool = "ovftool.exe"
 	}
 
-	if _, err := exec.LookPath(ovftool); err != nil {
-		return ""
+	if _, err := exec.LookPath(ovftool); err == nil {
+		return ovftool
+	}
+
+	// ovftool is bundled with Fusion and Workstation, but isn't always
+	// added to the PATH
+	for _, path := range ovfToolPaths() {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// ovfToolPaths returns the places where Fusion, Workstation and the
+// standalone installer put ovftool.
+func ovfToolPaths() []string {
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{
+			"/Applications/VMware Fusion.app/Contents/Library/VMware OVF Tool/ovftool",
+			"/Applications/VMware OVF Tool/ovftool",
+		}
+	case "windows":
+		var paths []string
+		for _, env := range []string{"ProgramFiles(x86)", "ProgramFiles"} {
+			dir := os.Getenv(env)
+			if dir == "" {
+				continue
+			}
+			paths = append(paths,
+				filepath.Join(dir, "VMware", "VMware Workstation", "OVFTool", "ovftool.exe"),
+				filepath.Join(dir, "VMware", "VMware OVF Tool", "ovftool.exe"))
+		}
+		return paths
+	default:
+		return []string{
+			"/usr/lib/vmware/bin/ovftool",
+			"/usr/lib/vmware-ovftool/ovftool",
+		}
 	}
-	return ovftool
 }
 
 func (s *StepExport) generateArgs(c *DriverConfig, displayName string, hidePassword bool) []string {
@@ -56,6 +97,17 @@ func (s *StepExport) generateArgs(c *DriverConfig, displayName string, hidePassw
 	return append(s.OVFToolOptions, args...)
 }
 
+func (s *StepExport) generateLocalArgs(vmxPath string) []string {
+	args := []string{
+		"--skipManifestCheck",
+		"-tt=" + s.Format,
+
+		vmxPath,
+		filepath.Join(s.OutputDir, s.VMName+"."+s.Format),
+	}
+	return append(s.OVFToolOptions, args...)
+}
+
 func (s *StepExport) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
 	c := state.Get("driverConfig").(*DriverConfig)
 	ui := state.Get("ui").(packer.Ui)
@@ -66,14 +118,19 @@ func (s *StepExport) Run(ctx context.Context, state multistep.StateBag) multiste
 		return multistep.ActionContinue
 	}
 
-	if c.RemoteType != "esx5" {
+	if c.RemoteType == "" {
+		if s.Format != "ova" && s.Format != "ovf" {
+			ui.Say("Skipping export of virtual machine (format is vmx)...")
+			return multistep.ActionContinue
+		}
+	} else if c.RemoteType != "esx5" {
 		ui.Say("Skipping export of virtual machine (export is allowed only for ESXi)...")
 		return multistep.ActionContinue
 	}
 
-	ovftool := GetOVFTool()
+	ovftool := c.ovfTool()
 	if ovftool == "" {
-		err := fmt.Errorf("Error %s not found: ", ovftool)
+		err := fmt.Errorf("Error exporting virtual machine: ovftool not found")
 		state.Put("error", err)
 		ui.Error(err.Error())
 		return multistep.ActionHalt
@@ -87,13 +144,21 @@ func (s *StepExport) Run(ctx context.Context, state multistep.StateBag) multiste
 	os.MkdirAll(s.OutputDir, 0755)
 
 	ui.Say("Exporting virtual machine...")
-	var displayName string
-	if v, ok := state.GetOk("display_name"); ok {
-		displayName = v.(string)
+	var args, logArgs []string
+	if c.RemoteType == "" {
+		args = s.generateLocalArgs(state.Get("vmx_path").(string))
+		logArgs = args
+	} else {
+		var displayName string
+		if v, ok := state.GetOk("display_name"); ok {
+			displayName = v.(string)
+		}
+		args = s.generateArgs(c, displayName, false)
+		logArgs = s.generateArgs(c, displayName, true)
 	}
-	ui.Message(fmt.Sprintf("Executing: %s %s", ovftool, strings.Join(s.generateArgs(c, displayName, true), " ")))
+	ui.Message(fmt.Sprintf("Executing: %s %s", ovftool, strings.Join(logArgs, " ")))
 	var out bytes.Buffer
-	cmd := exec.Command(ovftool, s.generateArgs(c, displayName, false)...)
+	cmd := exec.Command(ovftool, args...)
 	cmd.Stdout = &out
 	if err := cmd.Run(); err != nil {
 		err := fmt.Errorf("Error exporting virtual machine: %s\n%s\n", err, out.String())
@@ -2,6 +2,8 @@ package common
 
 import (
 	"context"
+	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/hashicorp/packer/helper/multistep"
@@ -34,3 +36,39 @@ func TestStepExport_wrongtype_impl(t *testing.T) {
 	testStepExport_wrongtype_impl(t, "foo")
 	testStepExport_wrongtype_impl(t, "")
 }
+
+func TestStepExport_localVMX(t *testing.T) {
+	state := testState(t)
+	step := &StepExport{Format: "vmx"}
+
+	var config DriverConfig
+	state.Put("driverConfig", &config)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if _, ok := state.GetOk("error"); ok {
+		t.Fatal("should NOT have error")
+	}
+}
+
+func TestStepExport_generateLocalArgs(t *testing.T) {
+	step := &StepExport{
+		Format:         "ova",
+		VMName:         "foo",
+		OutputDir:      "output",
+		OVFToolOptions: []string{"--compress=9"},
+	}
+
+	args := step.generateLocalArgs("output/foo.vmx")
+	expected := []string{
+		"--compress=9",
+		"--skipManifestCheck",
+		"-tt=ova",
+		"output/foo.vmx",
+		filepath.Join("output", "foo.ova"),
+	}
+	if !reflect.DeepEqual(args, expected) {
+		t.Fatalf("bad: %#v", args)
+	}
+}
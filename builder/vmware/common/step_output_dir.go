@@ -3,13 +3,20 @@ package common
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
 	"log"
+	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/hashicorp/packer/helper/multistep"
 	"github.com/hashicorp/packer/packer"
 )
 
+// keptDirs are the directories of a local output directory that are kept
+// when the build fails, since they explain why it failed.
+var keptDirs = []string{screenshotsDirName, diagnosticsDirName}
+
 // StepOutputDir sets up the output directory by creating it if it does
 // not exist, deleting it if it does exist and we're forcing, and cleaning
 // it up when we're done with it.
@@ -65,7 +72,7 @@ func (s *StepOutputDir) Cleanup(state multistep.StateBag) {
 		if exists {
 			ui.Say("Deleting output directory...")
 			for i := 0; i < 5; i++ {
-				err := dir.RemoveAll()
+				err := removeOutputDir(dir)
 				if err == nil {
 					break
 				}
@@ -76,3 +83,41 @@ func (s *StepOutputDir) Cleanup(state multistep.StateBag) {
 		}
 	}
 }
+
+// removeOutputDir removes the output directory, except for the kept
+// directories of a local output directory.
+func removeOutputDir(dir OutputDir) error {
+	local, ok := dir.(*LocalOutputDir)
+	if !ok {
+		return dir.RemoveAll()
+	}
+
+	entries, err := ioutil.ReadDir(local.dir)
+	if err != nil {
+		return err
+	}
+
+	kept := false
+	for _, entry := range entries {
+		if entry.IsDir() && isKeptDir(entry.Name()) {
+			kept = true
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(local.dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	if !kept {
+		return dir.RemoveAll()
+	}
+	return nil
+}
+
+func isKeptDir(name string) bool {
+	for _, kept := range keptDirs {
+		if name == kept {
+			return true
+		}
+	}
+	return false
+}
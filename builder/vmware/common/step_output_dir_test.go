@@ -4,6 +4,7 @@ import (
 	"context"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/hashicorp/packer/helper/multistep"
@@ -157,3 +158,37 @@ func TestStepOutputDir_halt(t *testing.T) {
 		t.Fatal("directory should not exist")
 	}
 }
+
+func TestStepOutputDir_haltKeepsDiagnostics(t *testing.T) {
+	state := testState(t)
+	step := new(StepOutputDir)
+
+	dir := testOutputDir(t)
+	defer os.RemoveAll(dir.dir)
+	state.Put("dir", dir)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	kept := []string{filepath.Join(dir.dir, "screenshots"), filepath.Join(dir.dir, "diagnostics")}
+	for _, d := range kept {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir.dir, "packer.vmx"), nil, 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	state.Put(multistep.StateHalted, true)
+	step.Cleanup(state)
+	if _, err := os.Stat(filepath.Join(dir.dir, "packer.vmx")); err == nil {
+		t.Fatal("VMX should be deleted")
+	}
+	for _, d := range kept {
+		if _, err := os.Stat(d); err != nil {
+			t.Fatalf("%s should be kept: %s", d, err)
+		}
+	}
+}
@@ -21,6 +21,7 @@ import (
 type StepRun struct {
 	DurationBeforeStop time.Duration
 	Headless           bool
+	StopTimeout        time.Duration
 
 	bootTime time.Time
 	vmxPath  string
@@ -57,7 +58,7 @@ func (s *StepRun) Run(ctx context.Context, state multistep.StateBag) multistep.S
 		}
 	}
 
-	if err := driver.Start(vmxPath, s.Headless); err != nil {
+	if err := driver.Start(ctx, vmxPath, s.Headless); err != nil {
 		err := fmt.Errorf("Error starting VM: %s", err)
 		state.Put("error", err)
 		ui.Error(err.Error())
@@ -84,10 +85,10 @@ func (s *StepRun) Cleanup(state multistep.StateBag) {
 		}
 
 		// See if it is running
-		running, _ := driver.IsRunning(s.vmxPath)
+		running, _ := driver.IsRunning(context.Background(), s.vmxPath)
 		if running {
 			ui.Say("Stopping virtual machine...")
-			if err := driver.Stop(s.vmxPath); err != nil {
+			if err := StopVM(context.Background(), ui, driver, s.vmxPath, s.StopTimeout); err != nil {
 				ui.Error(fmt.Sprintf("Error stopping VM: %s", err))
 			}
 		}
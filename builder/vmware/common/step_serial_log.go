@@ -0,0 +1,86 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// serialLogTailSize is the amount of the end of the serial console log that
+// is shown when the build fails.
+const serialLogTailSize = 4096
+
+// This step shows the end of the serial console log of the guest when the
+// build fails or is cancelled, which helps to diagnose guests that never
+// bring up their network.
+//
+// Uses:
+//   ui packer.Ui
+//
+// Produces:
+//   <nothing>
+type StepSerialLog struct {
+	Path string
+}
+
+func (s *StepSerialLog) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	if s.Path == "" {
+		return multistep.ActionContinue
+	}
+
+	// Don't show the log of a previous build
+	if err := os.Remove(s.Path); err != nil && !os.IsNotExist(err) {
+		log.Printf("Error removing serial log %s: %s", s.Path, err)
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *StepSerialLog) Cleanup(state multistep.StateBag) {
+	if s.Path == "" {
+		return
+	}
+
+	_, cancelled := state.GetOk(multistep.StateCancelled)
+	_, halted := state.GetOk(multistep.StateHalted)
+	if !cancelled && !halted {
+		return
+	}
+
+	ui := state.Get("ui").(packer.Ui)
+
+	tail, err := serialLogTail(s.Path, serialLogTailSize)
+	if err != nil {
+		log.Printf("Error reading serial log %s: %s", s.Path, err)
+		return
+	}
+	if tail == "" {
+		return
+	}
+
+	ui.Error(fmt.Sprintf("The end of the serial console log (%s):\n\n%s", s.Path, tail))
+}
+
+// serialLogTail returns at most the last size bytes of the log, starting at
+// a line boundary.
+func serialLogTail(path string, size int) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	if len(data) > size {
+		data = data[len(data)-size:]
+		if i := strings.IndexByte(string(data), '\n'); i >= 0 {
+			data = data[i+1:]
+		}
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
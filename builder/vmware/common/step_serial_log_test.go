@@ -0,0 +1,89 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+func TestStepSerialLog_impl(t *testing.T) {
+	var _ multistep.Step = new(StepSerialLog)
+}
+
+func TestStepSerialLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	logPath := filepath.Join(dir, "serial.log")
+	if err := ioutil.WriteFile(logPath, []byte("previous build"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	state := testState(t)
+	output := new(bytes.Buffer)
+	state.Put("ui", &packer.BasicUi{
+		Reader: new(bytes.Buffer),
+		Writer: output,
+	})
+	step := &StepSerialLog{Path: logPath}
+
+	// The log of a previous build is removed
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if _, err := os.Stat(logPath); !os.IsNotExist(err) {
+		t.Fatalf("log should be removed: %s", err)
+	}
+
+	if err := ioutil.WriteFile(logPath, []byte("Booting\nKernel panic\n"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// Nothing is shown when the build succeeds
+	step.Cleanup(state)
+	if output.Len() != 0 {
+		t.Fatalf("bad output: %s", output.String())
+	}
+
+	state.Put(multistep.StateHalted, true)
+	step.Cleanup(state)
+	if !strings.Contains(output.String(), "Kernel panic") {
+		t.Fatalf("bad output: %s", output.String())
+	}
+}
+
+func TestSerialLogTail(t *testing.T) {
+	f, err := ioutil.TempFile("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString("first line\nsecond line\nthird line\n")
+	f.Close()
+
+	tail, err := serialLogTail(f.Name(), 15)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if tail != "third line" {
+		t.Fatalf("bad tail: %q", tail)
+	}
+
+	tail, err = serialLogTail(f.Name(), 1024)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if tail != "first line\nsecond line\nthird line" {
+		t.Fatalf("bad tail: %q", tail)
+	}
+}
@@ -0,0 +1,122 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// StepAddSharedFolders shares host directories with the running guest
+// through vmrun, so provisioners can use large local files without
+// uploading them.
+//
+// Uses:
+//   driver   Driver
+//   ui       packer.Ui
+//   vmx_path string
+//
+// Produces:
+//   <nothing>
+type StepAddSharedFolders struct {
+	Folders []SharedFolder
+}
+
+func (s *StepAddSharedFolders) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	if len(s.Folders) == 0 {
+		return multistep.ActionContinue
+	}
+
+	ui := state.Get("ui").(packer.Ui)
+	vmxPath := state.Get("vmx_path").(string)
+
+	vd, err := sharedFoldersVmrun(state)
+	if err == nil {
+		ui.Say("Adding shared folders...")
+		err = runVmrun(ctx, vd, "enableSharedFolders", vmxPath)
+	}
+	for _, folder := range s.Folders {
+		if err != nil {
+			break
+		}
+		ui.Message(fmt.Sprintf("Sharing %s as '%s'", folder.HostPath, folder.Name))
+		err = runVmrun(ctx, vd, "addSharedFolder", vmxPath, folder.Name, folder.HostPath)
+	}
+
+	if err != nil {
+		err := fmt.Errorf("Error adding shared folders: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *StepAddSharedFolders) Cleanup(multistep.StateBag) {}
+
+// StepRemoveSharedFolders removes the shared folders again after
+// provisioning, so they don't end up in the artifact.
+//
+// Uses:
+//   driver   Driver
+//   ui       packer.Ui
+//   vmx_path string
+//
+// Produces:
+//   <nothing>
+type StepRemoveSharedFolders struct {
+	Folders []SharedFolder
+}
+
+func (s *StepRemoveSharedFolders) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	if len(s.Folders) == 0 {
+		return multistep.ActionContinue
+	}
+
+	ui := state.Get("ui").(packer.Ui)
+	vmxPath := state.Get("vmx_path").(string)
+
+	vd, err := sharedFoldersVmrun(state)
+	if err == nil {
+		ui.Say("Removing shared folders...")
+	}
+	for _, folder := range s.Folders {
+		if err != nil {
+			break
+		}
+		err = runVmrun(ctx, vd, "removeSharedFolder", vmxPath, folder.Name)
+	}
+	if err == nil {
+		err = runVmrun(ctx, vd, "disableSharedFolders", vmxPath)
+	}
+
+	if err != nil {
+		err := fmt.Errorf("Error removing shared folders: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *StepRemoveSharedFolders) Cleanup(multistep.StateBag) {}
+
+func sharedFoldersVmrun(state multistep.StateBag) (vmrunDriver, error) {
+	driver := state.Get("driver").(Driver)
+	vd, ok := driver.(vmrunDriver)
+	if !ok {
+		return nil, fmt.Errorf("shared folders aren't supported by this VMware driver")
+	}
+	return vd, nil
+}
+
+func runVmrun(ctx context.Context, vd vmrunDriver, args ...string) error {
+	vmrunPath, hostType := vd.vmrun()
+	cmd := exec.Command(vmrunPath, append([]string{"-T", hostType}, args...)...)
+	_, _, err := vd.runAndLog(ctx, cmd)
+	return err
+}
@@ -0,0 +1,58 @@
+package common
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/packer/helper/multistep"
+)
+
+func TestStepAddSharedFolders_impl(t *testing.T) {
+	var _ multistep.Step = new(StepAddSharedFolders)
+}
+
+func TestStepRemoveSharedFolders_impl(t *testing.T) {
+	var _ multistep.Step = new(StepRemoveSharedFolders)
+}
+
+func TestStepAddSharedFolders_none(t *testing.T) {
+	state := testState(t)
+	step := new(StepAddSharedFolders)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if _, ok := state.GetOk("error"); ok {
+		t.Fatal("should NOT have error")
+	}
+}
+
+func TestStepAddSharedFolders_unsupported(t *testing.T) {
+	state := testState(t)
+	step := &StepAddSharedFolders{
+		Folders: []SharedFolder{{Name: "foo", HostPath: "/foo"}},
+	}
+	state.Put("vmx_path", "foo.vmx")
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if _, ok := state.GetOk("error"); !ok {
+		t.Fatal("should have error")
+	}
+}
+
+func TestStepRemoveSharedFolders_unsupported(t *testing.T) {
+	state := testState(t)
+	step := &StepRemoveSharedFolders{
+		Folders: []SharedFolder{{Name: "foo", HostPath: "/foo"}},
+	}
+	state.Put("vmx_path", "foo.vmx")
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if _, ok := state.GetOk("error"); !ok {
+		t.Fatal("should have error")
+	}
+}
@@ -3,7 +3,6 @@ package common
 import (
 	"bytes"
 	"context"
-	"errors"
 	"fmt"
 	"log"
 	"regexp"
@@ -30,6 +29,13 @@ type StepShutdown struct {
 	Command string
 	Timeout time.Duration
 
+	// How long to wait for a soft stop when there is no Command, see
+	// StopVM.
+	StopTimeout time.Duration
+
+	// Suspend the machine instead of shutting it down.
+	Suspend bool
+
 	// Set this to true if we're testing
 	Testing bool
 }
@@ -41,7 +47,15 @@ func (s *StepShutdown) Run(ctx context.Context, state multistep.StateBag) multis
 	ui := state.Get("ui").(packer.Ui)
 	vmxPath := state.Get("vmx_path").(string)
 
-	if s.Command != "" {
+	if s.Suspend {
+		ui.Say("Suspending virtual machine...")
+		if err := driver.Suspend(ctx, vmxPath); err != nil {
+			err := fmt.Errorf("Error suspending VM: %s", err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+	} else if s.Command != "" {
 		ui.Say("Gracefully halting virtual machine...")
 		log.Printf("Executing shutdown command: %s", s.Command)
 
@@ -61,8 +75,9 @@ func (s *StepShutdown) Run(ctx context.Context, state multistep.StateBag) multis
 		// Wait for the machine to actually shut down
 		log.Printf("Waiting max %s for shutdown to complete", s.Timeout)
 		shutdownTimer := time.After(s.Timeout)
+	WaitLoop:
 		for {
-			running, _ := driver.IsRunning(vmxPath)
+			running, _ := driver.IsRunning(ctx, vmxPath)
 			if !running {
 				break
 			}
@@ -71,17 +86,28 @@ func (s *StepShutdown) Run(ctx context.Context, state multistep.StateBag) multis
 			case <-shutdownTimer:
 				log.Printf("Shutdown stdout: %s", stdout.String())
 				log.Printf("Shutdown stderr: %s", stderr.String())
-				err := errors.New("Timeout while waiting for machine to shut down.")
-				state.Put("error", err)
-				ui.Error(err.Error())
-				return multistep.ActionHalt
+
+				// Only halt the machine the hard way once the guest had
+				// its chance to shut down cleanly.
+				ui.Error(fmt.Sprintf("Timeout after %s waiting for machine to shut down (shutdown_timeout). Forcibly halting...", s.Timeout))
+				if err := StopVM(ctx, ui, driver, vmxPath, 0); err != nil {
+					err := fmt.Errorf("Error stopping VM: %s", err)
+					state.Put("error", err)
+					ui.Error(err.Error())
+					return multistep.ActionHalt
+				}
+				break WaitLoop
 			default:
 				time.Sleep(150 * time.Millisecond)
 			}
 		}
 	} else {
-		ui.Say("Forcibly halting virtual machine...")
-		if err := driver.Stop(vmxPath); err != nil {
+		if s.StopTimeout > 0 {
+			ui.Say("Halting virtual machine...")
+		} else {
+			ui.Say("Forcibly halting virtual machine...")
+		}
+		if err := StopVM(ctx, ui, driver, vmxPath, s.StopTimeout); err != nil {
 			err := fmt.Errorf("Error stopping VM: %s", err)
 			state.Put("error", err)
 			ui.Error(err.Error())
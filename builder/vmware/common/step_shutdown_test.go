@@ -127,6 +127,76 @@ func TestStepShutdown_noCommand(t *testing.T) {
 	}
 }
 
+func TestStepShutdown_timeout(t *testing.T) {
+	state := testStepShutdownState(t)
+	step := new(StepShutdown)
+	step.Command = "foo"
+	step.Timeout = 100 * time.Millisecond
+	step.Testing = true
+
+	driver := state.Get("driver").(*DriverMock)
+	driver.IsRunningResult = true
+
+	// Test the run
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if _, ok := state.GetOk("error"); ok {
+		t.Fatal("should NOT have error")
+	}
+
+	// The machine should be forcibly halted after the timeout
+	if !driver.StopCalled {
+		t.Fatal("stop should be called")
+	}
+
+	// Clean up the created test output directory
+	dir := state.Get("dir").(*LocalOutputDir)
+	if err := dir.RemoveAll(); err != nil {
+		t.Fatalf("Error cleaning up directory: %s", err)
+	}
+}
+
+func TestStepShutdown_suspend(t *testing.T) {
+	state := testStepShutdownState(t)
+	step := new(StepShutdown)
+	step.Command = "foo"
+	step.Suspend = true
+	step.Testing = true
+
+	comm := state.Get("communicator").(*packer.MockCommunicator)
+	driver := state.Get("driver").(*DriverMock)
+
+	// Test the run
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if _, ok := state.GetOk("error"); ok {
+		t.Fatal("should NOT have error")
+	}
+
+	// Test the driver
+	if !driver.SuspendCalled {
+		t.Fatal("suspend should be called")
+	}
+	if driver.SuspendPath != "foo" {
+		t.Fatal("should call with right path")
+	}
+	if driver.StopCalled {
+		t.Fatal("stop should not be called")
+	}
+
+	if comm.StartCalled {
+		t.Fatal("start should not be called")
+	}
+
+	// Clean up the created test output directory
+	dir := state.Get("dir").(*LocalOutputDir)
+	if err := dir.RemoveAll(); err != nil {
+		t.Fatalf("Error cleaning up directory: %s", err)
+	}
+}
+
 func TestStepShutdown_locks(t *testing.T) {
 	if os.Getenv("PACKER_ACC") == "" {
 		t.Skip("This test is only run with PACKER_ACC=1 due to the requirement of access to the VMware binaries.")
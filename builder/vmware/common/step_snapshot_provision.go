@@ -0,0 +1,125 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// This step runs the provisioners. If retries are enabled, a snapshot of
+// the freshly installed machine is taken first so that a failed
+// provisioning run can be retried from it without reinstalling the OS.
+// The snapshot is deleted once provisioning succeeds.
+//
+// Uses:
+//   communicator packer.Communicator
+//   driver Driver
+//   hook   packer.Hook
+//   ui     packer.Ui
+//   vmx_path string
+//
+// Produces:
+//   <nothing>
+type StepSnapshotProvision struct {
+	SnapshotName string
+	Retries      int
+	Headless     bool
+}
+
+func (s *StepSnapshotProvision) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	driver := state.Get("driver").(Driver)
+	ui := state.Get("ui").(packer.Ui)
+	vmxPath := state.Get("vmx_path").(string)
+
+	halt := func(err error) multistep.StepAction {
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	// A cancelled build halts without an error, like the provision step.
+	cancelled := func() bool {
+		_, ok := state.GetOk(multistep.StateCancelled)
+		return ok || ctx.Err() != nil
+	}
+
+	if s.SnapshotName == "" || s.Retries == 0 {
+		if err := s.provision(ctx, state); err != nil {
+			if !cancelled() {
+				state.Put("error", err)
+			}
+			return multistep.ActionHalt
+		}
+		return multistep.ActionContinue
+	}
+
+	ui.Say(fmt.Sprintf("Creating snapshot %s...", s.SnapshotName))
+	if err := driver.CreateSnapshot(ctx, vmxPath, s.SnapshotName); err != nil {
+		return halt(fmt.Errorf("Error creating snapshot: %s", err))
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := s.provision(ctx, state)
+		if err == nil {
+			break
+		}
+		if cancelled() {
+			return multistep.ActionHalt
+		}
+		if attempt >= s.Retries {
+			state.Put("error", err)
+			return multistep.ActionHalt
+		}
+
+		ui.Error(fmt.Sprintf("Provisioning failed: %s", err))
+		ui.Say(fmt.Sprintf("Reverting to snapshot %s and retrying...", s.SnapshotName))
+		if err := driver.RevertToSnapshot(ctx, vmxPath, s.SnapshotName); err != nil {
+			return halt(fmt.Errorf("Error reverting to snapshot: %s", err))
+		}
+		if err := driver.Start(ctx, vmxPath, s.Headless); err != nil {
+			return halt(fmt.Errorf("Error starting VM: %s", err))
+		}
+	}
+
+	ui.Say(fmt.Sprintf("Deleting snapshot %s...", s.SnapshotName))
+	if err := driver.DeleteSnapshot(ctx, vmxPath, s.SnapshotName); err != nil {
+		return halt(fmt.Errorf("Error deleting snapshot: %s", err))
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *StepSnapshotProvision) Cleanup(state multistep.StateBag) {}
+
+// provision runs the provision hook, returning early if the build is
+// cancelled.
+func (s *StepSnapshotProvision) provision(ctx context.Context, state multistep.StateBag) error {
+	comm, _ := state.Get("communicator").(packer.Communicator)
+	hook := state.Get("hook").(packer.Hook)
+	ui := state.Get("ui").(packer.Ui)
+
+	log.Println("Running the provision hook")
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- hook.Run(ctx, packer.HookProvision, ui, comm, nil)
+	}()
+
+	for {
+		select {
+		case err := <-errCh:
+			return err
+		case <-ctx.Done():
+			log.Printf("Cancelling provisioning due to context cancellation: %s", ctx.Err())
+			return ctx.Err()
+		case <-time.After(1 * time.Second):
+			if _, ok := state.GetOk(multistep.StateCancelled); ok {
+				log.Println("Cancelling provisioning due to interrupt...")
+				return fmt.Errorf("Provisioning was cancelled")
+			}
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+func testStepSnapshotProvisionState(t *testing.T) multistep.StateBag {
+	state := testState(t)
+	state.Put("communicator", new(packer.MockCommunicator))
+	state.Put("hook", new(packer.MockHook))
+	state.Put("vmx_path", "foo")
+	return state
+}
+
+func TestStepSnapshotProvision_impl(t *testing.T) {
+	var _ multistep.Step = new(StepSnapshotProvision)
+}
+
+func TestStepSnapshotProvision_noSnapshot(t *testing.T) {
+	state := testStepSnapshotProvisionState(t)
+	step := new(StepSnapshotProvision)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if _, ok := state.GetOk("error"); ok {
+		t.Fatal("should NOT have error")
+	}
+
+	driver := state.Get("driver").(*DriverMock)
+	hook := state.Get("hook").(*packer.MockHook)
+	if !hook.RunCalled || hook.RunName != packer.HookProvision {
+		t.Fatal("should have run the provision hook")
+	}
+	if driver.CreateSnapshotCalled {
+		t.Fatal("should NOT have created a snapshot")
+	}
+}
+
+func TestStepSnapshotProvision_snapshot(t *testing.T) {
+	state := testStepSnapshotProvisionState(t)
+	step := &StepSnapshotProvision{SnapshotName: "installed", Retries: 1}
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	driver := state.Get("driver").(*DriverMock)
+	if !driver.CreateSnapshotCalled || driver.CreateSnapshotName != "installed" {
+		t.Fatal("should have created the snapshot")
+	}
+	if driver.RevertToSnapshotCalled {
+		t.Fatal("should NOT have reverted")
+	}
+	if !driver.DeleteSnapshotCalled || driver.DeleteSnapshotName != "installed" {
+		t.Fatal("should have deleted the snapshot")
+	}
+}
+
+func TestStepSnapshotProvision_noRetries(t *testing.T) {
+	state := testStepSnapshotProvisionState(t)
+	step := &StepSnapshotProvision{SnapshotName: "installed"}
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	driver := state.Get("driver").(*DriverMock)
+	if driver.CreateSnapshotCalled || driver.DeleteSnapshotCalled {
+		t.Fatal("should NOT have taken a snapshot without retries")
+	}
+}
+
+func TestStepSnapshotProvision_retry(t *testing.T) {
+	state := testStepSnapshotProvisionState(t)
+	step := &StepSnapshotProvision{SnapshotName: "installed", Retries: 1}
+
+	hook := state.Get("hook").(*packer.MockHook)
+	runs := 0
+	hook.RunFunc = func(context.Context) error {
+		runs++
+		if runs == 1 {
+			return errors.New("provisioning failed")
+		}
+		return nil
+	}
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if runs != 2 {
+		t.Fatalf("bad runs: %d", runs)
+	}
+
+	driver := state.Get("driver").(*DriverMock)
+	if !driver.RevertToSnapshotCalled {
+		t.Fatal("should have reverted")
+	}
+	if !driver.StartCalled {
+		t.Fatal("should have started the VM after reverting")
+	}
+	if !driver.DeleteSnapshotCalled {
+		t.Fatal("should have deleted the snapshot")
+	}
+}
+
+func TestStepSnapshotProvision_retriesExhausted(t *testing.T) {
+	state := testStepSnapshotProvisionState(t)
+	step := &StepSnapshotProvision{SnapshotName: "installed", Retries: 1}
+
+	hook := state.Get("hook").(*packer.MockHook)
+	hook.RunFunc = func(context.Context) error {
+		return errors.New("provisioning failed")
+	}
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if _, ok := state.GetOk("error"); !ok {
+		t.Fatal("should have error")
+	}
+
+	driver := state.Get("driver").(*DriverMock)
+	if driver.DeleteSnapshotCalled {
+		t.Fatal("should NOT have deleted the snapshot")
+	}
+}
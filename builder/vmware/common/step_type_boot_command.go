@@ -15,7 +15,8 @@ import (
 	"github.com/mitchellh/go-vnc"
 )
 
-// This step "types" the boot command into the VM over VNC.
+// This step "types" the boot command into the VM over VNC, or with the USB
+// keyboard of the driver when USBKeyboard is set.
 //
 // Uses:
 //   http_port int
@@ -31,7 +32,24 @@ type StepTypeBootCommand struct {
 	VMName      string
 	Ctx         interpolate.Context
 	KeyInterval time.Duration
+
+	// Type the boot command with the USBKeyboardDriver methods of the driver,
+	// through the hypervisor API, instead of over VNC.
+	USBKeyboard bool
+
+	// How long to keep trying to connect to the VNC server of the VM, which
+	// may not be listening yet right after boot_wait.
+	BootTimeout time.Duration
 }
+
+// USBKeyboardDriver is implemented by the drivers that can type into the
+// console of the VM without VNC.
+type USBKeyboardDriver interface {
+	// SendUSBCode presses and releases a key, given as its USB HID usage ID,
+	// with the given modifier keys held down.
+	SendUSBCode(ctx context.Context, code uint16, modifiers bootcommand.USBModifiers) error
+}
+
 type bootCommandTemplateData struct {
 	HTTPIP   string
 	HTTPPort int
@@ -39,7 +57,7 @@ type bootCommandTemplateData struct {
 }
 
 func (s *StepTypeBootCommand) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
-	if !s.VNCEnabled {
+	if !s.VNCEnabled && !s.USBKeyboard {
 		log.Println("Skipping boot command step...")
 		return multistep.ActionContinue
 	}
@@ -48,9 +66,6 @@ func (s *StepTypeBootCommand) Run(ctx context.Context, state multistep.StateBag)
 	driver := state.Get("driver").(Driver)
 	httpPort := state.Get("http_port").(int)
 	ui := state.Get("ui").(packer.Ui)
-	vncIp := state.Get("vnc_ip").(string)
-	vncPort := state.Get("vnc_port").(int)
-	vncPassword := state.Get("vnc_password")
 
 	// Wait the for the vm to boot.
 	if int64(s.BootWait) > 0 {
@@ -68,36 +83,58 @@ func (s *StepTypeBootCommand) Run(ctx context.Context, state multistep.StateBag)
 		pauseFn = state.Get("pauseFn").(multistep.DebugPauseFn)
 	}
 
-	// Connect to VNC
-	ui.Say(fmt.Sprintf("Connecting to VM via VNC (%s:%d)", vncIp, vncPort))
+	var d bootcommand.BCDriver
+	if s.USBKeyboard {
+		keyboard, ok := driver.(USBKeyboardDriver)
+		if !ok {
+			err := fmt.Errorf("The %s driver can't type the boot command without VNC", driverName(driver))
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+		d = bootcommand.NewUSBDriver(func(code uint16, modifiers bootcommand.USBModifiers) error {
+			return keyboard.SendUSBCode(ctx, code, modifiers)
+		}, s.KeyInterval)
+		ui.Say("Typing the boot command with the USB keyboard...")
+	} else {
+		vncIp := state.Get("vnc_ip").(string)
+		vncPort := state.Get("vnc_port").(int)
+		vncPassword := state.Get("vnc_password")
+
+		// Connect to VNC
+		ui.Say(fmt.Sprintf("Connecting to VM via VNC (%s:%d)", vncIp, vncPort))
+
+		nc, err := dialVNC(ctx, fmt.Sprintf("%s:%d", vncIp, vncPort), s.BootTimeout)
+		if err != nil {
+			err := fmt.Errorf("Error connecting to VNC after %s (boot_timeout): %s", s.BootTimeout, err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+		defer nc.Close()
 
-	nc, err := net.Dial("tcp", fmt.Sprintf("%s:%d", vncIp, vncPort))
-	if err != nil {
-		err := fmt.Errorf("Error connecting to VNC: %s", err)
-		state.Put("error", err)
-		ui.Error(err.Error())
-		return multistep.ActionHalt
-	}
-	defer nc.Close()
+		var auth []vnc.ClientAuth
 
-	var auth []vnc.ClientAuth
+		if vncPassword != nil && len(vncPassword.(string)) > 0 {
+			auth = []vnc.ClientAuth{&vnc.PasswordAuth{Password: vncPassword.(string)}}
+		} else {
+			auth = []vnc.ClientAuth{new(vnc.ClientAuthNone)}
+		}
 
-	if vncPassword != nil && len(vncPassword.(string)) > 0 {
-		auth = []vnc.ClientAuth{&vnc.PasswordAuth{Password: vncPassword.(string)}}
-	} else {
-		auth = []vnc.ClientAuth{new(vnc.ClientAuthNone)}
-	}
+		c, err := vnc.Client(nc, &vnc.ClientConfig{Auth: auth, Exclusive: true})
+		if err != nil {
+			err := fmt.Errorf("Error handshaking with VNC: %s", err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+		defer c.Close()
 
-	c, err := vnc.Client(nc, &vnc.ClientConfig{Auth: auth, Exclusive: true})
-	if err != nil {
-		err := fmt.Errorf("Error handshaking with VNC: %s", err)
-		state.Put("error", err)
-		ui.Error(err.Error())
-		return multistep.ActionHalt
-	}
-	defer c.Close()
+		log.Printf("Connected to VNC desktop: %s", c.DesktopName)
 
-	log.Printf("Connected to VNC desktop: %s", c.DesktopName)
+		d = bootcommand.NewVNCDriver(c, s.KeyInterval)
+		ui.Say("Typing the boot command over VNC...")
+	}
 
 	// Determine the host IP
 	hostIP, err := driver.HostIP(state)
@@ -117,9 +154,6 @@ func (s *StepTypeBootCommand) Run(ctx context.Context, state multistep.StateBag)
 		s.VMName,
 	}
 
-	d := bootcommand.NewVNCDriver(c, s.KeyInterval)
-
-	ui.Say("Typing the boot command over VNC...")
 	command, err := interpolate.Render(s.BootCommand, &s.Ctx)
 	if err != nil {
 		err := fmt.Errorf("Error preparing boot command: %s", err)
@@ -152,3 +186,25 @@ func (s *StepTypeBootCommand) Run(ctx context.Context, state multistep.StateBag)
 }
 
 func (*StepTypeBootCommand) Cleanup(multistep.StateBag) {}
+
+// dialVNC connects to the VNC server at the given address, retrying until
+// it accepts the connection or the timeout expires.
+func dialVNC(ctx context.Context, address string, timeout time.Duration) (net.Conn, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		nc, err := net.DialTimeout("tcp", address, 5*time.Second)
+		if err == nil {
+			return nc, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, err
+		}
+
+		log.Printf("Error connecting to VNC, retrying: %s", err)
+		select {
+		case <-time.After(1 * time.Second):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
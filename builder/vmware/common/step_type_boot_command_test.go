@@ -0,0 +1,117 @@
+package common
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/packer/common/bootcommand"
+	"github.com/hashicorp/packer/helper/multistep"
+)
+
+func TestDialVNC(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer l.Close()
+
+	nc, err := dialVNC(context.Background(), l.Addr().String(), time.Second)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	nc.Close()
+}
+
+func TestDialVNC_retry(t *testing.T) {
+	// Find a free port, then only start listening on it after a while
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	address := l.Addr().String()
+	l.Close()
+
+	go func() {
+		time.Sleep(500 * time.Millisecond)
+		l, err := net.Listen("tcp", address)
+		if err != nil {
+			return
+		}
+		defer l.Close()
+		if c, err := l.Accept(); err == nil {
+			c.Close()
+		}
+	}()
+
+	nc, err := dialVNC(context.Background(), address, 5*time.Second)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	nc.Close()
+}
+
+func TestDialVNC_timeout(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	address := l.Addr().String()
+	l.Close()
+
+	if _, err := dialVNC(context.Background(), address, 0); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+type usbKeyboardDriverMock struct {
+	DriverMock
+
+	codes []uint16
+}
+
+func (d *usbKeyboardDriverMock) SendUSBCode(ctx context.Context, code uint16, modifiers bootcommand.USBModifiers) error {
+	d.codes = append(d.codes, code)
+	return nil
+}
+
+func TestStepTypeBootCommand_usbKeyboard(t *testing.T) {
+	state := testState(t)
+	driver := new(usbKeyboardDriverMock)
+	state.Put("driver", driver)
+	state.Put("debug", false)
+	state.Put("http_port", 8080)
+
+	step := &StepTypeBootCommand{
+		BootCommand: "ab<enter>",
+		USBKeyboard: true,
+		KeyInterval: time.Millisecond,
+	}
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", state.Get("error"))
+	}
+
+	expected := []uint16{0x04, 0x05, 0x28}
+	if !reflect.DeepEqual(driver.codes, expected) {
+		t.Fatalf("bad codes: %#v", driver.codes)
+	}
+}
+
+func TestStepTypeBootCommand_usbKeyboardUnsupported(t *testing.T) {
+	state := testState(t)
+	state.Put("debug", false)
+	state.Put("http_port", 8080)
+
+	step := &StepTypeBootCommand{
+		BootCommand: "ab<enter>",
+		USBKeyboard: true,
+	}
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if _, ok := state.GetOk("error"); !ok {
+		t.Fatal("should have error")
+	}
+}
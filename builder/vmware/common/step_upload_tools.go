@@ -23,6 +23,7 @@ type StepUploadTools struct {
 
 func (c *StepUploadTools) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
 	driver := state.Get("driver").(Driver)
+	ui := state.Get("ui").(packer.Ui)
 
 	if c.ToolsUploadFlavor == "" {
 		return multistep.ActionContinue
@@ -30,14 +31,16 @@ func (c *StepUploadTools) Run(ctx context.Context, state multistep.StateBag) mul
 
 	if c.RemoteType == "esx5" {
 		if err := driver.ToolsInstall(); err != nil {
-			state.Put("error", fmt.Errorf("Couldn't mount VMware tools ISO. Please check the 'guest_os_type' in your template.json."))
+			err := fmt.Errorf("Couldn't mount VMware tools ISO. Please check the 'guest_os_type' in your template.json.")
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
 		}
 		return multistep.ActionContinue
 	}
 
 	comm := state.Get("communicator").(packer.Communicator)
 	tools_source := state.Get("tools_upload_source").(string)
-	ui := state.Get("ui").(packer.Ui)
 
 	ui.Say(fmt.Sprintf("Uploading the '%s' VMware Tools", c.ToolsUploadFlavor))
 	f, err := os.Open(tools_source)
@@ -0,0 +1,80 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+func TestStepUploadTools_impl(t *testing.T) {
+	var _ multistep.Step = new(StepUploadTools)
+}
+
+func TestStepUploadTools(t *testing.T) {
+	state := testState(t)
+	step := &StepUploadTools{
+		ToolsUploadFlavor: "linux",
+		ToolsUploadPath:   "/tmp/{{ .Flavor }}.iso",
+	}
+
+	tf, err := ioutil.TempFile("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	tf.WriteString("tools")
+	tf.Close()
+	defer os.Remove(tf.Name())
+
+	comm := new(packer.MockCommunicator)
+	state.Put("communicator", comm)
+	state.Put("tools_upload_source", tf.Name())
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if _, ok := state.GetOk("error"); ok {
+		t.Fatal("should NOT have error")
+	}
+
+	if comm.UploadPath != "/tmp/linux.iso" {
+		t.Fatalf("bad path: %s", comm.UploadPath)
+	}
+	if comm.UploadData != "tools" {
+		t.Fatalf("bad data: %s", comm.UploadData)
+	}
+}
+
+func TestStepUploadTools_noFlavor(t *testing.T) {
+	state := testState(t)
+	step := new(StepUploadTools)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if _, ok := state.GetOk("error"); ok {
+		t.Fatal("should NOT have error")
+	}
+}
+
+func TestStepUploadTools_esx5Error(t *testing.T) {
+	state := testState(t)
+	step := &StepUploadTools{
+		RemoteType:        "esx5",
+		ToolsUploadFlavor: "linux",
+	}
+
+	driver := state.Get("driver").(*DriverMock)
+	driver.ToolsInstallErr = errors.New("error")
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if _, ok := state.GetOk("error"); !ok {
+		t.Fatal("should have error")
+	}
+}
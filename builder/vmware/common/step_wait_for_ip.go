@@ -0,0 +1,63 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// ipWaitInterval is how often the IP address of the VM is looked up.
+var ipWaitInterval = 5 * time.Second
+
+// This step waits for the VM to report an IP address, so that a VM that
+// never gets one fails with its own error instead of an SSH timeout. It is
+// skipped when Timeout is zero and for the communicators that don't need
+// the IP address.
+//
+// Uses:
+//   driver Driver
+//   ui packer.Ui
+//
+// Produces:
+//   <nothing>
+type StepWaitForIP struct {
+	CommType string
+	Timeout  time.Duration
+}
+
+func (s *StepWaitForIP) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	if s.Timeout == 0 || s.CommType == "none" || s.CommType == "vmrun" {
+		return multistep.ActionContinue
+	}
+
+	driver := state.Get("driver").(Driver)
+	ui := state.Get("ui").(packer.Ui)
+
+	ui.Say("Waiting for the IP address of the VM...")
+	timeout := time.After(s.Timeout)
+	for {
+		ip, err := driver.CommHost(state)
+		if err == nil {
+			ui.Message(fmt.Sprintf("Found IP address: %s", ip))
+			return multistep.ActionContinue
+		}
+		log.Printf("Waiting for the IP address of the VM: %s", err)
+
+		select {
+		case <-ctx.Done():
+			return multistep.ActionHalt
+		case <-timeout:
+			err := fmt.Errorf("Timeout waiting for the IP address of the VM after %s (ip_wait_timeout)", s.Timeout)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		case <-time.After(ipWaitInterval):
+		}
+	}
+}
+
+func (s *StepWaitForIP) Cleanup(multistep.StateBag) {}
@@ -0,0 +1,70 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/packer/helper/multistep"
+)
+
+func TestStepWaitForIP_impl(t *testing.T) {
+	var _ multistep.Step = new(StepWaitForIP)
+}
+
+func TestStepWaitForIP(t *testing.T) {
+	state := testState(t)
+	step := &StepWaitForIP{CommType: "ssh", Timeout: time.Minute}
+
+	driver := state.Get("driver").(*DriverMock)
+	driver.CommHostResult = "192.168.1.2"
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if _, ok := state.GetOk("error"); ok {
+		t.Fatal("should NOT have error")
+	}
+	if !driver.CommHostCalled {
+		t.Fatal("comm host should be called")
+	}
+}
+
+func TestStepWaitForIP_timeout(t *testing.T) {
+	defer func(interval time.Duration) { ipWaitInterval = interval }(ipWaitInterval)
+	ipWaitInterval = 10 * time.Millisecond
+
+	state := testState(t)
+	step := &StepWaitForIP{CommType: "ssh", Timeout: 50 * time.Millisecond}
+
+	driver := state.Get("driver").(*DriverMock)
+	driver.CommHostErr = errors.New("IP is blank")
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+	err, ok := state.GetOk("error")
+	if !ok {
+		t.Fatal("should have error")
+	}
+	if err.(error).Error() != "Timeout waiting for the IP address of the VM after 50ms (ip_wait_timeout)" {
+		t.Fatalf("bad error: %s", err)
+	}
+}
+
+func TestStepWaitForIP_skip(t *testing.T) {
+	for _, step := range []*StepWaitForIP{
+		{CommType: "ssh"},
+		{CommType: "none", Timeout: time.Minute},
+		{CommType: "vmrun", Timeout: time.Minute},
+	} {
+		state := testState(t)
+		if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+			t.Fatalf("bad action: %#v", action)
+		}
+		if state.Get("driver").(*DriverMock).CommHostCalled {
+			t.Fatalf("comm host should NOT be called: %#v", step)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// This step runs a command in the guest that fills the free space of the
+// disks with zeros, so that compacting them afterwards frees as much space
+// as possible.
+//
+// Uses:
+//   communicator packer.Communicator
+//   ui           packer.Ui
+//
+// Produces:
+//   <nothing>
+type StepZeroFill struct {
+	Command string
+	Skip    bool
+}
+
+func (s *StepZeroFill) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	if s.Command == "" {
+		return multistep.ActionContinue
+	}
+
+	if s.Skip {
+		log.Println("Skipping zero fill step, the disks won't be compacted...")
+		return multistep.ActionContinue
+	}
+
+	ui := state.Get("ui").(packer.Ui)
+	comm, ok := state.Get("communicator").(packer.Communicator)
+	if !ok {
+		ui.Say("No communicator to zero the free disk space with, skipping...")
+		return multistep.ActionContinue
+	}
+
+	ui.Say("Zeroing the free space of the virtual disks...")
+	log.Printf("Executing zero fill command: %s", s.Command)
+
+	cmd := &packer.RemoteCmd{Command: s.Command}
+	if err := cmd.RunWithUi(ctx, comm, ui); err != nil {
+		err := fmt.Errorf("Error running zero fill command: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	if status := cmd.ExitStatus(); status != 0 {
+		err := fmt.Errorf("Zero fill command exited with non-zero exit status: %d", status)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *StepZeroFill) Cleanup(multistep.StateBag) {}
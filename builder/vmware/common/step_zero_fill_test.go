@@ -0,0 +1,79 @@
+package common
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+func TestStepZeroFill_impl(t *testing.T) {
+	var _ multistep.Step = new(StepZeroFill)
+}
+
+func TestStepZeroFill(t *testing.T) {
+	state := testState(t)
+	step := &StepZeroFill{Command: "zero"}
+
+	comm := new(packer.MockCommunicator)
+	state.Put("communicator", comm)
+
+	// Test the run
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if _, ok := state.GetOk("error"); ok {
+		t.Fatal("should NOT have error")
+	}
+
+	if !comm.StartCalled || comm.StartCmd.Command != "zero" {
+		t.Fatalf("bad command: %#v", comm.StartCmd)
+	}
+}
+
+func TestStepZeroFill_failure(t *testing.T) {
+	state := testState(t)
+	step := &StepZeroFill{Command: "zero"}
+
+	comm := new(packer.MockCommunicator)
+	comm.StartExitStatus = 1
+	state.Put("communicator", comm)
+
+	// Test the run
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if _, ok := state.GetOk("error"); !ok {
+		t.Fatal("should have error")
+	}
+}
+
+func TestStepZeroFill_skip(t *testing.T) {
+	state := testState(t)
+	step := &StepZeroFill{Command: "zero", Skip: true}
+
+	comm := new(packer.MockCommunicator)
+	state.Put("communicator", comm)
+
+	// Test the run
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if comm.StartCalled {
+		t.Fatal("should not have run the command")
+	}
+}
+
+func TestStepZeroFill_noCommunicator(t *testing.T) {
+	state := testState(t)
+	step := &StepZeroFill{Command: "zero"}
+
+	// Test the run
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if _, ok := state.GetOk("error"); ok {
+		t.Fatal("should NOT have error")
+	}
+}
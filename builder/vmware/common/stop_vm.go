@@ -0,0 +1,116 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+// hardStopTimeout is how long StopVM waits for the VM to stop after a hard
+// stop before killing its vmware-vmx process.
+var hardStopTimeout = 30 * time.Second
+
+// StopVM stops the VM, escalating until it is no longer running. When
+// timeout isn't zero the guest is first asked to shut down through VMware
+// Tools and given timeout to do so, then the VM is stopped the hard way and
+// finally, for local VMs, its vmware-vmx process is killed.
+func StopVM(ctx context.Context, ui packer.Ui, driver Driver, vmxPath string, timeout time.Duration) error {
+	if vd, ok := driver.(vmrunDriver); ok && timeout > 0 {
+		vmrunPath, hostType := vd.vmrun()
+		cmd := exec.Command(vmrunPath, "-T", hostType, "stop", vmxPath, "soft")
+		if _, _, err := vd.runAndLog(ctx, cmd); err != nil {
+			log.Printf("Soft stop failed: %s", err)
+		} else if waitForStop(ctx, driver, vmxPath, timeout) {
+			return nil
+		}
+		ui.Say(fmt.Sprintf("Virtual machine didn't stop within %s, halting it...", timeout))
+	}
+
+	stopErr := driver.Stop(ctx, vmxPath)
+	if waitForStop(ctx, driver, vmxPath, hardStopTimeout) {
+		return nil
+	}
+
+	if _, ok := driver.(vmrunDriver); ok {
+		ui.Say("Virtual machine is still running, killing its vmware-vmx process...")
+		if err := killVMXProcesses(vmxPath); err != nil {
+			return fmt.Errorf("Error killing the vmware-vmx process: %s", err)
+		}
+		return nil
+	}
+
+	if stopErr != nil {
+		return stopErr
+	}
+	return fmt.Errorf("Virtual machine is still running after %s", hardStopTimeout)
+}
+
+// waitForStop polls until the VM isn't running anymore, returning false if
+// it still is after timeout.
+func waitForStop(ctx context.Context, driver Driver, vmxPath string, timeout time.Duration) bool {
+	deadline := time.After(timeout)
+	for {
+		if running, err := driver.IsRunning(ctx, vmxPath); err == nil && !running {
+			return true
+		}
+
+		select {
+		case <-deadline:
+			return false
+		case <-ctx.Done():
+			return false
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+// killVMXProcesses kills the vmware-vmx processes running the given VMX.
+func killVMXProcesses(vmxPath string) error {
+	out, err := listProcesses()
+	if err != nil {
+		return err
+	}
+
+	pids := findVMXProcesses(out, vmxPath)
+	if len(pids) == 0 {
+		return fmt.Errorf("no vmware-vmx process found for %s", vmxPath)
+	}
+
+	for _, pid := range pids {
+		log.Printf("Killing vmware-vmx process %d", pid)
+		p, err := os.FindProcess(pid)
+		if err != nil {
+			return err
+		}
+		if err := p.Kill(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findVMXProcesses parses "pid command line" lines as returned by
+// listProcesses and returns the vmware-vmx processes running vmxPath.
+func findVMXProcesses(out, vmxPath string) []int {
+	var pids []int
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(strings.TrimSpace(line), " ", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		if !strings.Contains(fields[1], "vmware-vmx") || !strings.Contains(fields[1], vmxPath) {
+			continue
+		}
+		if pid, err := strconv.Atoi(fields[0]); err == nil {
+			pids = append(pids, pid)
+		}
+	}
+	return pids
+}
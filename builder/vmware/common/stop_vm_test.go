@@ -0,0 +1,66 @@
+package common
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+func TestStopVM(t *testing.T) {
+	driver := new(DriverMock)
+	driver.IsRunningResult = true
+
+	ui := new(packer.BasicUi)
+	if err := StopVM(context.Background(), ui, driver, "foo.vmx", time.Second); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !driver.StopCalled {
+		t.Fatal("stop should be called")
+	}
+}
+
+func TestStopVM_stillRunning(t *testing.T) {
+	defer func(timeout time.Duration) {
+		hardStopTimeout = timeout
+	}(hardStopTimeout)
+	hardStopTimeout = 100 * time.Millisecond
+
+	driver := &stillRunningDriver{}
+	driver.IsRunningResult = true
+
+	ui := new(packer.BasicUi)
+	if err := StopVM(context.Background(), ui, driver, "foo.vmx", 0); err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+// stillRunningDriver is a remote driver whose VM ignores being stopped.
+type stillRunningDriver struct {
+	DriverMock
+}
+
+func (d *stillRunningDriver) Stop(ctx context.Context, path string) error {
+	d.StopCalled = true
+	return nil
+}
+
+func TestFindVMXProcesses(t *testing.T) {
+	out := `    1 /sbin/init
+  412 /usr/lib/vmware/bin/vmware-vmx -s vmx.stdio.keep=TRUE -# product=1 /home/packer/output/packer.vmx
+  413 /usr/lib/vmware/bin/vmware-vmx -# product=1 /home/packer/other/other.vmx
+  414 vim /home/packer/output/packer.vmx
+`
+	pids := findVMXProcesses(out, "/home/packer/output/packer.vmx")
+	if !reflect.DeepEqual(pids, []int{412}) {
+		t.Fatalf("bad: %#v", pids)
+	}
+
+	out = "5120 \"C:\\Program Files (x86)\\VMware\\VMware Workstation\\x64\\vmware-vmx.exe\" C:\\output\\packer.vmx\r\n"
+	pids = findVMXProcesses(out, `C:\output\packer.vmx`)
+	if !reflect.DeepEqual(pids, []int{5120}) {
+		t.Fatalf("bad: %#v", pids)
+	}
+}
@@ -0,0 +1,14 @@
+// +build !windows
+
+package common
+
+import (
+	"os/exec"
+)
+
+// listProcesses returns the pid and command line of every process, one per
+// line.
+func listProcesses() (string, error) {
+	out, err := exec.Command("ps", "-axww", "-o", "pid=,command=").Output()
+	return string(out), err
+}
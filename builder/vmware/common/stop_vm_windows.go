@@ -0,0 +1,16 @@
+// +build windows
+
+package common
+
+import (
+	"os/exec"
+)
+
+// listProcesses returns the pid and command line of every vmware-vmx
+// process, one per line.
+func listProcesses() (string, error) {
+	out, err := exec.Command("powershell", "-NoProfile", "-Command",
+		`Get-CimInstance Win32_Process -Filter "Name='vmware-vmx.exe'" | `+
+			`ForEach-Object { "$($_.ProcessId) $($_.CommandLine)" }`).Output()
+	return string(out), err
+}
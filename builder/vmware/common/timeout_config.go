@@ -0,0 +1,77 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/packer/template/interpolate"
+)
+
+// TimeoutConfig bounds the steps of a build that wait on the VM. SSH and
+// shutdown have their own ssh_timeout and shutdown_timeout.
+type TimeoutConfig struct {
+	// How long creating, copying, cloning or expanding the disks may take.
+	// Unlimited by default.
+	RawDiskCreationTimeout string `mapstructure:"disk_creation_timeout"`
+
+	// How long to keep trying to connect to the VNC server of the booting
+	// VM to type boot_command.
+	RawBootTimeout string `mapstructure:"boot_timeout"`
+
+	// How long to wait for the VM to report an IP address before connecting
+	// to it. Only limited by ssh_timeout by default.
+	RawIPWaitTimeout string `mapstructure:"ip_wait_timeout"`
+
+	DiskCreationTimeout time.Duration ``
+	BootTimeout         time.Duration ``
+	IPWaitTimeout       time.Duration ``
+}
+
+func (c *TimeoutConfig) Prepare(ctx *interpolate.Context) []error {
+	if c.RawBootTimeout == "" {
+		c.RawBootTimeout = "1m"
+	}
+
+	var errs []error
+	for _, timeout := range []struct {
+		name  string
+		raw   string
+		value *time.Duration
+	}{
+		{"disk_creation_timeout", c.RawDiskCreationTimeout, &c.DiskCreationTimeout},
+		{"boot_timeout", c.RawBootTimeout, &c.BootTimeout},
+		{"ip_wait_timeout", c.RawIPWaitTimeout, &c.IPWaitTimeout},
+	} {
+		if timeout.raw == "" {
+			continue
+		}
+		d, err := time.ParseDuration(timeout.raw)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("Failed parsing %s: %s", timeout.name, err))
+		} else if d < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative", timeout.name))
+		}
+		*timeout.value = d
+	}
+
+	return errs
+}
+
+// WithTimeout returns a copy of ctx that expires after timeout, unless it is
+// zero.
+func WithTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout == 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// TimeoutError names the option that bounded ctx when err was caused by its
+// deadline.
+func TimeoutError(ctx context.Context, err error, option string, timeout time.Duration) error {
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("Timeout after %s (%s): %s", timeout, option, err)
+	}
+	return err
+}
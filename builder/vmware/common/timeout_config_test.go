@@ -0,0 +1,65 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTimeoutConfigPrepare(t *testing.T) {
+	c := new(TimeoutConfig)
+	errs := c.Prepare(testConfigTemplate(t))
+	if len(errs) > 0 {
+		t.Fatalf("err: %#v", errs)
+	}
+	if c.BootTimeout != time.Minute {
+		t.Fatalf("bad: %s", c.BootTimeout)
+	}
+	if c.DiskCreationTimeout != 0 || c.IPWaitTimeout != 0 {
+		t.Fatalf("bad: %s %s", c.DiskCreationTimeout, c.IPWaitTimeout)
+	}
+
+	c = &TimeoutConfig{
+		RawDiskCreationTimeout: "10m",
+		RawBootTimeout:         "5m",
+		RawIPWaitTimeout:       "20m",
+	}
+	errs = c.Prepare(testConfigTemplate(t))
+	if len(errs) > 0 {
+		t.Fatalf("err: %#v", errs)
+	}
+	if c.DiskCreationTimeout != 10*time.Minute || c.BootTimeout != 5*time.Minute || c.IPWaitTimeout != 20*time.Minute {
+		t.Fatalf("bad: %#v", c)
+	}
+}
+
+func TestTimeoutConfigPrepare_bad(t *testing.T) {
+	c := &TimeoutConfig{RawIPWaitTimeout: "this is not good"}
+	if errs := c.Prepare(testConfigTemplate(t)); len(errs) != 1 {
+		t.Fatalf("bad: %#v", errs)
+	}
+
+	c = &TimeoutConfig{RawDiskCreationTimeout: "-1m"}
+	if errs := c.Prepare(testConfigTemplate(t)); len(errs) != 1 {
+		t.Fatalf("bad: %#v", errs)
+	}
+}
+
+func TestTimeoutError(t *testing.T) {
+	err := errors.New("VMware error: signal: killed")
+
+	ctx, cancel := WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	actual := TimeoutError(ctx, err, "disk_creation_timeout", time.Nanosecond)
+	if actual.Error() != "Timeout after 1ns (disk_creation_timeout): VMware error: signal: killed" {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	ctx, cancel = WithTimeout(context.Background(), 0)
+	defer cancel()
+	if actual := TimeoutError(ctx, err, "disk_creation_timeout", 0); actual != err {
+		t.Fatalf("bad: %s", actual)
+	}
+}
@@ -1,6 +1,8 @@
 package common
 
 import (
+	"fmt"
+
 	"github.com/hashicorp/packer/template/interpolate"
 )
 
@@ -14,5 +16,17 @@ func (c *ToolsConfig) Prepare(ctx *interpolate.Context) []error {
 		c.ToolsUploadPath = "{{ .Flavor }}.iso"
 	}
 
-	return nil
+	var errs []error
+	switch c.ToolsUploadFlavor {
+	case "", "darwin", "linux", "windows":
+	default:
+		errs = append(errs, fmt.Errorf(
+			"tools_upload_flavor must be one of darwin, linux or windows, got: %s", c.ToolsUploadFlavor))
+	}
+
+	if err := interpolate.Validate(c.ToolsUploadPath, ctx); err != nil {
+		errs = append(errs, fmt.Errorf("Error parsing tools_upload_path: %s", err))
+	}
+
+	return errs
 }
@@ -0,0 +1,40 @@
+package common
+
+import (
+	"testing"
+)
+
+func TestToolsConfigPrepare_Empty(t *testing.T) {
+	c := &ToolsConfig{}
+
+	errs := c.Prepare(testConfigTemplate(t))
+	if len(errs) > 0 {
+		t.Fatalf("err: %#v", errs)
+	}
+
+	if c.ToolsUploadPath != "{{ .Flavor }}.iso" {
+		t.Fatalf("bad value: %s", c.ToolsUploadPath)
+	}
+}
+
+func TestToolsConfigPrepare_Flavor(t *testing.T) {
+	c := &ToolsConfig{ToolsUploadFlavor: "linux"}
+	errs := c.Prepare(testConfigTemplate(t))
+	if len(errs) > 0 {
+		t.Fatalf("err: %#v", errs)
+	}
+
+	c = &ToolsConfig{ToolsUploadFlavor: "solaris"}
+	errs = c.Prepare(testConfigTemplate(t))
+	if len(errs) == 0 {
+		t.Fatal("should have error")
+	}
+}
+
+func TestToolsConfigPrepare_UploadPath(t *testing.T) {
+	c := &ToolsConfig{ToolsUploadPath: "{{ .Flavor"}
+	errs := c.Prepare(testConfigTemplate(t))
+	if len(errs) == 0 {
+		t.Fatal("should have error")
+	}
+}
@@ -0,0 +1,212 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+// VmrunCommunicator is a packer.Communicator that talks to the guest through
+// the guest operations of vmrun. It only needs VMware Tools to be running in
+// the guest, so it works for VMs without a network or an SSH server.
+type VmrunCommunicator struct {
+	VmrunPath   string
+	HostType    string
+	VmxPath     string
+	Username    string
+	Password    string
+	Interpreter string
+
+	// run runs vmrun with the retries of the driver. Commands aren't
+	// retried when it isn't set.
+	run func(context.Context, *exec.Cmd) (string, string, error)
+
+	lock sync.Mutex
+}
+
+var _ packer.Communicator = new(VmrunCommunicator)
+
+// Start runs the command in the guest with runScriptInGuest. vmrun doesn't
+// pass on the output of the command, only its exit status. Cancelling ctx
+// kills vmrun.
+func (c *VmrunCommunicator) Start(ctx context.Context, remote *packer.RemoteCmd) error {
+	go func() {
+		// vmrun only allows a single guest operation at a time per VM
+		c.lock.Lock()
+		defer c.lock.Unlock()
+
+		log.Printf("Running in guest: %s", remote.Command)
+		output, err := c.vmrun(ctx, "runScriptInGuest", c.VmxPath, c.Interpreter, remote.Command)
+		exitStatus := 0
+		if err != nil {
+			exitStatus = vmrunExitStatus(output)
+			log.Printf("Guest command failed with exit status %d: %s", exitStatus, err)
+		}
+
+		remote.SetExited(exitStatus)
+	}()
+
+	return nil
+}
+
+// Upload writes the contents of the reader to a temporary file on the host
+// and copies it into the guest.
+func (c *VmrunCommunicator) Upload(dst string, src io.Reader, fi *os.FileInfo) error {
+	tempfile, err := ioutil.TempFile("", "packer-vmrun-upload")
+	if err != nil {
+		return fmt.Errorf("Failed to open temp file for writing: %s", err)
+	}
+	defer os.Remove(tempfile.Name())
+
+	_, err = io.Copy(tempfile, src)
+	tempfile.Close()
+	if err != nil {
+		return fmt.Errorf("Failed to copy upload file to tempfile: %s", err)
+	}
+
+	return c.uploadFile(dst, tempfile.Name())
+}
+
+// UploadDir copies the directory into the guest file by file, creating the
+// directories along the way. Like rsync, the contents of src are copied
+// directly into dst if src ends with a slash.
+func (c *VmrunCommunicator) UploadDir(dst string, src string, exclude []string) error {
+	if !strings.HasSuffix(src, "/") {
+		dst = path.Join(dst, filepath.Base(src))
+	}
+
+	if err := c.mkdir(dst); err != nil {
+		return err
+	}
+
+	return filepath.Walk(src, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+
+		for _, e := range exclude {
+			if match, _ := filepath.Match(e, info.Name()); match {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+		}
+
+		target := path.Join(dst, filepath.ToSlash(rel))
+		if info.IsDir() {
+			return c.mkdir(target)
+		}
+		return c.uploadFile(target, p)
+	})
+}
+
+// Download copies the file out of the guest into a temporary file on the
+// host and writes it to dst.
+func (c *VmrunCommunicator) Download(src string, dst io.Writer) error {
+	tempfile, err := ioutil.TempFile("", "packer-vmrun-download")
+	if err != nil {
+		return fmt.Errorf("Failed to open temp file for writing: %s", err)
+	}
+	tempfile.Close()
+	defer os.Remove(tempfile.Name())
+
+	c.lock.Lock()
+	_, err = c.vmrun(context.TODO(), "CopyFileFromGuestToHost", c.VmxPath, src, tempfile.Name())
+	c.lock.Unlock()
+	if err != nil {
+		return fmt.Errorf("Failed to download '%s' from the guest: %s", src, err)
+	}
+
+	f, err := os.Open(tempfile.Name())
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(dst, f)
+	return err
+}
+
+func (c *VmrunCommunicator) DownloadDir(src string, dst string, exclude []string) error {
+	return fmt.Errorf("DownloadDir is not implemented for vmrun")
+}
+
+func (c *VmrunCommunicator) uploadFile(dst, src string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	log.Printf("Copying %s to %s in the guest", src, dst)
+	if _, err := c.vmrun(context.TODO(), "CopyFileFromHostToGuest", c.VmxPath, src, dst); err != nil {
+		return fmt.Errorf("Failed to upload to '%s' in the guest: %s", dst, err)
+	}
+	return nil
+}
+
+func (c *VmrunCommunicator) mkdir(dir string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if output, err := c.vmrun(context.TODO(), "directoryExistsInGuest", c.VmxPath, dir); err == nil {
+		if strings.Contains(output, "exists") && !strings.Contains(output, "not exist") {
+			return nil
+		}
+	}
+
+	if _, err := c.vmrun(context.TODO(), "createDirectoryInGuest", c.VmxPath, dir); err != nil {
+		return fmt.Errorf("Failed to create directory '%s' in the guest: %s", dir, err)
+	}
+	return nil
+}
+
+// vmrun runs a guest operation and returns its combined output. vmrun only
+// takes the guest password on its command line, so runAndLog leaves it out
+// of the log, but it can be seen in the process list of the host.
+func (c *VmrunCommunicator) vmrun(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.Command(c.VmrunPath, append(
+		[]string{"-T", c.HostType, "-gu", c.Username, "-gp", c.Password}, args...)...)
+	run := c.run
+	if run == nil {
+		run = RunRetry{}.runAndLog
+	}
+	stdout, stderr, err := run(ctx, cmd)
+	return strings.TrimSpace(stdout + stderr), err
+}
+
+var vmrunExitCodeRe = regexp.MustCompile(`exit code:\s*(\d+)`)
+
+// vmrunExitStatus extracts the exit status of a guest program from the
+// output of a failed vmrun command. Failures of vmrun itself are reported
+// with an exit status of 1.
+func vmrunExitStatus(output string) int {
+	matches := vmrunExitCodeRe.FindStringSubmatch(output)
+	if matches == nil {
+		return 1
+	}
+
+	status, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return 1
+	}
+	return status
+}
@@ -0,0 +1,57 @@
+package common
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+func TestVmrunCommunicator_impl(t *testing.T) {
+	var _ packer.Communicator = new(VmrunCommunicator)
+}
+
+func TestVmrunExitStatus(t *testing.T) {
+	cases := map[string]int{
+		"Error: Guest program exited with non-zero exit code: 3": 3,
+		"Error: Invalid user name or password for the guest OS":  1,
+		"": 1,
+	}
+
+	for output, expected := range cases {
+		if status := vmrunExitStatus(output); status != expected {
+			t.Fatalf("bad status for %q: %d", output, status)
+		}
+	}
+}
+
+func TestVmrunCommunicator_Start(t *testing.T) {
+	r, restore := withRunnerMock(runnerResponse{
+		Stdout: "Error: Guest program exited with non-zero exit code: 3",
+		Failed: true,
+	})
+	defer restore()
+
+	c := &VmrunCommunicator{
+		VmrunPath:   "vmrun",
+		HostType:    "ws",
+		VmxPath:     "/vms/packer.vmx",
+		Username:    "packer",
+		Password:    "secret",
+		Interpreter: "/bin/sh",
+	}
+	remote := &packer.RemoteCmd{Command: "exit 3"}
+	if err := c.Start(context.Background(), remote); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if status := remote.Wait(); status != 3 {
+		t.Fatalf("bad status: %d", status)
+	}
+
+	expected := [][]string{{"-T", "ws", "-gu", "packer", "-gp", "secret",
+		"runScriptInGuest", "/vms/packer.vmx", "/bin/sh", "exit 3"}}
+	if !reflect.DeepEqual(r.Commands, expected) {
+		t.Fatalf("bad: %#v", r.Commands)
+	}
+}
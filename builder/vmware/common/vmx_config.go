@@ -1,6 +1,9 @@
 package common
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/hashicorp/packer/template/interpolate"
 )
 
@@ -12,5 +15,23 @@ type VMXConfig struct {
 }
 
 func (c *VMXConfig) Prepare(ctx *interpolate.Context) []error {
-	return nil
+	var errs []error
+	errs = append(errs, validateVMXData("vmx_data", c.VMXData)...)
+	errs = append(errs, validateVMXData("vmx_data_post", c.VMXDataPost)...)
+	return errs
+}
+
+// validateVMXData makes sure the entries can be written to a VMX file
+// without corrupting it.
+func validateVMXData(name string, data map[string]string) []error {
+	var errs []error
+	for k, v := range data {
+		if k == "" || strings.ContainsAny(k, " \t\r\n=\"") {
+			errs = append(errs, fmt.Errorf("%s contains an invalid key: %q", name, k))
+		}
+		if strings.ContainsAny(v, "\r\n\"") {
+			errs = append(errs, fmt.Errorf("%s contains an invalid value for %s: %q", name, k, v))
+		}
+	}
+	return errs
 }
@@ -20,3 +20,25 @@ func TestVMXConfigPrepare(t *testing.T) {
 		t.Fatal("should have two items in VMXData")
 	}
 }
+
+func TestVMXConfigPrepare_invalid(t *testing.T) {
+	cases := []map[string]string{
+		{"": "foo"},
+		{"foo bar": "foo"},
+		{"foo=bar": "foo"},
+		{"foo": "bar\nbaz = qux"},
+		{"foo": `bar"`},
+	}
+
+	for _, data := range cases {
+		c := &VMXConfig{VMXData: data}
+		if errs := c.Prepare(testConfigTemplate(t)); len(errs) == 0 {
+			t.Fatalf("should have error: %#v", data)
+		}
+
+		c = &VMXConfig{VMXDataPost: data}
+		if errs := c.Prepare(testConfigTemplate(t)); len(errs) == 0 {
+			t.Fatalf("should have error: %#v", data)
+		}
+	}
+}
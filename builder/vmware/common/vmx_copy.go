@@ -0,0 +1,154 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// copyVM clones the virtual machine at src into dst by copying its files.
+// This is used by the drivers whose vmrun doesn't support the clone command,
+// and can therefore only create full clones.
+func copyVM(dst, src string, linked bool) error {
+	if linked {
+		return errors.New("Linked clones are not supported with this version of VMware. " +
+			"Set linked to false to make a full copy of the source VM instead.")
+	}
+
+	srcDir, err := filepath.Abs(filepath.Dir(src))
+	if err != nil {
+		return err
+	}
+	dstDir := filepath.Dir(dst)
+	if err := os.MkdirAll(dstDir, 0755); err != nil {
+		return err
+	}
+
+	if err := copyVMDir(dstDir, srcDir); err != nil {
+		return err
+	}
+
+	vmxData, err := ReadVMX(src)
+	if err != nil {
+		return err
+	}
+
+	// Have VMware generate a new UUID and MAC addresses for the copy
+	// rather than asking whether the machine was moved or copied.
+	macRe := regexp.MustCompile(`^ethernet\d+\.generatedaddress(offset)?$`)
+	for k := range vmxData {
+		if macRe.MatchString(k) || k == "uuid.bios" || k == "uuid.location" {
+			delete(vmxData, k)
+		}
+	}
+	vmxData["uuid.action"] = "create"
+
+	// Disks outside of the directory of the source VM are copied next to
+	// the VMX of the copy, so that it doesn't share them with the source.
+	for key, disk := range vmxData {
+		if !strings.HasSuffix(key, ".filename") || strings.ToLower(filepath.Ext(disk)) != ".vmdk" {
+			continue
+		}
+		srcDisk := disk
+		if !filepath.IsAbs(disk) {
+			srcDisk = filepath.Join(srcDir, disk)
+		}
+
+		// Disks inside the directory were copied with it, and only need
+		// a path relative to the copy.
+		if rel, err := filepath.Rel(srcDir, srcDisk); err == nil && rel != ".." &&
+			!strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			vmxData[key] = rel
+			continue
+		}
+
+		if err := copyDisk(dstDir, srcDisk); err != nil {
+			return fmt.Errorf("Failed to copy the disk %s of %s: %s", disk, key, err)
+		}
+		vmxData[key] = filepath.Base(srcDisk)
+	}
+
+	return WriteVMX(dst, vmxData)
+}
+
+// copyVMDir copies the files and subdirectories of the directory of a VM,
+// except for its VMX, which is rewritten by copyVM.
+func copyVMDir(dstDir, srcDir string) error {
+	files, err := ioutil.ReadDir(srcDir)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		// Lock directories, logs and the suspended state of the source
+		// machine are not needed for the copy.
+		switch strings.ToLower(filepath.Ext(f.Name())) {
+		case ".vmx", ".lck", ".log", ".vmem", ".vmss":
+			continue
+		}
+
+		dstPath := filepath.Join(dstDir, f.Name())
+		srcPath := filepath.Join(srcDir, f.Name())
+		if f.IsDir() {
+			if err := os.MkdirAll(dstPath, 0755); err != nil {
+				return err
+			}
+			if err := copyVMDir(dstPath, srcPath); err != nil {
+				return err
+			}
+			continue
+		}
+
+		log.Printf("Copying %s to %s", f.Name(), dstDir)
+		if err := copyFile(dstPath, srcPath); err != nil {
+			return fmt.Errorf("Failed to copy %s: %s", f.Name(), err)
+		}
+	}
+	return nil
+}
+
+// copyDisk copies a virtual disk into dstDir, including the extents of a
+// disk split into 2GB files.
+func copyDisk(dstDir, srcDisk string) error {
+	base := strings.TrimSuffix(srcDisk, filepath.Ext(srcDisk))
+	extents, _ := filepath.Glob(base + "-s[0-9][0-9][0-9].vmdk")
+	flat, _ := filepath.Glob(base + "-flat.vmdk")
+
+	for _, path := range append([]string{srcDisk}, append(extents, flat...)...) {
+		dstPath := filepath.Join(dstDir, filepath.Base(path))
+		if _, err := os.Stat(dstPath); err == nil {
+			return fmt.Errorf("%s already exists in %s", filepath.Base(path), dstDir)
+		}
+
+		log.Printf("Copying %s to %s", path, dstDir)
+		if err := copyFile(dstPath, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFile(dst, src string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
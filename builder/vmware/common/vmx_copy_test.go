@@ -0,0 +1,186 @@
+package common
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCopyVM(t *testing.T) {
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	srcDir := filepath.Join(td, "src")
+	if err := os.MkdirAll(filepath.Join(srcDir, "source.vmx.lck"), 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	src := filepath.Join(srcDir, "source.vmx")
+	err = WriteVMX(src, map[string]string{
+		"displayname":                      "source",
+		"scsi0:0.filename":                 "disk.vmdk",
+		"uuid.bios":                        "56 4d 12 34",
+		"ethernet0.generatedaddress":       "00:0c:29:12:34:56",
+		"ethernet0.generatedaddressoffset": "0",
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	for _, name := range []string{"disk.vmdk", "vmware.log", "source.nvram"} {
+		if err := ioutil.WriteFile(filepath.Join(srcDir, name), []byte(name), 0644); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+
+	dst := filepath.Join(td, "dst", "clone.vmx")
+	if err := copyVM(dst, src, false); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	for _, name := range []string{"disk.vmdk", "source.nvram"} {
+		if _, err := os.Stat(filepath.Join(td, "dst", name)); err != nil {
+			t.Fatalf("%s should have been copied: %s", name, err)
+		}
+	}
+	for _, name := range []string{"vmware.log", "source.vmx", "source.vmx.lck"} {
+		if _, err := os.Stat(filepath.Join(td, "dst", name)); err == nil {
+			t.Fatalf("%s should not have been copied", name)
+		}
+	}
+
+	vmxData, err := ReadVMX(dst)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if vmxData["scsi0:0.filename"] != "disk.vmdk" {
+		t.Fatalf("bad disk: %#v", vmxData)
+	}
+	if vmxData["uuid.action"] != "create" {
+		t.Fatalf("bad uuid.action: %#v", vmxData)
+	}
+	for _, k := range []string{"uuid.bios", "ethernet0.generatedaddress", "ethernet0.generatedaddressoffset"} {
+		if _, ok := vmxData[k]; ok {
+			t.Fatalf("%s should have been removed: %#v", k, vmxData)
+		}
+	}
+}
+
+func TestCopyVM_externalFiles(t *testing.T) {
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	srcDir := filepath.Join(td, "src")
+	if err := os.MkdirAll(filepath.Join(srcDir, "disks"), 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := os.MkdirAll(filepath.Join(td, "shared"), 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	src := filepath.Join(srcDir, "source.vmx")
+	err = WriteVMX(src, map[string]string{
+		"scsi0:0.filename": filepath.Join("disks", "disk.vmdk"),
+		"scsi0:1.filename": filepath.Join("..", "shared", "data.vmdk"),
+		"scsi0:2.filename": filepath.Join(td, "shared", "logs.vmdk"),
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	files := []string{
+		filepath.Join(srcDir, "disks", "disk.vmdk"),
+		filepath.Join(td, "shared", "data.vmdk"),
+		filepath.Join(td, "shared", "data-s001.vmdk"),
+		filepath.Join(td, "shared", "logs.vmdk"),
+	}
+	for _, name := range files {
+		if err := ioutil.WriteFile(name, []byte(name), 0644); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+
+	dstDir := filepath.Join(td, "dst")
+	dst := filepath.Join(dstDir, "clone.vmx")
+	if err := copyVM(dst, src, false); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	for _, name := range []string{filepath.Join("disks", "disk.vmdk"), "data.vmdk", "data-s001.vmdk", "logs.vmdk"} {
+		if _, err := os.Stat(filepath.Join(dstDir, name)); err != nil {
+			t.Fatalf("%s should have been copied: %s", name, err)
+		}
+	}
+
+	vmxData, err := ReadVMX(dst)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	expected := map[string]string{
+		"scsi0:0.filename": filepath.Join("disks", "disk.vmdk"),
+		"scsi0:1.filename": "data.vmdk",
+		"scsi0:2.filename": "logs.vmdk",
+	}
+	for k, v := range expected {
+		if vmxData[k] != v {
+			t.Fatalf("bad %s: %#v", k, vmxData)
+		}
+	}
+}
+
+func TestCopyVM_absoluteInside(t *testing.T) {
+	td, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	srcDir := filepath.Join(td, "src")
+	if err := os.MkdirAll(filepath.Join(srcDir, "disks"), 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	src := filepath.Join(srcDir, "source.vmx")
+	err = WriteVMX(src, map[string]string{
+		"scsi0:0.filename": filepath.Join(srcDir, "disk.vmdk"),
+		"scsi0:1.filename": filepath.Join(srcDir, "disks", "data.vmdk"),
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	for _, name := range []string{"disk.vmdk", filepath.Join("disks", "data.vmdk")} {
+		if err := ioutil.WriteFile(filepath.Join(srcDir, name), []byte(name), 0644); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+
+	dst := filepath.Join(td, "dst", "clone.vmx")
+	if err := copyVM(dst, src, false); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	vmxData, err := ReadVMX(dst)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	expected := map[string]string{
+		"scsi0:0.filename": "disk.vmdk",
+		"scsi0:1.filename": filepath.Join("disks", "data.vmdk"),
+	}
+	for k, v := range expected {
+		if vmxData[k] != v {
+			t.Fatalf("bad %s: %#v", k, vmxData)
+		}
+	}
+}
+
+func TestCopyVM_linked(t *testing.T) {
+	if err := copyVM("dst.vmx", "src.vmx", true); err == nil {
+		t.Fatal("should have error")
+	}
+}
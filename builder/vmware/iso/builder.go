@@ -36,6 +36,12 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 		return nil, fmt.Errorf("Failed creating VMware driver: %s", err)
 	}
 
+	// Make sure the VMware product can run the virtual hardware version, so
+	// the build doesn't fail when the VM is started.
+	if err := vmwcommon.VerifyHardwareVersion(driver, b.config.Version); err != nil {
+		return nil, err
+	}
+
 	// Determine the output dir implementation
 	var dir vmwcommon.OutputDir
 	switch d := driver.(type) {
@@ -54,6 +60,13 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 	}
 	dir.SetOutputDir(b.config.OutputDir)
 
+	// The serial console log is only available on the machine running Packer
+	// for local builds.
+	var serialLogPath string
+	if b.config.RemoteType == "" {
+		serialLogPath = b.config.HWConfig.SerialLogPath(b.config.OutputDir)
+	}
+
 	// Setup the state bag
 	state := new(multistep.BasicStateBag)
 	state.Put("config", &b.config)
@@ -71,27 +84,49 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			RemoteType:        b.config.RemoteType,
 			ToolsUploadFlavor: b.config.ToolsUploadFlavor,
 		},
-		&common.StepDownload{
+	}
+
+	// No ISO is needed when booting from a copy of disk_source_path
+	if len(b.config.ISOUrls) > 0 {
+		steps = append(steps, &common.StepDownload{
 			Checksum:     b.config.ISOChecksum,
 			ChecksumType: b.config.ISOChecksumType,
 			Description:  "ISO",
 			Extension:    b.config.TargetExtension,
+			Headers:      b.config.ISOHTTPHeaders,
 			ResultKey:    "iso_path",
 			TargetPath:   b.config.TargetPath,
 			Url:          b.config.ISOUrls,
-		},
+		})
+	}
+
+	steps = append(steps,
 		&vmwcommon.StepOutputDir{
 			Force: b.config.PackerForce,
 		},
+		&vmwcommon.StepSerialLog{
+			Path: serialLogPath,
+		},
 		&common.StepCreateFloppy{
 			Files:       b.config.FloppyConfig.FloppyFiles,
 			Directories: b.config.FloppyConfig.FloppyDirectories,
+			Content:     b.config.FloppyConfig.FloppyContent,
+		},
+		&common.StepCreateCD{
+			Files:   b.config.CDConfig.CDFiles,
+			Content: b.config.CDConfig.CDContent,
+			Label:   b.config.CDConfig.CDLabel,
 		},
 		&stepRemoteUpload{
 			Key:       "floppy_path",
 			Message:   "Uploading Floppy to remote machine...",
 			DoCleanup: true,
 		},
+		&stepRemoteUpload{
+			Key:       "cd_path",
+			Message:   "Uploading CD to remote machine...",
+			DoCleanup: true,
+		},
 		&stepRemoteUpload{
 			Key:     "iso_path",
 			Message: "Uploading ISO to remote machine...",
@@ -102,6 +137,7 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			CustomData:  b.config.VMXData,
 			VMName:      b.config.VMName,
 			DisplayName: b.config.VMXDisplayName,
+			ISOPaths:    b.config.AdditionalISOPaths,
 		},
 		&vmwcommon.StepSuppressMessages{},
 		&common.StepHTTPServer{
@@ -115,15 +151,26 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			VNCPortMin:         b.config.VNCPortMin,
 			VNCPortMax:         b.config.VNCPortMax,
 			VNCDisablePassword: b.config.VNCDisablePassword,
+			VNCPassword:        b.config.VNCPassword,
 		},
 		&vmwcommon.StepRegister{
 			Format:         b.config.Format,
 			KeepRegistered: b.config.KeepRegistered,
 			SkipExport:     b.config.SkipExport,
 		},
+		&vmwcommon.StepCollectDiagnostics{
+			OutputDir: exportOutputPath,
+		},
 		&vmwcommon.StepRun{
 			DurationBeforeStop: 5 * time.Second,
 			Headless:           b.config.Headless,
+			StopTimeout:        b.config.StopTimeout,
+		},
+		&vmwcommon.StepCaptureScreen{
+			OutputDir: exportOutputPath,
+		},
+		&vmwcommon.StepDebugConsole{
+			Headless: b.config.Headless,
 		},
 		&vmwcommon.StepTypeBootCommand{
 			BootWait:    b.config.BootWait,
@@ -132,11 +179,25 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			VMName:      b.config.VMName,
 			Ctx:         b.config.ctx,
 			KeyInterval: b.config.VNCConfig.BootKeyInterval,
+			USBKeyboard: b.config.BootCommandTransport == "vsphere",
+			BootTimeout: b.config.BootTimeout,
+		},
+		&vmwcommon.StepWaitForIP{
+			CommType: b.config.SSHConfig.Comm.Type,
+			Timeout:  b.config.IPWaitTimeout,
 		},
 		&communicator.StepConnect{
 			Config:    &b.config.SSHConfig.Comm,
 			Host:      driver.CommHost,
 			SSHConfig: b.config.SSHConfig.Comm.SSHConfigFunc(),
+			CustomConnect: map[string]multistep.Step{
+				"vmrun": &vmwcommon.StepConnectVmrun{
+					Config: &b.config.SSHConfig,
+				},
+			},
+		},
+		&vmwcommon.StepAddSharedFolders{
+			Folders: b.config.SharedFolders,
 		},
 		&vmwcommon.StepUploadTools{
 			RemoteType:        b.config.RemoteType,
@@ -144,15 +205,34 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			ToolsUploadPath:   b.config.ToolsUploadPath,
 			Ctx:               b.config.ctx,
 		},
-		&common.StepProvision{},
+		&vmwcommon.StepSnapshotProvision{
+			SnapshotName: b.config.SnapshotName,
+			Retries:      b.config.SnapshotRetries,
+			Headless:     b.config.Headless,
+		},
 		&common.StepCleanupTempKeys{
 			Comm: &b.config.SSHConfig.Comm,
 		},
+		&vmwcommon.StepRemoveSharedFolders{
+			Folders: b.config.SharedFolders,
+		},
+		&vmwcommon.StepZeroFill{
+			Command: b.config.ZeroFillCommand,
+			Skip:    b.config.SkipCompaction,
+		},
+		&vmwcommon.StepCaptureScreen{
+			OutputDir: exportOutputPath,
+			Debug:     true,
+		},
 		&vmwcommon.StepShutdown{
-			Command: b.config.ShutdownCommand,
-			Timeout: b.config.ShutdownTimeout,
+			Command:     b.config.ShutdownCommand,
+			Timeout:     b.config.ShutdownTimeout,
+			StopTimeout: b.config.StopTimeout,
+			Suspend:     b.config.Suspend,
+		},
+		&vmwcommon.StepCleanFiles{
+			KeepSuspendState: b.config.Suspend,
 		},
-		&vmwcommon.StepCleanFiles{},
 		&vmwcommon.StepCompactDisk{
 			Skip: b.config.SkipCompaction,
 		},
@@ -161,10 +241,12 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			SkipFloppy:  true,
 			VMName:      b.config.VMName,
 			DisplayName: b.config.VMXDisplayName,
+			Skip:        b.config.Suspend,
 		},
 		&vmwcommon.StepCleanVMX{
 			RemoveEthernetInterfaces: b.config.VMXConfig.VMXRemoveEthernet,
 			VNCEnabled:               !b.config.DisableVNC,
+			Skip:                     b.config.Suspend,
 		},
 		&vmwcommon.StepUploadVMX{
 			RemoteType: b.config.RemoteType,
@@ -176,7 +258,10 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			OVFToolOptions: b.config.OVFToolOptions,
 			OutputDir:      exportOutputPath,
 		},
-	}
+		&vmwcommon.StepConvertToTemplate{
+			Skip: !b.config.ConvertToTemplate,
+		},
+	)
 
 	// Run!
 	b.runner = common.NewRunnerWithPauseFn(steps, b.config.PackerConfig, ui, state)
@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/packer/packer"
@@ -58,6 +59,293 @@ func TestBuilderPrepare_Defaults(t *testing.T) {
 	}
 }
 
+func TestBuilderPrepare_DiskTypeId(t *testing.T) {
+	var b Builder
+	config := testConfig()
+
+	warns, err := b.Prepare(config)
+	if len(warns) > 0 {
+		t.Fatalf("bad: %#v", warns)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+	if b.config.DiskTypeId != "1" || b.config.SkipCompaction {
+		t.Fatalf("bad: %s %t", b.config.DiskTypeId, b.config.SkipCompaction)
+	}
+
+	// Preallocated disks can't be compacted
+	config["disk_type_id"] = "2"
+	b = Builder{}
+	warns, err = b.Prepare(config)
+	if len(warns) > 0 {
+		t.Fatalf("bad: %#v", warns)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+	if !b.config.SkipCompaction {
+		t.Fatal("should skip compaction")
+	}
+
+	for _, id := range []string{"6", "thin"} {
+		config["disk_type_id"] = id
+		b = Builder{}
+		_, err = b.Prepare(config)
+		if err == nil {
+			t.Fatalf("should have error for %s", id)
+		}
+	}
+
+	// Any type that vmkfstools accepts is passed through on ESXi
+	config["remote_type"] = "esx5"
+	config["remote_host"] = "foobar.example.com"
+	config["remote_password"] = "supersecret"
+	config["skip_compaction"] = true
+	config["skip_export"] = true
+	for _, id := range []string{"thin", "eagerzeroedthick", "2gbsparse", "rdm:/vmfs/devices/disks/naa.1"} {
+		config["disk_type_id"] = id
+		b = Builder{}
+		_, err = b.Prepare(config)
+		if err != nil {
+			t.Fatalf("should not have error for %s: %s", id, err)
+		}
+	}
+}
+
+func TestBuilderPrepare_AdditionalISOPaths(t *testing.T) {
+	var b Builder
+	config := testConfig()
+	config["additional_iso_paths"] = []string{"builder_test.go"}
+
+	warns, err := b.Prepare(config)
+	if len(warns) > 0 {
+		t.Fatalf("bad: %#v", warns)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	// The ISOs are not uploaded to remote hosts
+	config["remote_type"] = "esx5"
+	config["remote_host"] = "foobar.example.com"
+	b = Builder{}
+	_, err = b.Prepare(config)
+	if err == nil || !strings.Contains(err.Error(), "additional_iso_paths") {
+		t.Fatalf("should have error: %v", err)
+	}
+}
+
+func TestBuilderPrepare_Firmware(t *testing.T) {
+	var b Builder
+	config := testConfig()
+	config["firmware"] = "efi"
+
+	warns, err := b.Prepare(config)
+	if len(warns) > 0 {
+		t.Fatalf("bad: %#v", warns)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	// Secure boot needs a newer virtual hardware version
+	config["firmware"] = "efi-secure"
+	b = Builder{}
+	_, err = b.Prepare(config)
+	if err == nil {
+		t.Fatal("should have error")
+	}
+
+	config["version"] = "14"
+	b = Builder{}
+	_, err = b.Prepare(config)
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	config["firmware"] = "uefi"
+	b = Builder{}
+	_, err = b.Prepare(config)
+	if err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestBuilderPrepare_VTPM(t *testing.T) {
+	var b Builder
+	config := testConfig()
+	config["vtpm"] = true
+	config["firmware"] = "efi"
+	config["version"] = "14"
+
+	warns, err := b.Prepare(config)
+	if len(warns) > 0 {
+		t.Fatalf("bad: %#v", warns)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	bad := []map[string]interface{}{
+		{"firmware": "bios"},
+		{"version": "13"},
+		{"remote_type": "esx5", "remote_host": "foobar.example.com"},
+	}
+	for _, overrides := range bad {
+		config := testConfig()
+		config["vtpm"] = true
+		config["firmware"] = "efi"
+		config["version"] = "14"
+		for k, v := range overrides {
+			config[k] = v
+		}
+
+		b = Builder{}
+		_, err = b.Prepare(config)
+		if err == nil {
+			t.Fatalf("should have error for %#v", overrides)
+		}
+	}
+}
+
+func TestBuilderPrepare_Version(t *testing.T) {
+	var b Builder
+	config := testConfig()
+	config["version"] = "11"
+
+	warns, err := b.Prepare(config)
+	if len(warns) > 0 {
+		t.Fatalf("bad: %#v", warns)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	config = testConfig()
+	config["version"] = "8"
+	config["vhv_enabled"] = true
+	b = Builder{}
+	if _, err := b.Prepare(config); err == nil {
+		t.Fatal("should have error for vhv_enabled with version 8")
+	}
+
+	for _, version := range []string{"foo", "3"} {
+		config := testConfig()
+		config["version"] = version
+
+		b = Builder{}
+		_, err = b.Prepare(config)
+		if err == nil {
+			t.Fatalf("should have error for version %s", version)
+		}
+	}
+}
+
+func TestBuilderPrepare_DiskSourcePath(t *testing.T) {
+	tf, err := ioutil.TempFile("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	tf.Close()
+	defer os.Remove(tf.Name())
+
+	// No ISO is needed when booting from a disk
+	var b Builder
+	config := testConfig()
+	delete(config, "iso_url")
+	delete(config, "iso_checksum")
+	delete(config, "iso_checksum_type")
+	config["disk_source_path"] = tf.Name()
+	warns, err := b.Prepare(config)
+	if len(warns) > 0 {
+		t.Fatalf("bad: %#v", warns)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+	if b.config.DiskSourcePath != tf.Name() {
+		t.Fatalf("bad: %s", b.config.DiskSourcePath)
+	}
+
+	bad := []map[string]interface{}{
+		{"disk_source_path": "/i/dont/exist.vmdk"},
+		{"remote_type": "esx5", "remote_host": "foobar.example.com"},
+	}
+	for _, overrides := range bad {
+		config := testConfig()
+		config["disk_source_path"] = tf.Name()
+		for k, v := range overrides {
+			config[k] = v
+		}
+
+		b = Builder{}
+		if _, err := b.Prepare(config); err == nil {
+			t.Fatalf("should have error for %#v", overrides)
+		}
+	}
+}
+
+func TestBuilderPrepare_DiskAdapterType(t *testing.T) {
+	var b Builder
+	config := testConfig()
+
+	warns, err := b.Prepare(config)
+	if len(warns) > 0 {
+		t.Fatalf("bad: %#v", warns)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+	if b.config.DiskAdapterType != "lsilogic" {
+		t.Fatalf("bad adapter type: %s", b.config.DiskAdapterType)
+	}
+
+	for _, adapter := range []string{"ide", "SATA", "nvme", "pvscsi"} {
+		config["disk_adapter_type"] = adapter
+		b = Builder{}
+		warns, err = b.Prepare(config)
+		if len(warns) > 0 {
+			t.Fatalf("bad: %#v", warns)
+		}
+		if err != nil {
+			t.Fatalf("should not have error for %s: %s", adapter, err)
+		}
+	}
+
+	config["disk_adapter_type"] = "floppy"
+	b = Builder{}
+	_, err = b.Prepare(config)
+	if err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestBuilderPrepare_AdditionalDiskSize(t *testing.T) {
+	var b Builder
+	config := testConfig()
+
+	config["disk_additional_size"] = []uint{1024, 2048}
+	config["disk_adapter_type"] = "sata"
+	warns, err := b.Prepare(config)
+	if len(warns) > 0 {
+		t.Fatalf("bad: %#v", warns)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	config["disk_adapter_type"] = "ide"
+	b = Builder{}
+	warns, err = b.Prepare(config)
+	if len(warns) > 0 {
+		t.Fatalf("bad: %#v", warns)
+	}
+	if err == nil {
+		t.Fatal("should have error")
+	}
+}
+
 func TestBuilderPrepare_DiskSize(t *testing.T) {
 	var b Builder
 	config := testConfig()
@@ -227,6 +515,81 @@ func TestBuilderPrepare_RemoteExport(t *testing.T) {
 	}
 }
 
+func TestBuilderPrepare_BootCommandTransport(t *testing.T) {
+	var b Builder
+	config := testConfig()
+
+	// Bad, the vSphere API is needed
+	config["boot_command_transport"] = "vsphere"
+	warns, err := b.Prepare(config)
+	if len(warns) > 0 {
+		t.Fatalf("bad: %#v", warns)
+	}
+	if err == nil {
+		t.Fatal("should have error")
+	}
+
+	// Good
+	config["remote_type"] = "esx5"
+	config["remote_api"] = "vsphere"
+	config["remote_host"] = "foobar.example.com"
+	config["remote_password"] = "supersecret"
+	config["skip_validate_credentials"] = true
+	config["skip_compaction"] = true
+	config["format"] = "ovf"
+	b = Builder{}
+	warns, err = b.Prepare(config)
+	if len(warns) > 0 {
+		t.Fatalf("bad: %#v", warns)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+}
+
+func TestBuilderPrepare_Suspend(t *testing.T) {
+	var b Builder
+	config := testConfig()
+	config["suspend"] = true
+
+	// Good
+	warns, err := b.Prepare(config)
+	if len(warns) > 0 {
+		t.Fatalf("bad: %#v", warns)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+	if !b.config.SkipCompaction {
+		t.Fatal("should skip compaction")
+	}
+
+	// Bad
+	for k, v := range map[string]interface{}{
+		"format":                         "ova",
+		"vmx_data_post":                  map[string]string{"foo": "bar"},
+		"vmx_remove_ethernet_interfaces": true,
+	} {
+		config := testConfig()
+		config["suspend"] = true
+		config[k] = v
+		b = Builder{}
+		if _, err := b.Prepare(config); err == nil {
+			t.Fatalf("%s should have error", k)
+		}
+	}
+
+	config["remote_type"] = "esx5"
+	config["remote_host"] = "foobar.example.com"
+	config["remote_password"] = "supersecret"
+	config["skip_validate_credentials"] = true
+	b = Builder{}
+	_, err = b.Prepare(config)
+	if err == nil {
+		t.Fatal("should have error")
+	}
+}
+
 func TestBuilderPrepare_Format(t *testing.T) {
 	var b Builder
 	config := testConfig()
@@ -262,6 +625,35 @@ func TestBuilderPrepare_Format(t *testing.T) {
 	}
 }
 
+func TestBuilderPrepare_FormatLocal(t *testing.T) {
+	var b Builder
+	config := testConfig()
+
+	// Default
+	warns, err := b.Prepare(config)
+	if len(warns) > 0 {
+		t.Fatalf("bad: %#v", warns)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+	if b.config.Format != "vmx" {
+		t.Fatalf("bad format: %s", b.config.Format)
+	}
+
+	for _, format := range []string{"ova", "ovf", "vmx"} {
+		config["format"] = format
+		b = Builder{}
+		warns, err = b.Prepare(config)
+		if len(warns) > 0 {
+			t.Fatalf("bad: %#v", warns)
+		}
+		if err != nil {
+			t.Fatalf("should not have error: %s", err)
+		}
+	}
+}
+
 func TestBuilderPrepare_InvalidKey(t *testing.T) {
 	var b Builder
 	config := testConfig()
@@ -449,6 +841,32 @@ func TestBuilderPrepare_VNCPort(t *testing.T) {
 	}
 }
 
+func TestBuilderPrepare_ZeroFillCommand(t *testing.T) {
+	var b Builder
+	config := testConfig()
+
+	// Good
+	config["zero_fill_command"] = "zero"
+	warns, err := b.Prepare(config)
+	if len(warns) > 0 {
+		t.Fatalf("bad: %#v", warns)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	// Bad
+	config["communicator"] = "none"
+	b = Builder{}
+	warns, err = b.Prepare(config)
+	if len(warns) > 0 {
+		t.Fatalf("bad: %#v", warns)
+	}
+	if err == nil {
+		t.Fatal("should have error")
+	}
+}
+
 func TestBuilderCheckCollisions(t *testing.T) {
 	config := testConfig()
 	config["vmx_data"] = map[string]string{
@@ -463,6 +881,17 @@ func TestBuilderCheckCollisions(t *testing.T) {
 			t.Fatalf("Should have warning about two collisions.")
 		}
 	}
+	{
+		// VMX keys are case-insensitive
+		config["vmx_data"] = map[string]string{
+			"displayname": "a collision",
+		}
+		var b Builder
+		warns, _ := b.Prepare(config)
+		if len(warns) != 1 {
+			t.Fatalf("Should have warning about a collision.")
+		}
+	}
 	{
 		config["vmx_template_path"] = "some/path.vmx"
 		var b Builder
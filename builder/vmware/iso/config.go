@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strconv"
 	"strings"
 
 	vmwcommon "github.com/hashicorp/packer/builder/vmware/common"
@@ -15,26 +16,32 @@ import (
 )
 
 type Config struct {
-	common.PackerConfig      `mapstructure:",squash"`
-	common.HTTPConfig        `mapstructure:",squash"`
-	common.ISOConfig         `mapstructure:",squash"`
-	common.FloppyConfig      `mapstructure:",squash"`
-	bootcommand.VNCConfig    `mapstructure:",squash"`
-	vmwcommon.DriverConfig   `mapstructure:",squash"`
-	vmwcommon.HWConfig       `mapstructure:",squash"`
-	vmwcommon.OutputConfig   `mapstructure:",squash"`
-	vmwcommon.RunConfig      `mapstructure:",squash"`
-	vmwcommon.ShutdownConfig `mapstructure:",squash"`
-	vmwcommon.SSHConfig      `mapstructure:",squash"`
-	vmwcommon.ToolsConfig    `mapstructure:",squash"`
-	vmwcommon.VMXConfig      `mapstructure:",squash"`
-	vmwcommon.ExportConfig   `mapstructure:",squash"`
+	common.PackerConfig          `mapstructure:",squash"`
+	common.HTTPConfig            `mapstructure:",squash"`
+	common.ISOConfig             `mapstructure:",squash"`
+	common.FloppyConfig          `mapstructure:",squash"`
+	common.CDConfig              `mapstructure:",squash"`
+	bootcommand.VNCConfig        `mapstructure:",squash"`
+	vmwcommon.CDROMConfig        `mapstructure:",squash"`
+	vmwcommon.DriverConfig       `mapstructure:",squash"`
+	vmwcommon.HWConfig           `mapstructure:",squash"`
+	vmwcommon.OutputConfig       `mapstructure:",squash"`
+	vmwcommon.RunConfig          `mapstructure:",squash"`
+	vmwcommon.ShutdownConfig     `mapstructure:",squash"`
+	vmwcommon.SharedFolderConfig `mapstructure:",squash"`
+	vmwcommon.SnapshotConfig     `mapstructure:",squash"`
+	vmwcommon.SSHConfig          `mapstructure:",squash"`
+	vmwcommon.TimeoutConfig      `mapstructure:",squash"`
+	vmwcommon.ToolsConfig        `mapstructure:",squash"`
+	vmwcommon.VMXConfig          `mapstructure:",squash"`
+	vmwcommon.ExportConfig       `mapstructure:",squash"`
 
 	// disk drives
 	AdditionalDiskSize []uint `mapstructure:"disk_additional_size"`
 	DiskAdapterType    string `mapstructure:"disk_adapter_type"`
 	DiskName           string `mapstructure:"vmdk_name"`
 	DiskSize           uint   `mapstructure:"disk_size"`
+	DiskSourcePath     string `mapstructure:"disk_source_path"`
 	DiskTypeId         string `mapstructure:"disk_type_id"`
 	Format             string `mapstructure:"format"`
 
@@ -72,9 +79,12 @@ func NewConfig(raws ...interface{}) (*Config, []string, error) {
 	var errs *packer.MultiError
 	warnings := make([]string, 0)
 
-	isoWarnings, isoErrs := c.ISOConfig.Prepare(&c.ctx)
-	warnings = append(warnings, isoWarnings...)
-	errs = packer.MultiErrorAppend(errs, isoErrs...)
+	// An ISO is optional when booting from a copy of an existing disk
+	if c.DiskSourcePath == "" || c.RawSingleISOUrl != "" || len(c.ISOUrls) > 0 {
+		isoWarnings, isoErrs := c.ISOConfig.Prepare(&c.ctx)
+		warnings = append(warnings, isoWarnings...)
+		errs = packer.MultiErrorAppend(errs, isoErrs...)
+	}
 	errs = packer.MultiErrorAppend(errs, c.HTTPConfig.Prepare(&c.ctx)...)
 	errs = packer.MultiErrorAppend(errs, c.HWConfig.Prepare(&c.ctx)...)
 	errs = packer.MultiErrorAppend(errs, c.DriverConfig.Prepare(&c.ctx)...)
@@ -82,26 +92,71 @@ func NewConfig(raws ...interface{}) (*Config, []string, error) {
 		c.OutputConfig.Prepare(&c.ctx, &c.PackerConfig)...)
 	errs = packer.MultiErrorAppend(errs, c.RunConfig.Prepare(&c.ctx)...)
 	errs = packer.MultiErrorAppend(errs, c.ShutdownConfig.Prepare(&c.ctx)...)
+	errs = packer.MultiErrorAppend(errs, c.SharedFolderConfig.Prepare(&c.ctx)...)
+	errs = packer.MultiErrorAppend(errs, c.SnapshotConfig.Prepare(&c.ctx, &c.DriverConfig)...)
 	errs = packer.MultiErrorAppend(errs, c.SSHConfig.Prepare(&c.ctx)...)
+	errs = packer.MultiErrorAppend(errs, c.TimeoutConfig.Prepare(&c.ctx)...)
 	errs = packer.MultiErrorAppend(errs, c.ToolsConfig.Prepare(&c.ctx)...)
 	errs = packer.MultiErrorAppend(errs, c.VMXConfig.Prepare(&c.ctx)...)
 	errs = packer.MultiErrorAppend(errs, c.FloppyConfig.Prepare(&c.ctx)...)
+	errs = packer.MultiErrorAppend(errs, c.CDConfig.Prepare(&c.ctx)...)
+	errs = packer.MultiErrorAppend(errs, c.CDROMConfig.Prepare(&c.ctx)...)
 	errs = packer.MultiErrorAppend(errs, c.VNCConfig.Prepare(&c.ctx)...)
 	errs = packer.MultiErrorAppend(errs, c.ExportConfig.Prepare(&c.ctx)...)
 
+	if c.ZeroFillCommand != "" && c.Comm.Type == "none" {
+		errs = packer.MultiErrorAppend(errs,
+			fmt.Errorf("zero_fill_command can't be used with communicator = \"none\""))
+	}
+
 	if c.DiskName == "" {
 		c.DiskName = "disk"
 	}
 
-	if c.DiskSize == 0 {
+	// A copy of disk_source_path keeps its size unless disk_size is set
+	if c.DiskSize == 0 && c.DiskSourcePath == "" {
 		c.DiskSize = 40000
 	}
 
+	if c.DiskSourcePath != "" {
+		if _, err := os.Stat(c.DiskSourcePath); err != nil {
+			errs = packer.MultiErrorAppend(errs,
+				fmt.Errorf("disk_source_path is invalid: %s", err))
+		}
+	}
+
 	if c.DiskAdapterType == "" {
 		// Default is lsilogic
 		c.DiskAdapterType = "lsilogic"
 	}
 
+	switch strings.ToLower(c.DiskAdapterType) {
+	case "ide", "sata", "nvme", "scsi", "lsilogic", "buslogic", "lsisas1068", "pvscsi":
+	default:
+		errs = packer.MultiErrorAppend(errs,
+			fmt.Errorf("disk_adapter_type must be one of ide, sata, nvme, scsi, lsilogic, buslogic, lsisas1068 or pvscsi"))
+	}
+
+	if strings.ToLower(c.DiskAdapterType) == "ide" && len(c.AdditionalDiskSize) > 0 {
+		// The primary IDE bus only has room for the main disk and the CD-ROM
+		errs = packer.MultiErrorAppend(errs,
+			fmt.Errorf("disk_additional_size is not supported with the ide disk_adapter_type"))
+	}
+
+	if c.Suspend {
+		// The disks of a suspended VM can't be compacted, and its VMX
+		// can't be changed without losing the suspended state.
+		c.SkipCompaction = true
+		if len(c.VMXDataPost) > 0 {
+			errs = packer.MultiErrorAppend(errs,
+				fmt.Errorf("vmx_data_post can't be used when suspend is enabled"))
+		}
+		if c.VMXRemoveEthernet {
+			errs = packer.MultiErrorAppend(errs,
+				fmt.Errorf("vmx_remove_ethernet_interfaces can't be used when suspend is enabled"))
+		}
+	}
+
 	if !c.SkipCompaction {
 		if c.RemoteType == "esx5" {
 			if c.DiskTypeId == "" {
@@ -119,11 +174,27 @@ func NewConfig(raws ...interface{}) (*Config, []string, error) {
 		}
 	}
 
+	if c.RemoteType == "" {
+		switch c.DiskTypeId {
+		case "0", "1":
+		case "2", "3", "4", "5":
+			// Only growable disks can be compacted
+			c.SkipCompaction = true
+		default:
+			errs = packer.MultiErrorAppend(
+				errs, fmt.Errorf("disk_type_id must be between 0 and 5, got: %s", c.DiskTypeId))
+		}
+	}
+
 	if c.RemoteType == "esx5" {
 		if c.DiskTypeId != "thin" && !c.SkipCompaction {
 			errs = packer.MultiErrorAppend(
 				errs, fmt.Errorf("skip_compaction must be 'true' for disk_type_id: %s", c.DiskTypeId))
 		}
+		if c.RemoteAPI == "vsphere" && !c.SkipCompaction {
+			errs = packer.MultiErrorAppend(
+				errs, fmt.Errorf("skip_compaction must be 'true' when remote_api is vsphere"))
+		}
 	}
 
 	if c.GuestOSType == "" {
@@ -138,6 +209,32 @@ func NewConfig(raws ...interface{}) (*Config, []string, error) {
 		c.Version = "9"
 	}
 
+	version, err := strconv.Atoi(c.Version)
+	if err != nil || version < 4 {
+		errs = packer.MultiErrorAppend(errs,
+			fmt.Errorf("version must be a virtual hardware version of at least 4, got '%s'", c.Version))
+	}
+
+	// Secure boot and the virtual TPM need virtual hardware version 14
+	// (ESXi 6.7, Workstation 14)
+	if err == nil && version < 14 {
+		if c.Firmware == "efi-secure" {
+			errs = packer.MultiErrorAppend(errs,
+				fmt.Errorf("firmware efi-secure requires a version of at least 14"))
+		}
+		if c.VTPM {
+			errs = packer.MultiErrorAppend(errs,
+				fmt.Errorf("vtpm requires a version of at least 14"))
+		}
+	}
+
+	// Nested virtualization needs virtual hardware version 9 (ESXi 5.1,
+	// Workstation 9)
+	if err == nil && version < 9 && c.VHVEnabled {
+		errs = packer.MultiErrorAppend(errs,
+			fmt.Errorf("vhv_enabled requires a version of at least 9"))
+	}
+
 	if c.VMXTemplatePath != "" {
 		if err := c.validateVMXTemplatePath(); err != nil {
 			errs = packer.MultiErrorAppend(
@@ -165,15 +262,45 @@ func NewConfig(raws ...interface{}) (*Config, []string, error) {
 			errs = packer.MultiErrorAppend(errs,
 				fmt.Errorf("Only 'esx5' value is accepted for remote_type"))
 		}
-	}
 
-	if c.Format != "" {
-		if c.RemoteType != "esx5" {
+		if c.Comm.Type == "vmrun" {
 			errs = packer.MultiErrorAppend(errs,
-				fmt.Errorf("format is only valid when RemoteType=esx5"))
+				fmt.Errorf("the vmrun communicator is not supported with remote_type"))
+		}
+
+		if len(c.SharedFolders) > 0 {
+			errs = packer.MultiErrorAppend(errs,
+				fmt.Errorf("shared_folders are not supported with remote_type"))
+		}
+
+		if len(c.AdditionalISOPaths) > 0 {
+			errs = packer.MultiErrorAppend(errs,
+				fmt.Errorf("additional_iso_paths are not supported with remote_type"))
+		}
+
+		if c.DiskSourcePath != "" {
+			errs = packer.MultiErrorAppend(errs,
+				fmt.Errorf("disk_source_path is not supported with remote_type"))
+		}
+
+		// ESXi can only encrypt VMs through a key provider of vCenter
+		if c.VTPM {
+			errs = packer.MultiErrorAppend(errs,
+				fmt.Errorf("vtpm is not supported with remote_type"))
+		}
+	}
+
+	if c.BootCommandTransport == "vsphere" && (c.RemoteType == "" || c.RemoteAPI != "vsphere") {
+		errs = packer.MultiErrorAppend(errs,
+			fmt.Errorf("boot_command_transport vsphere can only be used with remote_api = \"vsphere\""))
+	}
+
+	if c.Format == "" {
+		if c.RemoteType == "" {
+			c.Format = "vmx"
+		} else {
+			c.Format = "ovf"
 		}
-	} else {
-		c.Format = "ovf"
 	}
 
 	if !(c.Format == "ova" || c.Format == "ovf" || c.Format == "vmx") {
@@ -181,13 +308,29 @@ func NewConfig(raws ...interface{}) (*Config, []string, error) {
 			fmt.Errorf("format must be one of ova, ovf, or vmx"))
 	}
 
+	// ovftool can only export VMs that are powered off
+	if c.Suspend && !c.SkipExport {
+		if c.RemoteType != "" {
+			errs = packer.MultiErrorAppend(errs,
+				fmt.Errorf("skip_export must be 'true' when suspend is enabled"))
+		} else if c.Format != "vmx" {
+			errs = packer.MultiErrorAppend(errs,
+				fmt.Errorf("format must be 'vmx' when suspend is enabled"))
+		}
+	}
+
+	if c.ConvertToTemplate && c.RemoteType == "" {
+		errs = packer.MultiErrorAppend(errs,
+			fmt.Errorf("convert_to_template is only valid when remote_type=esx5"))
+	}
+
 	err = c.DriverConfig.Validate(c.SkipExport)
 	if err != nil {
 		errs = packer.MultiErrorAppend(errs, err)
 	}
 
 	// Warnings
-	if c.ShutdownCommand == "" {
+	if c.ShutdownCommand == "" && !c.Suspend {
 		warnings = append(warnings,
 			"A shutdown_command was not specified. Without a shutdown command, Packer\n"+
 				"will forcibly halt the virtual machine, which may result in data loss.")
@@ -222,10 +365,16 @@ func (c *Config) checkForVMXTemplateAndVMXDataCollisions() string {
 		fmt.Sprintf("%s0:1.deviceType", strings.ToLower(c.DiskAdapterType)),
 	)
 
+	// VMX keys are case-insensitive
+	vmxData := make(map[string]struct{}, len(c.VMXData))
+	for k := range c.VMXData {
+		vmxData[strings.ToLower(k)] = struct{}{}
+	}
+
 	for _, line := range tplLines {
 		if strings.Contains(line, `{{`) {
 			key := line[:strings.Index(line, " =")]
-			if _, ok := c.VMXData[key]; ok {
+			if _, ok := vmxData[strings.ToLower(key)]; ok {
 				overridden = append(overridden, key)
 			}
 		}
@@ -29,6 +29,9 @@ func (stepCreateDisk) Run(ctx context.Context, state multistep.StateBag) multist
 
 	ui.Say("Creating required virtual machine disks")
 
+	ctx, cancel := vmwcommon.WithTimeout(ctx, config.DiskCreationTimeout)
+	defer cancel()
+
 	// Users can configure disks at several locations in the template so
 	// first collate all the disk requirements
 	var diskFullPaths, diskSizes []string
@@ -45,13 +48,38 @@ func (stepCreateDisk) Run(ctx context.Context, state multistep.StateBag) multist
 		}
 	}
 
-	// Create all required disks
+	// Create all required disks, starting the main disk from a copy of the
+	// source disk if there is one
 	for i, diskFullPath := range diskFullPaths {
+		if i == 0 && config.DiskSourcePath != "" {
+			log.Printf("[INFO] Copying disk %s to %s", config.DiskSourcePath, diskFullPath)
+			if err := driver.CopyDisk(ctx, diskFullPath, config.DiskSourcePath, config.DiskTypeId); err != nil {
+				err := fmt.Errorf("Error copying disk: %s",
+					vmwcommon.TimeoutError(ctx, err, "disk_creation_timeout", config.DiskCreationTimeout))
+				state.Put("error", err)
+				ui.Error(err.Error())
+				return multistep.ActionHalt
+			}
+
+			if config.DiskSize > 0 {
+				log.Printf("[INFO] Expanding disk %s to %s", diskFullPath, diskSizes[i])
+				if err := driver.ExpandDisk(ctx, diskFullPath, diskSizes[i]); err != nil {
+					err := fmt.Errorf("Error expanding disk: %s",
+						vmwcommon.TimeoutError(ctx, err, "disk_creation_timeout", config.DiskCreationTimeout))
+					state.Put("error", err)
+					ui.Error(err.Error())
+					return multistep.ActionHalt
+				}
+			}
+			continue
+		}
+
 		log.Printf("[INFO] Creating disk with Path: %s and Size: %s", diskFullPath, diskSizes[i])
 		// Additional disks currently use the same adapter type and disk
 		// type as specified for the main disk
-		if err := driver.CreateDisk(diskFullPath, diskSizes[i], config.DiskAdapterType, config.DiskTypeId); err != nil {
-			err := fmt.Errorf("Error creating disk: %s", err)
+		if err := driver.CreateDisk(ctx, diskFullPath, diskSizes[i], config.DiskAdapterType, config.DiskTypeId); err != nil {
+			err := fmt.Errorf("Error creating disk: %s",
+				vmwcommon.TimeoutError(ctx, err, "disk_creation_timeout", config.DiskCreationTimeout))
 			state.Put("error", err)
 			ui.Error(err.Error())
 			return multistep.ActionHalt
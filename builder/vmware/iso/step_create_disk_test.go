@@ -0,0 +1,97 @@
+package iso
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	vmwcommon "github.com/hashicorp/packer/builder/vmware/common"
+	"github.com/hashicorp/packer/helper/multistep"
+)
+
+func TestStepCreateDisk_impl(t *testing.T) {
+	var _ multistep.Step = new(stepCreateDisk)
+}
+
+func TestStepCreateDisk(t *testing.T) {
+	state := testState(t)
+	state.Put("config", &Config{
+		OutputConfig:       vmwcommon.OutputConfig{OutputDir: "output"},
+		AdditionalDiskSize: []uint{1024},
+		DiskName:           "disk",
+		DiskSize:           40000,
+		DiskTypeId:         "1",
+	})
+	step := new(stepCreateDisk)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	driver := state.Get("driver").(*vmwcommon.DriverMock)
+	if driver.CopyDiskCalled {
+		t.Fatal("copy disk should not be called")
+	}
+	if driver.CreateDiskOutput != filepath.Join("output", "disk-1.vmdk") {
+		t.Fatalf("bad: %s", driver.CreateDiskOutput)
+	}
+
+	paths := state.Get("disk_full_paths").([]string)
+	if len(paths) != 2 {
+		t.Fatalf("bad: %#v", paths)
+	}
+}
+
+func TestStepCreateDisk_sourcePath(t *testing.T) {
+	state := testState(t)
+	state.Put("config", &Config{
+		OutputConfig:   vmwcommon.OutputConfig{OutputDir: "output"},
+		DiskName:       "disk",
+		DiskSize:       40000,
+		DiskSourcePath: "appliance.vmdk",
+		DiskTypeId:     "1",
+	})
+	step := new(stepCreateDisk)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	driver := state.Get("driver").(*vmwcommon.DriverMock)
+	if driver.CreateDiskCalled {
+		t.Fatal("create disk should not be called")
+	}
+	if !driver.CopyDiskCalled {
+		t.Fatal("copy disk should be called")
+	}
+	if driver.CopyDiskSource != "appliance.vmdk" {
+		t.Fatalf("bad: %s", driver.CopyDiskSource)
+	}
+	if driver.CopyDiskOutput != filepath.Join("output", "disk.vmdk") {
+		t.Fatalf("bad: %s", driver.CopyDiskOutput)
+	}
+	if driver.CopyDiskTypeId != "1" {
+		t.Fatalf("bad: %s", driver.CopyDiskTypeId)
+	}
+	if !driver.ExpandDiskCalled {
+		t.Fatal("expand disk should be called")
+	}
+	if driver.ExpandDiskPath != driver.CopyDiskOutput || driver.ExpandDiskSize != "40000M" {
+		t.Fatalf("bad: %s %s", driver.ExpandDiskPath, driver.ExpandDiskSize)
+	}
+
+	// The copy keeps its size without a disk_size
+	state = testState(t)
+	state.Put("config", &Config{
+		OutputConfig:   vmwcommon.OutputConfig{OutputDir: "output"},
+		DiskName:       "disk",
+		DiskSourcePath: "appliance.vmdk",
+	})
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	driver = state.Get("driver").(*vmwcommon.DriverMock)
+	if driver.ExpandDiskCalled {
+		t.Fatal("expand disk should not be called")
+	}
+}
@@ -59,13 +59,33 @@ type vmxTemplateData struct {
 type additionalDiskTemplateData struct {
 	DiskNumber int
 	DiskName   string
+	DiskType   string
+	DiskUnit   int
+}
+
+// additionalDiskUnit returns the unit on the bus of the main disk to attach
+// the nth additional disk to, skipping the unit taken by the CD-ROM drive and
+// the unit reserved for the SCSI controller.
+func additionalDiskUnit(n int, diskType, cdromType, cdromUnit string) int {
+	unit := 0
+	for i := 0; i < n; {
+		unit++
+		if diskType == cdromType && strconv.Itoa(unit) == cdromUnit {
+			continue
+		}
+		if diskType == "scsi" && unit == 7 {
+			continue
+		}
+		i++
+	}
+	return unit
 }
 
 // This step creates the VMX file for the VM.
 //
 // Uses:
 //   config *config
-//   iso_path string
+//   iso_path string - Optional, the CD-ROM drive is left empty without it
 //   ui     packer.Ui
 //
 // Produces:
@@ -77,12 +97,15 @@ type stepCreateVMX struct {
 /* regular steps */
 func (s *stepCreateVMX) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
 	config := state.Get("config").(*Config)
-	isoPath := state.Get("iso_path").(string)
+	isoPath, hasISO := state.Get("iso_path").(string)
 	ui := state.Get("ui").(packer.Ui)
 
-	// Convert the iso_path into a path relative to the .vmx file if possible
-	if relativeIsoPath, err := filepath.Rel(config.VMXTemplatePath, filepath.FromSlash(isoPath)); err == nil {
-		isoPath = relativeIsoPath
+	// VMware resolves relative paths against the directory of the .vmx file,
+	// so make the iso_path absolute for local builds.
+	if hasISO && config.RemoteType == "" {
+		if absIsoPath, err := filepath.Abs(filepath.FromSlash(isoPath)); err == nil {
+			isoPath = absIsoPath
+		}
 	}
 
 	ui.Say("Building and writing VMX file")
@@ -110,48 +133,6 @@ func (s *stepCreateVMX) Run(ctx context.Context, state multistep.StateBag) multi
 	}
 
 	ictx := config.ctx
-
-	if len(config.AdditionalDiskSize) > 0 {
-		for i := range config.AdditionalDiskSize {
-			ictx.Data = &additionalDiskTemplateData{
-				DiskNumber: i + 1,
-				DiskName:   config.DiskName,
-			}
-
-			diskTemplate := DefaultAdditionalDiskTemplate
-			if config.VMXDiskTemplatePath != "" {
-				f, err := os.Open(config.VMXDiskTemplatePath)
-				if err != nil {
-					err := fmt.Errorf("Error reading VMX disk template: %s", err)
-					state.Put("error", err)
-					ui.Error(err.Error())
-					return multistep.ActionHalt
-				}
-				defer f.Close()
-
-				rawBytes, err := ioutil.ReadAll(f)
-				if err != nil {
-					err := fmt.Errorf("Error reading VMX disk template: %s", err)
-					state.Put("error", err)
-					ui.Error(err.Error())
-					return multistep.ActionHalt
-				}
-
-				diskTemplate = string(rawBytes)
-			}
-
-			diskContents, err := interpolate.Render(diskTemplate, &ictx)
-			if err != nil {
-				err := fmt.Errorf("Error preparing VMX template for additional disk: %s", err)
-				state.Put("error", err)
-				ui.Error(err.Error())
-				return multistep.ActionHalt
-			}
-
-			vmxTemplate += diskContents
-		}
-	}
-
 	templateData := vmxTemplateData{
 		Name:     config.VMName,
 		GuestOS:  config.GuestOSType,
@@ -250,6 +231,18 @@ func (s *stepCreateVMX) Run(ctx context.Context, state multistep.StateBag) multi
 		templateData.Network_Adapter = network_adapter
 	}
 
+	// Set the number of cpus if it was specified
+	if config.HWConfig.CpuCount > 0 {
+		templateData.CpuCount = strconv.Itoa(config.HWConfig.CpuCount)
+	}
+
+	// Apply the memory size that was specified
+	if config.HWConfig.MemorySize > 0 {
+		templateData.MemorySize = strconv.Itoa(config.HWConfig.MemorySize)
+	} else {
+		templateData.MemorySize = "512"
+	}
+
 	/// Check the network type that the user specified
 	network := config.HWConfig.Network
 	driver := state.Get("driver").(vmwcommon.Driver).GetVmwareDriver()
@@ -316,18 +309,6 @@ func (s *stepCreateVMX) Run(ctx context.Context, state multistep.StateBag) multi
 		templateData.Serial_Host = ""
 		templateData.Serial_Auto = "FALSE"
 
-		// Set the number of cpus if it was specified
-		if config.HWConfig.CpuCount > 0 {
-			templateData.CpuCount = strconv.Itoa(config.HWConfig.CpuCount)
-		}
-
-		// Apply the memory size that was specified
-		if config.HWConfig.MemorySize > 0 {
-			templateData.MemorySize = strconv.Itoa(config.HWConfig.MemorySize)
-		} else {
-			templateData.MemorySize = "512"
-		}
-
 		switch serial.Union.(type) {
 		case *vmwcommon.SerialConfigPipe:
 			templateData.Serial_Type = "pipe"
@@ -396,6 +377,49 @@ func (s *stepCreateVMX) Run(ctx context.Context, state multistep.StateBag) multi
 		}
 	}
 
+	if len(config.AdditionalDiskSize) > 0 {
+		for i := range config.AdditionalDiskSize {
+			ictx.Data = &additionalDiskTemplateData{
+				DiskNumber: i + 1,
+				DiskName:   config.DiskName,
+				DiskType:   templateData.DiskType,
+				DiskUnit:   additionalDiskUnit(i+1, templateData.DiskType, templateData.CDROMType, templateData.CDROMType_PrimarySecondary),
+			}
+
+			diskTemplate := DefaultAdditionalDiskTemplate
+			if config.VMXDiskTemplatePath != "" {
+				f, err := os.Open(config.VMXDiskTemplatePath)
+				if err != nil {
+					err := fmt.Errorf("Error reading VMX disk template: %s", err)
+					state.Put("error", err)
+					ui.Error(err.Error())
+					return multistep.ActionHalt
+				}
+				defer f.Close()
+
+				rawBytes, err := ioutil.ReadAll(f)
+				if err != nil {
+					err := fmt.Errorf("Error reading VMX disk template: %s", err)
+					state.Put("error", err)
+					ui.Error(err.Error())
+					return multistep.ActionHalt
+				}
+
+				diskTemplate = string(rawBytes)
+			}
+
+			diskContents, err := interpolate.Render(diskTemplate, &ictx)
+			if err != nil {
+				err := fmt.Errorf("Error preparing VMX template for additional disk: %s", err)
+				state.Put("error", err)
+				ui.Error(err.Error())
+				return multistep.ActionHalt
+			}
+
+			vmxTemplate += diskContents
+		}
+	}
+
 	ictx.Data = &templateData
 
 	/// render the .vmx template
@@ -431,11 +455,59 @@ func (s *stepCreateVMX) Run(ctx context.Context, state multistep.StateBag) multi
 		delete(vmxData, "numvcpus")
 	}
 
+	// Without an ISO, leave the CD-ROM drive empty instead of pointing it at
+	// a missing image.
+	if !hasISO {
+		vmxData[tmpCdromDevice+".devicetype"] = "cdrom-raw"
+		vmxData[tmpCdromDevice+".filename"] = "auto detect"
+		vmxData[tmpCdromDevice+".startconnected"] = "FALSE"
+	}
+
+	// The template sets up the first network adapter apart from its MAC
+	// address, add that and the other adapters.
+	for i, adapter := range config.HWConfig.NetworkAdapters {
+		if i == 0 {
+			if adapter.MACAddress != "" {
+				vmxData["ethernet0.addresstype"] = "static"
+				vmxData["ethernet0.address"] = adapter.MACAddress
+			}
+			continue
+		}
+
+		for k, v := range adapter.VMXData(i) {
+			vmxData[k] = v
+		}
+	}
+
 	// If some number of cores were specified, then update "cpuid.coresPerSocket" with the requested value
 	if config.HWConfig.CoreCount > 0 {
 		vmxData["cpuid.corespersocket"] = strconv.Itoa(config.HWConfig.CoreCount)
 	}
 
+	// Boot with UEFI instead of the BIOS, with secure boot if requested
+	switch config.HWConfig.Firmware {
+	case "bios":
+		vmxData["firmware"] = "bios"
+	case "efi":
+		vmxData["firmware"] = "efi"
+	case "efi-secure":
+		vmxData["firmware"] = "efi"
+		vmxData["uefi.secureboot.enabled"] = "TRUE"
+	}
+
+	// A virtual TPM can only be added to an encrypted VM. Let the product
+	// add it when the VM is first powered on, encrypting only the files the
+	// TPM needs with a key that it keeps in the VMX, so no password is needed
+	// to run the VM.
+	if config.HWConfig.VTPM {
+		vmxData["managedvm.autoaddvtpm"] = "software"
+	}
+
+	// Expose the hardware virtualization extensions of the CPU to the guest
+	if config.HWConfig.VHVEnabled {
+		vmxData["vhv.enable"] = "TRUE"
+	}
+
 	/// Write the vmxData to the vmxPath
 	vmxPath := filepath.Join(vmxDir, config.VMName+".vmx")
 	if err := vmwcommon.WriteVMX(vmxPath, vmxData); err != nil {
@@ -587,7 +659,7 @@ parallel0.bidirectional = "{{ .Parallel_Bidirectional }}"
 `
 
 const DefaultAdditionalDiskTemplate = `
-scsi0:{{ .DiskNumber }}.fileName = "{{ .DiskName}}-{{ .DiskNumber }}.vmdk"
-scsi0:{{ .DiskNumber }}.present = "TRUE"
-scsi0:{{ .DiskNumber }}.redo = ""
+{{ .DiskType }}0:{{ .DiskUnit }}.fileName = "{{ .DiskName}}-{{ .DiskNumber }}.vmdk"
+{{ .DiskType }}0:{{ .DiskUnit }}.present = "TRUE"
+{{ .DiskType }}0:{{ .DiskUnit }}.redo = ""
 `
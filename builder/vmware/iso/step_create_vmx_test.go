@@ -15,6 +15,8 @@ import (
 
 	"testing"
 
+	vmwcommon "github.com/hashicorp/packer/builder/vmware/common"
+	"github.com/hashicorp/packer/helper/multistep"
 	"github.com/hashicorp/packer/packer"
 	"github.com/hashicorp/packer/provisioner/shell"
 	"github.com/hashicorp/packer/template"
@@ -200,6 +202,147 @@ func setupVMwareBuild(t *testing.T, builderConfig map[string]string, provisioner
 	return nil
 }
 
+func TestStepCreateVmx_impl(t *testing.T) {
+	var _ multistep.Step = new(stepCreateVMX)
+}
+
+func TestStepCreateVmx_template(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	tplPath := filepath.Join(dir, "template.vmx")
+	tpl := `memsize = "{{ .MemorySize }}"
+numvcpus = "{{ .CpuCount }}"
+ide1:0.fileName = "{{ .ISOPath }}"
+scsi0:0.fileName = "{{ .DiskName }}.vmdk"
+`
+	if err := ioutil.WriteFile(tplPath, []byte(tpl), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	config := testConfig()
+	config["output_directory"] = filepath.Join(dir, "output")
+	config["vmx_template_path"] = tplPath
+	config["memory"] = 1024
+	config["cpus"] = 2
+	config["firmware"] = "efi-secure"
+	config["version"] = "14"
+	config["vtpm"] = true
+	config["vhv_enabled"] = true
+	config["disk_adapter_type"] = "sata"
+	config["disk_additional_size"] = []uint{1024}
+	config["network_adapters"] = []map[string]string{
+		{"network": "nat", "mac_address": "00:50:56:00:00:01"},
+		{"network": "vmnet2", "adapter_type": "vmxnet3"},
+	}
+
+	var b Builder
+	if _, err := b.Prepare(config); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := os.MkdirAll(b.config.OutputDir, 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	state := testState(t)
+	state.Put("config", &b.config)
+	state.Put("iso_path", "foo.iso")
+	state.Put("temporaryDevices", []string{})
+
+	step := new(stepCreateVMX)
+	defer step.Cleanup(state)
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if _, ok := state.GetOk("error"); ok {
+		t.Fatal("should NOT have error")
+	}
+
+	vmxData, err := vmwcommon.ReadVMX(state.Get("vmx_path").(string))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// The hardware settings apply even without a serial port
+	if vmxData["memsize"] != "1024" {
+		t.Fatalf("bad memsize: %s", vmxData["memsize"])
+	}
+	if vmxData["numvcpus"] != "2" {
+		t.Fatalf("bad numvcpus: %s", vmxData["numvcpus"])
+	}
+	if vmxData["firmware"] != "efi" || vmxData["uefi.secureboot.enabled"] != "TRUE" {
+		t.Fatalf("bad firmware: %#v", vmxData)
+	}
+	if vmxData["managedvm.autoaddvtpm"] != "software" {
+		t.Fatalf("bad vtpm: %#v", vmxData)
+	}
+	if vmxData["vhv.enable"] != "TRUE" {
+		t.Fatalf("bad vhv: %#v", vmxData)
+	}
+	if vmxData["scsi0:0.filename"] != "disk.vmdk" {
+		t.Fatalf("bad disk: %s", vmxData["scsi0:0.filename"])
+	}
+
+	// The additional disk skips the CD-ROM drive on the SATA bus
+	if vmxData["sata0:2.filename"] != "disk-1.vmdk" {
+		t.Fatalf("bad additional disk: %#v", vmxData)
+	}
+
+	if vmxData["ethernet0.address"] != "00:50:56:00:00:01" {
+		t.Fatalf("bad mac address: %#v", vmxData)
+	}
+	if vmxData["ethernet1.vnet"] != "vmnet2" || vmxData["ethernet1.virtualdev"] != "vmxnet3" {
+		t.Fatalf("bad second adapter: %#v", vmxData)
+	}
+
+	isoPath, _ := filepath.Abs("foo.iso")
+	if vmxData["ide1:0.filename"] != isoPath {
+		t.Fatalf("bad iso path: %s", vmxData["ide1:0.filename"])
+	}
+}
+
+func TestStepCreateVmx_noISO(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	config := testConfig()
+	config["output_directory"] = filepath.Join(dir, "output")
+
+	var b Builder
+	if _, err := b.Prepare(config); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := os.MkdirAll(b.config.OutputDir, 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	state := testState(t)
+	state.Put("config", &b.config)
+	state.Put("temporaryDevices", []string{})
+
+	step := new(stepCreateVMX)
+	defer step.Cleanup(state)
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	vmxData, err := vmwcommon.ReadVMX(state.Get("vmx_path").(string))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// The CD-ROM drive is left empty
+	if vmxData["ide0:0.devicetype"] != "cdrom-raw" || vmxData["ide0:0.filename"] != "auto detect" {
+		t.Fatalf("bad cdrom: %#v", vmxData)
+	}
+}
+
 func TestStepCreateVmx_SerialFile(t *testing.T) {
 	if os.Getenv("PACKER_ACC") == "" {
 		t.Skip("This test is only run with PACKER_ACC=1 due to the requirement of access to the VMware binaries.")
@@ -409,3 +552,29 @@ func TestStepCreateVmx_Sound(t *testing.T) {
 		t.Errorf("Soundcard not detected : %v", data)
 	}
 }
+
+func TestAdditionalDiskUnit(t *testing.T) {
+	cases := []struct {
+		n         int
+		diskType  string
+		cdromType string
+		cdromUnit string
+		expected  int
+	}{
+		// The CD-ROM drive is on another bus
+		{1, "scsi", "ide", "0", 1},
+		{6, "scsi", "ide", "0", 6},
+		{7, "scsi", "ide", "0", 8},
+		// The CD-ROM drive is the second device on the bus
+		{1, "sata", "sata", "1", 2},
+		{2, "sata", "sata", "1", 3},
+		{1, "nvme", "sata", "0", 1},
+	}
+
+	for _, tc := range cases {
+		unit := additionalDiskUnit(tc.n, tc.diskType, tc.cdromType, tc.cdromUnit)
+		if unit != tc.expected {
+			t.Fatalf("bad unit for disk %d on %s: %d", tc.n, tc.diskType, unit)
+		}
+	}
+}
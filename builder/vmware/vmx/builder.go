@@ -82,17 +82,30 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 		&common.StepCreateFloppy{
 			Files:       b.config.FloppyConfig.FloppyFiles,
 			Directories: b.config.FloppyConfig.FloppyDirectories,
+			Content:     b.config.FloppyConfig.FloppyContent,
+		},
+		&common.StepCreateCD{
+			Files:   b.config.CDConfig.CDFiles,
+			Content: b.config.CDConfig.CDContent,
+			Label:   b.config.CDConfig.CDLabel,
 		},
 		&StepCloneVMX{
 			OutputDir: b.config.OutputDir,
 			Path:      b.config.SourcePath,
 			VMName:    b.config.VMName,
 			Linked:    b.config.Linked,
+			Network:   b.config.Network,
+			Timeout:   b.config.DiskCreationTimeout,
+		},
+		&StepExpandDisk{
+			Size:    b.config.DiskSize,
+			Timeout: b.config.DiskCreationTimeout,
 		},
 		&vmwcommon.StepConfigureVMX{
 			CustomData:  b.config.VMXData,
 			VMName:      b.config.VMName,
 			DisplayName: b.config.VMXDisplayName,
+			ISOPaths:    b.config.AdditionalISOPaths,
 		},
 		&vmwcommon.StepSuppressMessages{},
 		&common.StepHTTPServer{
@@ -109,15 +122,29 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			VNCPortMin:         b.config.VNCPortMin,
 			VNCPortMax:         b.config.VNCPortMax,
 			VNCDisablePassword: b.config.VNCDisablePassword,
+			VNCPassword:        b.config.VNCPassword,
 		},
 		&vmwcommon.StepRegister{
 			Format:         b.config.Format,
 			KeepRegistered: b.config.KeepRegistered,
 			SkipExport:     b.config.SkipExport,
 		},
+		&vmwcommon.StepCollectDiagnostics{
+			OutputDir: exportOutputPath,
+		},
+		&StepCustomize{
+			Spec: b.config.CustomizationSpec,
+		},
 		&vmwcommon.StepRun{
 			DurationBeforeStop: 5 * time.Second,
 			Headless:           b.config.Headless,
+			StopTimeout:        b.config.StopTimeout,
+		},
+		&vmwcommon.StepCaptureScreen{
+			OutputDir: exportOutputPath,
+		},
+		&vmwcommon.StepDebugConsole{
+			Headless: b.config.Headless,
 		},
 		&vmwcommon.StepTypeBootCommand{
 			BootWait:    b.config.BootWait,
@@ -126,11 +153,25 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			VMName:      b.config.VMName,
 			Ctx:         b.config.ctx,
 			KeyInterval: b.config.VNCConfig.BootKeyInterval,
+			USBKeyboard: b.config.BootCommandTransport == "vsphere",
+			BootTimeout: b.config.BootTimeout,
+		},
+		&vmwcommon.StepWaitForIP{
+			CommType: b.config.SSHConfig.Comm.Type,
+			Timeout:  b.config.IPWaitTimeout,
 		},
 		&communicator.StepConnect{
 			Config:    &b.config.SSHConfig.Comm,
 			Host:      driver.CommHost,
 			SSHConfig: b.config.SSHConfig.Comm.SSHConfigFunc(),
+			CustomConnect: map[string]multistep.Step{
+				"vmrun": &vmwcommon.StepConnectVmrun{
+					Config: &b.config.SSHConfig,
+				},
+			},
+		},
+		&vmwcommon.StepAddSharedFolders{
+			Folders: b.config.SharedFolders,
 		},
 		&vmwcommon.StepUploadTools{
 			RemoteType:        b.config.RemoteType,
@@ -138,15 +179,34 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			ToolsUploadPath:   b.config.ToolsUploadPath,
 			Ctx:               b.config.ctx,
 		},
-		&common.StepProvision{},
+		&vmwcommon.StepSnapshotProvision{
+			SnapshotName: b.config.SnapshotName,
+			Retries:      b.config.SnapshotRetries,
+			Headless:     b.config.Headless,
+		},
 		&common.StepCleanupTempKeys{
 			Comm: &b.config.SSHConfig.Comm,
 		},
+		&vmwcommon.StepRemoveSharedFolders{
+			Folders: b.config.SharedFolders,
+		},
+		&vmwcommon.StepZeroFill{
+			Command: b.config.ZeroFillCommand,
+			Skip:    b.config.SkipCompaction,
+		},
+		&vmwcommon.StepCaptureScreen{
+			OutputDir: exportOutputPath,
+			Debug:     true,
+		},
 		&vmwcommon.StepShutdown{
-			Command: b.config.ShutdownCommand,
-			Timeout: b.config.ShutdownTimeout,
+			Command:     b.config.ShutdownCommand,
+			Timeout:     b.config.ShutdownTimeout,
+			StopTimeout: b.config.StopTimeout,
+			Suspend:     b.config.Suspend,
+		},
+		&vmwcommon.StepCleanFiles{
+			KeepSuspendState: b.config.Suspend,
 		},
-		&vmwcommon.StepCleanFiles{},
 		&vmwcommon.StepCompactDisk{
 			Skip: b.config.SkipCompaction,
 		},
@@ -155,10 +215,12 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			SkipFloppy:  true,
 			VMName:      b.config.VMName,
 			DisplayName: b.config.VMXDisplayName,
+			Skip:        b.config.Suspend,
 		},
 		&vmwcommon.StepCleanVMX{
 			RemoveEthernetInterfaces: b.config.VMXConfig.VMXRemoveEthernet,
 			VNCEnabled:               !b.config.DisableVNC,
+			Skip:                     b.config.Suspend,
 		},
 		&vmwcommon.StepUploadVMX{
 			RemoteType: b.config.RemoteType,
@@ -170,6 +232,9 @@ func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (pack
 			OVFToolOptions: b.config.OVFToolOptions,
 			OutputDir:      exportOutputPath,
 		},
+		&vmwcommon.StepConvertToTemplate{
+			Skip: !b.config.ConvertToTemplate,
+		},
 	}
 
 	// Run the steps.
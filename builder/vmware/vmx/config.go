@@ -3,6 +3,8 @@ package vmx
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 
 	vmwcommon "github.com/hashicorp/packer/builder/vmware/common"
 	"github.com/hashicorp/packer/common"
@@ -14,23 +16,42 @@ import (
 
 // Config is the configuration structure for the builder.
 type Config struct {
-	common.PackerConfig      `mapstructure:",squash"`
-	common.HTTPConfig        `mapstructure:",squash"`
-	common.FloppyConfig      `mapstructure:",squash"`
-	bootcommand.VNCConfig    `mapstructure:",squash"`
-	vmwcommon.DriverConfig   `mapstructure:",squash"`
-	vmwcommon.OutputConfig   `mapstructure:",squash"`
-	vmwcommon.RunConfig      `mapstructure:",squash"`
-	vmwcommon.ShutdownConfig `mapstructure:",squash"`
-	vmwcommon.SSHConfig      `mapstructure:",squash"`
-	vmwcommon.ToolsConfig    `mapstructure:",squash"`
-	vmwcommon.VMXConfig      `mapstructure:",squash"`
-	vmwcommon.ExportConfig   `mapstructure:",squash"`
-
-	Linked     bool   `mapstructure:"linked"`
-	RemoteType string `mapstructure:"remote_type"`
-	SourcePath string `mapstructure:"source_path"`
-	VMName     string `mapstructure:"vm_name"`
+	common.PackerConfig          `mapstructure:",squash"`
+	common.HTTPConfig            `mapstructure:",squash"`
+	common.FloppyConfig          `mapstructure:",squash"`
+	common.CDConfig              `mapstructure:",squash"`
+	bootcommand.VNCConfig        `mapstructure:",squash"`
+	vmwcommon.CDROMConfig        `mapstructure:",squash"`
+	vmwcommon.DriverConfig       `mapstructure:",squash"`
+	vmwcommon.OutputConfig       `mapstructure:",squash"`
+	vmwcommon.RunConfig          `mapstructure:",squash"`
+	vmwcommon.ShutdownConfig     `mapstructure:",squash"`
+	vmwcommon.SharedFolderConfig `mapstructure:",squash"`
+	vmwcommon.SnapshotConfig     `mapstructure:",squash"`
+	vmwcommon.SSHConfig          `mapstructure:",squash"`
+	vmwcommon.TimeoutConfig      `mapstructure:",squash"`
+	vmwcommon.ToolsConfig        `mapstructure:",squash"`
+	vmwcommon.VMXConfig          `mapstructure:",squash"`
+	vmwcommon.ExportConfig       `mapstructure:",squash"`
+
+	CoreCount          int                        `mapstructure:"cores"`
+	CoresPerSocket     int                        `mapstructure:"cores_per_socket"`
+	CpuCount           int                        `mapstructure:"cpus"`
+	CustomizationSpec  string                     `mapstructure:"customization_spec"`
+	DiskSize           uint                       `mapstructure:"disk_size"`
+	Linked             bool                       `mapstructure:"linked"`
+	MemorySize         int                        `mapstructure:"memory"`
+	Network            string                     `mapstructure:"network"`
+	NetworkAdapterType string                     `mapstructure:"network_adapter_type"`
+	NetworkAdapters    []vmwcommon.NetworkAdapter `mapstructure:"network_adapters"`
+	Parallel           string                     `mapstructure:"parallel"`
+	RemoteType         string                     `mapstructure:"remote_type"`
+	Serial             string                     `mapstructure:"serial"`
+	Sound              *bool                      `mapstructure:"sound"`
+	SourcePath         string                     `mapstructure:"source_path"`
+	USB                *bool                      `mapstructure:"usb"`
+	VHVEnabled         bool                       `mapstructure:"vhv_enabled"`
+	VMName             string                     `mapstructure:"vm_name"`
 
 	ctx interpolate.Context
 }
@@ -64,13 +85,23 @@ func NewConfig(raws ...interface{}) (*Config, []string, error) {
 	errs = packer.MultiErrorAppend(errs, c.OutputConfig.Prepare(&c.ctx, &c.PackerConfig)...)
 	errs = packer.MultiErrorAppend(errs, c.RunConfig.Prepare(&c.ctx)...)
 	errs = packer.MultiErrorAppend(errs, c.ShutdownConfig.Prepare(&c.ctx)...)
+	errs = packer.MultiErrorAppend(errs, c.SharedFolderConfig.Prepare(&c.ctx)...)
+	errs = packer.MultiErrorAppend(errs, c.SnapshotConfig.Prepare(&c.ctx, &c.DriverConfig)...)
 	errs = packer.MultiErrorAppend(errs, c.SSHConfig.Prepare(&c.ctx)...)
+	errs = packer.MultiErrorAppend(errs, c.TimeoutConfig.Prepare(&c.ctx)...)
 	errs = packer.MultiErrorAppend(errs, c.ToolsConfig.Prepare(&c.ctx)...)
 	errs = packer.MultiErrorAppend(errs, c.VMXConfig.Prepare(&c.ctx)...)
 	errs = packer.MultiErrorAppend(errs, c.FloppyConfig.Prepare(&c.ctx)...)
+	errs = packer.MultiErrorAppend(errs, c.CDConfig.Prepare(&c.ctx)...)
+	errs = packer.MultiErrorAppend(errs, c.CDROMConfig.Prepare(&c.ctx)...)
 	errs = packer.MultiErrorAppend(errs, c.VNCConfig.Prepare(&c.ctx)...)
 	errs = packer.MultiErrorAppend(errs, c.ExportConfig.Prepare(&c.ctx)...)
 
+	if c.ZeroFillCommand != "" && c.Comm.Type == "none" {
+		errs = packer.MultiErrorAppend(errs,
+			fmt.Errorf("zero_fill_command can't be used with communicator = \"none\""))
+	}
+
 	if c.RemoteType == "" {
 		if c.SourcePath == "" {
 			errs = packer.MultiErrorAppend(errs, fmt.Errorf("source_path is blank, but is required"))
@@ -91,6 +122,116 @@ func NewConfig(raws ...interface{}) (*Config, []string, error) {
 			errs = packer.MultiErrorAppend(errs,
 				fmt.Errorf("Only 'esx5' value is accepted for remote_type"))
 		}
+
+		if c.Linked {
+			errs = packer.MultiErrorAppend(errs,
+				fmt.Errorf("linked clones are not supported with remote_type"))
+		}
+
+		if c.Comm.Type == "vmrun" {
+			errs = packer.MultiErrorAppend(errs,
+				fmt.Errorf("the vmrun communicator is not supported with remote_type"))
+		}
+
+		if len(c.SharedFolders) > 0 {
+			errs = packer.MultiErrorAppend(errs,
+				fmt.Errorf("shared_folders are not supported with remote_type"))
+		}
+
+		if len(c.AdditionalISOPaths) > 0 {
+			errs = packer.MultiErrorAppend(errs,
+				fmt.Errorf("additional_iso_paths are not supported with remote_type"))
+		}
+
+		if len(c.CDFiles) > 0 || len(c.CDContent) > 0 {
+			errs = packer.MultiErrorAppend(errs,
+				fmt.Errorf("cd_files and cd_content are not supported with remote_type"))
+		}
+	}
+
+	if c.CustomizationSpec != "" && (c.RemoteType == "" || c.RemoteAPI != "vsphere") {
+		errs = packer.MultiErrorAppend(errs,
+			fmt.Errorf("customization_spec can only be used with remote_api = \"vsphere\""))
+	}
+
+	// The disk of a linked clone is a delta of the source disk, which
+	// can't be expanded
+	if c.DiskSize > 0 && c.Linked {
+		errs = packer.MultiErrorAppend(errs,
+			fmt.Errorf("disk_size can't be used with linked clones"))
+	}
+
+	if c.NetworkAdapterType != "" && !vmwcommon.ValidNetworkAdapterType(c.NetworkAdapterType) {
+		errs = packer.MultiErrorAppend(errs,
+			fmt.Errorf("An invalid network_adapter_type was specified: %s", c.NetworkAdapterType))
+	}
+
+	adapters := c.NetworkAdapters
+	if len(adapters) > 0 {
+		if c.Network != "" || c.NetworkAdapterType != "" {
+			errs = packer.MultiErrorAppend(errs,
+				fmt.Errorf("network and network_adapter_type can't be used with network_adapters"))
+		}
+		errs = packer.MultiErrorAppend(errs, vmwcommon.PrepareNetworkAdapters(adapters)...)
+		c.Network = adapters[0].Network
+	} else if c.Network != "" || c.NetworkAdapterType != "" {
+		adapters = []vmwcommon.NetworkAdapter{{
+			Network:     c.Network,
+			AdapterType: c.NetworkAdapterType,
+		}}
+	}
+
+	var cpuErrs []error
+	c.CoreCount, cpuErrs = vmwcommon.PrepareCPUs(c.CpuCount, c.CoreCount, c.CoresPerSocket, c.MemorySize)
+	errs = packer.MultiErrorAppend(errs, cpuErrs...)
+
+	// The serial and parallel ports of the source VM can only be removed.
+	// Their configuration is kept when they are not set.
+	for name, port := range map[string]string{"serial": c.Serial, "parallel": c.Parallel} {
+		if port != "" && strings.ToLower(port) != "none" {
+			errs = packer.MultiErrorAppend(errs,
+				fmt.Errorf("%s can only be 'none' to remove the %s port of the source VM", name, name))
+		}
+	}
+
+	// Resize and reconnect the network and toggle the devices of the source
+	// VM, unless vmx_data already does. VMX keys are case-insensitive.
+	vmxKeys := make(map[string]struct{}, len(c.VMXData))
+	for k := range c.VMXData {
+		vmxKeys[strings.ToLower(k)] = struct{}{}
+	}
+	deviceData := []map[string]string{c.hardwareVMXData()}
+	for i, adapter := range adapters {
+		deviceData = append(deviceData, adapter.VMXData(i))
+	}
+	for _, data := range deviceData {
+		for k, v := range data {
+			if c.VMXData == nil {
+				c.VMXData = make(map[string]string)
+			}
+			if _, ok := vmxKeys[k]; !ok {
+				c.VMXData[k] = v
+			}
+		}
+	}
+
+	if c.Suspend {
+		// The disks of a suspended VM can't be compacted, and its VMX
+		// can't be changed without losing the suspended state.
+		c.SkipCompaction = true
+		if len(c.VMXDataPost) > 0 {
+			errs = packer.MultiErrorAppend(errs,
+				fmt.Errorf("vmx_data_post can't be used when suspend is enabled"))
+		}
+		if c.VMXRemoveEthernet {
+			errs = packer.MultiErrorAppend(errs,
+				fmt.Errorf("vmx_remove_ethernet_interfaces can't be used when suspend is enabled"))
+		}
+	}
+
+	if c.ConvertToTemplate && c.RemoteType == "" {
+		errs = packer.MultiErrorAppend(errs,
+			fmt.Errorf("convert_to_template is only valid when remote_type=esx5"))
 	}
 
 	err = c.DriverConfig.Validate(c.SkipExport)
@@ -98,13 +239,17 @@ func NewConfig(raws ...interface{}) (*Config, []string, error) {
 		errs = packer.MultiErrorAppend(errs, err)
 	}
 
-	if c.Format != "" {
-		if c.RemoteType != "esx5" {
-			errs = packer.MultiErrorAppend(errs,
-				fmt.Errorf("format is only valid when remote_type=esx5"))
+	if c.BootCommandTransport == "vsphere" && (c.RemoteType == "" || c.RemoteAPI != "vsphere") {
+		errs = packer.MultiErrorAppend(errs,
+			fmt.Errorf("boot_command_transport vsphere can only be used with remote_api = \"vsphere\""))
+	}
+
+	if c.Format == "" {
+		if c.RemoteType == "" {
+			c.Format = "vmx"
+		} else {
+			c.Format = "ovf"
 		}
-	} else {
-		c.Format = "ovf"
 	}
 
 	if !(c.Format == "ova" || c.Format == "ovf" || c.Format == "vmx") {
@@ -112,9 +257,20 @@ func NewConfig(raws ...interface{}) (*Config, []string, error) {
 			fmt.Errorf("format must be one of ova, ovf, or vmx"))
 	}
 
+	// ovftool can only export VMs that are powered off
+	if c.Suspend && !c.SkipExport {
+		if c.RemoteType != "" {
+			errs = packer.MultiErrorAppend(errs,
+				fmt.Errorf("skip_export must be 'true' when suspend is enabled"))
+		} else if c.Format != "vmx" {
+			errs = packer.MultiErrorAppend(errs,
+				fmt.Errorf("format must be 'vmx' when suspend is enabled"))
+		}
+	}
+
 	// Warnings
 	var warnings []string
-	if c.ShutdownCommand == "" {
+	if c.ShutdownCommand == "" && !c.Suspend {
 		warnings = append(warnings,
 			"A shutdown_command was not specified. Without a shutdown command, Packer\n"+
 				"will forcibly halt the virtual machine, which may result in data loss.")
@@ -133,3 +289,54 @@ func NewConfig(raws ...interface{}) (*Config, []string, error) {
 
 	return c, warnings, nil
 }
+
+// hardwareVMXData returns the VMX data that resizes the cpus and memory of
+// the source VM, and adds or removes its sound card, USB controllers, and
+// serial and parallel ports. It also enables nested virtualization.
+func (c *Config) hardwareVMXData() map[string]string {
+	data := make(map[string]string)
+	present := map[bool]string{true: "TRUE", false: "FALSE"}
+
+	if c.CpuCount > 0 {
+		data["numvcpus"] = strconv.Itoa(c.CpuCount)
+	}
+
+	if c.CoreCount > 0 {
+		data["cpuid.corespersocket"] = strconv.Itoa(c.CoreCount)
+	}
+
+	if c.MemorySize > 0 {
+		data["memsize"] = strconv.Itoa(c.MemorySize)
+	}
+
+	if c.Sound != nil {
+		data["sound.present"] = present[*c.Sound]
+		data["sound.startconnected"] = present[*c.Sound]
+		if *c.Sound {
+			data["sound.filename"] = "-1"
+			data["sound.autodetect"] = "TRUE"
+		}
+	}
+
+	if c.USB != nil {
+		data["usb.present"] = present[*c.USB]
+		data["ehci.present"] = present[*c.USB]
+		if !*c.USB {
+			data["usb_xhci.present"] = "FALSE"
+		}
+	}
+
+	if strings.ToLower(c.Serial) == "none" {
+		data["serial0.present"] = "FALSE"
+	}
+
+	if strings.ToLower(c.Parallel) == "none" {
+		data["parallel0.present"] = "FALSE"
+	}
+
+	if c.VHVEnabled {
+		data["vhv.enable"] = "TRUE"
+	}
+
+	return data
+}
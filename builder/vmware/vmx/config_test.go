@@ -58,3 +58,205 @@ func TestNewConfig_sourcePath(t *testing.T) {
 	_, warns, errs = NewConfig(c)
 	testConfigOk(t, warns, errs)
 }
+
+func TestNewConfig_linked(t *testing.T) {
+	// Good
+	c := testConfig(t)
+	c["linked"] = true
+	_, warns, errs := NewConfig(c)
+	testConfigOk(t, warns, errs)
+
+	// Bad
+	c = testConfig(t)
+	c["linked"] = true
+	c["remote_type"] = "esx5"
+	c["remote_host"] = "esxi"
+	c["skip_export"] = true
+	_, warns, errs = NewConfig(c)
+	testConfigErr(t, warns, errs)
+}
+
+func TestNewConfig_diskSize(t *testing.T) {
+	// Good
+	c := testConfig(t)
+	c["disk_size"] = 40000
+	_, warns, errs := NewConfig(c)
+	testConfigOk(t, warns, errs)
+
+	// Bad
+	c = testConfig(t)
+	c["disk_size"] = 40000
+	c["linked"] = true
+	_, warns, errs = NewConfig(c)
+	testConfigErr(t, warns, errs)
+}
+
+func TestNewConfig_convertToTemplate(t *testing.T) {
+	// Bad
+	c := testConfig(t)
+	c["keep_registered"] = true
+	c["convert_to_template"] = true
+	_, warns, errs := NewConfig(c)
+	testConfigErr(t, warns, errs)
+
+	// Good
+	c = testConfig(t)
+	c["keep_registered"] = true
+	c["convert_to_template"] = true
+	c["remote_type"] = "esx5"
+	c["remote_host"] = "esxi"
+	c["skip_export"] = true
+	_, warns, errs = NewConfig(c)
+	testConfigOk(t, warns, errs)
+}
+
+func TestNewConfig_customizationSpec(t *testing.T) {
+	// Bad
+	c := testConfig(t)
+	c["customization_spec"] = "linux-dhcp"
+	c["remote_type"] = "esx5"
+	c["remote_host"] = "vcenter"
+	c["skip_export"] = true
+	_, warns, errs := NewConfig(c)
+	testConfigErr(t, warns, errs)
+
+	// Good
+	c["remote_api"] = "vsphere"
+	c["skip_compaction"] = true
+	_, warns, errs = NewConfig(c)
+	testConfigOk(t, warns, errs)
+}
+
+func TestNewConfig_network(t *testing.T) {
+	c := testConfig(t)
+	c["network"] = "vmnet2"
+	c["network_adapter_type"] = "vmxnet3"
+	c["vmx_data"] = map[string]string{
+		"ethernet0.virtualDev": "e1000",
+	}
+	config, warns, errs := NewConfig(c)
+	testConfigOk(t, warns, errs)
+
+	if config.VMXData["ethernet0.connectiontype"] != "custom" {
+		t.Fatalf("bad: %#v", config.VMXData)
+	}
+	if config.VMXData["ethernet0.vnet"] != "vmnet2" {
+		t.Fatalf("bad: %#v", config.VMXData)
+	}
+	// vmx_data takes precedence
+	if _, ok := config.VMXData["ethernet0.virtualdev"]; ok {
+		t.Fatalf("bad: %#v", config.VMXData)
+	}
+
+	c = testConfig(t)
+	c["network_adapter_type"] = "rtl8139"
+	_, warns, errs = NewConfig(c)
+	testConfigErr(t, warns, errs)
+}
+
+func TestNewConfig_networkAdapters(t *testing.T) {
+	c := testConfig(t)
+	c["network_adapters"] = []map[string]string{
+		{"network": "bridged"},
+		{"network": "vmnet3", "mac_address": "00:50:56:00:00:02"},
+	}
+	config, warns, errs := NewConfig(c)
+	testConfigOk(t, warns, errs)
+
+	if config.Network != "bridged" {
+		t.Fatalf("bad network: %s", config.Network)
+	}
+	if config.VMXData["ethernet0.connectiontype"] != "bridged" {
+		t.Fatalf("bad: %#v", config.VMXData)
+	}
+	if config.VMXData["ethernet1.vnet"] != "vmnet3" || config.VMXData["ethernet1.address"] != "00:50:56:00:00:02" {
+		t.Fatalf("bad: %#v", config.VMXData)
+	}
+
+	c["network"] = "nat"
+	_, warns, errs = NewConfig(c)
+	testConfigErr(t, warns, errs)
+}
+
+func TestNewConfig_devices(t *testing.T) {
+	c := testConfig(t)
+	config, warns, errs := NewConfig(c)
+	testConfigOk(t, warns, errs)
+
+	// The devices of the source VM are kept by default
+	for _, k := range []string{"sound.present", "usb.present", "serial0.present", "parallel0.present"} {
+		if _, ok := config.VMXData[k]; ok {
+			t.Fatalf("bad: %#v", config.VMXData)
+		}
+	}
+
+	c["sound"] = true
+	c["usb"] = false
+	c["serial"] = "none"
+	c["parallel"] = "NONE"
+	c["vhv_enabled"] = true
+	c["vmx_data"] = map[string]string{
+		"Parallel0.present": "TRUE",
+	}
+	config, warns, errs = NewConfig(c)
+	testConfigOk(t, warns, errs)
+
+	expected := map[string]string{
+		"sound.present":     "TRUE",
+		"usb.present":       "FALSE",
+		"ehci.present":      "FALSE",
+		"usb_xhci.present":  "FALSE",
+		"serial0.present":   "FALSE",
+		"Parallel0.present": "TRUE",
+		"vhv.enable":        "TRUE",
+	}
+	for k, v := range expected {
+		if config.VMXData[k] != v {
+			t.Fatalf("bad %s: %#v", k, config.VMXData)
+		}
+	}
+	// vmx_data takes precedence
+	if _, ok := config.VMXData["parallel0.present"]; ok {
+		t.Fatalf("bad: %#v", config.VMXData)
+	}
+
+	c = testConfig(t)
+	c["serial"] = "FILE:/tmp/serial"
+	_, warns, errs = NewConfig(c)
+	testConfigErr(t, warns, errs)
+}
+
+func TestNewConfig_cpus(t *testing.T) {
+	c := testConfig(t)
+	c["cpus"] = 4
+	c["cores_per_socket"] = 2
+	c["memory"] = 4096
+	config, warns, errs := NewConfig(c)
+	testConfigOk(t, warns, errs)
+
+	expected := map[string]string{
+		"numvcpus":             "4",
+		"cpuid.corespersocket": "2",
+		"memsize":              "4096",
+	}
+	for k, v := range expected {
+		if config.VMXData[k] != v {
+			t.Fatalf("bad %s: %#v", k, config.VMXData)
+		}
+	}
+
+	c["cpus"] = 3
+	_, warns, errs = NewConfig(c)
+	testConfigErr(t, warns, errs)
+}
+
+func TestNewConfig_zeroFillCommand(t *testing.T) {
+	c := testConfig(t)
+	c["zero_fill_command"] = "zero"
+	_, warns, errs := NewConfig(c)
+	testConfigOk(t, warns, errs)
+
+	c["communicator"] = "none"
+	_, warns, errs = NewConfig(c)
+	testConfigErr(t, warns, errs)
+}
@@ -7,6 +7,10 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	vmwcommon "github.com/hashicorp/packer/builder/vmware/common"
 	"github.com/hashicorp/packer/helper/multistep"
@@ -14,12 +18,49 @@ import (
 	"github.com/hashicorp/packer/packer/tmp"
 )
 
+var diskPathKeyRe = regexp.MustCompile(`(?i)^(scsi|sata|ide|nvme)([[:digit:]]):([[:digit:]]{1,2})\.fileName`)
+
+// diskBusOrder is the order in which the disks of the adapter types are
+// tried for the boot disk, after the one named by bios.hddOrder.
+var diskBusOrder = map[string]int{"scsi": 0, "sata": 1, "nvme": 2, "ide": 3}
+
+// sortDiskKeys sorts the keys of the paths to the disks of a VM so that the
+// boot disk comes first: the disk that hddOrder names, if any, then the
+// disks of the SCSI, SATA, NVMe and IDE adapters, by adapter and unit.
+func sortDiskKeys(keys []string, hddOrder string) {
+	bootDevice := strings.ToLower(strings.TrimSpace(strings.Split(hddOrder, ",")[0]))
+
+	rank := func(key string) []int {
+		m := diskPathKeyRe.FindStringSubmatch(key)
+		bus := strings.ToLower(m[1])
+		adapter, _ := strconv.Atoi(m[2])
+		unit, _ := strconv.Atoi(m[3])
+		boot := 1
+		if bootDevice != "" && bootDevice == fmt.Sprintf("%s%d:%d", bus, adapter, unit) {
+			boot = 0
+		}
+		return []int{boot, diskBusOrder[bus], adapter, unit}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := rank(keys[i]), rank(keys[j])
+		for k := range a {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return false
+	})
+}
+
 // StepCloneVMX takes a VMX file and clones the VM into the output directory.
 type StepCloneVMX struct {
 	OutputDir string
 	Path      string
 	VMName    string
 	Linked    bool
+	Network   string
+	Timeout   time.Duration
 	tempDir   string
 }
 
@@ -38,8 +79,10 @@ func (s *StepCloneVMX) Run(ctx context.Context, state multistep.StateBag) multis
 	log.Printf("Cloning from: %s", s.Path)
 	log.Printf("Cloning to: %s", vmxPath)
 
-	if err := driver.Clone(vmxPath, s.Path, s.Linked); err != nil {
-		return halt(err)
+	cloneCtx, cancel := vmwcommon.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+	if err := driver.Clone(cloneCtx, vmxPath, s.Path, s.Linked); err != nil {
+		return halt(vmwcommon.TimeoutError(cloneCtx, err, "disk_creation_timeout", s.Timeout))
 	}
 
 	// Read in the machine configuration from the cloned VMX file
@@ -83,14 +126,18 @@ func (s *StepCloneVMX) Run(ctx context.Context, state multistep.StateBag) multis
 	// https://kb.vmware.com/s/article/1003746
 	// The following regexp is used to match all possible disk attachment
 	// points that may be found in the VMX file across all VMware
-	// platforms/versions and Virtual Machine Hardware versions
-	diskPathKeyRe := regexp.MustCompile(`(?i)^(scsi|sata|ide|nvme)[[:digit:]]:[[:digit:]]{1,2}\.fileName`)
+	// platforms/versions and Virtual Machine Hardware versions.
+	var diskKeys []string
 	for k, v := range vmxData {
 		match := diskPathKeyRe.FindString(k)
 		if match != "" && filepath.Ext(v) == ".vmdk" {
-			diskFilenames = append(diskFilenames, v)
+			diskKeys = append(diskKeys, k)
 		}
 	}
+	sortDiskKeys(diskKeys, vmxData["bios.hddorder"])
+	for _, k := range diskKeys {
+		diskFilenames = append(diskFilenames, vmxData[k])
+	}
 
 	// Write out the relative, host filesystem paths to the disks
 	var diskFullPaths []string
@@ -109,6 +156,10 @@ func (s *StepCloneVMX) Run(ctx context.Context, state multistep.StateBag) multis
 		networkType = vmxData["ethernet0.connectiontype"]
 		log.Printf("Discovered the network type: %s", networkType)
 	}
+	if s.Network != "" {
+		networkType = s.Network
+		log.Printf("Using the configured network: %s", networkType)
+	}
 	if networkType == "" {
 		networkType = "nat"
 		log.Printf("Defaulting to network type: %s", networkType)
@@ -93,6 +93,7 @@ func TestStepCloneVMX(t *testing.T) {
 	} else {
 		assert.ElementsMatchf(t, stateDiskPaths.([]string), diskFullPaths,
 			"%s\nshould contain the same elements as:\n%s", stateDiskPaths.([]string), diskFullPaths)
+		assert.Equal(t, diskFullPaths[0], stateDiskPaths.([]string)[0], "The SCSI disk should be first.")
 	}
 
 	// Test we got the network type
@@ -102,3 +103,28 @@ func TestStepCloneVMX(t *testing.T) {
 		t.Fatalf("bad network type: %#v", networkType)
 	}
 }
+
+func TestSortDiskKeys(t *testing.T) {
+	keys := []string{
+		"ide0:0.filename",
+		"sata0:0.filename",
+		"scsi1:0.filename",
+		"scsi0:10.filename",
+		"nvme0:0.filename",
+		"scsi0:2.filename",
+	}
+	sortDiskKeys(keys, "")
+	expected := []string{
+		"scsi0:2.filename",
+		"scsi0:10.filename",
+		"scsi1:0.filename",
+		"sata0:0.filename",
+		"nvme0:0.filename",
+		"ide0:0.filename",
+	}
+	assert.Equal(t, expected, keys)
+
+	// The disk that bios.hddOrder names boots first
+	sortDiskKeys(keys, "sata0:0")
+	assert.Equal(t, "sata0:0.filename", keys[0])
+}
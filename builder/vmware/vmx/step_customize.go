@@ -0,0 +1,58 @@
+package vmx
+
+import (
+	"context"
+	"fmt"
+
+	vmwcommon "github.com/hashicorp/packer/builder/vmware/common"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// customizer is implemented by the drivers that can apply a guest
+// customization spec to the VM.
+type customizer interface {
+	Customize(ctx context.Context, specName string) error
+}
+
+// StepCustomize applies the guest customization spec to the registered VM
+// before it is started, unless no spec is given.
+//
+// Uses:
+//   driver Driver
+//   ui     packer.Ui
+//
+// Produces:
+//   <nothing>
+type StepCustomize struct {
+	Spec string
+}
+
+func (s *StepCustomize) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	if s.Spec == "" {
+		return multistep.ActionContinue
+	}
+
+	driver := state.Get("driver").(vmwcommon.Driver)
+	ui := state.Get("ui").(packer.Ui)
+
+	c, ok := driver.(customizer)
+	if !ok {
+		err := fmt.Errorf("customization_spec requires remote_api = \"vsphere\"")
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	ui.Say(fmt.Sprintf("Applying customization spec %s...", s.Spec))
+	if err := c.Customize(ctx, s.Spec); err != nil {
+		err := fmt.Errorf("Error customizing VM: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *StepCustomize) Cleanup(multistep.StateBag) {}
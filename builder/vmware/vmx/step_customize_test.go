@@ -0,0 +1,62 @@
+package vmx
+
+import (
+	"context"
+	"testing"
+
+	vmwcommon "github.com/hashicorp/packer/builder/vmware/common"
+	"github.com/hashicorp/packer/helper/multistep"
+)
+
+type customizerDriverMock struct {
+	vmwcommon.DriverMock
+
+	specName string
+}
+
+func (d *customizerDriverMock) Customize(ctx context.Context, specName string) error {
+	d.specName = specName
+	return nil
+}
+
+func TestStepCustomize_impl(t *testing.T) {
+	var _ multistep.Step = new(StepCustomize)
+}
+
+func TestStepCustomize(t *testing.T) {
+	state := testState(t)
+	driver := new(customizerDriverMock)
+	state.Put("driver", driver)
+	step := &StepCustomize{Spec: "linux-dhcp"}
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if _, ok := state.GetOk("error"); ok {
+		t.Fatal("should NOT have error")
+	}
+	if driver.specName != "linux-dhcp" {
+		t.Fatalf("bad: %s", driver.specName)
+	}
+}
+
+func TestStepCustomize_skip(t *testing.T) {
+	state := testState(t)
+	step := &StepCustomize{}
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+}
+
+func TestStepCustomize_unsupported(t *testing.T) {
+	state := testState(t)
+	step := &StepCustomize{Spec: "linux-dhcp"}
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if _, ok := state.GetOk("error"); !ok {
+		t.Fatal("should have error")
+	}
+}
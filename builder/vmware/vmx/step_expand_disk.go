@@ -0,0 +1,56 @@
+package vmx
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	vmwcommon "github.com/hashicorp/packer/builder/vmware/common"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// StepExpandDisk expands the boot disk of the cloned VM, which is the first
+// of disk_full_paths, to the size given in megabytes, unless the size is
+// zero.
+//
+// Uses:
+//   driver Driver
+//   disk_full_paths ([]string) - The full paths to all attached disks, the
+//     boot disk first
+//   ui     packer.Ui
+//
+// Produces:
+//   <nothing>
+type StepExpandDisk struct {
+	Size    uint
+	Timeout time.Duration
+}
+
+func (s *StepExpandDisk) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	if s.Size == 0 {
+		return multistep.ActionContinue
+	}
+
+	driver := state.Get("driver").(vmwcommon.Driver)
+	ui := state.Get("ui").(packer.Ui)
+	diskFullPaths := state.Get("disk_full_paths").([]string)
+
+	size := fmt.Sprintf("%dM", uint64(s.Size))
+	ui.Say(fmt.Sprintf("Expanding virtual disk to %s...", size))
+	log.Printf("Expanding disk %s to %s", diskFullPaths[0], size)
+	ctx, cancel := vmwcommon.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+	if err := driver.ExpandDisk(ctx, diskFullPaths[0], size); err != nil {
+		err := fmt.Errorf("Error expanding disk: %s",
+			vmwcommon.TimeoutError(ctx, err, "disk_creation_timeout", s.Timeout))
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *StepExpandDisk) Cleanup(multistep.StateBag) {}
@@ -0,0 +1,51 @@
+package vmx
+
+import (
+	"context"
+	"testing"
+
+	vmwcommon "github.com/hashicorp/packer/builder/vmware/common"
+	"github.com/hashicorp/packer/helper/multistep"
+)
+
+func TestStepExpandDisk_impl(t *testing.T) {
+	var _ multistep.Step = new(StepExpandDisk)
+}
+
+func TestStepExpandDisk(t *testing.T) {
+	state := testState(t)
+	state.Put("disk_full_paths", []string{"output/disk1.vmdk", "output/disk2.vmdk"})
+	step := &StepExpandDisk{Size: 40000}
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if _, ok := state.GetOk("error"); ok {
+		t.Fatal("should NOT have error")
+	}
+
+	driver := state.Get("driver").(*vmwcommon.DriverMock)
+	if !driver.ExpandDiskCalled {
+		t.Fatal("expand disk should be called")
+	}
+	if driver.ExpandDiskPath != "output/disk1.vmdk" {
+		t.Fatalf("bad: %s", driver.ExpandDiskPath)
+	}
+	if driver.ExpandDiskSize != "40000M" {
+		t.Fatalf("bad: %s", driver.ExpandDiskSize)
+	}
+}
+
+func TestStepExpandDisk_skip(t *testing.T) {
+	state := testState(t)
+	step := &StepExpandDisk{}
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	driver := state.Get("driver").(*vmwcommon.DriverMock)
+	if driver.ExpandDiskCalled {
+		t.Fatal("expand disk should NOT be called")
+	}
+}
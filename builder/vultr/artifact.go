@@ -0,0 +1,44 @@
+package vultr
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+type Artifact struct {
+	// The ID of the snapshot
+	snapshotID string
+
+	// The description of the snapshot
+	description string
+
+	// The client for making API calls
+	client *client
+}
+
+func (*Artifact) BuilderId() string {
+	return BuilderId
+}
+
+func (*Artifact) Files() []string {
+	// No files with Vultr
+	return nil
+}
+
+func (a *Artifact) Id() string {
+	return a.snapshotID
+}
+
+func (a *Artifact) String() string {
+	return fmt.Sprintf("A snapshot was created: '%s' (ID: %s)", a.description, a.snapshotID)
+}
+
+func (a *Artifact) State(name string) interface{} {
+	return nil
+}
+
+func (a *Artifact) Destroy() error {
+	log.Printf("Destroying snapshot: %s (%s)", a.snapshotID, a.description)
+	return a.client.DestroySnapshot(context.TODO(), a.snapshotID)
+}
@@ -0,0 +1,33 @@
+package vultr
+
+import (
+	"testing"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+func TestArtifact_Impl(t *testing.T) {
+	var raw interface{}
+	raw = &Artifact{}
+	if _, ok := raw.(packer.Artifact); !ok {
+		t.Fatalf("Artifact should be artifact")
+	}
+}
+
+func TestArtifactId(t *testing.T) {
+	a := &Artifact{"5359435d28b9a", "packer-foobar", nil}
+	expected := "5359435d28b9a"
+
+	if a.Id() != expected {
+		t.Fatalf("artifact ID should match: %v", expected)
+	}
+}
+
+func TestArtifactString(t *testing.T) {
+	a := &Artifact{"5359435d28b9a", "packer-foobar", nil}
+	expected := "A snapshot was created: 'packer-foobar' (ID: 5359435d28b9a)"
+
+	if a.String() != expected {
+		t.Fatalf("artifact string should match: %v", expected)
+	}
+}
@@ -0,0 +1,257 @@
+package vultr
+
+import (
+	"io/ioutil"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/packer/packer"
+)
+
+func testConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"api_key": "bar",
+		"region":  "ewr",
+		"plan":    "vc2-1c-1gb",
+		"os_id":   387,
+	}
+}
+
+func TestBuilder_ImplementsBuilder(t *testing.T) {
+	var raw interface{}
+	raw = &Builder{}
+	if _, ok := raw.(packer.Builder); !ok {
+		t.Fatalf("Builder should be a builder")
+	}
+}
+
+func TestBuilder_Prepare_BadType(t *testing.T) {
+	b := &Builder{}
+	c := map[string]interface{}{
+		"api_key": []string{},
+	}
+
+	warnings, err := b.Prepare(c)
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err == nil {
+		t.Fatalf("prepare should fail")
+	}
+}
+
+func TestBuilderPrepare_InvalidKey(t *testing.T) {
+	var b Builder
+	config := testConfig()
+
+	// Add a random key
+	config["i_should_not_be_valid"] = true
+	warnings, err := b.Prepare(config)
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestBuilderPrepare_Defaults(t *testing.T) {
+	var b Builder
+	config := testConfig()
+
+	warnings, err := b.Prepare(config)
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	if b.config.Comm.SSHUsername != "root" {
+		t.Errorf("invalid: %s", b.config.Comm.SSHUsername)
+	}
+	if b.config.ShutdownCommand != "shutdown -P now" {
+		t.Errorf("invalid: %s", b.config.ShutdownCommand)
+	}
+	if b.config.StateTimeout != 10*time.Minute {
+		t.Errorf("invalid: %s", b.config.StateTimeout)
+	}
+	if b.config.SnapshotTimeout != 60*time.Minute {
+		t.Errorf("invalid: %s", b.config.SnapshotTimeout)
+	}
+	if b.config.InstanceLabel == "" {
+		t.Errorf("invalid: %s", b.config.InstanceLabel)
+	}
+}
+
+func TestBuilderPrepare_Required(t *testing.T) {
+	for _, key := range []string{"region", "plan", "os_id"} {
+		var b Builder
+		config := testConfig()
+		delete(config, key)
+
+		warnings, err := b.Prepare(config)
+		if len(warnings) > 0 {
+			t.Fatalf("bad: %#v", warnings)
+		}
+		if err == nil {
+			t.Fatalf("%s: should have error", key)
+		}
+	}
+}
+
+func TestBuilderPrepare_APIKey(t *testing.T) {
+	var b Builder
+	config := testConfig()
+	delete(config, "api_key")
+
+	// Test missing
+	os.Setenv("VULTR_API_KEY", "")
+	warnings, err := b.Prepare(config)
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err == nil {
+		t.Fatal("should have error")
+	}
+
+	// Test environment
+	os.Setenv("VULTR_API_KEY", "foo")
+	defer os.Setenv("VULTR_API_KEY", "")
+	b = Builder{}
+	warnings, err = b.Prepare(config)
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	if b.config.APIKey != "foo" {
+		t.Errorf("invalid: %s", b.config.APIKey)
+	}
+}
+
+func TestBuilderPrepare_SnapshotID(t *testing.T) {
+	var b Builder
+	config := testConfig()
+
+	// Test with os_id
+	config["snapshot_id"] = "5359435d-28b9-4a1f-8a8e-6b0e4b6b6f3c"
+	warnings, err := b.Prepare(config)
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err == nil {
+		t.Fatal("should have error")
+	}
+
+	// Test without os_id
+	delete(config, "os_id")
+	b = Builder{}
+	warnings, err = b.Prepare(config)
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+}
+
+func TestBuilderPrepare_StartupScript(t *testing.T) {
+	var b Builder
+	config := testConfig()
+
+	// Test set
+	config["startup_script"] = "#!/bin/sh\necho hello"
+	warnings, err := b.Prepare(config)
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	// Test with script_id
+	config["script_id"] = "cb676a46-66fd-4dfb-b839-443f2e6c0b60"
+	b = Builder{}
+	warnings, err = b.Prepare(config)
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestBuilderPrepare_UserDataFile(t *testing.T) {
+	var b Builder
+	config := testConfig()
+
+	tf, err := ioutil.TempFile("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.Remove(tf.Name())
+	tf.Write([]byte("#cloud-config"))
+	tf.Close()
+
+	// Test set
+	config["user_data_file"] = tf.Name()
+	warnings, err := b.Prepare(config)
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	if b.config.UserData != "#cloud-config" {
+		t.Errorf("invalid: %s", b.config.UserData)
+	}
+
+	// Test with user_data
+	config["user_data"] = "#cloud-config"
+	b = Builder{}
+	warnings, err = b.Prepare(config)
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err == nil {
+		t.Fatal("should have error")
+	}
+
+	// Test missing
+	delete(config, "user_data")
+	config["user_data_file"] = tf.Name() + ".missing"
+	b = Builder{}
+	warnings, err = b.Prepare(config)
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestBuilderPrepare_SnapshotDescription(t *testing.T) {
+	var b Builder
+	config := testConfig()
+
+	// Test set with template
+	config["snapshot_description"] = "{{timestamp}}"
+	warnings, err := b.Prepare(config)
+	if len(warnings) > 0 {
+		t.Fatalf("bad: %#v", warnings)
+	}
+	if err != nil {
+		t.Fatalf("should not have error: %s", err)
+	}
+
+	_, err = strconv.ParseInt(b.config.SnapshotDescription, 0, 0)
+	if err != nil {
+		t.Fatalf("failed to parse int in template: %s", err)
+	}
+}
@@ -0,0 +1,228 @@
+package vultr
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/packer/version"
+)
+
+// defaultEndpoint is the base URL of version 2 of the Vultr API.
+const defaultEndpoint = "https://api.vultr.com/v2/"
+
+// client is a minimal client for the parts of the Vultr API that the
+// builder uses.
+type client struct {
+	apiKey   string
+	endpoint string
+	http     *http.Client
+}
+
+func newClient(apiKey string) *client {
+	return &client{
+		apiKey:   apiKey,
+		endpoint: defaultEndpoint,
+		http:     http.DefaultClient,
+	}
+}
+
+type server struct {
+	ID           string `json:"id"`
+	Label        string `json:"label"`
+	MainIP       string `json:"main_ip"`
+	Status       string `json:"status"`
+	PowerStatus  string `json:"power_status"`
+	ServerStatus string `json:"server_status"`
+}
+
+// ready returns whether the server has been installed and is running.
+func (s *server) ready() bool {
+	return s.Status == "active" && s.ServerStatus == "ok" && s.PowerStatus == "running"
+}
+
+type snapshot struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	Size        int64  `json:"size"`
+	Status      string `json:"status"`
+}
+
+type serverCreateOptions struct {
+	Region     string   `json:"region"`
+	Plan       string   `json:"plan"`
+	OSID       int      `json:"os_id,omitempty"`
+	SnapshotID string   `json:"snapshot_id,omitempty"`
+	ScriptID   string   `json:"script_id,omitempty"`
+	SSHKeyIDs  []string `json:"sshkey_id,omitempty"`
+	Label      string   `json:"label,omitempty"`
+	Hostname   string   `json:"hostname,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	UserData   string   `json:"user_data,omitempty"`
+	EnableIPv6 bool     `json:"enable_ipv6,omitempty"`
+}
+
+func (c *client) CreateServer(ctx context.Context, opts serverCreateOptions) (string, error) {
+	if opts.UserData != "" {
+		opts.UserData = base64.StdEncoding.EncodeToString([]byte(opts.UserData))
+	}
+
+	var result struct {
+		Instance server `json:"instance"`
+	}
+	if err := c.request(ctx, "POST", "instances", opts, &result); err != nil {
+		return "", err
+	}
+	return result.Instance.ID, nil
+}
+
+func (c *client) GetServer(ctx context.Context, id string) (*server, error) {
+	var result struct {
+		Instance *server `json:"instance"`
+	}
+	if err := c.request(ctx, "GET", "instances/"+id, nil, &result); err != nil {
+		return nil, err
+	}
+	if result.Instance == nil {
+		return nil, fmt.Errorf("server %s not found", id)
+	}
+	return result.Instance, nil
+}
+
+func (c *client) HaltServer(ctx context.Context, id string) error {
+	return c.request(ctx, "POST", "instances/"+id+"/halt", nil, nil)
+}
+
+func (c *client) DestroyServer(ctx context.Context, id string) error {
+	return c.request(ctx, "DELETE", "instances/"+id, nil, nil)
+}
+
+func (c *client) CreateSnapshot(ctx context.Context, serverID, description string) (string, error) {
+	var result struct {
+		Snapshot snapshot `json:"snapshot"`
+	}
+	err := c.request(ctx, "POST", "snapshots", map[string]string{
+		"instance_id": serverID,
+		"description": description,
+	}, &result)
+	if err != nil {
+		return "", err
+	}
+	return result.Snapshot.ID, nil
+}
+
+func (c *client) GetSnapshot(ctx context.Context, id string) (*snapshot, error) {
+	var result struct {
+		Snapshot *snapshot `json:"snapshot"`
+	}
+	if err := c.request(ctx, "GET", "snapshots/"+id, nil, &result); err != nil {
+		return nil, err
+	}
+	if result.Snapshot == nil {
+		return nil, fmt.Errorf("snapshot %s not found", id)
+	}
+	return result.Snapshot, nil
+}
+
+func (c *client) DestroySnapshot(ctx context.Context, id string) error {
+	return c.request(ctx, "DELETE", "snapshots/"+id, nil, nil)
+}
+
+func (c *client) CreateSSHKey(ctx context.Context, name, publicKey string) (string, error) {
+	var result struct {
+		SSHKey struct {
+			ID string `json:"id"`
+		} `json:"ssh_key"`
+	}
+	err := c.request(ctx, "POST", "ssh-keys", map[string]string{
+		"name":    name,
+		"ssh_key": publicKey,
+	}, &result)
+	if err != nil {
+		return "", err
+	}
+	return result.SSHKey.ID, nil
+}
+
+func (c *client) DestroySSHKey(ctx context.Context, id string) error {
+	return c.request(ctx, "DELETE", "ssh-keys/"+id, nil, nil)
+}
+
+// CreateStartupScript creates a script that runs when the server boots.
+func (c *client) CreateStartupScript(ctx context.Context, name, script string) (string, error) {
+	var result struct {
+		StartupScript struct {
+			ID string `json:"id"`
+		} `json:"startup_script"`
+	}
+	err := c.request(ctx, "POST", "startup-scripts", map[string]string{
+		"name":   name,
+		"script": base64.StdEncoding.EncodeToString([]byte(script)),
+		"type":   "boot",
+	}, &result)
+	if err != nil {
+		return "", err
+	}
+	return result.StartupScript.ID, nil
+}
+
+func (c *client) DestroyStartupScript(ctx context.Context, id string) error {
+	return c.request(ctx, "DELETE", "startup-scripts/"+id, nil, nil)
+}
+
+// request sends a request to the API with body encoded as JSON, if it isn't
+// nil, and decodes the JSON response into result, if it isn't nil. The API
+// returns errors as a JSON object with an "error" message.
+func (c *client) request(ctx context.Context, method, path string, body, result interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequest(method, c.endpoint+path, reqBody)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	req.Header.Set("User-Agent", fmt.Sprintf("Packer/%s", version.FormattedVersion()))
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		msg := strings.TrimSpace(string(respBody))
+		if json.Unmarshal(respBody, &apiErr) == nil && apiErr.Error != "" {
+			msg = apiErr.Error
+		}
+		return fmt.Errorf("%s %s: %s: %s", method, req.URL.Path, resp.Status, msg)
+	}
+
+	if result == nil || len(respBody) == 0 {
+		return nil
+	}
+	return json.Unmarshal(respBody, result)
+}
@@ -0,0 +1,155 @@
+package vultr
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testClient returns a client for a fake API that handles requests with
+// handler, checking that they are authenticated.
+func testClient(t *testing.T, handler http.HandlerFunc) (*client, func()) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "Bearer secret" {
+			t.Errorf("bad authorization: %q", auth)
+		}
+		handler(w, r)
+	}))
+
+	c := newClient("secret")
+	c.endpoint = ts.URL + "/v2/"
+	return c, ts.Close
+}
+
+func TestClient_CreateServer(t *testing.T) {
+	c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/v2/instances" {
+			t.Errorf("bad request: %s %s", r.Method, r.URL.Path)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("bad content type: %q", ct)
+		}
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		expected := map[string]interface{}{
+			"region":      "ewr",
+			"plan":        "vc2-1c-1gb",
+			"snapshot_id": "5359435d-28b9-4a1f-8a8e-6b0e4b6b6f3c",
+			"script_id":   "cb676a46-66fd-4dfb-b839-443f2e6c0b60",
+			"sshkey_id":   []interface{}{"541b4960-f23b-4d2a-9e4d-1e6c8b5f0a9e"},
+			"label":       "packer",
+			"user_data":   base64.StdEncoding.EncodeToString([]byte("#cloud-config")),
+		}
+		if !reflect.DeepEqual(body, expected) {
+			t.Errorf("bad body: %#v", body)
+		}
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprint(w, `{"instance": {"id": "cb676a46-66fd-4dfb-b839-443f2e6c0b61", "status": "pending"}}`)
+	})
+	defer done()
+
+	id, err := c.CreateServer(context.Background(), serverCreateOptions{
+		Region:     "ewr",
+		Plan:       "vc2-1c-1gb",
+		SnapshotID: "5359435d-28b9-4a1f-8a8e-6b0e4b6b6f3c",
+		ScriptID:   "cb676a46-66fd-4dfb-b839-443f2e6c0b60",
+		SSHKeyIDs:  []string{"541b4960-f23b-4d2a-9e4d-1e6c8b5f0a9e"},
+		Label:      "packer",
+		UserData:   "#cloud-config",
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if id != "cb676a46-66fd-4dfb-b839-443f2e6c0b61" {
+		t.Fatalf("bad id: %s", id)
+	}
+}
+
+func TestClient_GetSnapshot(t *testing.T) {
+	c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/v2/snapshots/5359435d-28b9-4a1f-8a8e-6b0e4b6b6f3c" {
+			t.Errorf("bad request: %s %s", r.Method, r.URL.Path)
+		}
+		fmt.Fprint(w, `{"snapshot": {"id": "5359435d-28b9-4a1f-8a8e-6b0e4b6b6f3c",
+			"description": "packer", "size": 42949672960, "status": "complete"}}`)
+	})
+	defer done()
+
+	s, err := c.GetSnapshot(context.Background(), "5359435d-28b9-4a1f-8a8e-6b0e4b6b6f3c")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if s.ID != "5359435d-28b9-4a1f-8a8e-6b0e4b6b6f3c" || s.Status != "complete" {
+		t.Fatalf("bad snapshot: %#v", s)
+	}
+}
+
+func TestClient_DestroyServer(t *testing.T) {
+	c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "DELETE" || r.URL.Path != "/v2/instances/cb676a46-66fd-4dfb-b839-443f2e6c0b61" {
+			t.Errorf("bad request: %s %s", r.Method, r.URL.Path)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	defer done()
+
+	if err := c.DestroyServer(context.Background(), "cb676a46-66fd-4dfb-b839-443f2e6c0b61"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+func TestClient_error(t *testing.T) {
+	c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error": "instance not found", "status": 404}`)
+	})
+	defer done()
+
+	err := c.DestroyServer(context.Background(), "cb676a46-66fd-4dfb-b839-443f2e6c0b61")
+	if err == nil {
+		t.Fatal("should have error")
+	}
+	expected := "DELETE /v2/instances/cb676a46-66fd-4dfb-b839-443f2e6c0b61: 404 Not Found: instance not found"
+	if err.Error() != expected {
+		t.Fatalf("bad error: %s", err)
+	}
+}
+
+func TestWaitForServer(t *testing.T) {
+	defer func(d time.Duration) { pollInterval = d }(pollInterval)
+	pollInterval = time.Millisecond
+
+	calls := 0
+	c, done := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		status := "installingbooting"
+		if calls == 3 {
+			status = "ok"
+		}
+		fmt.Fprintf(w, `{"instance": {"id": "cb676a46-66fd-4dfb-b839-443f2e6c0b61",
+			"main_ip": "192.0.2.10", "status": "active", "power_status": "running",
+			"server_status": "%s"}}`, status)
+	})
+	defer done()
+
+	s, err := waitForServer(context.Background(), c, "cb676a46-66fd-4dfb-b839-443f2e6c0b61",
+		time.Minute, (*server).ready)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if calls != 3 {
+		t.Fatalf("bad number of calls: %d", calls)
+	}
+	if s.MainIP != "192.0.2.10" {
+		t.Fatalf("bad IP: %s", s.MainIP)
+	}
+}
@@ -0,0 +1,154 @@
+package vultr
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/uuid"
+	"github.com/hashicorp/packer/helper/communicator"
+	"github.com/hashicorp/packer/helper/config"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/template/interpolate"
+)
+
+type Config struct {
+	common.PackerConfig `mapstructure:",squash"`
+	Comm                communicator.Config `mapstructure:",squash"`
+
+	APIKey string `mapstructure:"api_key"`
+
+	Region     string `mapstructure:"region"`
+	Plan       string `mapstructure:"plan"`
+	OSID       int    `mapstructure:"os_id"`
+	SnapshotID string `mapstructure:"snapshot_id"`
+
+	InstanceLabel string   `mapstructure:"instance_label"`
+	Hostname      string   `mapstructure:"hostname"`
+	Tags          []string `mapstructure:"tags"`
+	EnableIPv6    bool     `mapstructure:"enable_ipv6"`
+
+	ScriptID      string `mapstructure:"script_id"`
+	StartupScript string `mapstructure:"startup_script"`
+	UserData      string `mapstructure:"user_data"`
+	UserDataFile  string `mapstructure:"user_data_file"`
+
+	SnapshotDescription string        `mapstructure:"snapshot_description"`
+	ShutdownCommand     string        `mapstructure:"shutdown_command"`
+	StateTimeout        time.Duration `mapstructure:"state_timeout"`
+	SnapshotTimeout     time.Duration `mapstructure:"snapshot_timeout"`
+
+	ctx interpolate.Context
+}
+
+func NewConfig(raws ...interface{}) (*Config, []string, error) {
+	c := new(Config)
+
+	err := config.Decode(c, &config.DecodeOpts{
+		Interpolate:        true,
+		InterpolateContext: &c.ctx,
+	}, raws...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Defaults
+	if c.APIKey == "" {
+		// Default to environment variable for api_key, if it exists
+		c.APIKey = os.Getenv("VULTR_API_KEY")
+	}
+
+	if c.SnapshotDescription == "" {
+		def, err := interpolate.Render("packer-{{timestamp}}", nil)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// Default to packer-{{ unix timestamp (utc) }}
+		c.SnapshotDescription = def
+	}
+
+	if c.InstanceLabel == "" {
+		// Default to packer-[time-ordered-uuid]
+		c.InstanceLabel = fmt.Sprintf("packer-%s", uuid.TimeOrderedUUID())
+	}
+
+	if c.ShutdownCommand == "" {
+		c.ShutdownCommand = "shutdown -P now"
+	}
+
+	if c.StateTimeout == 0 {
+		// Default to 10 minute timeouts waiting for desired state. i.e
+		// waiting for the server to be installed
+		c.StateTimeout = 10 * time.Minute
+	}
+
+	if c.SnapshotTimeout == 0 {
+		// Default to 60 minutes timeout, waiting for the snapshot to complete
+		c.SnapshotTimeout = 60 * time.Minute
+	}
+
+	if c.Comm.SSHUsername == "" {
+		c.Comm.SSHUsername = "root"
+	}
+
+	var errs *packer.MultiError
+	if es := c.Comm.Prepare(&c.ctx); len(es) > 0 {
+		errs = packer.MultiErrorAppend(errs, es...)
+	}
+	if c.APIKey == "" {
+		// Required configurations that will display errors if not set
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("api_key for auth must be specified"))
+	}
+
+	if c.Region == "" {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("region is required"))
+	}
+
+	if c.Plan == "" {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("plan is required"))
+	}
+
+	if c.OSID == 0 && c.SnapshotID == "" {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("one of os_id or snapshot_id is required"))
+	} else if c.OSID != 0 && c.SnapshotID != "" {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("only one of os_id or snapshot_id can be specified"))
+	}
+
+	if c.ScriptID != "" && c.StartupScript != "" {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("only one of script_id or startup_script can be specified"))
+	}
+
+	if c.UserData != "" && c.UserDataFile != "" {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("only one of user_data or user_data_file can be specified"))
+	} else if c.UserDataFile != "" {
+		contents, err := ioutil.ReadFile(c.UserDataFile)
+		if err != nil {
+			errs = packer.MultiErrorAppend(
+				errs, fmt.Errorf("user_data_file not found: %s", c.UserDataFile))
+		}
+		c.UserData = string(contents)
+	}
+
+	if errs != nil && len(errs.Errors) > 0 {
+		return nil, nil, errs
+	}
+
+	packer.LogSecretFilter.Set(c.APIKey)
+	return c, nil, nil
+}
+
+func commHost(state multistep.StateBag) (string, error) {
+	return state.Get("server_ip").(string), nil
+}
@@ -0,0 +1,88 @@
+package vultr
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/packer/common/retry"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+type stepCreateServer struct {
+	serverID string
+}
+
+func (s *stepCreateServer) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	client := state.Get("client").(*client)
+	ui := state.Get("ui").(packer.Ui)
+	c := state.Get("config").(*Config)
+	sshKeyID := state.Get("ssh_key_id").(string)
+	scriptID := state.Get("script_id").(string)
+
+	// Create the server based on configuration
+	ui.Say("Creating server...")
+	serverID, err := client.CreateServer(ctx, serverCreateOptions{
+		Region:     c.Region,
+		Plan:       c.Plan,
+		OSID:       c.OSID,
+		SnapshotID: c.SnapshotID,
+		ScriptID:   scriptID,
+		SSHKeyIDs:  []string{sshKeyID},
+		Label:      c.InstanceLabel,
+		Hostname:   c.Hostname,
+		Tags:       c.Tags,
+		UserData:   c.UserData,
+		EnableIPv6: c.EnableIPv6,
+	})
+	if err != nil {
+		err := fmt.Errorf("Error creating server: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	// We use this in cleanup
+	s.serverID = serverID
+
+	// Store the server id for later
+	state.Put("server_id", serverID)
+
+	ui.Say("Waiting for server to become active...")
+	server, err := waitForServer(ctx, client, serverID, c.StateTimeout, (*server).ready)
+	if err != nil {
+		err := fmt.Errorf("Error creating server: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	state.Put("server_ip", server.MainIP)
+
+	return multistep.ActionContinue
+}
+
+func (s *stepCreateServer) Cleanup(state multistep.StateBag) {
+	// If the server id isn't there, we probably never created it
+	if s.serverID == "" {
+		return
+	}
+
+	client := state.Get("client").(*client)
+	ui := state.Get("ui").(packer.Ui)
+	c := state.Get("config").(*Config)
+
+	// Destroy the server we just created. Vultr refuses to destroy servers
+	// for a few minutes after they are created, so keep trying.
+	ui.Say("Destroying server...")
+	err := retry.Config{
+		StartTimeout: c.StateTimeout,
+		RetryDelay:   func() time.Duration { return pollInterval },
+	}.Run(context.TODO(), func(ctx context.Context) error {
+		return client.DestroyServer(ctx, s.serverID)
+	})
+	if err != nil {
+		ui.Error(fmt.Sprintf(
+			"Error destroying server. Please destroy it manually: %s", err))
+	}
+}
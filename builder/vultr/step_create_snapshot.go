@@ -0,0 +1,42 @@
+package vultr
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+type stepCreateSnapshot struct{}
+
+func (s *stepCreateSnapshot) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	client := state.Get("client").(*client)
+	ui := state.Get("ui").(packer.Ui)
+	c := state.Get("config").(*Config)
+	serverID := state.Get("server_id").(string)
+
+	ui.Say(fmt.Sprintf("Creating snapshot: %s", c.SnapshotDescription))
+	snapshotID, err := client.CreateSnapshot(ctx, serverID, c.SnapshotDescription)
+	if err != nil {
+		err := fmt.Errorf("Error creating snapshot: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	ui.Say("Waiting for snapshot to complete...")
+	if err := waitForSnapshot(ctx, client, snapshotID, c.SnapshotTimeout); err != nil {
+		err := fmt.Errorf("Error creating snapshot: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	state.Put("snapshot_id", snapshotID)
+	return multistep.ActionContinue
+}
+
+func (s *stepCreateSnapshot) Cleanup(state multistep.StateBag) {
+	// no cleanup
+}
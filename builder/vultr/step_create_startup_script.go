@@ -0,0 +1,59 @@
+package vultr
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/packer/common/uuid"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// stepCreateStartupScript creates a boot script from startup_script for the
+// server to run, and deletes it once the build is done.
+type stepCreateStartupScript struct {
+	scriptID string
+}
+
+func (s *stepCreateStartupScript) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	client := state.Get("client").(*client)
+	ui := state.Get("ui").(packer.Ui)
+	c := state.Get("config").(*Config)
+
+	if c.StartupScript == "" {
+		state.Put("script_id", c.ScriptID)
+		return multistep.ActionContinue
+	}
+
+	ui.Say("Creating temporary startup script...")
+	name := fmt.Sprintf("packer-%s", uuid.TimeOrderedUUID())
+	scriptID, err := client.CreateStartupScript(ctx, name, c.StartupScript)
+	if err != nil {
+		err := fmt.Errorf("Error creating startup script: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	// We use this to check cleanup
+	s.scriptID = scriptID
+
+	state.Put("script_id", scriptID)
+	return multistep.ActionContinue
+}
+
+func (s *stepCreateStartupScript) Cleanup(state multistep.StateBag) {
+	// If no script id is set, then we never created it, so just return
+	if s.scriptID == "" {
+		return
+	}
+
+	client := state.Get("client").(*client)
+	ui := state.Get("ui").(packer.Ui)
+
+	ui.Say("Deleting temporary startup script...")
+	if err := client.DestroyStartupScript(context.TODO(), s.scriptID); err != nil {
+		ui.Error(fmt.Sprintf(
+			"Error cleaning up startup script. Please delete it manually: %s", err))
+	}
+}
@@ -0,0 +1,56 @@
+package vultr
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// stepShutdown shuts the server down with shutdown_command so that its disk
+// is consistent when it is snapshotted. The API can only power the server
+// off, which is used when the command doesn't stop it in time.
+type stepShutdown struct{}
+
+func (s *stepShutdown) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	client := state.Get("client").(*client)
+	comm := state.Get("communicator").(packer.Communicator)
+	c := state.Get("config").(*Config)
+	ui := state.Get("ui").(packer.Ui)
+	serverID := state.Get("server_id").(string)
+
+	ui.Say("Gracefully shutting down server...")
+	log.Printf("Executing shutdown command: %s", c.ShutdownCommand)
+	cmd := &packer.RemoteCmd{Command: c.ShutdownCommand}
+	if err := comm.Start(ctx, cmd); err != nil {
+		err := fmt.Errorf("Failed to send shutdown command: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	stopped := func(s *server) bool { return s.PowerStatus == "stopped" }
+	if _, err := waitForServer(ctx, client, serverID, c.StateTimeout, stopped); err == nil {
+		return multistep.ActionContinue
+	}
+
+	ui.Say("Server didn't shut down in time, halting it...")
+	err := client.HaltServer(ctx, serverID)
+	if err == nil {
+		_, err = waitForServer(ctx, client, serverID, c.StateTimeout, stopped)
+	}
+	if err != nil {
+		err := fmt.Errorf("Error halting server: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *stepShutdown) Cleanup(state multistep.StateBag) {
+	// no cleanup
+}
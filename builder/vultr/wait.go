@@ -0,0 +1,59 @@
+package vultr
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/packer/common/retry"
+)
+
+// pollInterval is the time between two checks of the state of a server or
+// snapshot.
+var pollInterval = 5 * time.Second
+
+// waitForServer waits until done returns true for the server, and returns
+// the server.
+func waitForServer(ctx context.Context, c *client, id string, timeout time.Duration,
+	done func(*server) bool) (*server, error) {
+	var s *server
+	err := retry.Config{
+		StartTimeout: timeout,
+		RetryDelay:   func() time.Duration { return pollInterval },
+	}.Run(ctx, func(ctx context.Context) error {
+		var err error
+		s, err = c.GetServer(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !done(s) {
+			return fmt.Errorf("server is %s, %s and %s", s.Status, s.ServerStatus, s.PowerStatus)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("timeout while waiting for the server: %s", err)
+	}
+	return s, nil
+}
+
+// waitForSnapshot waits until the snapshot is complete.
+func waitForSnapshot(ctx context.Context, c *client, id string, timeout time.Duration) error {
+	err := retry.Config{
+		StartTimeout: timeout,
+		RetryDelay:   func() time.Duration { return pollInterval },
+	}.Run(ctx, func(ctx context.Context) error {
+		s, err := c.GetSnapshot(ctx, id)
+		if err != nil {
+			return err
+		}
+		if s.Status != "complete" {
+			return fmt.Errorf("snapshot is %s", s.Status)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("timeout while waiting for the snapshot: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+// The xenserver package contains a packer.Builder implementation that
+// builds VMs on XenServer and XCP-ng hosts through the XenAPI, and exports
+// them as XVA files or disk images.
+package xenserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/helper/communicator"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// The unique id for the builder
+const BuilderId = "packer.xenserver"
+
+type Builder struct {
+	config Config
+	runner multistep.Runner
+}
+
+func (b *Builder) Prepare(raws ...interface{}) ([]string, error) {
+	c, warnings, errs := NewConfig(raws...)
+	if errs != nil {
+		return warnings, errs
+	}
+	b.config = *c
+
+	return warnings, nil
+}
+
+func (b *Builder) Run(ctx context.Context, ui packer.Ui, hook packer.Hook) (packer.Artifact, error) {
+	client := newClient(b.config.RemoteHost, b.config.InsecureSkipTLSVerify)
+	if err := client.Login(ctx, b.config.RemoteUsername, b.config.RemotePassword); err != nil {
+		return nil, fmt.Errorf("Error logging in to %s: %s", b.config.RemoteHost, err)
+	}
+	defer func() {
+		if err := client.Logout(context.TODO()); err != nil {
+			log.Printf("Error logging out: %s", err)
+		}
+	}()
+
+	steps := []multistep.Step{
+		&common.StepOutputDir{
+			Force: b.config.PackerForce,
+			Path:  b.config.OutputDir,
+		},
+		new(stepCreateVM),
+		new(stepCreateDisk),
+		new(stepAttachISO),
+		new(stepCreateVIFs),
+		&common.StepHTTPServer{
+			HTTPDir:     b.config.HTTPDir,
+			HTTPPortMin: b.config.HTTPPortMin,
+			HTTPPortMax: b.config.HTTPPortMax,
+		},
+		new(stepStartVM),
+		new(stepTypeBootCommand),
+	}
+
+	if b.config.Comm.Type != "none" {
+		steps = append(steps,
+			&communicator.StepConnect{
+				Config:    &b.config.Comm,
+				Host:      commHost(b.config.Comm.SSHHost),
+				SSHConfig: b.config.Comm.SSHConfigFunc(),
+			},
+		)
+	}
+
+	steps = append(steps,
+		new(common.StepProvision),
+		&common.StepCleanupTempKeys{
+			Comm: &b.config.Comm,
+		},
+		new(stepShutdown),
+		new(stepEjectISO),
+		new(stepExport),
+	)
+
+	// Setup the state bag
+	state := new(multistep.BasicStateBag)
+	state.Put("client", client)
+	state.Put("config", &b.config)
+	state.Put("debug", b.config.PackerDebug)
+	state.Put("hook", hook)
+	state.Put("ui", ui)
+
+	// Run
+	b.runner = common.NewRunnerWithPauseFn(steps, b.config.PackerConfig, ui, state)
+	b.runner.Run(ctx, state)
+
+	// If there was an error, return that
+	if rawErr, ok := state.GetOk("error"); ok {
+		return nil, rawErr.(error)
+	}
+
+	// If we were interrupted or cancelled, then just exit.
+	if _, ok := state.GetOk(multistep.StateCancelled); ok {
+		return nil, errors.New("Build was cancelled.")
+	}
+
+	if _, ok := state.GetOk(multistep.StateHalted); ok {
+		return nil, errors.New("Build was halted.")
+	}
+
+	// Compile the artifact list
+	files := make([]string, 0, 1)
+	visit := func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+		}
+
+		return nil
+	}
+
+	if err := filepath.Walk(b.config.OutputDir, visit); err != nil {
+		return nil, err
+	}
+
+	artifact := &Artifact{
+		dir:   b.config.OutputDir,
+		f:     files,
+		state: make(map[string]interface{}),
+	}
+
+	artifact.state["diskType"] = b.config.Format
+
+	return artifact, nil
+}
@@ -0,0 +1,316 @@
+package xenserver
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/packer/version"
+)
+
+// nullRef is the reference XenAPI returns for unset objects.
+const nullRef = "OpaqueRef:NULL"
+
+// client is a minimal client for the parts of the XenAPI that the builder
+// uses. It talks JSON-RPC, which XenServer 7.3 and XCP-ng serve at
+// /jsonrpc.
+type client struct {
+	endpoint string
+	http     *http.Client
+	session  string
+	id       int
+}
+
+func newClient(host string, insecure bool) *client {
+	return &client{
+		endpoint: "https://" + host,
+		http: &http.Client{
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: insecure},
+			},
+		},
+	}
+}
+
+// apiError is a failure of a XenAPI call, such as HANDLE_INVALID.
+type apiError struct {
+	Code    int           `json:"code"`
+	Message string        `json:"message"`
+	Data    []interface{} `json:"data"`
+}
+
+func (e *apiError) Error() string {
+	if len(e.Data) == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("%s %v", e.Message, e.Data)
+}
+
+// Login opens the session that the other calls use.
+func (c *client) Login(ctx context.Context, username, password string) error {
+	var session string
+	err := c.rpc(ctx, &session, "session.login_with_password",
+		username, password, "1.0", fmt.Sprintf("packer %s", version.FormattedVersion()))
+	if err != nil {
+		return err
+	}
+	c.session = session
+	return nil
+}
+
+func (c *client) Logout(ctx context.Context) error {
+	if c.session == "" {
+		return nil
+	}
+	err := c.rpc(ctx, nil, "session.logout", c.session)
+	c.session = ""
+	return err
+}
+
+// Call calls a XenAPI method in the session, passing the session as the
+// first argument.
+func (c *client) Call(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	if c.session == "" {
+		return errors.New("not logged in")
+	}
+	return c.rpc(ctx, result, method, append([]interface{}{c.session}, args...)...)
+}
+
+// TemplateByName returns the template with the given name label.
+func (c *client) TemplateByName(ctx context.Context, name string) (string, error) {
+	var refs []string
+	if err := c.Call(ctx, &refs, "VM.get_by_name_label", name); err != nil {
+		return "", err
+	}
+	for _, ref := range refs {
+		var isTemplate bool
+		if err := c.Call(ctx, &isTemplate, "VM.get_is_a_template", ref); err != nil {
+			return "", err
+		}
+		if isTemplate {
+			return ref, nil
+		}
+	}
+	return "", fmt.Errorf("template %q not found", name)
+}
+
+// byName returns the object of the class, such as SR or network, with the
+// given name label.
+func (c *client) byName(ctx context.Context, class, name string) (string, error) {
+	var refs []string
+	if err := c.Call(ctx, &refs, class+".get_by_name_label", name); err != nil {
+		return "", err
+	}
+	switch len(refs) {
+	case 0:
+		return "", fmt.Errorf("%s %q not found", class, name)
+	case 1:
+		return refs[0], nil
+	default:
+		return "", fmt.Errorf("found %d of %s %q, the name must be unique", len(refs), class, name)
+	}
+}
+
+func (c *client) SRByName(ctx context.Context, name string) (string, error) {
+	return c.byName(ctx, "SR", name)
+}
+
+func (c *client) VDIByName(ctx context.Context, name string) (string, error) {
+	return c.byName(ctx, "VDI", name)
+}
+
+func (c *client) NetworkByName(ctx context.Context, name string) (string, error) {
+	return c.byName(ctx, "network", name)
+}
+
+// DefaultSR returns the default storage repository of the pool.
+func (c *client) DefaultSR(ctx context.Context) (string, error) {
+	var pools []string
+	if err := c.Call(ctx, &pools, "pool.get_all"); err != nil {
+		return "", err
+	}
+	if len(pools) == 0 {
+		return "", errors.New("no pool found")
+	}
+
+	var sr string
+	if err := c.Call(ctx, &sr, "pool.get_default_SR", pools[0]); err != nil {
+		return "", err
+	}
+	if sr == nullRef {
+		return "", errors.New("the pool has no default SR, set sr_name")
+	}
+	return sr, nil
+}
+
+// vbd is the part of a VBD record that the builder uses.
+type vbd struct {
+	Ref        string `json:"-"`
+	Type       string `json:"type"`
+	VDI        string `json:"VDI"`
+	Userdevice string `json:"userdevice"`
+	Empty      bool   `json:"empty"`
+}
+
+// VBDs returns the block devices of the VM.
+func (c *client) VBDs(ctx context.Context, vm string) ([]vbd, error) {
+	var refs []string
+	if err := c.Call(ctx, &refs, "VM.get_VBDs", vm); err != nil {
+		return nil, err
+	}
+
+	vbds := make([]vbd, len(refs))
+	for i, ref := range refs {
+		if err := c.Call(ctx, &vbds[i], "VBD.get_record", ref); err != nil {
+			return nil, err
+		}
+		vbds[i].Ref = ref
+	}
+	return vbds, nil
+}
+
+// PowerState returns the power state of the VM, such as "Running" or
+// "Halted".
+func (c *client) PowerState(ctx context.Context, vm string) (string, error) {
+	var state string
+	err := c.Call(ctx, &state, "VM.get_power_state", vm)
+	return state, err
+}
+
+// GuestIP returns the first IPv4 address that the guest tools of the VM
+// report, or "" if they haven't reported one.
+func (c *client) GuestIP(ctx context.Context, vm string) (string, error) {
+	var metrics string
+	if err := c.Call(ctx, &metrics, "VM.get_guest_metrics", vm); err != nil {
+		return "", err
+	}
+	if metrics == nullRef {
+		return "", nil
+	}
+
+	var networks map[string]string
+	if err := c.Call(ctx, &networks, "VM_guest_metrics.get_networks", metrics); err != nil {
+		return "", err
+	}
+	for _, key := range []string{"0/ipv4/0", "0/ip"} {
+		if ip := networks[key]; ip != "" {
+			return ip, nil
+		}
+	}
+	return "", nil
+}
+
+// ConsoleLocation returns the URL of the VNC console of the running VM.
+func (c *client) ConsoleLocation(ctx context.Context, vm string) (string, error) {
+	var consoles []string
+	if err := c.Call(ctx, &consoles, "VM.get_consoles", vm); err != nil {
+		return "", err
+	}
+	for _, console := range consoles {
+		var protocol string
+		if err := c.Call(ctx, &protocol, "console.get_protocol", console); err != nil {
+			return "", err
+		}
+		if protocol != "rfb" {
+			continue
+		}
+
+		var location string
+		err := c.Call(ctx, &location, "console.get_location", console)
+		return location, err
+	}
+	return "", errors.New("the VM has no VNC console")
+}
+
+// Download saves what an HTTP handler of the host, such as /export,
+// returns to the file at dst.
+func (c *client) Download(ctx context.Context, path string, query url.Values, dst string) error {
+	values := url.Values{"session_id": {c.session}}
+	for k, v := range query {
+		values[k] = v
+	}
+	req, err := http.NewRequest("GET", c.endpoint+path+"?"+values.Encode(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.http.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", path, resp.Status)
+	}
+
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (c *client) rpc(ctx context.Context, result interface{}, method string, params ...interface{}) error {
+	c.id++
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  method,
+		"params":  params,
+		"id":      c.id,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", c.endpoint+"/jsonrpc", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", fmt.Sprintf("Packer/%s", version.FormattedVersion()))
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s: %s", method, resp.Status, strings.TrimSpace(string(respBody)))
+	}
+
+	var response struct {
+		Result json.RawMessage `json:"result"`
+		Error  *apiError       `json:"error"`
+	}
+	if err := json.Unmarshal(respBody, &response); err != nil {
+		return fmt.Errorf("%s: %s", method, err)
+	}
+	if response.Error != nil {
+		return fmt.Errorf("%s: %s", method, response.Error)
+	}
+
+	if result == nil || len(response.Result) == 0 {
+		return nil
+	}
+	return json.Unmarshal(response.Result, result)
+}
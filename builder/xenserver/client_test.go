@@ -0,0 +1,198 @@
+package xenserver
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testServer serves XenAPI calls with the results of the handler, keyed by
+// method.
+func testServer(t *testing.T, results map[string]interface{}) (*client, *httptest.Server) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/export" {
+			if r.URL.Query().Get("session_id") != "OpaqueRef:session" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte("xva"))
+			return
+		}
+
+		var req struct {
+			Method string        `json:"method"`
+			Params []interface{} `json:"params"`
+			ID     int           `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("err: %s", err)
+		}
+
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		if result, ok := results[req.Method]; ok {
+			resp["result"] = result
+		} else {
+			resp["error"] = map[string]interface{}{
+				"code":    1,
+				"message": "MESSAGE_METHOD_UNKNOWN",
+				"data":    []string{req.Method},
+			}
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+
+	c := newClient("", false)
+	c.endpoint = ts.URL
+	c.http = ts.Client()
+	return c, ts
+}
+
+func TestClient_Login(t *testing.T) {
+	c, ts := testServer(t, map[string]interface{}{
+		"session.login_with_password": "OpaqueRef:session",
+		"session.logout":              nil,
+	})
+	defer ts.Close()
+
+	if err := c.Call(context.Background(), nil, "VM.get_all"); err == nil {
+		t.Fatal("should have error without session")
+	}
+
+	if err := c.Login(context.Background(), "root", "secret"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if c.session != "OpaqueRef:session" {
+		t.Fatalf("bad session: %s", c.session)
+	}
+
+	if err := c.Logout(context.Background()); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if c.session != "" {
+		t.Fatalf("bad session: %s", c.session)
+	}
+}
+
+func TestClient_Error(t *testing.T) {
+	c, ts := testServer(t, map[string]interface{}{})
+	defer ts.Close()
+	c.session = "OpaqueRef:session"
+
+	err := c.Call(context.Background(), nil, "VM.get_all")
+	if err == nil {
+		t.Fatal("should have error")
+	}
+	if !strings.Contains(err.Error(), "MESSAGE_METHOD_UNKNOWN") {
+		t.Fatalf("bad error: %s", err)
+	}
+}
+
+func TestClient_TemplateByName(t *testing.T) {
+	c, ts := testServer(t, map[string]interface{}{
+		"VM.get_by_name_label": []string{"OpaqueRef:vm"},
+		"VM.get_is_a_template": true,
+	})
+	defer ts.Close()
+	c.session = "OpaqueRef:session"
+
+	ref, err := c.TemplateByName(context.Background(), "Other install media")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if ref != "OpaqueRef:vm" {
+		t.Fatalf("bad ref: %s", ref)
+	}
+}
+
+func TestClient_SRByName(t *testing.T) {
+	c, ts := testServer(t, map[string]interface{}{
+		"SR.get_by_name_label": []string{"OpaqueRef:sr1", "OpaqueRef:sr2"},
+	})
+	defer ts.Close()
+	c.session = "OpaqueRef:session"
+
+	if _, err := c.SRByName(context.Background(), "Local storage"); err == nil {
+		t.Fatal("should have error for an ambiguous name")
+	}
+}
+
+func TestClient_GuestIP(t *testing.T) {
+	c, ts := testServer(t, map[string]interface{}{
+		"VM.get_guest_metrics": "OpaqueRef:metrics",
+		"VM_guest_metrics.get_networks": map[string]string{
+			"0/ip":     "10.0.0.5",
+			"0/ipv6/0": "fe80::1",
+		},
+	})
+	defer ts.Close()
+	c.session = "OpaqueRef:session"
+
+	ip, err := c.GuestIP(context.Background(), "OpaqueRef:vm")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if ip != "10.0.0.5" {
+		t.Fatalf("bad ip: %s", ip)
+	}
+}
+
+func TestClient_VBDs(t *testing.T) {
+	c, ts := testServer(t, map[string]interface{}{
+		"VM.get_VBDs": []string{"OpaqueRef:vbd"},
+		"VBD.get_record": map[string]interface{}{
+			"type":       "Disk",
+			"VDI":        "OpaqueRef:vdi",
+			"userdevice": "0",
+			"empty":      false,
+		},
+	})
+	defer ts.Close()
+	c.session = "OpaqueRef:session"
+
+	vbds, err := c.VBDs(context.Background(), "OpaqueRef:vm")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := []vbd{{
+		Ref:        "OpaqueRef:vbd",
+		Type:       "Disk",
+		VDI:        "OpaqueRef:vdi",
+		Userdevice: "0",
+	}}
+	if !reflect.DeepEqual(vbds, expected) {
+		t.Fatalf("bad: %#v", vbds)
+	}
+}
+
+func TestClient_Download(t *testing.T) {
+	c, ts := testServer(t, map[string]interface{}{})
+	defer ts.Close()
+	c.session = "OpaqueRef:session"
+
+	dir, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	dst := filepath.Join(dir, "vm.xva")
+	if err := c.Download(context.Background(), "/export", nil, dst); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	contents, err := ioutil.ReadFile(dst)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if string(contents) != "xva" {
+		t.Fatalf("bad contents: %s", contents)
+	}
+}
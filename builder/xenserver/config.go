@@ -0,0 +1,151 @@
+package xenserver
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/bootcommand"
+	"github.com/hashicorp/packer/helper/communicator"
+	"github.com/hashicorp/packer/helper/config"
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/template/interpolate"
+)
+
+var formats = map[string]bool{
+	"xva": true,
+	"vhd": true,
+	"raw": true,
+}
+
+type Config struct {
+	common.PackerConfig   `mapstructure:",squash"`
+	common.HTTPConfig     `mapstructure:",squash"`
+	bootcommand.VNCConfig `mapstructure:",squash"`
+	Comm                  communicator.Config `mapstructure:",squash"`
+
+	RemoteHost            string   `mapstructure:"remote_host"`
+	RemoteUsername        string   `mapstructure:"remote_username"`
+	RemotePassword        string   `mapstructure:"remote_password"`
+	InsecureSkipTLSVerify bool     `mapstructure:"insecure_skip_tls_verify"`
+	CloneTemplate         string   `mapstructure:"clone_template"`
+	VMName                string   `mapstructure:"vm_name"`
+	VMDescription         string   `mapstructure:"vm_description"`
+	VMMemory              uint     `mapstructure:"vm_memory"`
+	VCPUs                 uint     `mapstructure:"vcpus"`
+	DiskSize              uint     `mapstructure:"disk_size"`
+	SRName                string   `mapstructure:"sr_name"`
+	ISOName               string   `mapstructure:"iso_name"`
+	NetworkNames          []string `mapstructure:"network_names"`
+	ShutdownCommand       string   `mapstructure:"shutdown_command"`
+	RawShutdownTimeout    string   `mapstructure:"shutdown_timeout"`
+	Format                string   `mapstructure:"format"`
+	KeepVM                bool     `mapstructure:"keep_vm"`
+	OutputDir             string   `mapstructure:"output_directory"`
+
+	shutdownTimeout time.Duration
+
+	ctx interpolate.Context
+}
+
+func NewConfig(raws ...interface{}) (*Config, []string, error) {
+	c := new(Config)
+
+	err := config.Decode(c, &config.DecodeOpts{
+		Interpolate:        true,
+		InterpolateContext: &c.ctx,
+		InterpolateFilter: &interpolate.RenderFilter{
+			Exclude: []string{
+				"boot_command",
+			},
+		},
+	}, raws...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var errs *packer.MultiError
+	var warnings []string
+
+	// Defaults
+	if c.RemoteUsername == "" {
+		c.RemoteUsername = "root"
+	}
+
+	if c.CloneTemplate == "" {
+		c.CloneTemplate = "Other install media"
+	}
+
+	if c.VMName == "" {
+		c.VMName = fmt.Sprintf("packer-%s", c.PackerBuildName)
+	}
+
+	if c.VMMemory == 0 {
+		c.VMMemory = 1024
+	}
+
+	if c.VCPUs == 0 {
+		c.VCPUs = 1
+	}
+
+	// A VM installed from an ISO needs a disk to install onto
+	if c.ISOName != "" && c.DiskSize == 0 {
+		c.DiskSize = 40960
+	}
+
+	if c.RawShutdownTimeout == "" {
+		c.RawShutdownTimeout = "5m"
+	}
+
+	if c.Format == "" {
+		c.Format = "xva"
+	}
+
+	if c.OutputDir == "" {
+		c.OutputDir = fmt.Sprintf("output-%s", c.PackerBuildName)
+	}
+
+	errs = packer.MultiErrorAppend(errs, c.HTTPConfig.Prepare(&c.ctx)...)
+	errs = packer.MultiErrorAppend(errs, c.VNCConfig.Prepare(&c.ctx)...)
+	if es := c.Comm.Prepare(&c.ctx); len(es) > 0 {
+		errs = packer.MultiErrorAppend(errs, es...)
+	}
+
+	if c.RemoteHost == "" {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("remote_host must be specified"))
+	}
+
+	if c.RemotePassword == "" {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("remote_password must be specified"))
+	}
+
+	if !formats[c.Format] {
+		errs = packer.MultiErrorAppend(
+			errs, errors.New("format must be one of 'xva', 'vhd' or 'raw'"))
+	}
+
+	c.shutdownTimeout, err = time.ParseDuration(c.RawShutdownTimeout)
+	if err != nil {
+		errs = packer.MultiErrorAppend(
+			errs, fmt.Errorf("Failed parsing shutdown_timeout: %s", err))
+	}
+
+	if !c.PackerForce {
+		if _, err := os.Stat(c.OutputDir); err == nil {
+			errs = packer.MultiErrorAppend(
+				errs,
+				fmt.Errorf("Output directory '%s' already exists. It must not exist.", c.OutputDir))
+		}
+	}
+
+	if errs != nil && len(errs.Errors) > 0 {
+		return nil, warnings, errs
+	}
+
+	packer.LogSecretFilter.Set(c.RemotePassword)
+	return c, warnings, nil
+}
@@ -0,0 +1,68 @@
+package xenserver
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// dialConsole opens the VNC stream of a console of a VM. XenServer serves
+// it through an HTTP CONNECT to the location of the console.
+func dialConsole(location, session string, insecure bool) (net.Conn, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+
+	var conn net.Conn
+	switch u.Scheme {
+	case "https":
+		conn, err = tls.Dial("tcp", hostPort(u, "443"), &tls.Config{InsecureSkipVerify: insecure})
+	case "http":
+		conn, err = net.Dial("tcp", hostPort(u, "80"))
+	default:
+		return nil, fmt.Errorf("unsupported console location %q", location)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	query := u.Query()
+	query.Set("session_id", session)
+	u.RawQuery = query.Encode()
+
+	fmt.Fprintf(conn, "CONNECT %s HTTP/1.0\r\nHost: %s\r\n\r\n", u.RequestURI(), u.Host)
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, &http.Request{Method: "CONNECT"})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("error connecting to console: %s", resp.Status)
+	}
+
+	return &bufferedConn{conn, r}, nil
+}
+
+func hostPort(u *url.URL, defaultPort string) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	return net.JoinHostPort(u.Hostname(), defaultPort)
+}
+
+// bufferedConn reads the bytes that the reader of the response of the
+// CONNECT buffered before reading from the connection.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
@@ -0,0 +1,53 @@
+package xenserver
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+)
+
+func TestDialConsole(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer l.Close()
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		req, err := http.ReadRequest(bufio.NewReader(conn))
+		if err != nil {
+			t.Errorf("err: %s", err)
+			return
+		}
+		if req.Method != "CONNECT" || req.URL.Query().Get("session_id") != "OpaqueRef:session" {
+			conn.Write([]byte("HTTP/1.1 403 Forbidden\r\n\r\n"))
+			return
+		}
+
+		// The VNC server speaks first, in the same packet as the response
+		conn.Write([]byte("HTTP/1.1 200 OK\r\n\r\nRFB 003.008\n"))
+	}()
+
+	location := "http://" + l.Addr().String() + "/console?ref=OpaqueRef:console"
+	conn, err := dialConsole(location, "OpaqueRef:session", false)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer conn.Close()
+
+	version := make([]byte, 12)
+	if _, err := io.ReadFull(conn, version); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if string(version) != "RFB 003.008\n" {
+		t.Fatalf("bad version: %q", version)
+	}
+}
@@ -0,0 +1,30 @@
+package xenserver
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/hashicorp/packer/helper/multistep"
+)
+
+func commHost(host string) func(multistep.StateBag) (string, error) {
+	return func(state multistep.StateBag) (string, error) {
+		if host != "" {
+			log.Printf("Using ssh_host value: %s", host)
+			return host, nil
+		}
+
+		c := state.Get("client").(*client)
+		vm := state.Get("vm").(string)
+
+		ip, err := c.GuestIP(context.TODO(), vm)
+		if err != nil {
+			return "", err
+		}
+		if ip == "" {
+			return "", errors.New("the guest tools of the VM haven't reported an IP address yet")
+		}
+		return ip, nil
+	}
+}
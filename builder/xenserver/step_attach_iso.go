@@ -0,0 +1,102 @@
+package xenserver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// This step inserts the ISO into the CD drive of the VM, adding a drive if
+// the template has none, and boots the VM from the CD once its disk is
+// found empty.
+//
+// Uses:
+//   client *client
+//   config *Config
+//   ui     packer.Ui
+//   vm     string
+//
+// Produces:
+//   cd_vbd string - The reference of the CD drive holding the ISO
+type stepAttachISO struct{}
+
+func (s *stepAttachISO) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	c := state.Get("client").(*client)
+	config := state.Get("config").(*Config)
+	ui := state.Get("ui").(packer.Ui)
+	vm := state.Get("vm").(string)
+
+	if config.ISOName == "" {
+		return multistep.ActionContinue
+	}
+
+	ui.Say(fmt.Sprintf("Inserting ISO %s...", config.ISOName))
+	iso, err := c.VDIByName(ctx, config.ISOName)
+	if err != nil {
+		err := fmt.Errorf("Error finding ISO: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	vbds, err := c.VBDs(ctx, vm)
+	if err != nil {
+		err := fmt.Errorf("Error reading the drives of the VM: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	var cd string
+	for _, vbd := range vbds {
+		if vbd.Type == "CD" {
+			cd = vbd.Ref
+			if !vbd.Empty {
+				err = c.Call(ctx, nil, "VBD.eject", cd)
+			}
+			if err == nil {
+				err = c.Call(ctx, nil, "VBD.insert", cd, iso)
+			}
+			break
+		}
+	}
+	if cd == "" {
+		err = c.Call(ctx, &cd, "VBD.create", map[string]interface{}{
+			"VM":                   vm,
+			"VDI":                  iso,
+			"userdevice":           "3",
+			"bootable":             true,
+			"mode":                 "RO",
+			"type":                 "CD",
+			"empty":                false,
+			"other_config":         map[string]string{},
+			"qos_algorithm_type":   "",
+			"qos_algorithm_params": map[string]string{},
+		})
+	}
+	if err != nil {
+		err := fmt.Errorf("Error inserting ISO: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	// Boot from the disk, then the CD, so that the installer runs until
+	// there's an operating system on the disk
+	err = c.Call(ctx, nil, "VM.set_HVM_boot_params", vm, map[string]string{
+		"order": "cd",
+	})
+	if err != nil {
+		err := fmt.Errorf("Error setting the boot order: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	state.Put("cd_vbd", cd)
+	return multistep.ActionContinue
+}
+
+func (s *stepAttachISO) Cleanup(state multistep.StateBag) {}
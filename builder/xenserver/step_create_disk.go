@@ -0,0 +1,109 @@
+package xenserver
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// This step creates the disk to install the operating system onto, and
+// attaches it to the VM. The disk is destroyed with the VM.
+//
+// Uses:
+//   client *client
+//   config *Config
+//   ui     packer.Ui
+//   vm     string
+//
+// Produces:
+//   <nothing>
+type stepCreateDisk struct{}
+
+func (s *stepCreateDisk) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	c := state.Get("client").(*client)
+	config := state.Get("config").(*Config)
+	ui := state.Get("ui").(packer.Ui)
+	vm := state.Get("vm").(string)
+
+	if config.DiskSize == 0 {
+		log.Println("No disk_size, keeping the disks of the template...")
+		return multistep.ActionContinue
+	}
+
+	var sr string
+	var err error
+	if config.SRName != "" {
+		sr, err = c.SRByName(ctx, config.SRName)
+	} else {
+		sr, err = c.DefaultSR(ctx)
+	}
+	if err != nil {
+		err := fmt.Errorf("Error finding SR: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	var devices []string
+	if err := c.Call(ctx, &devices, "VM.get_allowed_VBD_devices", vm); err != nil {
+		err := fmt.Errorf("Error finding a device for the disk: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	if len(devices) == 0 {
+		err := fmt.Errorf("The VM has no free device for the disk")
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	ui.Say(fmt.Sprintf("Creating disk of %d MB...", config.DiskSize))
+	var vdi string
+	err = c.Call(ctx, &vdi, "VDI.create", map[string]interface{}{
+		"name_label":   fmt.Sprintf("%s disk", config.VMName),
+		"SR":           sr,
+		"virtual_size": int64(config.DiskSize) * 1024 * 1024,
+		"type":         "user",
+		"sharable":     false,
+		"read_only":    false,
+		"other_config": map[string]string{},
+	})
+	if err != nil {
+		err := fmt.Errorf("Error creating disk: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	err = c.Call(ctx, nil, "VBD.create", map[string]interface{}{
+		"VM":                   vm,
+		"VDI":                  vdi,
+		"userdevice":           devices[0],
+		"bootable":             true,
+		"mode":                 "RW",
+		"type":                 "Disk",
+		"empty":                false,
+		"other_config":         map[string]string{},
+		"qos_algorithm_type":   "",
+		"qos_algorithm_params": map[string]string{},
+	})
+	if err != nil {
+		// The disk isn't attached, so it isn't destroyed with the VM
+		if err := c.Call(ctx, nil, "VDI.destroy", vdi); err != nil {
+			ui.Error(fmt.Sprintf("Error destroying disk: %s", err))
+		}
+
+		err := fmt.Errorf("Error attaching disk: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *stepCreateDisk) Cleanup(state multistep.StateBag) {}
@@ -0,0 +1,62 @@
+package xenserver
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// This step attaches the VM to the networks in network_names, in order.
+// The interfaces are destroyed with the VM.
+//
+// Uses:
+//   client *client
+//   config *Config
+//   ui     packer.Ui
+//   vm     string
+//
+// Produces:
+//   <nothing>
+type stepCreateVIFs struct{}
+
+func (s *stepCreateVIFs) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	c := state.Get("client").(*client)
+	config := state.Get("config").(*Config)
+	ui := state.Get("ui").(packer.Ui)
+	vm := state.Get("vm").(string)
+
+	for i, name := range config.NetworkNames {
+		ui.Say(fmt.Sprintf("Attaching VM to network %s...", name))
+		network, err := c.NetworkByName(ctx, name)
+		if err != nil {
+			err := fmt.Errorf("Error finding network: %s", err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+
+		err = c.Call(ctx, nil, "VIF.create", map[string]interface{}{
+			"device":               strconv.Itoa(i),
+			"network":              network,
+			"VM":                   vm,
+			"MAC":                  "",
+			"MTU":                  1500,
+			"other_config":         map[string]string{},
+			"qos_algorithm_type":   "",
+			"qos_algorithm_params": map[string]string{},
+		})
+		if err != nil {
+			err := fmt.Errorf("Error attaching VM to network: %s", err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *stepCreateVIFs) Cleanup(state multistep.StateBag) {}
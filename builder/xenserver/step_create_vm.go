@@ -0,0 +1,116 @@
+package xenserver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// This step clones the template into the VM to build, and sizes its memory
+// and CPUs. It destroys the VM and its disks at the end of the build,
+// unless keep_vm is set and the build succeeded.
+//
+// Uses:
+//   client *client
+//   config *Config
+//   ui     packer.Ui
+//
+// Produces:
+//   vm string - The reference of the VM
+type stepCreateVM struct {
+	vm string
+}
+
+func (s *stepCreateVM) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	c := state.Get("client").(*client)
+	config := state.Get("config").(*Config)
+	ui := state.Get("ui").(packer.Ui)
+
+	ui.Say(fmt.Sprintf("Cloning template %q into VM %s...", config.CloneTemplate, config.VMName))
+	template, err := c.TemplateByName(ctx, config.CloneTemplate)
+	if err != nil {
+		err := fmt.Errorf("Error finding template: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	var vm string
+	if err := c.Call(ctx, &vm, "VM.clone", template, config.VMName); err != nil {
+		err := fmt.Errorf("Error cloning template: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	s.vm = vm
+	state.Put("vm", vm)
+
+	memory := int64(config.VMMemory) * 1024 * 1024
+	calls := []struct {
+		method string
+		args   []interface{}
+	}{
+		{"VM.provision", []interface{}{vm}},
+		{"VM.set_name_description", []interface{}{vm, config.VMDescription}},
+		{"VM.set_memory_limits", []interface{}{vm, memory, memory, memory, memory}},
+		{"VM.set_VCPUs_max", []interface{}{vm, config.VCPUs}},
+		{"VM.set_VCPUs_at_startup", []interface{}{vm, config.VCPUs}},
+	}
+	for _, call := range calls {
+		if err := c.Call(ctx, nil, call.method, call.args...); err != nil {
+			err := fmt.Errorf("Error configuring VM: %s", err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *stepCreateVM) Cleanup(state multistep.StateBag) {
+	if s.vm == "" {
+		return
+	}
+
+	c := state.Get("client").(*client)
+	config := state.Get("config").(*Config)
+	ui := state.Get("ui").(packer.Ui)
+	ctx := context.TODO()
+
+	_, cancelled := state.GetOk(multistep.StateCancelled)
+	_, halted := state.GetOk(multistep.StateHalted)
+	if config.KeepVM && !cancelled && !halted {
+		ui.Say(fmt.Sprintf("Keeping VM %s", config.VMName))
+		return
+	}
+
+	if powerState, err := c.PowerState(ctx, s.vm); err == nil && powerState != "Halted" {
+		ui.Say("Stopping VM...")
+		if err := c.Call(ctx, nil, "VM.hard_shutdown", s.vm); err != nil {
+			ui.Error(fmt.Sprintf("Error stopping VM: %s", err))
+		}
+	}
+
+	ui.Say("Destroying VM...")
+	vbds, err := c.VBDs(ctx, s.vm)
+	if err != nil {
+		ui.Error(fmt.Sprintf("Error reading the disks of the VM: %s", err))
+	}
+	if err := c.Call(ctx, nil, "VM.destroy", s.vm); err != nil {
+		ui.Error(fmt.Sprintf("Error destroying VM: %s", err))
+		return
+	}
+
+	// Destroying the VM leaves its disks behind
+	for _, vbd := range vbds {
+		if vbd.Type != "Disk" || vbd.VDI == nullRef {
+			continue
+		}
+		if err := c.Call(ctx, nil, "VDI.destroy", vbd.VDI); err != nil {
+			ui.Error(fmt.Sprintf("Error destroying disk: %s", err))
+		}
+	}
+}
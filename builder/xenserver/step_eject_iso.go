@@ -0,0 +1,42 @@
+package xenserver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// This step ejects the ISO, so that the exported VM doesn't refer to it.
+//
+// Uses:
+//   cd_vbd string
+//   client *client
+//   ui     packer.Ui
+//
+// Produces:
+//   <nothing>
+type stepEjectISO struct{}
+
+func (s *stepEjectISO) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	cd, ok := state.GetOk("cd_vbd")
+	if !ok {
+		return multistep.ActionContinue
+	}
+
+	c := state.Get("client").(*client)
+	ui := state.Get("ui").(packer.Ui)
+
+	ui.Say("Ejecting ISO...")
+	if err := c.Call(ctx, nil, "VBD.eject", cd.(string)); err != nil {
+		err := fmt.Errorf("Error ejecting ISO: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *stepEjectISO) Cleanup(state multistep.StateBag) {}
@@ -0,0 +1,83 @@
+package xenserver
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path/filepath"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// This step exports the VM into the output directory: the whole VM as an
+// XVA, or each of its disks as a VHD or raw image.
+//
+// Uses:
+//   client *client
+//   config *Config
+//   ui     packer.Ui
+//   vm     string
+//
+// Produces:
+//   <nothing>
+type stepExport struct{}
+
+func (s *stepExport) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	c := state.Get("client").(*client)
+	config := state.Get("config").(*Config)
+	ui := state.Get("ui").(packer.Ui)
+	vm := state.Get("vm").(string)
+
+	if config.Format == "xva" {
+		var uuid string
+		if err := c.Call(ctx, &uuid, "VM.get_uuid", vm); err != nil {
+			err := fmt.Errorf("Error reading the UUID of the VM: %s", err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+
+		path := filepath.Join(config.OutputDir, config.VMName+".xva")
+		ui.Say(fmt.Sprintf("Exporting VM to %s...", path))
+		if err := c.Download(ctx, "/export", url.Values{"uuid": {uuid}}, path); err != nil {
+			err := fmt.Errorf("Error exporting VM: %s", err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+		return multistep.ActionContinue
+	}
+
+	vbds, err := c.VBDs(ctx, vm)
+	if err != nil {
+		err := fmt.Errorf("Error reading the disks of the VM: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	for _, vbd := range vbds {
+		if vbd.Type != "Disk" || vbd.VDI == nullRef {
+			continue
+		}
+
+		name := fmt.Sprintf("%s-%s.%s", config.VMName, vbd.Userdevice, config.Format)
+		path := filepath.Join(config.OutputDir, name)
+		ui.Say(fmt.Sprintf("Exporting disk %s to %s...", vbd.Userdevice, path))
+		query := url.Values{
+			"vdi":    {vbd.VDI},
+			"format": {config.Format},
+		}
+		if err := c.Download(ctx, "/export_raw_vdi", query, path); err != nil {
+			err := fmt.Errorf("Error exporting disk: %s", err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *stepExport) Cleanup(state multistep.StateBag) {}
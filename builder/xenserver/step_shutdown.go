@@ -0,0 +1,95 @@
+package xenserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// This step shuts down the VM. It runs the shutdown command if there is
+// one, or otherwise asks the guest to shut down through the XenAPI, then
+// waits for the VM to halt.
+//
+// Uses:
+//   client *client
+//   communicator packer.Communicator
+//   config *Config
+//   ui     packer.Ui
+//   vm     string
+//
+// Produces:
+//   <nothing>
+type stepShutdown struct{}
+
+func (s *stepShutdown) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	c := state.Get("client").(*client)
+	config := state.Get("config").(*Config)
+	ui := state.Get("ui").(packer.Ui)
+	vm := state.Get("vm").(string)
+
+	if state.Get("communicator") == nil {
+		ui.Say("Waiting for shutdown...")
+	} else if config.ShutdownCommand != "" {
+		comm := state.Get("communicator").(packer.Communicator)
+		ui.Say("Gracefully halting virtual machine...")
+		log.Printf("Executing shutdown command: %s", config.ShutdownCommand)
+		cmd := &packer.RemoteCmd{Command: config.ShutdownCommand}
+		if err := cmd.RunWithUi(ctx, comm, ui); err != nil {
+			err := fmt.Errorf("Failed to send shutdown command: %s", err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+	} else {
+		ui.Say("Halting the virtual machine...")
+		if err := c.Call(ctx, nil, "VM.clean_shutdown", vm); err != nil {
+			err := fmt.Errorf("Error stopping VM: %s", err)
+			state.Put("error", err)
+			ui.Error(err.Error())
+			return multistep.ActionHalt
+		}
+	}
+
+	log.Printf("Waiting max %s for shutdown to complete", config.shutdownTimeout)
+	if err := waitForHalt(ctx, c, vm, config.shutdownTimeout); err != nil {
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	log.Println("VM shut down.")
+	return multistep.ActionContinue
+}
+
+func (s *stepShutdown) Cleanup(state multistep.StateBag) {}
+
+// pollInterval is how often the power state of the VM is checked.
+var pollInterval = 2 * time.Second
+
+func waitForHalt(ctx context.Context, c *client, vm string, timeout time.Duration) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		powerState, err := c.PowerState(ctx, vm)
+		if err != nil {
+			return fmt.Errorf("Error reading the power state of the VM: %s", err)
+		}
+		if powerState == "Halted" {
+			return nil
+		}
+
+		select {
+		case <-time.After(pollInterval):
+		case <-timer.C:
+			return errors.New("Timeout while waiting for machine to shut down.")
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package xenserver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+// This step starts the VM. The VM is stopped when it's destroyed.
+//
+// Uses:
+//   client *client
+//   ui     packer.Ui
+//   vm     string
+//
+// Produces:
+//   <nothing>
+type stepStartVM struct{}
+
+func (s *stepStartVM) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	c := state.Get("client").(*client)
+	ui := state.Get("ui").(packer.Ui)
+	vm := state.Get("vm").(string)
+
+	ui.Say("Starting VM...")
+	if err := c.Call(ctx, nil, "VM.start", vm, false, false); err != nil {
+		err := fmt.Errorf("Error starting VM: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	return multistep.ActionContinue
+}
+
+func (s *stepStartVM) Cleanup(state multistep.StateBag) {}
@@ -0,0 +1,160 @@
+package xenserver
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"time"
+
+	"github.com/hashicorp/packer/common"
+	"github.com/hashicorp/packer/common/bootcommand"
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/template/interpolate"
+	"github.com/mitchellh/go-vnc"
+)
+
+type bootCommandTemplateData struct {
+	HTTPIP   string
+	HTTPPort int
+	Name     string
+}
+
+// This step "types" the boot command into the VM over its VNC console.
+//
+// Uses:
+//   client *client
+//   config *Config
+//   http_port int
+//   ui     packer.Ui
+//   vm     string
+//
+// Produces:
+//   <nothing>
+type stepTypeBootCommand struct{}
+
+func (s *stepTypeBootCommand) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	c := state.Get("client").(*client)
+	config := state.Get("config").(*Config)
+	debug := state.Get("debug").(bool)
+	httpPort := state.Get("http_port").(int)
+	ui := state.Get("ui").(packer.Ui)
+	vm := state.Get("vm").(string)
+
+	if config.VNCConfig.DisableVNC || len(config.BootCommand) == 0 {
+		log.Println("Skipping boot command step...")
+		return multistep.ActionContinue
+	}
+
+	// Wait the for the vm to boot.
+	if int64(config.BootWait) > 0 {
+		ui.Say(fmt.Sprintf("Waiting %s for boot...", config.BootWait.String()))
+		select {
+		case <-time.After(config.BootWait):
+			break
+		case <-ctx.Done():
+			return multistep.ActionHalt
+		}
+	}
+
+	var pauseFn multistep.DebugPauseFn
+	if debug {
+		pauseFn = state.Get("pauseFn").(multistep.DebugPauseFn)
+	}
+
+	ui.Say("Connecting to the console of the VM...")
+	location, err := c.ConsoleLocation(ctx, vm)
+	if err != nil {
+		err := fmt.Errorf("Error finding the console of the VM: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	nc, err := dialConsole(location, c.session, config.InsecureSkipTLSVerify)
+	if err != nil {
+		err := fmt.Errorf("Error connecting to VNC: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	defer nc.Close()
+
+	vc, err := vnc.Client(nc, &vnc.ClientConfig{Exclusive: false})
+	if err != nil {
+		err := fmt.Errorf("Error handshaking with VNC: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	defer vc.Close()
+
+	log.Printf("Connected to VNC desktop: %s", vc.DesktopName)
+
+	hostIP, err := localIP(config.RemoteHost)
+	if err != nil {
+		err := fmt.Errorf("Error finding the address of the HTTP server: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	common.SetHTTPIP(hostIP)
+	configCtx := config.ctx
+	configCtx.Data = &bootCommandTemplateData{
+		hostIP,
+		httpPort,
+		config.VMName,
+	}
+
+	d := bootcommand.NewVNCDriver(vc, config.VNCConfig.BootKeyInterval)
+
+	ui.Say("Typing the boot command over VNC...")
+	command, err := interpolate.Render(config.VNCConfig.FlatBootCommand(), &configCtx)
+	if err != nil {
+		err := fmt.Errorf("Error preparing boot command: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	seq, err := bootcommand.GenerateExpressionSequence(command)
+	if err != nil {
+		err := fmt.Errorf("Error generating boot command: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	if err := seq.Do(ctx, d); err != nil {
+		err := fmt.Errorf("Error running boot command: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	if pauseFn != nil {
+		pauseFn(multistep.DebugLocationAfterRun, fmt.Sprintf("boot_command: %s", command), state)
+	}
+
+	return multistep.ActionContinue
+}
+
+func (*stepTypeBootCommand) Cleanup(multistep.StateBag) {}
+
+// localIP returns the address that packer reaches the remote host from,
+// which the VM reaches the HTTP server on when it's bridged to the network
+// of the host.
+func localIP(remoteHost string) (string, error) {
+	host, _, err := net.SplitHostPort(remoteHost)
+	if err != nil {
+		host = remoteHost
+	}
+
+	conn, err := net.Dial("udp", net.JoinHostPort(host, "443"))
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	return conn.LocalAddr().(*net.UDPAddr).IP.String(), nil
+}
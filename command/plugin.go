@@ -19,6 +19,7 @@ import (
 	amazonebssurrogatebuilder "github.com/hashicorp/packer/builder/amazon/ebssurrogate"
 	amazonebsvolumebuilder "github.com/hashicorp/packer/builder/amazon/ebsvolume"
 	amazoninstancebuilder "github.com/hashicorp/packer/builder/amazon/instance"
+	armimagebuilder "github.com/hashicorp/packer/builder/arm-image"
 	azurearmbuilder "github.com/hashicorp/packer/builder/azure/arm"
 	cloudstackbuilder "github.com/hashicorp/packer/builder/cloudstack"
 	digitaloceanbuilder "github.com/hashicorp/packer/builder/digitalocean"
@@ -29,11 +30,15 @@ import (
 	hyperonebuilder "github.com/hashicorp/packer/builder/hyperone"
 	hypervisobuilder "github.com/hashicorp/packer/builder/hyperv/iso"
 	hypervvmcxbuilder "github.com/hashicorp/packer/builder/hyperv/vmcx"
+	isoremasterbuilder "github.com/hashicorp/packer/builder/iso-remaster"
+	kubevirtbuilder "github.com/hashicorp/packer/builder/kubevirt"
+	libvirtbuilder "github.com/hashicorp/packer/builder/libvirt"
 	linodebuilder "github.com/hashicorp/packer/builder/linode"
 	lxcbuilder "github.com/hashicorp/packer/builder/lxc"
 	lxdbuilder "github.com/hashicorp/packer/builder/lxd"
 	ncloudbuilder "github.com/hashicorp/packer/builder/ncloud"
 	nullbuilder "github.com/hashicorp/packer/builder/null"
+	nutanixbuilder "github.com/hashicorp/packer/builder/nutanix"
 	oneandonebuilder "github.com/hashicorp/packer/builder/oneandone"
 	openstackbuilder "github.com/hashicorp/packer/builder/openstack"
 	oracleclassicbuilder "github.com/hashicorp/packer/builder/oracle/classic"
@@ -47,15 +52,20 @@ import (
 	profitbricksbuilder "github.com/hashicorp/packer/builder/profitbricks"
 	proxmoxbuilder "github.com/hashicorp/packer/builder/proxmox"
 	qemubuilder "github.com/hashicorp/packer/builder/qemu"
+	rootfsbuilder "github.com/hashicorp/packer/builder/rootfs"
 	scalewaybuilder "github.com/hashicorp/packer/builder/scaleway"
+	tartbuilder "github.com/hashicorp/packer/builder/tart"
 	tencentcloudcvmbuilder "github.com/hashicorp/packer/builder/tencentcloud/cvm"
 	tritonbuilder "github.com/hashicorp/packer/builder/triton"
 	uclouduhostbuilder "github.com/hashicorp/packer/builder/ucloud/uhost"
+	utmbuilder "github.com/hashicorp/packer/builder/utm"
 	vagrantbuilder "github.com/hashicorp/packer/builder/vagrant"
 	virtualboxisobuilder "github.com/hashicorp/packer/builder/virtualbox/iso"
 	virtualboxovfbuilder "github.com/hashicorp/packer/builder/virtualbox/ovf"
 	vmwareisobuilder "github.com/hashicorp/packer/builder/vmware/iso"
 	vmwarevmxbuilder "github.com/hashicorp/packer/builder/vmware/vmx"
+	vultrbuilder "github.com/hashicorp/packer/builder/vultr"
+	xenserverbuilder "github.com/hashicorp/packer/builder/xenserver"
 	yandexbuilder "github.com/hashicorp/packer/builder/yandex"
 	alicloudimportpostprocessor "github.com/hashicorp/packer/post-processor/alicloud-import"
 	amazonimportpostprocessor "github.com/hashicorp/packer/post-processor/amazon-import"
@@ -106,6 +116,7 @@ var Builders = map[string]packer.Builder{
 	"amazon-ebssurrogate": new(amazonebssurrogatebuilder.Builder),
 	"amazon-ebsvolume":    new(amazonebsvolumebuilder.Builder),
 	"amazon-instance":     new(amazoninstancebuilder.Builder),
+	"arm-image":           new(armimagebuilder.Builder),
 	"azure-arm":           new(azurearmbuilder.Builder),
 	"cloudstack":          new(cloudstackbuilder.Builder),
 	"digitalocean":        new(digitaloceanbuilder.Builder),
@@ -116,11 +127,15 @@ var Builders = map[string]packer.Builder{
 	"hyperone":            new(hyperonebuilder.Builder),
 	"hyperv-iso":          new(hypervisobuilder.Builder),
 	"hyperv-vmcx":         new(hypervvmcxbuilder.Builder),
+	"iso-remaster":        new(isoremasterbuilder.Builder),
+	"kubevirt":            new(kubevirtbuilder.Builder),
+	"libvirt":             new(libvirtbuilder.Builder),
 	"linode":              new(linodebuilder.Builder),
 	"lxc":                 new(lxcbuilder.Builder),
 	"lxd":                 new(lxdbuilder.Builder),
 	"ncloud":              new(ncloudbuilder.Builder),
 	"null":                new(nullbuilder.Builder),
+	"nutanix":             new(nutanixbuilder.Builder),
 	"oneandone":           new(oneandonebuilder.Builder),
 	"openstack":           new(openstackbuilder.Builder),
 	"oracle-classic":      new(oracleclassicbuilder.Builder),
@@ -134,15 +149,20 @@ var Builders = map[string]packer.Builder{
 	"profitbricks":        new(profitbricksbuilder.Builder),
 	"proxmox":             new(proxmoxbuilder.Builder),
 	"qemu":                new(qemubuilder.Builder),
+	"rootfs":              new(rootfsbuilder.Builder),
 	"scaleway":            new(scalewaybuilder.Builder),
+	"tart":                new(tartbuilder.Builder),
 	"tencentcloud-cvm":    new(tencentcloudcvmbuilder.Builder),
 	"triton":              new(tritonbuilder.Builder),
 	"ucloud-uhost":        new(uclouduhostbuilder.Builder),
+	"utm":                 new(utmbuilder.Builder),
 	"vagrant":             new(vagrantbuilder.Builder),
 	"virtualbox-iso":      new(virtualboxisobuilder.Builder),
 	"virtualbox-ovf":      new(virtualboxovfbuilder.Builder),
 	"vmware-iso":          new(vmwareisobuilder.Builder),
 	"vmware-vmx":          new(vmwarevmxbuilder.Builder),
+	"vultr":               new(vultrbuilder.Builder),
+	"xenserver":           new(xenserverbuilder.Builder),
 	"yandex":              new(yandexbuilder.Builder),
 }
 
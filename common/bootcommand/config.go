@@ -34,6 +34,9 @@ func (c *BootConfig) Prepare(ctx *interpolate.Context) (errs []error) {
 		if err != nil {
 			errs = append(
 				errs, fmt.Errorf("Failed parsing boot_wait: %s", err))
+		} else if bw < 0 {
+			errs = append(
+				errs, fmt.Errorf("boot_wait must not be negative, use 0s to not wait: %s", c.RawBootWait))
 		} else {
 			c.BootWait = bw
 		}
@@ -2,6 +2,7 @@ package bootcommand
 
 import (
 	"testing"
+	"time"
 
 	"github.com/hashicorp/packer/template/interpolate"
 )
@@ -28,6 +29,14 @@ func TestConfigPrepare(t *testing.T) {
 		t.Fatal("should error")
 	}
 
+	// Test with a negative boot_wait
+	c = new(BootConfig)
+	c.RawBootWait = "-1s"
+	errs = c.Prepare(&interpolate.Context{})
+	if len(errs) == 0 {
+		t.Fatal("should error")
+	}
+
 	// Test with a good one
 	c = new(BootConfig)
 	c.RawBootWait = "5s"
@@ -35,6 +44,20 @@ func TestConfigPrepare(t *testing.T) {
 	if len(errs) > 0 {
 		t.Fatalf("bad: %#v", errs)
 	}
+	if c.BootWait != 5*time.Second {
+		t.Fatalf("bad boot_wait: %s", c.BootWait)
+	}
+
+	// Test not waiting at all
+	c = new(BootConfig)
+	c.RawBootWait = "0s"
+	errs = c.Prepare(&interpolate.Context{})
+	if len(errs) > 0 {
+		t.Fatalf("bad: %#v", errs)
+	}
+	if c.BootWait != 0 {
+		t.Fatalf("bad boot_wait: %s", c.BootWait)
+	}
 }
 
 func TestVNCConfigPrepare(t *testing.T) {
@@ -0,0 +1,212 @@
+package bootcommand
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/hashicorp/packer/common"
+)
+
+// USBModifiers are the modifier keys held down while a key is pressed.
+type USBModifiers struct {
+	LeftCtrl   bool
+	LeftShift  bool
+	LeftAlt    bool
+	LeftSuper  bool
+	RightCtrl  bool
+	RightShift bool
+	RightAlt   bool
+	RightSuper bool
+}
+
+// SendUSBCodeFunc will be called to press and release a key on the VM. The
+// key is given as its USB HID usage ID, along with the modifier keys that
+// should be held down while it is pressed.
+type SendUSBCodeFunc func(code uint16, modifiers USBModifiers) error
+
+type usbKeyEvent struct {
+	code      uint16
+	modifiers USBModifiers
+}
+
+type usbDriver struct {
+	interval   time.Duration
+	sendImpl   SendUSBCodeFunc
+	specialMap map[string]uint16
+	keyMap     map[rune]uint16
+	held       USBModifiers
+	buffer     []usbKeyEvent
+}
+
+// NewUSBDriver creates a new boot command driver for VMs that take USB HID
+// key codes, such as the vSphere API. `send` should press and release its
+// key on the VM.
+//
+// Unlike the other drivers, every key sent is pressed and released at once,
+// so the On and Off actions only have an effect on the modifier keys, which
+// stay held down until they are released.
+func NewUSBDriver(send SendUSBCodeFunc, interval time.Duration) *usbDriver {
+	// We delay (default 100ms) between each input event to allow for CPU or
+	// network latency. See PackerKeyEnv for tuning.
+	keyInterval := common.PackerKeyDefault
+	if delay, err := time.ParseDuration(os.Getenv(common.PackerKeyEnv)); err == nil {
+		keyInterval = delay
+	}
+	// Override interval based on builder-specific override
+	if interval > time.Duration(0) {
+		keyInterval = interval
+	}
+
+	// Usage IDs reference: https://www.usb.org/sites/default/files/documents/hut1_12v2.pdf
+	// chapter 10, "Keyboard/Keypad Page".
+	sMap := map[string]uint16{
+		"bs":         0x2a,
+		"del":        0x4c,
+		"down":       0x51,
+		"end":        0x4d,
+		"enter":      0x28,
+		"esc":        0x29,
+		"f1":         0x3a,
+		"f2":         0x3b,
+		"f3":         0x3c,
+		"f4":         0x3d,
+		"f5":         0x3e,
+		"f6":         0x3f,
+		"f7":         0x40,
+		"f8":         0x41,
+		"f9":         0x42,
+		"f10":        0x43,
+		"f11":        0x44,
+		"f12":        0x45,
+		"home":       0x4a,
+		"insert":     0x49,
+		"left":       0x50,
+		"leftalt":    0xe2,
+		"leftctrl":   0xe0,
+		"leftshift":  0xe1,
+		"leftsuper":  0xe3,
+		"menu":       0x65,
+		"pagedown":   0x4e,
+		"pageup":     0x4b,
+		"return":     0x28,
+		"right":      0x4f,
+		"rightalt":   0xe6,
+		"rightctrl":  0xe4,
+		"rightshift": 0xe5,
+		"rightsuper": 0xe7,
+		"spacebar":   0x2c,
+		"tab":        0x2b,
+		"up":         0x52,
+	}
+
+	// Each string lists the characters of consecutive usage IDs, starting
+	// at the given one. The shifted characters are on the same keys.
+	keyIndex := map[string]uint16{
+		"abcdefghijklmnopqrstuvwxyz": 0x04,
+		"ABCDEFGHIJKLMNOPQRSTUVWXYZ": 0x04,
+		"1234567890":                 0x1e,
+		"!@#$%^&*()":                 0x1e,
+		" ":                          0x2c,
+		"-=[]\\":                     0x2d,
+		"_+{}|":                      0x2d,
+		";'`,./":                     0x33,
+		":\"~<>?":                    0x33,
+	}
+
+	keyMap := make(map[rune]uint16)
+	for chars, start := range keyIndex {
+		for i, r := range []rune(chars) {
+			keyMap[r] = start + uint16(i)
+		}
+	}
+
+	return &usbDriver{
+		interval:   keyInterval,
+		sendImpl:   send,
+		specialMap: sMap,
+		keyMap:     keyMap,
+	}
+}
+
+// Flush sends all key events, one at a time.
+func (d *usbDriver) Flush() error {
+	defer func() {
+		d.buffer = nil
+	}()
+	for _, e := range d.buffer {
+		if err := d.sendImpl(e.code, e.modifiers); err != nil {
+			return err
+		}
+		time.Sleep(d.interval)
+	}
+	return nil
+}
+
+func (d *usbDriver) SendKey(key rune, action KeyAction) error {
+	code, ok := d.keyMap[key]
+	if !ok {
+		return fmt.Errorf("key %q can't be typed with a USB keyboard", key)
+	}
+	if action&(KeyOn|KeyPress) == 0 {
+		return nil
+	}
+
+	keyShift := unicode.IsUpper(key) || strings.ContainsRune(shiftedChars, key)
+	modifiers := d.held
+	if keyShift {
+		modifiers.LeftShift = true
+	}
+
+	log.Printf("Sending char '%c', code '%02x', shift %v", key, code, keyShift)
+	d.send(code, modifiers)
+	return nil
+}
+
+func (d *usbDriver) SendSpecial(special string, action KeyAction) error {
+	code, ok := d.specialMap[special]
+	if !ok {
+		return fmt.Errorf("special %s not found.", special)
+	}
+	log.Printf("Special code '%s' '<%s>' found, replacing with: %02x", action.String(), special, code)
+
+	if modifier := d.modifier(special); modifier != nil && action != KeyPress {
+		*modifier = action == KeyOn
+		return nil
+	}
+	if action&(KeyOn|KeyPress) != 0 {
+		d.send(code, d.held)
+	}
+	return nil
+}
+
+// modifier returns the held state of the special key if it is a modifier.
+func (d *usbDriver) modifier(special string) *bool {
+	switch special {
+	case "leftctrl":
+		return &d.held.LeftCtrl
+	case "leftshift":
+		return &d.held.LeftShift
+	case "leftalt":
+		return &d.held.LeftAlt
+	case "leftsuper":
+		return &d.held.LeftSuper
+	case "rightctrl":
+		return &d.held.RightCtrl
+	case "rightshift":
+		return &d.held.RightShift
+	case "rightalt":
+		return &d.held.RightAlt
+	case "rightsuper":
+		return &d.held.RightSuper
+	}
+	return nil
+}
+
+// send stores the key event in an internal buffer. Use Flush to send them.
+func (d *usbDriver) send(code uint16, modifiers USBModifiers) {
+	d.buffer = append(d.buffer, usbKeyEvent{code, modifiers})
+}
@@ -0,0 +1,56 @@
+package bootcommand
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_usbKeys(t *testing.T) {
+	in := "aZ1!<enter>"
+	expected := []usbKeyEvent{
+		{0x04, USBModifiers{}},
+		{0x1d, USBModifiers{LeftShift: true}},
+		{0x1e, USBModifiers{}},
+		{0x1e, USBModifiers{LeftShift: true}},
+		{0x28, USBModifiers{}},
+	}
+	var actual []usbKeyEvent
+	sendCodes := func(code uint16, modifiers USBModifiers) error {
+		actual = append(actual, usbKeyEvent{code, modifiers})
+		return nil
+	}
+	d := NewUSBDriver(sendCodes, time.Duration(1))
+	seq, err := GenerateExpressionSequence(in)
+	assert.NoError(t, err)
+	err = seq.Do(context.Background(), d)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func Test_usbModifiersHeld(t *testing.T) {
+	in := "<leftCtrlOn><leftAltOn><del><leftAltOff>c<leftCtrlOff><leftSuper>"
+	expected := []usbKeyEvent{
+		{0x4c, USBModifiers{LeftCtrl: true, LeftAlt: true}},
+		{0x06, USBModifiers{LeftCtrl: true}},
+		{0xe3, USBModifiers{}},
+	}
+	var actual []usbKeyEvent
+	sendCodes := func(code uint16, modifiers USBModifiers) error {
+		actual = append(actual, usbKeyEvent{code, modifiers})
+		return nil
+	}
+	d := NewUSBDriver(sendCodes, time.Duration(1))
+	seq, err := GenerateExpressionSequence(in)
+	assert.NoError(t, err)
+	err = seq.Do(context.Background(), d)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func Test_usbUnknownKey(t *testing.T) {
+	d := NewUSBDriver(nil, time.Duration(1))
+	assert.Error(t, d.SendKey('é', KeyPress))
+}
@@ -50,3 +50,39 @@ func Test_vncIntervalGiven(t *testing.T) {
 	d := NewVNCDriver(s, time.Duration(5000)*time.Millisecond)
 	assert.Equal(t, d.interval, time.Duration(5000)*time.Millisecond)
 }
+
+func Test_vncBootCommandKeys(t *testing.T) {
+	in := "<enter><tab><esc><spacebar><leftShiftOn>a<leftShiftOff><wait><wait5><wait10><wait2s>"
+	expected := []event{
+		{0xFF0D, true},
+		{0xFF0D, false},
+		{0xFF09, true},
+		{0xFF09, false},
+		{0xFF1B, true},
+		{0xFF1B, false},
+		{0x20, true},
+		{0x20, false},
+		{0xFFE1, true},
+		{'a', true},
+		{'a', false},
+		{0xFFE1, false},
+	}
+
+	seq, err := GenerateExpressionSequence(in)
+	assert.NoError(t, err)
+
+	var waits []string
+	for _, exp := range seq {
+		if w, ok := exp.(*waitExpression); ok {
+			waits = append(waits, w.d.String())
+		}
+	}
+	assert.Equal(t, []string{"1s", "5s", "10s", "2s"}, waits)
+
+	// Type everything but the waits
+	s := &sender{}
+	d := NewVNCDriver(s, time.Millisecond)
+	err = seq[:len(seq)-len(waits)].Do(context.Background(), d)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, s.e)
+}
@@ -0,0 +1,56 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/packer/template/interpolate"
+)
+
+// CDConfig configures an ISO image that is built from files on the host and
+// attached to the VM as an additional CD-ROM, for example to pass an
+// autounattend.xml to a Windows installer.
+type CDConfig struct {
+	CDFiles   []string          `mapstructure:"cd_files"`
+	CDContent map[string]string `mapstructure:"cd_content"`
+	CDLabel   string            `mapstructure:"cd_label"`
+}
+
+func (c *CDConfig) Prepare(ctx *interpolate.Context) []error {
+	var errs []error
+	var err error
+
+	if c.CDFiles == nil {
+		c.CDFiles = make([]string, 0)
+	}
+
+	for _, path := range c.CDFiles {
+		if strings.ContainsAny(path, "*?[") {
+			var matches []string
+			matches, err = filepath.Glob(path)
+			if err == nil && len(matches) == 0 {
+				err = errors.New("no file matches the pattern")
+			}
+		} else {
+			_, err = os.Stat(path)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("Bad CD disk file '%s': %s", path, err))
+		}
+	}
+
+	for path := range c.CDContent {
+		if path == "" || filepath.IsAbs(path) || strings.HasPrefix(filepath.Clean(path), "..") {
+			errs = append(errs, fmt.Errorf("Bad CD content path '%s': must be relative to the root of the CD", path))
+		}
+	}
+
+	if c.CDLabel == "" {
+		c.CDLabel = "packer"
+	}
+
+	return errs
+}
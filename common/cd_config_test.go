@@ -0,0 +1,50 @@
+package common
+
+import (
+	"testing"
+)
+
+func TestCDConfigPrepare(t *testing.T) {
+	c := CDConfig{}
+	if errs := c.Prepare(nil); len(errs) != 0 {
+		t.Fatalf("err: %#v", errs)
+	}
+	if c.CDLabel != "packer" {
+		t.Fatalf("bad label: %s", c.CDLabel)
+	}
+
+	c = CDConfig{
+		CDFiles: []string{"cd_config.go", "cd_config*.go"},
+		CDContent: map[string]string{
+			"autounattend.xml": "<unattend/>",
+			"scripts/a.ps1":    "exit 0",
+		},
+		CDLabel: "cidata",
+	}
+	if errs := c.Prepare(nil); len(errs) != 0 {
+		t.Fatalf("err: %#v", errs)
+	}
+	if c.CDLabel != "cidata" {
+		t.Fatalf("bad label: %s", c.CDLabel)
+	}
+}
+
+func TestCDConfigPrepare_bad(t *testing.T) {
+	c := CDConfig{
+		CDFiles: []string{"cd_config.foo", "cd_config*.foo"},
+	}
+	if errs := c.Prepare(nil); len(errs) != 2 {
+		t.Fatalf("bad: %#v", errs)
+	}
+
+	c = CDConfig{
+		CDContent: map[string]string{
+			"/etc/passwd":  "",
+			"../outside":   "",
+			"inside/../ok": "",
+		},
+	}
+	if errs := c.Prepare(nil); len(errs) != 2 {
+		t.Fatalf("bad: %#v", errs)
+	}
+}
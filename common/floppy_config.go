@@ -10,8 +10,9 @@ import (
 )
 
 type FloppyConfig struct {
-	FloppyFiles       []string `mapstructure:"floppy_files"`
-	FloppyDirectories []string `mapstructure:"floppy_dirs"`
+	FloppyFiles       []string          `mapstructure:"floppy_files"`
+	FloppyDirectories []string          `mapstructure:"floppy_dirs"`
+	FloppyContent     map[string]string `mapstructure:"floppy_content"`
 }
 
 func (c *FloppyConfig) Prepare(ctx *interpolate.Context) []error {
@@ -48,5 +49,11 @@ func (c *FloppyConfig) Prepare(ctx *interpolate.Context) []error {
 		}
 	}
 
+	for path := range c.FloppyContent {
+		if path == "" || filepath.IsAbs(path) || strings.HasPrefix(filepath.Clean(path), "..") {
+			errs = append(errs, fmt.Errorf("Bad Floppy content path '%s': must be relative to the root of the floppy", path))
+		}
+	}
+
 	return errs
 }
@@ -68,3 +68,25 @@ func TestMultiErrorFloppyFiles(t *testing.T) {
 		t.Fatalf("array with %v non existing floppy should return %v errors but it is returning %v", expectedErrors, expectedErrors, count)
 	}
 }
+
+func TestFloppyContent(t *testing.T) {
+	c := FloppyConfig{
+		FloppyContent: map[string]string{
+			"ks.cfg":          "text",
+			"scripts/init.sh": "echo init",
+		},
+	}
+	if errs := c.Prepare(nil); len(errs) != 0 {
+		t.Fatalf("err: %#v", errs)
+	}
+
+	c = FloppyConfig{
+		FloppyContent: map[string]string{
+			"/ks.cfg":    "text",
+			"../init.sh": "echo init",
+		},
+	}
+	if errs := c.Prepare(nil); len(errs) != 2 {
+		t.Fatalf("bad: %#v", errs)
+	}
+}
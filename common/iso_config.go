@@ -15,13 +15,14 @@ import (
 
 // ISOConfig contains configuration for downloading ISO images.
 type ISOConfig struct {
-	ISOChecksum     string   `mapstructure:"iso_checksum"`
-	ISOChecksumURL  string   `mapstructure:"iso_checksum_url"`
-	ISOChecksumType string   `mapstructure:"iso_checksum_type"`
-	ISOUrls         []string `mapstructure:"iso_urls"`
-	TargetPath      string   `mapstructure:"iso_target_path"`
-	TargetExtension string   `mapstructure:"iso_target_extension"`
-	RawSingleISOUrl string   `mapstructure:"iso_url"`
+	ISOChecksum     string            `mapstructure:"iso_checksum"`
+	ISOChecksumURL  string            `mapstructure:"iso_checksum_url"`
+	ISOChecksumType string            `mapstructure:"iso_checksum_type"`
+	ISOHTTPHeaders  map[string]string `mapstructure:"iso_http_headers"`
+	ISOUrls         []string          `mapstructure:"iso_urls"`
+	TargetPath      string            `mapstructure:"iso_target_path"`
+	TargetExtension string            `mapstructure:"iso_target_extension"`
+	RawSingleISOUrl string            `mapstructure:"iso_url"`
 }
 
 func (c *ISOConfig) Prepare(ctx *interpolate.Context) (warnings []string, errs []error) {
@@ -43,6 +44,14 @@ func (c *ISOConfig) Prepare(ctx *interpolate.Context) (warnings []string, errs [
 	}
 
 	c.ISOChecksumType = strings.ToLower(c.ISOChecksumType)
+	switch c.ISOChecksumType {
+	case "", "none", "file", "md5", "sha1", "sha256", "sha512":
+	default:
+		errs = append(errs, fmt.Errorf(
+			"Unsupported checksum type: %s. Valid values are none, file, md5, sha1, sha256 and sha512",
+			c.ISOChecksumType))
+		return
+	}
 
 	if c.TargetExtension == "" {
 		c.TargetExtension = "iso"
@@ -77,6 +86,12 @@ func (c *ISOConfig) Prepare(ctx *interpolate.Context) (warnings []string, errs [
 		errs = append(errs, fmt.Errorf("A checksum must be specified"))
 	}
 	if c.ISOChecksumType == "file" {
+		// The checksum is the file to read the checksum from when the
+		// type is set explicitly.
+		if c.ISOChecksumURL == "" {
+			c.ISOChecksumURL = c.ISOChecksum
+		}
+
 		u, err := url.Parse(c.ISOUrls[0])
 		wd, err := os.Getwd()
 		if err != nil {
@@ -89,7 +104,7 @@ func (c *ISOConfig) Prepare(ctx *interpolate.Context) (warnings []string, errs [
 			Src:     u.String(),
 			Pwd:     wd,
 			Dir:     false,
-			Getters: getter.Getters,
+			Getters: httpGetters(c.ISOHTTPHeaders, u.Host),
 		}
 		cksum, err := gc.ChecksumFromFile(c.ISOChecksumURL, u)
 		if cksum == nil || err != nil {
@@ -3,8 +3,10 @@
 package common
 
 import (
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"reflect"
 	"testing"
 )
@@ -141,6 +143,39 @@ func TestISOConfigPrepare_ISOChecksumType(t *testing.T) {
 	if i.ISOChecksumType != "none" {
 		t.Fatalf("should've lowercased: %s", i.ISOChecksumType)
 	}
+
+	// Test unsupported
+	i = testISOConfig()
+	i.ISOChecksumType = "crc32"
+	_, err = i.Prepare(nil)
+	if err == nil {
+		t.Fatal("should have error")
+	}
+}
+
+func TestISOConfigPrepare_ISOChecksumTypeFile(t *testing.T) {
+	f, err := ioutil.TempFile("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString("d41d8cd98f00b204e9800998ecf8427e  the-OS.iso\n")
+	f.Close()
+
+	// The checksum is read from the file named by iso_checksum
+	i := testISOConfig()
+	i.ISOChecksumType = "file"
+	i.ISOChecksum = f.Name()
+	warns, errs := i.Prepare(nil)
+	if len(warns) > 0 {
+		t.Fatalf("bad: %#v", warns)
+	}
+	if len(errs) > 0 {
+		t.Fatalf("should not have error: %#v", errs)
+	}
+	if i.ISOChecksumType != "md5" || i.ISOChecksum != "d41d8cd98f00b204e9800998ecf8427e" {
+		t.Fatalf("bad checksum: %s %s", i.ISOChecksumType, i.ISOChecksum)
+	}
 }
 
 func TestISOConfigPrepare_ISOUrl(t *testing.T) {
@@ -0,0 +1,59 @@
+package net
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+
+	"github.com/hashicorp/packer/common/filelock"
+	"github.com/hashicorp/packer/packer"
+)
+
+// PortLock reserves a port between the Packer processes running on this
+// host without listening on it, for example a port on a remote hypervisor
+// that Packer only probes. Unlock must be called to release the port.
+type PortLock struct {
+	Host string
+	Port int
+
+	lock *filelock.Flock
+	path string
+}
+
+var unsafeHostRe = regexp.MustCompile(`[^A-Za-z0-9.-]`)
+
+// LockPort locks port on host, or returns ErrPortFileLocked if another build
+// holds the lock.
+func LockPort(host string, port int) (*PortLock, error) {
+	name := fmt.Sprintf("%s-%d", unsafeHostRe.ReplaceAllString(host, "_"), port)
+	path, err := packer.CachePath("port", name)
+	if err != nil {
+		return nil, err
+	}
+
+	lock := filelock.New(path)
+	locked, err := lock.TryLock()
+	if err != nil {
+		return nil, err
+	}
+	if !locked {
+		return nil, ErrPortFileLocked(port)
+	}
+
+	log.Printf("Locked port %d on host %s", port, host)
+	return &PortLock{
+		Host: host,
+		Port: port,
+		lock: lock,
+		path: path,
+	}, nil
+}
+
+// Unlock releases the port so other builds can use it.
+func (l *PortLock) Unlock() error {
+	if err := l.lock.Unlock(); err != nil {
+		return err
+	}
+	return os.Remove(l.path)
+}
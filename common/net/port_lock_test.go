@@ -0,0 +1,36 @@
+package net
+
+import (
+	"testing"
+)
+
+func TestLockPort(t *testing.T) {
+	lock, err := LockPort("esxi.example.com", 5901)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if _, err := LockPort("esxi.example.com", 5901); err == nil {
+		t.Fatal("port should be locked")
+	} else if p := int(err.(ErrPortFileLocked)); p != 5901 {
+		t.Fatalf("wrong fileport: %d", p)
+	}
+
+	// The same port on another host is independent
+	other, err := LockPort("fe80::1", 5901)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := other.Unlock(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if err := lock.Unlock(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	lock, err = LockPort("esxi.example.com", 5901)
+	if err != nil {
+		t.Fatalf("port should be unlocked: %s", err)
+	}
+	lock.Unlock()
+}
@@ -0,0 +1,220 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+	"github.com/hashicorp/packer/packer/tmp"
+)
+
+// StepCreateCD will create an ISO image with the given files and content,
+// using whichever of xorriso, mkisofs, genisoimage, hdiutil or oscdimg is
+// available on the host.
+//
+// Produces:
+//   cd_path string - The path to the ISO image
+type StepCreateCD struct {
+	Files   []string
+	Content map[string]string
+	Label   string
+
+	cdPath string
+}
+
+// isoTools are the tools that can create an ISO image, in order of
+// preference.
+var isoTools = []string{"xorriso", "mkisofs", "genisoimage", "hdiutil", "oscdimg"}
+
+func (s *StepCreateCD) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
+	if len(s.Files) == 0 && len(s.Content) == 0 {
+		log.Println("No CD files specified. CD disk will not be made.")
+		return multistep.ActionContinue
+	}
+
+	ui := state.Get("ui").(packer.Ui)
+	ui.Say("Creating CD disk...")
+
+	tool, toolPath := findISOTool()
+	if toolPath == "" {
+		err := fmt.Errorf("Error creating CD: could not find any of %s in the PATH",
+			strings.Join(isoTools, ", "))
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	rootDir, err := tmp.Dir("packer-cd")
+	if err != nil {
+		err := fmt.Errorf("Error creating temporary directory for CD: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	defer os.RemoveAll(rootDir)
+
+	if err := s.addFiles(ui, rootDir); err != nil {
+		err := fmt.Errorf("Error creating CD: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	cdF, err := tmp.File("packer*.iso")
+	if err != nil {
+		err := fmt.Errorf("Error creating temporary file for CD: %s", err)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+	cdF.Close()
+
+	// The tools refuse to overwrite an existing file
+	os.Remove(cdF.Name())
+	s.cdPath = cdF.Name()
+	log.Printf("CD path: %s", s.cdPath)
+
+	args := isoToolArgs(tool, s.Label, rootDir, s.cdPath)
+	log.Printf("Executing: %s %s", toolPath, strings.Join(args, " "))
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, toolPath, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		log.Printf("stdout: %s", stdout.String())
+		err := fmt.Errorf("Error creating CD with %s: %s\n\nStderr: %s",
+			tool, err, strings.TrimSpace(stderr.String()))
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
+	// Set the path to the CD so it can be used later
+	state.Put("cd_path", s.cdPath)
+
+	return multistep.ActionContinue
+}
+
+func (s *StepCreateCD) Cleanup(multistep.StateBag) {
+	if s.cdPath != "" {
+		log.Printf("Deleting CD disk: %s", s.cdPath)
+		os.Remove(s.cdPath)
+	}
+}
+
+// addFiles copies the files and content into the root directory of the CD.
+// Files are copied to the root, directories keep their name unless the path
+// ends with a slash, in which case only their contents are copied.
+func (s *StepCreateCD) addFiles(ui packer.Ui, rootDir string) error {
+	for _, pattern := range s.Files {
+		paths := []string{pattern}
+		if strings.ContainsAny(pattern, "*?[") {
+			matches, err := filepath.Glob(pattern)
+			if err != nil {
+				return err
+			}
+			paths = matches
+		}
+
+		for _, path := range paths {
+			finfo, err := os.Stat(path)
+			if err != nil {
+				return err
+			}
+
+			dst := filepath.Join(rootDir, filepath.Base(path))
+			if finfo.IsDir() && strings.HasSuffix(path, "/") {
+				dst = rootDir
+			}
+
+			ui.Message(fmt.Sprintf("Adding path: %s", path))
+			if err := copyPath(dst, path); err != nil {
+				return err
+			}
+		}
+	}
+
+	for path, content := range s.Content {
+		ui.Message(fmt.Sprintf("Adding content: %s", path))
+		dst := filepath.Join(rootDir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(dst, []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// copyPath copies a file or a directory tree from src to dst.
+func copyPath(dst, src string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		if info.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+
+		in, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+
+		out, err := os.Create(target)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+
+		_, err = io.Copy(out, in)
+		return err
+	})
+}
+
+// findISOTool returns the name and path of the first ISO tool found in the
+// PATH.
+func findISOTool() (string, string) {
+	for _, tool := range isoTools {
+		if path, err := exec.LookPath(tool); err == nil {
+			return tool, path
+		}
+	}
+	return "", ""
+}
+
+// isoToolArgs returns the arguments for the tool to create an ISO image
+// with Joliet and Rock Ridge extensions from the contents of src.
+func isoToolArgs(tool, label, src, dst string) []string {
+	switch tool {
+	case "xorriso":
+		return []string{"-as", "genisoimage", "-rock", "-joliet", "-volid", label, "-output", dst, src}
+	case "mkisofs", "genisoimage":
+		return []string{"-rock", "-joliet", "-volid", label, "-output", dst, src}
+	case "hdiutil":
+		return []string{"makehybrid", "-iso", "-joliet", "-default-volume-name", label, "-o", dst, src}
+	case "oscdimg":
+		return []string{"-j1", "-o", "-m", "-l" + label, src, dst}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package common
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/hashicorp/packer/helper/multistep"
+	"github.com/hashicorp/packer/packer"
+)
+
+func TestStepCreateCD_Impl(t *testing.T) {
+	var _ multistep.Step = new(StepCreateCD)
+}
+
+func TestStepCreateCD_empty(t *testing.T) {
+	state := testStepCreateFloppyState(t)
+	step := new(StepCreateCD)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if _, ok := state.GetOk("cd_path"); ok {
+		t.Fatal("should not have cd_path")
+	}
+}
+
+func TestStepCreateCD_addFiles(t *testing.T) {
+	state := testStepCreateFloppyState(t)
+	ui := state.Get("ui").(packer.Ui)
+
+	rootDir, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(rootDir)
+
+	dir := filepath.Join(TestFixtures, "floppy-hier", "test-1")
+	step := &StepCreateCD{
+		Files: []string{
+			"cd_config.go",
+			dir,
+			dir + "/",
+		},
+		Content: map[string]string{
+			"autounattend.xml": "<unattend/>",
+			"scripts/a.ps1":    "exit 0",
+		},
+	}
+	if err := step.addFiles(ui, rootDir); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	var files []string
+	filepath.Walk(rootDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			rel, _ := filepath.Rel(rootDir, path)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	sort.Strings(files)
+
+	expected := []string{
+		"autounattend.xml",
+		"cd_config.go",
+		"dir1/file1",
+		"dir1/file2",
+		"dir1/file3",
+		"scripts/a.ps1",
+		"test-1/dir1/file1",
+		"test-1/dir1/file2",
+		"test-1/dir1/file3",
+	}
+	if !reflect.DeepEqual(files, expected) {
+		t.Fatalf("bad files: %#v", files)
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(rootDir, "autounattend.xml"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if string(content) != "<unattend/>" {
+		t.Fatalf("bad content: %s", content)
+	}
+}
+
+func TestIsoToolArgs(t *testing.T) {
+	for _, tool := range isoTools {
+		if args := isoToolArgs(tool, "packer", "src", "dst.iso"); len(args) == 0 {
+			t.Fatalf("no args for %s", tool)
+		}
+	}
+
+	args := isoToolArgs("oscdimg", "packer", "src", "dst.iso")
+	expected := []string{"-j1", "-o", "-m", "-lpacker", "src", "dst.iso"}
+	if !reflect.DeepEqual(args, expected) {
+		t.Fatalf("bad args: %#v", args)
+	}
+}
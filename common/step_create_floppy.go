@@ -17,10 +17,12 @@ import (
 	"github.com/mitchellh/go-fs/fat"
 )
 
-// StepCreateFloppy will create a floppy disk with the given files.
+// StepCreateFloppy will create a floppy disk with the given files,
+// directories and content.
 type StepCreateFloppy struct {
 	Files       []string
 	Directories []string
+	Content     map[string]string
 
 	floppyPath string
 
@@ -28,7 +30,7 @@ type StepCreateFloppy struct {
 }
 
 func (s *StepCreateFloppy) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
-	if len(s.Files) == 0 && len(s.Directories) == 0 {
+	if len(s.Files) == 0 && len(s.Directories) == 0 && len(s.Content) == 0 {
 		log.Println("No floppy files specified. Floppy disk will not be made.")
 		return multistep.ActionContinue
 	}
@@ -208,6 +210,15 @@ func (s *StepCreateFloppy) Run(ctx context.Context, state multistep.StateBag) mu
 	}
 	ui.Message("Done copying paths from floppy_dirs")
 
+	// Write the content into files on the floppy
+	for filename, content := range s.Content {
+		ui.Message(fmt.Sprintf("Adding content: %s", filename))
+		if err = s.AddContent(cache, filename, content); err != nil {
+			state.Put("error", fmt.Errorf("Error adding content %s to floppy: %s", filename, err))
+			return multistep.ActionHalt
+		}
+	}
+
 	// Set the path to the floppy so it can be used later
 	state.Put("floppy_path", s.floppyPath)
 
@@ -298,6 +309,31 @@ func (s *StepCreateFloppy) Add(dircache directoryCache, src string) error {
 	return filepath.Walk(src, visit)
 }
 
+// AddContent creates a file with the given content on the floppy. The
+// directories of the path are created if they don't exist yet.
+func (s *StepCreateFloppy) AddContent(dircache directoryCache, filename string, content string) error {
+	directory, name := path.Split(filepath.ToSlash(filename))
+
+	d, err := dircache(strings.TrimSuffix(directory, "/"))
+	if err != nil {
+		return err
+	}
+
+	entry, err := d.AddFile(name)
+	if err != nil {
+		return err
+	}
+
+	fatFile, err := entry.File()
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(fatFile, strings.NewReader(content))
+	s.FilesAdded[filename] = true
+	return err
+}
+
 func (s *StepCreateFloppy) Cleanup(multistep.StateBag) {
 	if s.floppyPath != "" {
 		log.Printf("Deleting floppy disk: %s", s.floppyPath)
@@ -403,7 +439,7 @@ func fsDirectoryCache(rootDirectory fs.Directory) directoryCache {
 			cache[input] = res
 
 			// ..and yield it
-			Output <- entry
+			Output <- res
 		}
 	}(Error)
 
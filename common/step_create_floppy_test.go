@@ -15,6 +15,8 @@ import (
 
 	"github.com/hashicorp/packer/helper/multistep"
 	"github.com/hashicorp/packer/packer"
+	"github.com/mitchellh/go-fs"
+	"github.com/mitchellh/go-fs/fat"
 )
 
 const TestFixtures = "test-fixtures"
@@ -116,6 +118,84 @@ func TestStepCreateFloppy(t *testing.T) {
 	}
 }
 
+func TestStepCreateFloppy_content(t *testing.T) {
+	state := testStepCreateFloppyState(t)
+	step := &StepCreateFloppy{
+		Content: map[string]string{
+			"ks.cfg":          "text",
+			"scripts/init.sh": "echo init",
+		},
+	}
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if _, ok := state.GetOk("error"); ok {
+		t.Fatal("state should be ok")
+	}
+	defer step.Cleanup(state)
+
+	if len(step.FilesAdded) != 2 {
+		t.Fatalf("expected 2, found %d", len(step.FilesAdded))
+	}
+
+	floppyF, err := os.Open(state.Get("floppy_path").(string))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer floppyF.Close()
+
+	device, err := fs.NewFileDisk(floppyF)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	fatFs, err := fat.New(device)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	rootDir, err := fatFs.RootDir()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	entry := rootDir.Entry("scripts")
+	if entry == nil || !entry.IsDir() {
+		t.Fatal("scripts directory not found")
+	}
+	scriptsDir, err := entry.Dir()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	cases := []struct {
+		Dir     fs.Directory
+		Name    string
+		Content string
+	}{
+		{rootDir, "ks.cfg", "text"},
+		{scriptsDir, "init.sh", "echo init"},
+	}
+
+	for _, tc := range cases {
+		name := tc.Name
+		entry := tc.Dir.Entry(name)
+		if entry == nil {
+			t.Fatalf("file not found: %s", name)
+		}
+		f, err := entry.File()
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		content, err := ioutil.ReadAll(f)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if !strings.HasPrefix(string(content), tc.Content) {
+			t.Fatalf("bad content for %s: %q", name, content)
+		}
+	}
+}
+
 func xxxTestStepCreateFloppy_missing(t *testing.T) {
 	state := testStepCreateFloppyState(t)
 	step := new(StepCreateFloppy)
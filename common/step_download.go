@@ -6,11 +6,14 @@ import (
 	"encoding/hex"
 	"fmt"
 	"log"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
 
+	cleanhttp "github.com/hashicorp/go-cleanhttp"
 	getter "github.com/hashicorp/go-getter"
 	urlhelper "github.com/hashicorp/go-getter/helper/url"
 	"github.com/hashicorp/packer/common/filelock"
@@ -50,6 +53,10 @@ type StepDownload struct {
 	// extension on the URL is used. Otherwise, this will be forced
 	// on the downloaded file for every URL.
 	Extension string
+
+	// Headers are added to the HTTP requests to the host of the URL being
+	// downloaded, for example to pass the API token of an artifact store.
+	Headers map[string]string
 }
 
 func (s *StepDownload) Run(ctx context.Context, state multistep.StateBag) multistep.StepAction {
@@ -64,7 +71,7 @@ func (s *StepDownload) Run(ctx context.Context, state multistep.StateBag) multis
 			state.Put("error", fmt.Errorf("Download cancelled: %v", errs))
 			return multistep.ActionHalt
 		}
-		ui.Say(fmt.Sprintf("Trying %s", source))
+		ui.Say(fmt.Sprintf("Trying %s", redactURL(source)))
 		var err error
 		var dst string
 		if s.Description == "OVF/OVA" && strings.HasSuffix(source, ".ovf") {
@@ -108,6 +115,69 @@ func init() {
 	}
 }
 
+// httpGetters returns the getters with the HTTP ones sending the headers
+// in the requests to host. Other hosts, such as the one of a checksum file
+// or a redirect, don't get the headers. Proxies set with HTTP_PROXY and
+// HTTPS_PROXY are used by every HTTP getter.
+func httpGetters(headers map[string]string, host string) map[string]getter.Getter {
+	if len(headers) == 0 {
+		return getters
+	}
+
+	header := make(http.Header)
+	for k, v := range headers {
+		header.Set(k, v)
+	}
+
+	client := cleanhttp.DefaultClient()
+	client.Transport = &headerTransport{
+		host:   host,
+		header: header,
+		base:   client.Transport,
+	}
+
+	withHeaders := make(map[string]getter.Getter, len(getters))
+	for k, v := range getters {
+		withHeaders[k] = v
+	}
+	withHeaders["http"] = &getter.HttpGetter{Netrc: true, Client: client}
+	withHeaders["https"] = &getter.HttpGetter{Netrc: true, Client: client}
+	return withHeaders
+}
+
+// headerTransport adds the header to the requests to a single host.
+type headerTransport struct {
+	host   string
+	header http.Header
+	base   http.RoundTripper
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if strings.EqualFold(req.URL.Host, t.host) {
+		req = req.Clone(req.Context())
+		if req.Header == nil {
+			req.Header = make(http.Header)
+		}
+		for k, v := range t.header {
+			req.Header[k] = v
+		}
+	}
+	return t.base.RoundTrip(req)
+}
+
+// redactURL hides the password of a URL with embedded credentials so that
+// it can be logged.
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.User == nil {
+		return rawURL
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), "xxxxx")
+	}
+	return u.String()
+}
+
 func (s *StepDownload) download(ctx context.Context, ui packer.Ui, source string) (string, error) {
 	if runtime.GOOS == "windows" {
 		// Check that the user specified a UNC path, and promote it to an smb:// uri.
@@ -157,7 +227,7 @@ func (s *StepDownload) download(ctx context.Context, ui packer.Ui, source string
 	}
 	lockFile := targetPath + ".lock"
 
-	log.Printf("Acquiring lock for: %s (%s)", u.String(), lockFile)
+	log.Printf("Acquiring lock for: %s (%s)", redactURL(u.String()), lockFile)
 	lock := filelock.New(lockFile)
 	lock.Lock()
 	defer lock.Unlock()
@@ -186,7 +256,7 @@ func (s *StepDownload) download(ctx context.Context, ui packer.Ui, source string
 		}
 	}
 
-	ui.Say(fmt.Sprintf("Trying %s", u.String()))
+	ui.Say(fmt.Sprintf("Trying %s", redactURL(u.String())))
 	gc := getter.Client{
 		Ctx:              ctx,
 		Dst:              targetPath,
@@ -194,12 +264,12 @@ func (s *StepDownload) download(ctx context.Context, ui packer.Ui, source string
 		ProgressListener: ui,
 		Pwd:              wd,
 		Dir:              false,
-		Getters:          getters,
+		Getters:          httpGetters(s.Headers, u.Host),
 	}
 
 	switch err := gc.Get(); err.(type) {
 	case nil: // success !
-		ui.Say(fmt.Sprintf("%s => %s", u.String(), targetPath))
+		ui.Say(fmt.Sprintf("%s => %s", redactURL(u.String()), targetPath))
 		return targetPath, nil
 	case *getter.ChecksumError:
 		ui.Say(fmt.Sprintf("Checksum did not match, removing %s", targetPath))
@@ -3,8 +3,9 @@ package common
 import (
 	"context"
 	"fmt"
-
+	"log"
 	"net/http"
+	"os"
 
 	"github.com/hashicorp/packer/common/net"
 	"github.com/hashicorp/packer/helper/common"
@@ -37,6 +38,15 @@ func (s *StepHTTPServer) Run(ctx context.Context, state multistep.StateBag) mult
 		return multistep.ActionContinue
 	}
 
+	// Serving a directory that doesn't exist would only answer 404s, which
+	// installers tend to report as a hang long after booting
+	if info, err := os.Stat(s.HTTPDir); err != nil || !info.IsDir() {
+		err := fmt.Errorf("Error starting HTTP server: http_directory %s is not a directory", s.HTTPDir)
+		state.Put("error", err)
+		ui.Error(err.Error())
+		return multistep.ActionHalt
+	}
+
 	// Find an available TCP port for our HTTP server
 	var httpAddr string
 	var err error
@@ -58,7 +68,7 @@ func (s *StepHTTPServer) Run(ctx context.Context, state multistep.StateBag) mult
 
 	// Start the HTTP server and run it in the background
 	fileServer := http.FileServer(http.Dir(s.HTTPDir))
-	server := &http.Server{Addr: httpAddr, Handler: fileServer}
+	server := &http.Server{Addr: httpAddr, Handler: logRequests(fileServer)}
 	go server.Serve(s.l)
 
 	// Save the address into the state so it can be accessed in the future
@@ -68,6 +78,15 @@ func (s *StepHTTPServer) Run(ctx context.Context, state multistep.StateBag) mult
 	return multistep.ActionContinue
 }
 
+// logRequests logs the requests the guest makes, to help debug installers
+// that can't find their files.
+func logRequests(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log.Printf("HTTP server: %s %s from %s", r.Method, r.URL.Path, r.RemoteAddr)
+		h.ServeHTTP(w, r)
+	})
+}
+
 func SetHTTPPort(port string) error {
 	return common.SetSharedState("port", port, "")
 }
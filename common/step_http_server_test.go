@@ -0,0 +1,73 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/packer/helper/multistep"
+)
+
+func TestStepHTTPServer_Impl(t *testing.T) {
+	var _ multistep.Step = new(StepHTTPServer)
+}
+
+func TestStepHTTPServer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "packer")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "ks.cfg"), []byte("text"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	state := testStepCreateFloppyState(t)
+	step := &StepHTTPServer{
+		HTTPDir:     dir,
+		HTTPPortMin: 8000,
+		HTTPPortMax: 9000,
+	}
+	defer step.Cleanup(state)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionContinue {
+		t.Fatalf("bad action: %#v", action)
+	}
+
+	port := state.Get("http_port").(int)
+	resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/ks.cfg", port))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if string(body) != "text" {
+		t.Fatalf("bad body: %s", body)
+	}
+}
+
+func TestStepHTTPServer_missingDir(t *testing.T) {
+	state := testStepCreateFloppyState(t)
+	step := &StepHTTPServer{
+		HTTPDir:     "i-dont-exist",
+		HTTPPortMin: 8000,
+		HTTPPortMax: 9000,
+	}
+	defer step.Cleanup(state)
+
+	if action := step.Run(context.Background(), state); action != multistep.ActionHalt {
+		t.Fatalf("bad action: %#v", action)
+	}
+	if _, ok := state.GetOk("error"); !ok {
+		t.Fatal("should have error")
+	}
+}
@@ -210,7 +210,7 @@ func (c *Config) Prepare(ctx *interpolate.Context) []error {
 		if es := c.prepareWinRM(ctx); len(es) > 0 {
 			errs = append(errs, es...)
 		}
-	case "docker", "dockerWindowsContainer", "none":
+	case "docker", "dockerWindowsContainer", "none", "vmrun":
 		break
 	default:
 		return []error{fmt.Errorf("Communicator type %s is invalid", c.Type)}
@@ -24,6 +24,7 @@ type rawTemplate struct {
 	Description string `json:"description,omitempty"`
 
 	Builders           []interface{}          `mapstructure:"builders" json:"builders,omitempty"`
+	BuilderBases       map[string]interface{} `mapstructure:"builder_bases" json:"builder_bases,omitempty"`
 	Comments           []map[string]string    `json:"comments,omitempty"`
 	Push               map[string]interface{} `json:"push,omitempty"`
 	PostProcessors     []interface{}          `mapstructure:"post-processors" json:"post-processors,omitempty"`
@@ -106,6 +107,12 @@ func (r *rawTemplate) Template() (*Template, error) {
 		result.Variables[k] = &v
 	}
 
+	// Gather the bases that builders share configuration through
+	bases, err := r.builderBases()
+	if err != nil {
+		errs = multierror.Append(errs, err)
+	}
+
 	// Let's start by gathering all the builders
 	if len(r.Builders) > 0 {
 		result.Builders = make(map[string]*Builder, len(r.Builders))
@@ -124,6 +131,31 @@ func (r *rawTemplate) Template() (*Template, error) {
 		delete(b.Config, "name")
 		delete(b.Config, "type")
 
+		// Merge the configuration of the bases, in order, under the
+		// configuration of the builder itself
+		if rawBase, ok := b.Config["base"]; ok {
+			delete(b.Config, "base")
+
+			var names []string
+			if err := mapstructure.WeakDecode(rawBase, &names); err != nil {
+				errs = multierror.Append(errs, fmt.Errorf(
+					"builder %d: base: %s", i+1, err))
+				continue
+			}
+
+			config := make(map[string]interface{})
+			for _, name := range names {
+				base, ok := bases[name]
+				if !ok {
+					errs = multierror.Append(errs, fmt.Errorf(
+						"builder %d: unknown base '%s'", i+1, name))
+					continue
+				}
+				config = mergeConfig(config, base)
+			}
+			b.Config = mergeConfig(config, b.Config)
+		}
+
 		if len(b.Config) == 0 {
 			b.Config = nil
 		}
@@ -278,6 +310,55 @@ func (r *rawTemplate) decoder(
 	return d
 }
 
+// builderBases returns the configurations of builder_bases by name.
+func (r *rawTemplate) builderBases() (map[string]map[string]interface{}, error) {
+	var errs error
+	bases := make(map[string]map[string]interface{}, len(r.BuilderBases))
+	for name, raw := range r.BuilderBases {
+		base, ok := raw.(map[string]interface{})
+		if !ok {
+			errs = multierror.Append(errs, fmt.Errorf(
+				"builder base '%s': must be an object", name))
+			continue
+		}
+
+		// Bases share configuration, while these keys set what a builder is
+		for _, key := range []string{"base", "name", "type"} {
+			if _, ok := base[key]; ok {
+				errs = multierror.Append(errs, fmt.Errorf(
+					"builder base '%s': '%s' can't be set in a base", name, key))
+			}
+		}
+
+		bases[name] = base
+	}
+
+	return bases, errs
+}
+
+// mergeConfig returns a copy of dst with the keys of src set on it. Objects
+// are merged key by key, while src replaces any other value, lists
+// included.
+func mergeConfig(dst, src map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(dst)+len(src))
+	for k, v := range dst {
+		if m, ok := v.(map[string]interface{}); ok {
+			v = mergeConfig(nil, m)
+		}
+		result[k] = v
+	}
+
+	for k, v := range src {
+		if m, ok := v.(map[string]interface{}); ok {
+			existing, _ := result[k].(map[string]interface{})
+			v = mergeConfig(existing, m)
+		}
+		result[k] = v
+	}
+
+	return result
+}
+
 func (r *rawTemplate) parsePostProcessor(
 	i int, raw interface{}) ([]map[string]interface{}, error) {
 	switch v := raw.(type) {
@@ -64,6 +64,50 @@ func TestParse(t *testing.T) {
 			nil,
 			true,
 		},
+		{
+			"parse-builder-base.json",
+			&Template{
+				Builders: map[string]*Builder{
+					"something": {
+						Name: "something",
+						Type: "something",
+						Config: map[string]interface{}{
+							"ssh_username": "packer",
+							"tags": map[string]interface{}{
+								"team": "infra",
+								"os":   "ubuntu",
+							},
+						},
+					},
+					"other": {
+						Name: "other",
+						Type: "something",
+						Config: map[string]interface{}{
+							"ssh_username": "root",
+							"tags": map[string]interface{}{
+								"team": "infra",
+								"os":   "linux",
+							},
+							"vmx_data": map[string]interface{}{
+								"memsize":  "4096",
+								"numvcpus": "2",
+							},
+						},
+					},
+				},
+			},
+			false,
+		},
+		{
+			"parse-builder-base-unknown.json",
+			nil,
+			true,
+		},
+		{
+			"parse-builder-base-type.json",
+			nil,
+			true,
+		},
 
 		/*
 		 * Provisioners